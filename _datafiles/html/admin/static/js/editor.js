@@ -0,0 +1,299 @@
+//
+// Editor forms for the admin pages.
+//
+// A form with a data-api attribute is saved to the json api at that url (see internal/web/api.go).
+// Existing things are sent as a json merge patch, so anything the form doesn't show is left alone.
+// Set data-method="POST" on the form to create something new instead.
+//
+// Inputs are named after the fields they edit:
+//   name="Title"                        {"Title": "..."}
+//   name="ZoneConfig.MobAutoScale.Minimum"  nested fields
+//   name="SpawnInfo[0].MobId"           a list of objects (lists are always sent whole)
+//   name="BuffIds[]"                    a list of values. Checkboxes are only added when checked.
+//   name="Mutators[].MutatorId"         a list of objects, one for each checked box
+//
+// data-type on an input says what to send:
+//   int, float    numbers (empty is 0)
+//   bool          true/false from a checkbox
+//   csv           a list of strings from comma separated text
+//   lines         a list of strings, one per line
+//   list          an empty list, put before the rows of a list so removing every row still clears it
+//
+// Maps whose keys can be changed have an input with data-key="Exits.north" that holds the key.
+// Changing it renames the entry, and emptying it removes the entry.
+//
+// Anything inside [data-editor-row] can be removed with a [data-editor-remove] button, and
+// a [data-editor-add="templateId"] button adds a copy of <template id="templateId"> to its [data-editor-rows].
+// __KEY__ in a template is replaced with a unique key, so each copy gets its own names.
+//
+
+function editorPath(name) {
+    const path = [];
+    name.split('.').forEach(part => {
+        const match = part.match(/^([^\[]*)((?:\[\d*\])*)$/);
+        if ( !match ) {
+            path.push(part);
+            return;
+        }
+        if ( match[1] !== '' ) {
+            path.push(match[1]);
+        }
+        (match[2].match(/\[\d*\]/g) || []).forEach(idx => {
+            // null means "add to the end"
+            path.push(idx === '[]' ? null : parseInt(idx.slice(1, -1), 10));
+        });
+    });
+    return path;
+}
+
+function editorValue(el) {
+
+    const type = el.dataset.type || 'string';
+
+    if ( el.tagName === 'SELECT' && el.multiple ) {
+        return Array.from(el.selectedOptions).map(opt => editorConvert(opt.value, type));
+    }
+
+    if ( type === 'bool' ) {
+        return el.checked;
+    }
+
+    if ( type === 'list' ) {
+        return [];
+    }
+
+    return editorConvert(el.value, type);
+}
+
+function editorConvert(value, type) {
+    switch (type) {
+        case 'int':
+            return value.trim() === '' ? 0 : parseInt(value, 10);
+        case 'float':
+            return value.trim() === '' ? 0 : parseFloat(value);
+        case 'csv':
+            return value.split(',').map(s => s.trim()).filter(s => s !== '');
+        case 'lines':
+            return value.split('\n').map(s => s.trim()).filter(s => s !== '');
+    }
+    return value;
+}
+
+function editorSet(root, path, value, add) {
+
+    let node = root;
+    for (let i = 0; i < path.length - 1; i++) {
+        const key = path[i] === null ? node.length : path[i];
+        if ( node[key] === undefined || node[key] === null ) {
+            node[key] = typeof path[i + 1] === 'string' ? {} : [];
+        }
+        node = node[key];
+    }
+
+    const last = path[path.length - 1];
+    if ( last === null ) {
+        if ( add ) {
+            node.push(value);
+        }
+        return;
+    }
+
+    // A list marker doesn't wipe out rows already read in
+    if ( Array.isArray(value) && value.length === 0 && Array.isArray(node[last]) ) {
+        return;
+    }
+
+    node[last] = value;
+}
+
+function editorGet(root, path) {
+    let node = root;
+    for (const key of path) {
+        if ( node === undefined || node === null ) {
+            return undefined;
+        }
+        node = node[key];
+    }
+    return node;
+}
+
+// Lists built from numbered rows can have gaps where rows were removed
+function editorCompact(value) {
+    if ( Array.isArray(value) ) {
+        return value.filter(v => v !== undefined).map(editorCompact);
+    }
+    if ( value !== null && typeof value === 'object' ) {
+        Object.keys(value).forEach(key => value[key] = editorCompact(value[key]));
+    }
+    return value;
+}
+
+function editorPatch(form) {
+
+    const patch = {};
+
+    form.querySelectorAll('[name]').forEach(el => {
+
+        if ( el.disabled || el.closest('template') ) {
+            return;
+        }
+
+        const path = editorPath(el.name);
+        const listItem = path.includes(null);
+
+        // Checked boxes add their value to a list, like BuffIds[] or Mutators[].MutatorId
+        if ( el.type === 'checkbox' && listItem ) {
+            if ( el.checked ) {
+                editorSet(patch, path, editorConvert(el.value, el.dataset.type || 'string'), true);
+            }
+            return;
+        }
+
+        editorSet(patch, path, editorValue(el), true);
+    });
+
+    form.querySelectorAll('[data-key]').forEach(el => {
+
+        if ( el.closest('template') ) {
+            return;
+        }
+
+        const path = editorPath(el.dataset.key);
+        const oldKey = path.pop();
+        const newKey = el.value.trim();
+
+        if ( newKey === oldKey ) {
+            return;
+        }
+
+        const parent = editorGet(patch, path);
+        if ( parent === undefined ) {
+            return;
+        }
+
+        const entry = parent[oldKey];
+        parent[oldKey] = null;
+        if ( newKey !== '' ) {
+            parent[newKey] = entry === undefined ? {} : entry;
+        }
+    });
+
+    return editorCompact(patch);
+}
+
+// New things have nothing to remove, and null would add empty entries
+function editorStripNulls(value) {
+    if ( value !== null && typeof value === 'object' && !Array.isArray(value) ) {
+        Object.keys(value).forEach(key => {
+            if ( value[key] === null ) {
+                delete value[key];
+            } else {
+                editorStripNulls(value[key]);
+            }
+        });
+    }
+    return value;
+}
+
+function editorStatus(form, level, message) {
+    let status = form.querySelector('.editor-status');
+    if ( !status ) {
+        status = document.createElement('div');
+        form.appendChild(status);
+    }
+    status.className = 'editor-status alert alert-' + level + ' mt-3';
+    status.textContent = message;
+}
+
+function editorRequest(form, method, url, body) {
+
+    const options = { method: method, headers: {} };
+    if ( body !== undefined ) {
+        options.headers['Content-Type'] = 'application/json';
+        options.body = JSON.stringify(body);
+    }
+
+    return fetch(url, options).then(resp => {
+        if ( resp.status === 204 ) {
+            return { ok: true, data: null, location: null };
+        }
+        return resp.json().then(data => ({ ok: resp.ok, data: data, location: resp.headers.get('Location') }));
+    }).then(result => {
+        if ( !result.ok ) {
+            editorStatus(form, 'danger', 'Not saved: ' + result.data.error);
+        }
+        return result;
+    }).catch(err => {
+        editorStatus(form, 'danger', 'Not saved: ' + err);
+        return { ok: false };
+    });
+}
+
+$(document).on('submit', 'form[data-api]', function(event) {
+    event.preventDefault();
+
+    const form = this;
+    const method = form.dataset.method || 'PATCH';
+
+    let body = editorPatch(form);
+    if ( method === 'POST' ) {
+        body = editorStripNulls(body);
+    }
+
+    editorRequest(form, method, form.dataset.api, body).then(result => {
+        if ( !result.ok ) {
+            return;
+        }
+        if ( method === 'POST' ) {
+            editorStatus(form, 'success', 'Created ' + result.location.replace('/api/v1/', '') + '. Reload the page to see it in the list.');
+            return;
+        }
+        editorStatus(form, 'success', 'Saved.');
+        $(form).trigger('editor:saved', [result.data]);
+    });
+});
+
+$(document).on('click', 'form[data-api] [data-editor-delete]', function(event) {
+    event.preventDefault();
+
+    const form = this.closest('form');
+    if ( !confirm('Delete this for good?') ) {
+        return;
+    }
+
+    editorRequest(form, 'DELETE', form.dataset.api).then(result => {
+        if ( result.ok ) {
+            form.querySelectorAll('input, select, textarea, button').forEach(el => el.disabled = true);
+            editorStatus(form, 'success', 'Deleted.');
+        }
+    });
+});
+
+$(document).on('click', '[data-editor-remove]', function(event) {
+    event.preventDefault();
+
+    const row = this.closest('[data-editor-row]');
+    const keyInput = row.querySelector('[data-key]');
+
+    // Map entries stay in the form with an empty key, so the save knows to remove them
+    if ( keyInput ) {
+        keyInput.value = '';
+        row.style.display = 'none';
+        return;
+    }
+
+    row.remove();
+});
+
+let editorNewKeyCount = 0;
+
+$(document).on('click', '[data-editor-add]', function(event) {
+    event.preventDefault();
+
+    const template = document.getElementById(this.dataset.editorAdd);
+    const rows = this.closest('form').querySelector('[data-editor-rows="' + this.dataset.editorAdd + '"]');
+
+    editorNewKeyCount++;
+    const html = template.innerHTML.replaceAll('__KEY__', String(1000 + editorNewKeyCount));
+    rows.insertAdjacentHTML('beforeend', html);
+});
@@ -0,0 +1,325 @@
+//
+// Zone map editor for /admin/zones/
+//
+// Draws a zone the way the mapper lays it out (see internal/web/admin.zones.go), one level at a time.
+//   drag a room           moves it, which rewrites the map direction of its exits
+//   shift + drag          from one room to another adds an exit
+//   click a room          loads the room editor below the map
+//   click an exit         removes it
+//
+
+const zoneGraphCell = 64;
+const zoneGraphRoomSize = 36;
+const zoneGraphPad = 40;
+
+const zoneGraphSvg = document.getElementById('zonegraph');
+const zoneGraphLevel = document.getElementById('zonegraph-level');
+const zoneGraphNS = 'http://www.w3.org/2000/svg';
+
+let zoneGraph = null;
+let zoneGraphOrigin = { x: 0, y: 0 };
+let zoneGraphDrag = null;
+
+function zoneGraphStatus(level, message) {
+    const status = document.getElementById('zonegraph-status');
+    if ( message === '' ) {
+        status.innerHTML = '';
+        return;
+    }
+    status.className = 'alert alert-' + level + ' mt-2';
+    status.textContent = message;
+}
+
+function zoneGraphRequest(method, url, body) {
+
+    const options = { method: method, headers: {} };
+    if ( body !== undefined ) {
+        options.headers['Content-Type'] = 'application/json';
+        options.body = JSON.stringify(body);
+    }
+
+    return fetch(url, options).then(resp => resp.json().then(data => {
+        if ( !resp.ok ) {
+            throw new Error(data.error);
+        }
+        return data;
+    }));
+}
+
+function zoneGraphLoad() {
+    zoneGraphRequest('GET', '/admin/zones/graphdata/?zone=' + encodeURIComponent(zoneGraphSvg.dataset.zone))
+        .then(zoneGraphShow)
+        .catch(err => zoneGraphStatus('danger', err.message));
+}
+
+function zoneGraphShow(graph) {
+
+    zoneGraph = graph;
+
+    const levels = [...new Set(graph.Rooms.map(room => room.Z))].sort((a, b) => b - a);
+    const current = zoneGraphLevel.value === '' ? 0 : parseInt(zoneGraphLevel.value, 10);
+
+    zoneGraphLevel.innerHTML = '';
+    levels.forEach(z => {
+        const opt = document.createElement('option');
+        opt.value = z;
+        opt.textContent = z === 0 ? '0 (root level)' : (z > 0 ? '+' + z : z);
+        zoneGraphLevel.appendChild(opt);
+    });
+    zoneGraphLevel.value = levels.includes(current) ? current : (levels.includes(0) ? 0 : levels[0]);
+
+    zoneGraphDraw();
+}
+
+function zoneGraphPos(x, y) {
+    return {
+        x: (x - zoneGraphOrigin.x) * zoneGraphCell + zoneGraphPad,
+        y: (y - zoneGraphOrigin.y) * zoneGraphCell + zoneGraphPad,
+    };
+}
+
+function zoneGraphEl(name, attrs, parent) {
+    const el = document.createElementNS(zoneGraphNS, name);
+    Object.keys(attrs).forEach(key => el.setAttribute(key, attrs[key]));
+    if ( parent ) {
+        parent.appendChild(el);
+    }
+    return el;
+}
+
+function zoneGraphDraw() {
+
+    const z = parseInt(zoneGraphLevel.value, 10);
+    const levelRooms = zoneGraph.Rooms.filter(room => room.Z === z);
+    const byId = {};
+    zoneGraph.Rooms.forEach(room => byId[room.RoomId] = room);
+
+    zoneGraphSvg.innerHTML = '';
+    if ( levelRooms.length === 0 ) {
+        return;
+    }
+
+    zoneGraphOrigin = {
+        x: Math.min(...levelRooms.map(room => room.X)),
+        y: Math.min(...levelRooms.map(room => room.Y)),
+    };
+    const maxX = Math.max(...levelRooms.map(room => room.X));
+    const maxY = Math.max(...levelRooms.map(room => room.Y));
+
+    zoneGraphSvg.setAttribute('width', (maxX - zoneGraphOrigin.x) * zoneGraphCell + zoneGraphPad * 2);
+    zoneGraphSvg.setAttribute('height', (maxY - zoneGraphOrigin.y) * zoneGraphCell + zoneGraphPad * 2);
+
+    const exitLayer = zoneGraphEl('g', {}, zoneGraphSvg);
+    const roomLayer = zoneGraphEl('g', {}, zoneGraphSvg);
+
+    levelRooms.forEach(room => {
+
+        const from = zoneGraphPos(room.X, room.Y);
+
+        (room.Exits || []).forEach(exitInfo => {
+
+            const target = byId[exitInfo.RoomId];
+            const title = room.RoomId + ' ' + exitInfo.Name + ' -> ' + exitInfo.RoomId + (exitInfo.Zone ? ' (' + exitInfo.Zone + ')' : '') + (exitInfo.MapDirection ? ' [map: ' + exitInfo.MapDirection + ']' : '');
+
+            let to = null;
+            if ( target && target.Z === z && target.Island === room.Island ) {
+                to = zoneGraphPos(target.X, target.Y);
+            }
+
+            // Exits to other zones, levels or islands are drawn as short stubs
+            let stub = null;
+            if ( to === null ) {
+                stub = exitInfo.Zone ? exitInfo.Zone : (target && target.Z !== z ? (target.Z > z ? 'up' : 'down') : '#' + exitInfo.RoomId);
+                to = { x: from.x + zoneGraphRoomSize * 0.9, y: from.y - zoneGraphRoomSize * 0.9 };
+            }
+
+            const group = zoneGraphEl('g', { 'class': 'zonegraph-exit', 'style': 'cursor: pointer' }, exitLayer);
+            zoneGraphEl('title', {}, group).textContent = title;
+            zoneGraphEl('line', {
+                x1: from.x, y1: from.y, x2: to.x, y2: to.y,
+                stroke: exitInfo.Secret ? '#6f42c1' : (exitInfo.Mapped ? '#6c757d' : '#fd7e14'),
+                'stroke-width': 2,
+                'stroke-dasharray': exitInfo.Mapped ? '' : '4 3',
+            }, group);
+            // Wider invisible line so it's easier to click
+            zoneGraphEl('line', { x1: from.x, y1: from.y, x2: to.x, y2: to.y, stroke: 'transparent', 'stroke-width': 10 }, group);
+            if ( stub !== null ) {
+                zoneGraphEl('text', { x: to.x + 2, y: to.y - 2, 'font-size': 10, fill: '#fd7e14' }, group).textContent = stub;
+            }
+
+            group.addEventListener('click', () => zoneGraphRemoveExit(room, exitInfo));
+        });
+    });
+
+    levelRooms.forEach(room => {
+
+        const pos = zoneGraphPos(room.X, room.Y);
+        const group = zoneGraphEl('g', { 'class': 'zonegraph-room', transform: 'translate(' + pos.x + ',' + pos.y + ')', style: 'cursor: move' }, roomLayer);
+
+        zoneGraphEl('title', {}, group).textContent = room.RoomId + ': ' + room.Title;
+        zoneGraphEl('rect', {
+            x: -zoneGraphRoomSize / 2, y: -zoneGraphRoomSize / 2,
+            width: zoneGraphRoomSize, height: zoneGraphRoomSize, rx: 4,
+            fill: room.ZoneRoot ? '#f8d7da' : '#ffffff',
+            stroke: room.ZoneRoot ? '#dc3545' : '#343a40',
+            'stroke-width': 2,
+            'stroke-dasharray': room.Island > 0 ? '4 3' : '',
+        }, group);
+        zoneGraphEl('text', { x: 0, y: -2, 'text-anchor': 'middle', 'font-size': 14 }, group).textContent = room.Symbol;
+        zoneGraphEl('text', { x: 0, y: 13, 'text-anchor': 'middle', 'font-size': 9, fill: '#6c757d' }, group).textContent = room.RoomId;
+
+        group.addEventListener('mousedown', event => zoneGraphStartDrag(event, room, group));
+    });
+}
+
+function zoneGraphPointer(event) {
+    const box = zoneGraphSvg.getBoundingClientRect();
+    return { x: event.clientX - box.left, y: event.clientY - box.top };
+}
+
+function zoneGraphCellAt(point) {
+    return {
+        x: Math.round((point.x - zoneGraphPad) / zoneGraphCell) + zoneGraphOrigin.x,
+        y: Math.round((point.y - zoneGraphPad) / zoneGraphCell) + zoneGraphOrigin.y,
+    };
+}
+
+function zoneGraphStartDrag(event, room, group) {
+    event.preventDefault();
+
+    zoneGraphDrag = {
+        room: room,
+        group: group,
+        connect: event.shiftKey,
+        start: zoneGraphPointer(event),
+        moved: false,
+        line: null,
+    };
+
+    if ( zoneGraphDrag.connect ) {
+        const pos = zoneGraphPos(room.X, room.Y);
+        zoneGraphDrag.line = zoneGraphEl('line', { x1: pos.x, y1: pos.y, x2: pos.x, y2: pos.y, stroke: '#007bff', 'stroke-width': 2 }, zoneGraphSvg);
+    }
+}
+
+document.addEventListener('mousemove', event => {
+
+    if ( zoneGraphDrag === null ) {
+        return;
+    }
+
+    const point = zoneGraphPointer(event);
+    if ( Math.abs(point.x - zoneGraphDrag.start.x) + Math.abs(point.y - zoneGraphDrag.start.y) > 4 ) {
+        zoneGraphDrag.moved = true;
+    }
+
+    if ( zoneGraphDrag.connect ) {
+        zoneGraphDrag.line.setAttribute('x2', point.x);
+        zoneGraphDrag.line.setAttribute('y2', point.y);
+        return;
+    }
+
+    zoneGraphDrag.group.setAttribute('transform', 'translate(' + point.x + ',' + point.y + ')');
+});
+
+document.addEventListener('mouseup', event => {
+
+    if ( zoneGraphDrag === null ) {
+        return;
+    }
+
+    const drag = zoneGraphDrag;
+    zoneGraphDrag = null;
+
+    if ( !drag.moved ) {
+        if ( drag.line ) {
+            drag.line.remove();
+        }
+        htmx.ajax('GET', '/admin/rooms/roomdata/?roomid=' + drag.room.RoomId, '#roomdata-edit');
+        return;
+    }
+
+    const cell = zoneGraphCellAt(zoneGraphPointer(event));
+
+    if ( drag.connect ) {
+        drag.line.remove();
+
+        const z = parseInt(zoneGraphLevel.value, 10);
+        const target = zoneGraph.Rooms.find(room => room.Z === z && room.Island === drag.room.Island && room.X === cell.x && room.Y === cell.y);
+        if ( !target || target.RoomId === drag.room.RoomId ) {
+            zoneGraphDraw();
+            return;
+        }
+
+        zoneGraphConnect(drag.room, target);
+        return;
+    }
+
+    if ( cell.x === drag.room.X && cell.y === drag.room.Y ) {
+        zoneGraphDraw();
+        return;
+    }
+
+    zoneGraphRequest('POST', '/admin/zones/graph/move', {
+        Zone: zoneGraph.Zone,
+        RoomId: drag.room.RoomId,
+        X: cell.x,
+        Y: cell.y,
+    }).then(graph => {
+        zoneGraphStatus('', '');
+        zoneGraphShow(graph);
+    }).catch(err => {
+        zoneGraphStatus('danger', 'Not moved: ' + err.message);
+        zoneGraphDraw();
+    });
+});
+
+function zoneGraphConnect(from, to) {
+
+    const exitName = prompt('Name of the exit from ' + from.RoomId + ' to ' + to.RoomId + ':');
+    if ( !exitName ) {
+        zoneGraphDraw();
+        return;
+    }
+    const returnExitName = prompt('Name of the exit back from ' + to.RoomId + ' to ' + from.RoomId + ' (leave empty for a one way exit):') || '';
+
+    zoneGraphRequest('POST', '/admin/zones/graph/exit', {
+        Zone: zoneGraph.Zone,
+        FromRoomId: from.RoomId,
+        ToRoomId: to.RoomId,
+        ExitName: exitName.trim(),
+        ReturnExitName: returnExitName.trim(),
+    }).then(graph => {
+        zoneGraphStatus('', '');
+        zoneGraphShow(graph);
+    }).catch(err => {
+        zoneGraphStatus('danger', 'Exit not added: ' + err.message);
+        zoneGraphDraw();
+    });
+}
+
+function zoneGraphRemoveExit(room, exitInfo) {
+
+    if ( !confirm('Remove the "' + exitInfo.Name + '" exit from room ' + room.RoomId + '?') ) {
+        return;
+    }
+
+    const patch = { Exits: {} };
+    patch.Exits[exitInfo.Name] = null;
+
+    zoneGraphRequest('PATCH', '/admin/api/rooms/' + room.RoomId, patch)
+        .then(() => {
+            zoneGraphStatus('', '');
+            zoneGraphLoad();
+        })
+        .catch(err => zoneGraphStatus('danger', 'Exit not removed: ' + err.message));
+}
+
+if ( zoneGraphSvg ) {
+    zoneGraphLevel.addEventListener('change', zoneGraphDraw);
+
+    // Room edits can change exits, so redraw after a save
+    $(document).on('editor:saved', '#roomdata-edit form', zoneGraphLoad);
+
+    zoneGraphLoad();
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/copyover"
+	"github.com/GoMudEngine/GoMud/internal/inputhandlers"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/plugins"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+// copyoverServer saves everything, then execs the server binary again, handing over the telnet connections.
+// The binary can be replaced beforehand to deploy a new build.
+// It only returns if the copyover couldn't happen.
+func copyoverServer() error {
+
+	if !copyover.Supported() {
+		return copyover.ErrUnsupported
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(executable); err != nil {
+		return fmt.Errorf("server binary: %w", err)
+	}
+
+	mudlog.Warn("Copyover", "state", "starting", "executable", executable)
+
+	tplTxt, _ := templates.Process("admincommands/copyover-start", nil)
+	for _, user := range users.GetAllActiveUsers() {
+		connections.SendTo([]byte(templates.AnsiParse(tplTxt, user.ConnectionId())), user.ConnectionId())
+	}
+
+	// Nothing in the world changes from here on, so what is saved is what the next process starts with
+	util.LockMud()
+	defer util.UnlockMud()
+
+	if err := rooms.SaveAllRooms(); err != nil {
+		mudlog.Error("rooms.SaveAllRooms()", "error", err.Error())
+	}
+	users.SaveAllUsers()
+	util.SaveRoundCount(configs.GetFilePathsConfig().DataFiles.String() + `/` + util.RoundCountFilename)
+	plugins.Save()
+
+	state := copyover.NewState()
+	dropped := []connections.ConnectionId{}
+
+	for _, connectionId := range connections.GetAllConnectionIds() {
+
+		connDetails := connections.Get(connectionId)
+		if connDetails == nil {
+			continue
+		}
+
+		username := ``
+		if user := users.GetByConnectionId(connectionId); user != nil {
+			username = user.Username
+		}
+
+		if err := state.AddConnection(connDetails, username, plugins.CopyoverSave(connectionId)); err != nil {
+			mudlog.Warn("Copyover", "connectionId", connectionId, "username", username, "error", err)
+			dropped = append(dropped, connectionId)
+		}
+	}
+
+	// TLS and the web client can't be carried over, and are closed by the exec
+	if len(dropped) > 0 {
+		droppedTxt, _ := templates.Process("admincommands/copyover-dropped", nil)
+		for _, connectionId := range dropped {
+			connections.SendTo([]byte(templates.AnsiParse(droppedTxt, connectionId)), connectionId)
+		}
+	}
+
+	mudlog.Warn("Copyover", "state", "exec", "connections", len(state.Connections), "dropped", len(dropped))
+
+	err = state.Exec(executable)
+
+	if user := users.GetByUserId(state.RequestedBy); user != nil {
+		user.SendText(`<ansi fg="red">Copyover failed:</ansi> ` + err.Error())
+	}
+
+	return err
+}
+
+// resumeCopyover reattaches the connections handed over by the previous process
+func resumeCopyover(state copyover.State, wg *sync.WaitGroup) {
+
+	mudlog.Warn("Copyover", "state", "resuming", "connections", len(state.Connections), "requestedBy", state.RequestedBy, "took", time.Since(state.Started))
+
+	doneTxt, _ := templates.Process("admincommands/copyover-done", nil)
+	droppedTxt, _ := templates.Process("admincommands/copyover-dropped", nil)
+
+	util.LockMud()
+	defer util.UnlockMud()
+
+	for _, saved := range state.Connections {
+
+		conn, err := saved.Conn()
+		if err != nil {
+			mudlog.Error("Copyover", "connectionId", saved.ConnectionId, "error", err)
+			continue
+		}
+
+		connDetails := connections.Restore(saved.ConnectionId, conn, saved.ClientSettings)
+
+		connDetails.AllowInputCompression(saved.InputCompressionAllowed)
+		if saved.Compressed {
+			connDetails.StartCompression()
+		}
+
+		// Anyone who hadn't finished logging in starts over
+		if saved.Username == `` {
+			wg.Add(1)
+			go handleTelnetConnection(connDetails, wg)
+			continue
+		}
+
+		userObject, err := users.LoadUser(saved.Username)
+		if err == nil {
+			userObject, _, err = users.LoginUser(userObject, connDetails.ConnectionId())
+		}
+
+		if err != nil {
+			mudlog.Error("Copyover", "connectionId", saved.ConnectionId, "username", saved.Username, "error", err)
+			connections.SendTo([]byte(templates.AnsiParse(droppedTxt, connDetails.ConnectionId())), connDetails.ConnectionId())
+			connections.Remove(connDetails.ConnectionId())
+			continue
+		}
+
+		plugins.CopyoverRestore(connDetails.ConnectionId(), saved.Plugins)
+
+		connections.SendTo([]byte(templates.AnsiParse(doneTxt, connDetails.ConnectionId())), connDetails.ConnectionId())
+
+		wg.Add(1)
+		go resumeTelnetConnection(connDetails, userObject, wg)
+	}
+}
+
+// resumeTelnetConnection puts a player carried over by a copyover straight back into the game
+func resumeTelnetConnection(connDetails *connections.ConnectionDetails, userObject *users.UserRecord, wg *sync.WaitGroup) {
+	defer func() {
+		wg.Done()
+	}()
+
+	mudlog.Info("Resumed Connection", "connectionID", connDetails.ConnectionId(), "remoteAddr", connDetails.RemoteAddr().String(), "username", userObject.Username)
+
+	connDetails.AddInputHandler("TelnetIACHandler", inputhandlers.TelnetIACHandler)
+	connDetails.AddInputHandler("AnsiHandler", inputhandlers.AnsiHandler)
+	connDetails.AddInputHandler("CleanserInputHandler", inputhandlers.CleanserInputHandler)
+
+	addGameInputHandlers(connDetails, userObject)
+
+	worldManager.SendEnterWorld(userObject.UserId, userObject.Character.RoomId)
+
+	telnetInputLoop(connDetails, map[string]any{}, userObject)
+}
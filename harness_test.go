@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/recordings"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+//
+// A headless harness for testing gameplay end to end.
+// The world is loaded in-process, with no listeners. Players are logged in on
+// virtual connections that capture everything sent to them, commands go through
+// the same input processing as a real player, and turns only pass when a test asks.
+//
+// The game keeps its state in package globals, so the world is loaded once per
+// test binary and shared. Tests shouldn't run in parallel, and should use their own player names.
+//
+
+const (
+	harnessWorld = `_datafiles/world/empty`
+	harnessSeed  = `harness`
+)
+
+var (
+	// Cursor movement and line clearing sent ahead of prompts, which StripANSI leaves in
+	terminalControl = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+	harnessBoot     = sync.Once{}
+	harnessBootErr  error
+	harnessTempDir  string
+	harnessBootedAs string
+)
+
+func TestMain(m *testing.M) {
+	mudlog.SetupLogger(nil, `LOW`, ``, false)
+
+	code := m.Run()
+
+	if harnessTempDir != `` {
+		os.RemoveAll(harnessTempDir)
+	}
+
+	os.Exit(code)
+}
+
+// Harness drives a world loaded for a test
+type Harness struct {
+	t *testing.T
+
+	lock       sync.Mutex
+	events     []events.Event
+	listenerId events.ListenerId
+	players    []*VirtualPlayer
+}
+
+// NewHarness loads the world (a copy, so nothing is saved over the original) the first time it is called,
+// and reseeds the randomness so every test rolls the same way each run.
+func NewHarness(t *testing.T, worldFolder string) *Harness {
+	t.Helper()
+
+	harnessBoot.Do(func() {
+		harnessBootedAs = worldFolder
+		harnessBootErr = bootHarness(worldFolder)
+	})
+	require.NoError(t, harnessBootErr, "loading the world")
+	require.Equal(t, harnessBootedAs, worldFolder, "only one world can be loaded per test binary")
+
+	util.SeedRand(configs.GetConfig().SeedInt())
+
+	h := &Harness{t: t}
+
+	util.LockMud()
+	h.listenerId = events.RegisterListener(nil, h.captureEvent, events.First)
+	util.UnlockMud()
+
+	t.Cleanup(h.close)
+
+	return h
+}
+
+// bootHarness loads a copy of a world with the same steps as the server, short of listening for connections
+func bootHarness(worldFolder string) error {
+
+	var err error
+	if harnessTempDir, err = os.MkdirTemp(``, `gomud-harness-`); err != nil {
+		return err
+	}
+
+	dataFiles := filepath.Join(harnessTempDir, `world`)
+	if err := os.CopyFS(dataFiles, os.DirFS(worldFolder)); err != nil {
+		return err
+	}
+
+	overridesFile := filepath.Join(harnessTempDir, `config-overrides.yaml`)
+	overrides := fmt.Sprintf("FilePaths:\n  DataFiles: %q\nServer:\n  Seed: %q\n", dataFiles, harnessSeed)
+	if err := os.WriteFile(overridesFile, []byte(overrides), 0644); err != nil {
+		return err
+	}
+	os.Setenv(`CONFIG_PATH`, overridesFile)
+
+	if err := configs.ReloadConfig(); err != nil {
+		return err
+	}
+
+	c := configs.GetConfig()
+
+	registerSystems(c)
+	loadWorld(c)
+
+	return nil
+}
+
+// close logs out and deletes the players, so the names are free for the next test, and stops capturing events
+func (h *Harness) close() {
+
+	util.LockMud()
+	defer util.UnlockMud()
+
+	for _, p := range h.players {
+		if users.GetByUserId(p.UserId) == nil {
+			continue
+		}
+		events.AddToQueue(events.PlayerDespawn{
+			UserId:        p.UserId,
+			RoomId:        p.Character.RoomId,
+			Username:      p.Username,
+			CharacterName: p.Character.Name,
+		})
+	}
+	worldManager.EventLoop()
+
+	idx := users.NewUserIndex()
+	for _, p := range h.players {
+		idx.RemoveByUsername(p.Username)
+		os.Remove(util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, `users`, `/`, strconv.Itoa(p.UserId)+`.yaml`))
+	}
+
+	events.UnregisterListener(nil, h.listenerId)
+}
+
+func (h *Harness) captureEvent(e events.Event) events.ListenerReturn {
+	h.lock.Lock()
+	h.events = append(h.events, e)
+	h.lock.Unlock()
+	return events.Continue
+}
+
+// Events returns every event handled since the harness started, or since ClearEvents
+func (h *Harness) Events() []events.Event {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]events.Event{}, h.events...)
+}
+
+func (h *Harness) ClearEvents() {
+	h.lock.Lock()
+	h.events = h.events[:0]
+	h.lock.Unlock()
+}
+
+// EventsOf returns the captured events of one type, such as EventsOf[events.MobDeath](h)
+func EventsOf[T events.Event](h *Harness) []T {
+	found := []T{}
+	for _, e := range h.Events() {
+		if evt, ok := e.(T); ok {
+			found = append(found, evt)
+		}
+	}
+	return found
+}
+
+// Flush handles everything waiting in the event queue, without letting any time pass
+func (h *Harness) Flush() {
+	util.LockMud()
+	worldManager.EventLoop()
+	util.UnlockMud()
+}
+
+// Turns lets some turns pass, handling the events of each before the next
+func (h *Harness) Turns(ct int) {
+	for i := 0; i < ct; i++ {
+		util.LockMud()
+		worldManager.advanceTurn()
+		worldManager.EventLoop()
+		util.UnlockMud()
+	}
+}
+
+// Rounds lets turns pass until some rounds have started
+func (h *Harness) Rounds(ct int) {
+	target := util.GetRoundCount() + uint64(ct)
+	for util.GetRoundCount() < target {
+		h.Turns(1)
+	}
+}
+
+// RoundsUntil lets rounds pass until done returns true, failing the test if it doesn't within maxRounds
+func (h *Harness) RoundsUntil(maxRounds int, done func() bool) {
+	h.t.Helper()
+
+	for i := 0; i < maxRounds; i++ {
+		if done() {
+			return
+		}
+		h.Rounds(1)
+	}
+
+	require.True(h.t, done(), "still waiting after %d rounds", maxRounds)
+}
+
+// VirtualPlayer is a logged in character on a connection that only exists in memory
+type VirtualPlayer struct {
+	*users.UserRecord
+
+	h    *Harness
+	conn *connections.VirtualConn
+}
+
+// NewPlayer creates a new character and brings them into the world in a room
+func (h *Harness) NewPlayer(name string, roomId int) *VirtualPlayer {
+	h.t.Helper()
+
+	conn := &connections.VirtualConn{}
+
+	util.LockMud()
+	user, err := h.createPlayer(conn, name, roomId)
+	util.UnlockMud()
+
+	require.NoError(h.t, err)
+
+	p := &VirtualPlayer{
+		UserRecord: user,
+		h:          h,
+		conn:       conn,
+	}
+	h.players = append(h.players, p)
+
+	h.Flush()
+
+	return p
+}
+
+// createPlayer expects the mud lock to already be held
+func (h *Harness) createPlayer(conn *connections.VirtualConn, name string, roomId int) (*users.UserRecord, error) {
+
+	connDetails := connections.Add(conn, nil)
+	connDetails.SetState(connections.LoggedIn)
+
+	user := users.NewUserRecord(0, connDetails.ConnectionId())
+	if err := user.SetUsername(name); err != nil {
+		connections.Remove(connDetails.ConnectionId())
+		return nil, err
+	}
+	if err := user.SetPassword(`password`); err != nil {
+		connections.Remove(connDetails.ConnectionId())
+		return nil, err
+	}
+
+	user.Character.Name = name
+	user.Character.RaceId = 1
+	user.Character.RoomId = roomId
+	user.Character.Validate()
+
+	if err := users.CreateUser(user); err != nil {
+		connections.Remove(connDetails.ConnectionId())
+		return nil, err
+	}
+
+	worldManager.enterWorld(user.UserId, roomId)
+
+	return user, nil
+}
+
+// Do runs a command as if the player typed it, and handles whatever it causes
+func (p *VirtualPlayer) Do(command string) {
+	util.LockMud()
+	worldManager.processInput(p.UserId, command, events.EventFlag(0))
+	worldManager.EventLoop()
+	util.UnlockMud()
+}
+
+// Output is everything sent to the player so far, as plain text
+func (p *VirtualPlayer) Output() string {
+	out := recordings.StripTelnet(p.conn.Written())
+	return strings.ReplaceAll(terminalControl.ReplaceAllString(string(out), ``), "\r\n", "\n")
+}
+
+func (p *VirtualPlayer) ClearOutput() {
+	p.conn.Reset()
+}
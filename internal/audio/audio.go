@@ -10,9 +10,22 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	MusicTag = `music` // Music is always tagged this, so it can be stopped by tag like any sound
+
+	// How long zone music fades between tracks, unless the track sets its own fade.
+	// Only clients that support GMCP Client.Media can fade.
+	ZoneCrossfadeMs = 3000
+)
+
 type AudioConfig struct {
 	FilePath string `yaml:"filepath,omitempty"`
 	Volume   int    `yaml:"volume,omitempty"`
+	Tag      string `yaml:"tag,omitempty"`      // Groups sounds so they can be stopped together. Defaults to the sound category.
+	Priority int    `yaml:"priority,omitempty"` // 1-100, a sound stops any lower priority sounds playing
+	Loops    int    `yaml:"loops,omitempty"`    // How many times to play it, -1 to repeat forever
+	FadeMs   int    `yaml:"fade,omitempty"`     // Milliseconds to fade in and out
+	Preload  bool   `yaml:"preload,omitempty"`  // Clients that support it download the file when they log in
 }
 
 var (
@@ -26,6 +39,17 @@ func GetFile(identifier string) AudioConfig {
 	return AudioConfig{}
 }
 
+// GetPreloads returns every file that should be downloaded ahead of time, keyed by identifier
+func GetPreloads() map[string]AudioConfig {
+	preloads := map[string]AudioConfig{}
+	for identifier, f := range audioLookup {
+		if f.Preload && f.FilePath != `` {
+			preloads[identifier] = f
+		}
+	}
+	return preloads
+}
+
 func LoadAudioConfig() {
 
 	start := time.Now()
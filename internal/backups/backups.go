@@ -0,0 +1,554 @@
+package backups
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// Snapshots of everything the game saves while running (users, alts, room instances, plugin data
+// and the round count), each a .tar.gz in the "backups" folder of DataFiles.
+//
+// Records are read through the storage package, so the same snapshot can be restored into
+// either backend. Inside the archive each record is a file named {collection}/{key}.
+//
+// Anything still in memory should be saved before Create() is called.
+//
+
+const (
+	folderName      = `backups`
+	namePrefix      = `backup-`
+	nameTimeFormat  = `20060102-150405`
+	fileExt         = `.tar.gz`
+	roundCountEntry = `roundcount`
+	Latest          = `latest` // May be used in place of a snapshot name
+)
+
+var (
+	ErrNoSnapshots      = errors.New(`there are no snapshots`)
+	ErrSnapshotNotFound = errors.New(`snapshot not found`)
+	ErrUserNotFound     = errors.New(`user not found in snapshot`)
+)
+
+type Snapshot struct {
+	Name    string // Such as backup-20261017-150405
+	Created time.Time
+	Size    int64
+}
+
+func (s Snapshot) path() string {
+	return filepath.Join(folderPath(), s.Name+fileExt)
+}
+
+// The contents of a snapshot
+type contents struct {
+	records    map[string]map[string][]byte // collection => key => data
+	roundCount []byte
+}
+
+func folderPath() string {
+	return util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, folderName)
+}
+
+func roundCountPath() string {
+	return util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, util.RoundCountFilename)
+}
+
+// The collections a snapshot covers
+func backedUp(collection string) bool {
+	switch collection {
+	case storage.Users, storage.Alts, storage.RoomInstances:
+		return true
+	}
+	return strings.HasPrefix(collection, storage.PluginData+`/`)
+}
+
+// Entries are {collection}/{key}. Plugin collections have a slash in them, and room instance keys do too.
+func splitEntry(name string) (collection string, key string, ok bool) {
+	if strings.HasPrefix(name, storage.PluginData+`/`) {
+		rest := strings.TrimPrefix(name, storage.PluginData+`/`)
+		folder, key, ok := strings.Cut(rest, `/`)
+		return storage.PluginData + `/` + folder, key, ok
+	}
+	return strings.Cut(name, `/`)
+}
+
+// Create writes a snapshot of the active store and the round count, then prunes old snapshots
+func Create() (Snapshot, error) {
+
+	snap, err := create(storage.Active(), util.Now())
+	if err != nil {
+		return snap, err
+	}
+
+	_, err = Prune()
+
+	return snap, err
+}
+
+func create(s storage.Store, now time.Time) (Snapshot, error) {
+
+	if err := os.MkdirAll(folderPath(), 0755); err != nil {
+		return Snapshot{}, err
+	}
+
+	snap := Snapshot{
+		Name:    namePrefix + now.Format(nameTimeFormat),
+		Created: now,
+	}
+
+	// More than one a second
+	for i := 2; ; i++ {
+		if _, err := os.Stat(snap.path()); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		snap.Name = namePrefix + now.Format(nameTimeFormat) + `-` + strconv.Itoa(i)
+	}
+
+	collections, err := s.Collections()
+	if err != nil {
+		return snap, err
+	}
+
+	// Written to a temporary file so a partial snapshot is never listed
+	tmpPath := snap.path() + `.new`
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return snap, err
+	}
+
+	err = func() error {
+
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+
+		addEntry := func(name string, data []byte) error {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: now}); err != nil {
+				return err
+			}
+			_, err := tw.Write(data)
+			return err
+		}
+
+		for _, collection := range collections {
+
+			if !backedUp(collection) {
+				continue
+			}
+
+			keys, err := s.Keys(collection)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				data, err := s.Get(collection, key)
+				if err != nil {
+					return fmt.Errorf(`%s/%s: %w`, collection, key, err)
+				}
+				if err := addEntry(collection+`/`+key, data); err != nil {
+					return err
+				}
+			}
+		}
+
+		if data, err := os.ReadFile(roundCountPath()); err == nil {
+			if err := addEntry(roundCountEntry, data); err != nil {
+				return err
+			}
+		}
+
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		return f.Sync()
+	}()
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return snap, err
+	}
+
+	if err := os.Rename(tmpPath, snap.path()); err != nil {
+		os.Remove(tmpPath)
+		return snap, err
+	}
+
+	if fInfo, err := os.Stat(snap.path()); err == nil {
+		snap.Size = fInfo.Size()
+	}
+
+	return snap, nil
+}
+
+// List returns the snapshots saved, newest first
+func List() ([]Snapshot, error) {
+
+	entries, err := os.ReadDir(folderPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []Snapshot{}
+
+	for _, entry := range entries {
+
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), namePrefix) || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), fileExt)
+
+		timeStr := strings.TrimPrefix(name, namePrefix)
+		if len(timeStr) > len(nameTimeFormat) {
+			timeStr = timeStr[:len(nameTimeFormat)]
+		}
+
+		created, err := time.ParseInLocation(nameTimeFormat, timeStr, time.Local)
+		if err != nil {
+			continue
+		}
+
+		snap := Snapshot{Name: name, Created: created}
+		if fInfo, err := entry.Info(); err == nil {
+			snap.Size = fInfo.Size()
+		}
+
+		snapshots = append(snapshots, snap)
+	}
+
+	slices.SortFunc(snapshots, func(a, b Snapshot) int {
+		if c := b.Created.Compare(a.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(b.Name, a.Name)
+	})
+
+	return snapshots, nil
+}
+
+// Find looks up a snapshot by name (with or without the extension), or Latest
+func Find(name string) (Snapshot, error) {
+
+	snapshots, err := List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if len(snapshots) == 0 {
+		return Snapshot{}, ErrNoSnapshots
+	}
+
+	if strings.EqualFold(name, Latest) {
+		return snapshots[0], nil
+	}
+
+	name = strings.TrimSuffix(filepath.Base(name), fileExt)
+
+	for _, snap := range snapshots {
+		if snap.Name == name || snap.Name == namePrefix+name {
+			return snap, nil
+		}
+	}
+
+	return Snapshot{}, fmt.Errorf(`%w: %s`, ErrSnapshotNotFound, name)
+}
+
+// Prune deletes the snapshots the retention rules don't keep, and returns their names
+func Prune() ([]string, error) {
+
+	snapshots, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	c := configs.GetBackupsConfig()
+
+	removed := []string{}
+	for _, snap := range expired(snapshots, int(c.Keep), int(c.KeepDays), util.Now()) {
+		if err := os.Remove(snap.path()); err != nil {
+			return removed, err
+		}
+		removed = append(removed, snap.Name)
+	}
+
+	return removed, nil
+}
+
+// expired picks the snapshots (newest first) that aren't kept.
+// The newest {keep} are kept, and the newest from each of the last {keepDays} days.
+func expired(snapshots []Snapshot, keep int, keepDays int, now time.Time) []Snapshot {
+
+	oldestDay := now.AddDate(0, 0, -keepDays)
+	keptDays := map[string]struct{}{}
+
+	result := []Snapshot{}
+
+	for i, snap := range snapshots {
+
+		day := snap.Created.Format(`2006-01-02`)
+		_, dayKept := keptDays[day]
+
+		if i < keep {
+			keptDays[day] = struct{}{}
+			continue
+		}
+
+		if !dayKept && snap.Created.After(oldestDay) {
+			keptDays[day] = struct{}{}
+			continue
+		}
+
+		result = append(result, snap)
+	}
+
+	return result
+}
+
+func load(snap Snapshot) (contents, error) {
+
+	c := contents{records: map[string]map[string][]byte{}}
+
+	f, err := os.Open(snap.path())
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return c, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c, err
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return c, err
+		}
+
+		if hdr.Name == roundCountEntry {
+			c.roundCount = data
+			continue
+		}
+
+		collection, key, ok := splitEntry(hdr.Name)
+		if !ok || !backedUp(collection) {
+			continue
+		}
+
+		if c.records[collection] == nil {
+			c.records[collection] = map[string][]byte{}
+		}
+		c.records[collection][key] = data
+	}
+
+	return c, nil
+}
+
+// replace makes the records of a collection whose keys match match the same as in the snapshot
+func replace(tx storage.Tx, collection string, current []string, saved map[string][]byte, match func(key string) bool) (int, error) {
+
+	for _, key := range current {
+		if _, ok := saved[key]; !ok && match(key) {
+			if err := tx.Delete(collection, key); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	ct := 0
+	for _, key := range slices.Sorted(maps.Keys(saved)) {
+		if !match(key) {
+			continue
+		}
+		if err := tx.Put(collection, storage.Record{Key: key, Data: saved[key]}); err != nil {
+			return ct, err
+		}
+		ct++
+	}
+
+	return ct, nil
+}
+
+// RestoreAll puts everything back the way it was when the snapshot was taken.
+// It should only be used while the server isn't running, since anything in memory is saved over it.
+func RestoreAll(name string) (Snapshot, int, error) {
+
+	snap, err := Find(name)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	saved, err := load(snap)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	s := storage.Active()
+
+	currentCollections, err := s.Collections()
+	if err != nil {
+		return snap, 0, err
+	}
+
+	collections := []string{}
+	for _, collection := range currentCollections {
+		if backedUp(collection) {
+			collections = append(collections, collection)
+		}
+	}
+	for collection := range saved.records {
+		if !slices.Contains(collections, collection) {
+			collections = append(collections, collection)
+		}
+	}
+	slices.Sort(collections)
+
+	restored := 0
+
+	err = s.Update(func(tx storage.Tx) error {
+		for _, collection := range collections {
+
+			current, err := s.Keys(collection)
+			if err != nil {
+				return err
+			}
+
+			ct, err := replace(tx, collection, current, saved.records[collection], func(string) bool { return true })
+			if err != nil {
+				return err
+			}
+			restored += ct
+		}
+		return nil
+	})
+
+	if err != nil {
+		return snap, 0, err
+	}
+
+	if saved.roundCount != nil {
+		if err := os.WriteFile(roundCountPath(), saved.roundCount, 0644); err != nil {
+			return snap, restored, err
+		}
+	}
+
+	return snap, restored, nil
+}
+
+// RestoreUser puts back a user and their alts, found by username or UserId. The user must not be online.
+func RestoreUser(name string, usernameOrId string) (Snapshot, int, error) {
+
+	snap, err := Find(name)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	saved, err := load(snap)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	userKey := ``
+	if _, ok := saved.records[storage.Users][usernameOrId]; ok {
+		userKey = usernameOrId
+	} else {
+		for _, key := range slices.Sorted(maps.Keys(saved.records[storage.Users])) {
+			u := struct {
+				Username string `yaml:"username"`
+			}{}
+			if yaml.Unmarshal(saved.records[storage.Users][key], &u) == nil && strings.EqualFold(u.Username, usernameOrId) {
+				userKey = key
+				break
+			}
+		}
+	}
+
+	if userKey == `` {
+		return snap, 0, fmt.Errorf(`%w: %s`, ErrUserNotFound, usernameOrId)
+	}
+
+	userId, _ := strconv.Atoi(userKey)
+
+	err = storage.Update(func(tx storage.Tx) error {
+
+		if err := tx.Put(storage.Users, storage.Record{Key: userKey, Data: saved.records[storage.Users][userKey]}); err != nil {
+			return err
+		}
+
+		if alts, ok := saved.records[storage.Alts][userKey]; ok {
+			return tx.Put(storage.Alts, storage.Record{Key: userKey, Data: alts})
+		}
+		return tx.Delete(storage.Alts, userKey)
+	})
+
+	return snap, userId, err
+}
+
+// RestoreZone puts back the instance data of every room in a zone folder (such as "frostfang/").
+// Rooms in the zone must not be loaded, or they will save over it.
+func RestoreZone(name string, zoneFolder string) (Snapshot, int, error) {
+
+	snap, err := Find(name)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	saved, err := load(snap)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	current, err := storage.Keys(storage.RoomInstances)
+	if err != nil {
+		return snap, 0, err
+	}
+
+	inZone := func(key string) bool {
+		return strings.HasPrefix(key, zoneFolder)
+	}
+
+	restored := 0
+	err = storage.Update(func(tx storage.Tx) error {
+		restored, err = replace(tx, storage.RoomInstances, current, saved.records[storage.RoomInstances], inZone)
+		return err
+	})
+
+	return snap, restored, err
+}
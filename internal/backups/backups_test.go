@@ -0,0 +1,195 @@
+package backups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useDataFiles points everything at an empty folder with a few records saved
+func useDataFiles(t *testing.T) string {
+	t.Helper()
+
+	dir := testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.Storage": storage.BackendYAML,
+		"Backups.Keep":      100,
+		"Backups.KeepDays":  0,
+	})
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	require.NoError(t, storage.Put(storage.Users,
+		storage.Record{Key: `1`, Data: []byte("username: alice\ngold: 10\n")},
+		storage.Record{Key: `2`, Data: []byte("username: bob\ngold: 20\n")},
+	))
+	require.NoError(t, storage.Put(storage.Alts, storage.Record{Key: `1`, Data: []byte("- name: alicealt\n")}))
+	require.NoError(t, storage.Put(storage.RoomInstances,
+		storage.Record{Key: `frostfang/1`, Data: []byte("title: Town Square\n")},
+		storage.Record{Key: `catacombs/50`, Data: []byte("title: Crypt\n")},
+	))
+	require.NoError(t, storage.Put(storage.PluginData+`/leaderboards-v1.0`, storage.Record{Key: `latest`, Data: []byte("top: []\n")}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, util.RoundCountFilename), []byte(`1234`), 0644))
+
+	return dir
+}
+
+func get(t *testing.T, collection string, key string) string {
+	t.Helper()
+
+	data, err := storage.Get(collection, key)
+	if err != nil {
+		return ``
+	}
+	return string(data)
+}
+
+func TestCreateAndFind(t *testing.T) {
+
+	useDataFiles(t)
+
+	_, err := Find(Latest)
+	assert.ErrorIs(t, err, ErrNoSnapshots)
+
+	first, err := create(storage.Active(), time.Date(2026, 10, 17, 3, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, `backup-20261017-030000`, first.Name)
+
+	second, err := create(storage.Active(), time.Date(2026, 10, 17, 3, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, `backup-20261017-030000-2`, second.Name)
+
+	snapshots, err := List()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, second.Name, snapshots[0].Name)
+	assert.Greater(t, snapshots[0].Size, int64(0))
+
+	found, err := Find(Latest)
+	require.NoError(t, err)
+	assert.Equal(t, second.Name, found.Name)
+
+	found, err = Find(`20261017-030000.tar.gz`)
+	require.NoError(t, err)
+	assert.Equal(t, first.Name, found.Name)
+
+	_, err = Find(`20261018-030000`)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+}
+
+func TestRestoreAll(t *testing.T) {
+
+	dir := useDataFiles(t)
+
+	_, err := Create()
+	require.NoError(t, err)
+
+	// Things change after the snapshot
+	require.NoError(t, storage.Put(storage.Users,
+		storage.Record{Key: `1`, Data: []byte("username: alice\ngold: 0\n")},
+		storage.Record{Key: `3`, Data: []byte("username: carol\n")},
+	))
+	require.NoError(t, storage.Delete(storage.RoomInstances, `frostfang/1`))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, util.RoundCountFilename), []byte(`9999`), 0644))
+
+	_, restored, err := RestoreAll(Latest)
+	require.NoError(t, err)
+	assert.Equal(t, 6, restored)
+
+	assert.Equal(t, "username: alice\ngold: 10\n", get(t, storage.Users, `1`))
+	assert.Equal(t, ``, get(t, storage.Users, `3`))
+	assert.Equal(t, "title: Town Square\n", get(t, storage.RoomInstances, `frostfang/1`))
+	assert.Equal(t, "top: []\n", get(t, storage.PluginData+`/leaderboards-v1.0`, `latest`))
+
+	roundCount, err := os.ReadFile(filepath.Join(dir, util.RoundCountFilename))
+	require.NoError(t, err)
+	assert.Equal(t, `1234`, string(roundCount))
+}
+
+func TestRestoreUser(t *testing.T) {
+
+	useDataFiles(t)
+
+	_, err := Create()
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Put(storage.Users,
+		storage.Record{Key: `1`, Data: []byte("username: alice\ngold: 0\n")},
+		storage.Record{Key: `2`, Data: []byte("username: bob\ngold: 0\n")},
+	))
+	require.NoError(t, storage.Delete(storage.Alts, `1`))
+
+	_, userId, err := RestoreUser(Latest, `ALICE`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, userId)
+
+	assert.Equal(t, "username: alice\ngold: 10\n", get(t, storage.Users, `1`))
+	assert.Equal(t, "- name: alicealt\n", get(t, storage.Alts, `1`))
+	assert.Equal(t, "username: bob\ngold: 0\n", get(t, storage.Users, `2`), `other users are left alone`)
+
+	_, userId, err = RestoreUser(Latest, `2`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, userId)
+	assert.Equal(t, "username: bob\ngold: 20\n", get(t, storage.Users, `2`))
+
+	_, _, err = RestoreUser(Latest, `nobody`)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestRestoreZone(t *testing.T) {
+
+	useDataFiles(t)
+
+	_, err := Create()
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Put(storage.RoomInstances,
+		storage.Record{Key: `frostfang/1`, Data: []byte("title: Ruins\n")},
+		storage.Record{Key: `frostfang/2`, Data: []byte("title: New\n")},
+		storage.Record{Key: `catacombs/50`, Data: []byte("title: Changed\n")},
+	))
+
+	_, restored, err := RestoreZone(Latest, `frostfang/`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	assert.Equal(t, "title: Town Square\n", get(t, storage.RoomInstances, `frostfang/1`))
+	assert.Equal(t, ``, get(t, storage.RoomInstances, `frostfang/2`))
+	assert.Equal(t, "title: Changed\n", get(t, storage.RoomInstances, `catacombs/50`), `other zones are left alone`)
+}
+
+func TestExpired(t *testing.T) {
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+
+	// Every 6 hours for 5 days, newest first
+	snapshots := []Snapshot{}
+	for i := 0; i < 20; i++ {
+		created := now.Add(time.Duration(-6*i) * time.Hour)
+		snapshots = append(snapshots, Snapshot{Name: created.Format(nameTimeFormat), Created: created})
+	}
+
+	names := func(snaps []Snapshot) []string {
+		result := []string{}
+		for _, s := range snaps {
+			result = append(result, s.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, names(snapshots[3:]), names(expired(snapshots, 3, 0, now)))
+
+	// The newest 3 are all from the 17th, then one is kept from each of the 16th and 15th
+	removed := expired(snapshots, 3, 2, now)
+	assert.Len(t, removed, 15)
+	assert.NotContains(t, names(removed), `20261016-180000`)
+	assert.NotContains(t, names(removed), `20261015-180000`)
+	assert.Contains(t, names(removed), `20261016-120000`)
+	assert.Contains(t, names(removed), `20261014-180000`)
+}
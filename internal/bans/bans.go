@@ -0,0 +1,335 @@
+package bans
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	KindUsername = `username`
+	KindIP       = `ip`
+	KindCIDR     = `cidr`
+)
+
+var (
+	ErrInvalidDuration = errors.New("invalid duration")
+
+	lock      = sync.Mutex{}
+	banList   = []Ban{}
+	banLoaded bool
+)
+
+// Ban keeps a username, an IP address or a range of addresses (CIDR) from connecting
+type Ban struct {
+	Kind    string    `yaml:"kind"`
+	Value   string    `yaml:"value"` // Lowercase username, IP or CIDR
+	Reason  string    `yaml:"reason,omitempty"`
+	By      string    `yaml:"by,omitempty"` // Who added the ban
+	Created time.Time `yaml:"created"`
+	Expires time.Time `yaml:"expires,omitempty"` // Zero for a permanent ban
+}
+
+func (b Ban) Expired(now time.Time) bool {
+	return !b.Expires.IsZero() && !now.Before(b.Expires)
+}
+
+// matchesIP expects ip to already be parsed
+func (b Ban) matchesIP(ip net.IP) bool {
+	switch b.Kind {
+	case KindIP:
+		return ip.Equal(net.ParseIP(b.Value))
+	case KindCIDR:
+		if _, network, err := net.ParseCIDR(b.Value); err == nil {
+			return network.Contains(ip)
+		}
+	}
+	return false
+}
+
+// BanError is returned when a username or address is banned
+type BanError struct {
+	Ban Ban
+}
+
+func (e BanError) Error() string {
+
+	msg := `you are banned from this server`
+	if e.Ban.Reason != `` {
+		msg += `: ` + e.Ban.Reason
+	}
+
+	if !e.Ban.Expires.IsZero() {
+		msg += fmt.Sprintf(` (expires in %s)`, e.Ban.Expires.Sub(util.Now()).Round(time.Second))
+	}
+
+	return msg
+}
+
+// Kind works out what sort of ban a value is: username, IP or CIDR.
+// The value is returned normalized.
+func Kind(value string) (kind string, normalized string, err error) {
+
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if strings.Contains(value, `/`) {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return ``, ``, err
+		}
+		return KindCIDR, network.String(), nil
+	}
+
+	if ip := net.ParseIP(value); ip != nil {
+		return KindIP, ip.String(), nil
+	}
+
+	if value == `` {
+		return ``, ``, errors.New("nothing to ban")
+	}
+
+	return KindUsername, value, nil
+}
+
+// ParseDuration accepts anything time.ParseDuration does, plus days ("7d") and weeks ("2w")
+func ParseDuration(s string) (time.Duration, error) {
+
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	for suffix, unit := range map[string]time.Duration{`d`: 24 * time.Hour, `w`: 7 * 24 * time.Hour} {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.Atoi(num)
+			if err != nil || n < 1 {
+				return 0, ErrInvalidDuration
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, ErrInvalidDuration
+	}
+
+	return d, nil
+}
+
+// AddressIP returns the IP of a remote address, without the port
+func AddressIP(addr net.Addr) string {
+	if addr == nil {
+		return ``
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func banFilePath() string {
+	return util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `bans.yaml`)
+}
+
+// loadBans expects lock to already be held
+func loadBans() {
+
+	if banLoaded {
+		return
+	}
+	banLoaded = true
+
+	banList = []Ban{}
+
+	bytes, err := os.ReadFile(banFilePath())
+	if err != nil {
+		return
+	}
+
+	if err := yaml.Unmarshal(bytes, &banList); err != nil {
+		mudlog.Error("Bans", "error", "Problem unmarshalling bans: "+err.Error())
+	}
+}
+
+// saveBans expects lock to already be held
+func saveBans() {
+
+	data, err := yaml.Marshal(banList)
+	if err != nil {
+		mudlog.Error("Bans", "error", "Could not marshal bans: "+err.Error())
+		return
+	}
+
+	if err := util.Save(banFilePath(), data, bool(configs.GetFilePathsConfig().CarefulSaveFiles)); err != nil {
+		mudlog.Error("Bans", "error", "Could not save bans: "+err.Error())
+	}
+}
+
+// pruneBans removes expired bans, and expects lock to already be held
+func pruneBans() {
+
+	now := util.Now()
+
+	before := len(banList)
+	banList = slices.DeleteFunc(banList, func(b Ban) bool { return b.Expired(now) })
+
+	if len(banList) != before {
+		saveBans()
+	}
+}
+
+// Add bans a username, IP or CIDR, replacing any existing ban of the same value.
+// A duration of zero never expires.
+func Add(value string, duration time.Duration, reason string, by string) (Ban, error) {
+
+	kind, value, err := Kind(value)
+	if err != nil {
+		return Ban{}, err
+	}
+
+	ban := Ban{
+		Kind:    kind,
+		Value:   value,
+		Reason:  strings.TrimSpace(reason),
+		By:      by,
+		Created: util.Now(),
+	}
+
+	if duration > 0 {
+		ban.Expires = ban.Created.Add(duration)
+	}
+
+	lock.Lock()
+	loadBans()
+	banList = slices.DeleteFunc(banList, func(b Ban) bool { return b.Kind == kind && b.Value == value })
+	banList = append(banList, ban)
+	saveBans()
+	lock.Unlock()
+
+	expires := `never`
+	if !ban.Expires.IsZero() {
+		expires = ban.Expires.Format(time.RFC3339)
+	}
+
+	mudlog.Warn("BAN", "kind", ban.Kind, "value", ban.Value, "reason", ban.Reason, "by", ban.By, "expires", expires)
+
+	events.AddToQueue(events.BanChange{
+		Action: `ban`,
+		Kind:   ban.Kind,
+		Value:  ban.Value,
+		Reason: ban.Reason,
+		By:     ban.By,
+		Until:  ban.Expires,
+	})
+
+	return ban, nil
+}
+
+// Remove lifts a ban, returning false if there wasn't one
+func Remove(value string, by string) bool {
+
+	kind, value, err := Kind(value)
+	if err != nil {
+		return false
+	}
+
+	lock.Lock()
+	loadBans()
+	before := len(banList)
+	banList = slices.DeleteFunc(banList, func(b Ban) bool { return b.Kind == kind && b.Value == value })
+	removed := len(banList) != before
+	if removed {
+		saveBans()
+	}
+	lock.Unlock()
+
+	if removed {
+		mudlog.Warn("UNBAN", "kind", kind, "value", value, "by", by)
+
+		events.AddToQueue(events.BanChange{
+			Action: `unban`,
+			Kind:   kind,
+			Value:  value,
+			By:     by,
+		})
+	}
+
+	return removed
+}
+
+// List returns all bans that haven't expired
+func List() []Ban {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	loadBans()
+	pruneBans()
+
+	return slices.Clone(banList)
+}
+
+// CheckAddress returns a BanError if the IP is banned, directly or by CIDR
+func CheckAddress(ipStr string) error {
+
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return nil
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	loadBans()
+	pruneBans()
+
+	for _, b := range banList {
+		if b.matchesIP(ip) {
+			return BanError{Ban: b}
+		}
+	}
+
+	return nil
+}
+
+// CheckUsername returns a BanError if the username is banned
+func CheckUsername(username string) error {
+
+	username = strings.ToLower(username)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	loadBans()
+	pruneBans()
+
+	for _, b := range banList {
+		if b.Kind == KindUsername && b.Value == username {
+			return BanError{Ban: b}
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether a ban applies to a username or IP
+func (b Ban) Matches(username string, ipStr string) bool {
+	if b.Kind == KindUsername {
+		return b.Value == strings.ToLower(username)
+	}
+	if ip := net.ParseIP(ipStr); ip != nil {
+		return b.matchesIP(ip)
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package bans
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+// useBanDir points the datafiles somewhere temporary, starts from no bans or failures
+// and freezes the clock so it can be moved along by hand.
+func useBanDir(t *testing.T) (string, *util.SimClock) {
+	t.Helper()
+
+	dir := testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.CarefulSaveFiles": false,
+		"Security.MaxLoginFailures":  3,
+		"Security.LockoutSeconds":    30,
+		"Security.MaxLockoutSeconds": 100,
+	})
+
+	clock := testsupport.FreezeClock(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	lock.Lock()
+	banList = []Ban{}
+	banLoaded = false
+	failures = map[string]*loginFailures{}
+	lock.Unlock()
+
+	return dir, clock
+}
+
+// collectBanChanges captures every BanChange event queued from now on
+func collectBanChanges(t *testing.T) func() []events.BanChange {
+	t.Helper()
+
+	// Drop anything left queued by earlier tests
+	events.ProcessEvents()
+
+	changes := []events.BanChange{}
+	id := events.RegisterListener(events.BanChange{}, func(e events.Event) events.ListenerReturn {
+		changes = append(changes, e.(events.BanChange))
+		return events.Continue
+	})
+	t.Cleanup(func() { events.UnregisterListener(events.BanChange{}, id) })
+
+	return func() []events.BanChange {
+		events.ProcessEvents()
+		return changes
+	}
+}
+
+func TestBans(t *testing.T) {
+
+	dir, clock := useBanDir(t)
+
+	_, err := Add(`10.0.0.0/24`, 0, `spam bots`, `admin`)
+	require.NoError(t, err)
+	_, err = Add(`Bob`, time.Hour, `griefing`, `admin`)
+	require.NoError(t, err)
+	_, err = Add(`192.168.1.10`, 0, ``, `admin`)
+	require.NoError(t, err)
+
+	assert.FileExists(t, dir+`/bans.yaml`)
+
+	var banErr BanError
+	require.ErrorAs(t, CheckAddress(`10.0.0.200`), &banErr)
+	assert.Equal(t, `spam bots`, banErr.Ban.Reason)
+	assert.Error(t, CheckAddress(`192.168.1.10`))
+	assert.NoError(t, CheckAddress(`192.168.1.11`))
+	assert.NoError(t, CheckAddress(`10.0.1.1`))
+
+	require.ErrorAs(t, CheckUsername(`bob`), &banErr)
+	assert.Equal(t, `you are banned from this server: griefing (expires in 1h0m0s)`, banErr.Error())
+	assert.NoError(t, CheckUsername(`alice`))
+
+	// Bans survive a restart
+	lock.Lock()
+	banLoaded = false
+	lock.Unlock()
+	assert.Len(t, List(), 3)
+
+	// Temporary bans expire
+	clock.Advance(time.Hour)
+	assert.NoError(t, CheckUsername(`BOB`))
+	assert.Len(t, List(), 2)
+
+	assert.True(t, Remove(`10.0.0.0/24`, `admin`))
+	assert.False(t, Remove(`10.0.0.0/24`, `admin`))
+	assert.NoError(t, CheckAddress(`10.0.0.200`))
+}
+
+func TestLoginLockout(t *testing.T) {
+
+	_, clock := useBanDir(t)
+
+	// Two failures are allowed
+	LoginFailed(`bob`, `10.0.0.5`)
+	LoginFailed(`bob`, `10.0.0.5`)
+	assert.NoError(t, CheckLogin(`bob`, `10.0.0.5`))
+
+	// The third locks out both the account and the address
+	LoginFailed(`bob`, `10.0.0.5`)
+
+	var lockoutErr LockoutError
+	require.ErrorAs(t, CheckLogin(`bob`, `10.0.0.9`), &lockoutErr)
+	assert.Equal(t, clock.Now().Add(30*time.Second), lockoutErr.Until)
+	assert.Error(t, CheckLogin(`alice`, `10.0.0.5`))
+	assert.NoError(t, CheckLogin(`alice`, `10.0.0.9`))
+	assert.Len(t, Lockouts(), 2)
+
+	// Every failure after that doubles it, up to the max
+	clock.Advance(31 * time.Second)
+	assert.NoError(t, CheckLogin(`bob`, `10.0.0.5`))
+
+	LoginFailed(`bob`, ``)
+	require.ErrorAs(t, CheckLogin(`bob`, ``), &lockoutErr)
+	assert.Equal(t, clock.Now().Add(60*time.Second), lockoutErr.Until)
+
+	clock.Advance(61 * time.Second)
+	LoginFailed(`bob`, ``)
+	require.ErrorAs(t, CheckLogin(`bob`, ``), &lockoutErr)
+	assert.Equal(t, clock.Now().Add(100*time.Second), lockoutErr.Until)
+
+	assert.True(t, Unlock(`Bob`))
+	assert.NoError(t, CheckLogin(`bob`, ``))
+
+	// Succeeding forgets earlier failures
+	LoginFailed(`alice`, ``)
+	LoginFailed(`alice`, ``)
+	LoginSucceeded(`alice`, ``)
+	LoginFailed(`alice`, ``)
+	assert.NoError(t, CheckLogin(`alice`, ``))
+
+	// As does waiting long enough
+	LoginFailed(`alice`, ``)
+	clock.Advance(101 * time.Second)
+	LoginFailed(`alice`, ``)
+	assert.NoError(t, CheckLogin(`alice`, ``))
+}
+
+func TestBanChangeEvents(t *testing.T) {
+
+	_, clock := useBanDir(t)
+	changes := collectBanChanges(t)
+
+	_, err := Add(`Bob`, time.Hour, `griefing`, `admin`)
+	require.NoError(t, err)
+	assert.True(t, Remove(`bob`, `admin`))
+
+	LoginFailed(`alice`, ``)
+	LoginFailed(`alice`, ``)
+	LoginFailed(`alice`, ``)
+
+	assert.Equal(t, []events.BanChange{
+		{Action: `ban`, Kind: KindUsername, Value: `bob`, Reason: `griefing`, By: `admin`, Until: clock.Now().Add(time.Hour)},
+		{Action: `unban`, Kind: KindUsername, Value: `bob`, By: `admin`},
+		{Action: `lockout`, Kind: KindUsername, Value: `alice`, Until: clock.Now().Add(30 * time.Second), Failures: 3},
+	}, changes())
+}
@@ -0,0 +1,201 @@
+package bans
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+// Failed logins are tracked in memory only, a restart forgives everyone.
+var (
+	failures = map[string]*loginFailures{} // "username:bob" or "address:127.0.0.1"
+)
+
+type loginFailures struct {
+	Count       int
+	Last        time.Time
+	LockedUntil time.Time
+}
+
+// LockoutError is returned while an account or address is locked out after too many failed logins
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e LockoutError) Error() string {
+	return fmt.Sprintf(`too many failed logins, try again in %s`, e.Until.Sub(util.Now()).Round(time.Second))
+}
+
+// Lockout describes a locked out account or address, for admins
+type Lockout struct {
+	Kind     string // KindUsername or KindIP
+	Value    string
+	Failures int
+	Until    time.Time
+}
+
+func failureKeys(username string, ipStr string) []string {
+	keys := []string{}
+	if username != `` {
+		keys = append(keys, KindUsername+`:`+strings.ToLower(username))
+	}
+	if ipStr != `` {
+		keys = append(keys, KindIP+`:`+ipStr)
+	}
+	return keys
+}
+
+// pruneFailures forgets failures that are old enough, and expects lock to already be held
+func pruneFailures(now time.Time) {
+
+	forgetAfter := time.Duration(configs.GetSecurityConfig().MaxLockoutSeconds) * time.Second
+
+	for key, f := range failures {
+		if now.After(f.LockedUntil) && now.Sub(f.Last) > forgetAfter {
+			delete(failures, key)
+		}
+	}
+}
+
+// CheckLogin returns a LockoutError if the account or the address (either may be empty) is locked out
+func CheckLogin(username string, ipStr string) error {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	now := util.Now()
+	pruneFailures(now)
+
+	until := time.Time{}
+	for _, key := range failureKeys(username, ipStr) {
+		if f, ok := failures[key]; ok && f.LockedUntil.After(until) {
+			until = f.LockedUntil
+		}
+	}
+
+	if until.After(now) {
+		return LockoutError{Until: until}
+	}
+
+	return nil
+}
+
+// LoginFailed counts a failed login against the account and address,
+// locking them out once they pass MaxLoginFailures.
+// Every failure after that doubles the lockout, up to MaxLockoutSeconds.
+func LoginFailed(username string, ipStr string) {
+
+	sec := configs.GetSecurityConfig()
+	if sec.MaxLoginFailures < 1 {
+		return
+	}
+
+	lock.Lock()
+
+	now := util.Now()
+	pruneFailures(now)
+
+	lockedOut := []events.BanChange{}
+
+	for _, key := range failureKeys(username, ipStr) {
+
+		f, ok := failures[key]
+		if !ok {
+			f = &loginFailures{}
+			failures[key] = f
+		}
+
+		f.Count++
+		f.Last = now
+
+		over := f.Count - int(sec.MaxLoginFailures)
+		if over < 0 {
+			continue
+		}
+
+		lockout := time.Duration(sec.LockoutSeconds) * time.Second
+		maxLockout := time.Duration(sec.MaxLockoutSeconds) * time.Second
+		for i := 0; i < over && lockout < maxLockout; i++ {
+			lockout *= 2
+		}
+		lockout = min(lockout, maxLockout)
+
+		f.LockedUntil = now.Add(lockout)
+
+		kind, value, _ := strings.Cut(key, `:`)
+		lockedOut = append(lockedOut, events.BanChange{
+			Action:   `lockout`,
+			Kind:     kind,
+			Value:    value,
+			Until:    f.LockedUntil,
+			Failures: f.Count,
+		})
+	}
+
+	lock.Unlock()
+
+	for _, l := range lockedOut {
+		mudlog.Warn("LOGIN LOCKOUT", "locked", fmt.Sprintf(`%s:%s (%d failures, %s)`, l.Kind, l.Value, l.Failures, l.Until.Sub(now)))
+		events.AddToQueue(l)
+	}
+}
+
+// LoginSucceeded forgets any failures for the account and address
+func LoginSucceeded(username string, ipStr string) {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, key := range failureKeys(username, ipStr) {
+		delete(failures, key)
+	}
+}
+
+// Unlock forgets the failures of a username or IP, returning false if there weren't any
+func Unlock(value string) bool {
+
+	kind, value, err := Kind(value)
+	if err != nil || kind == KindCIDR {
+		return false
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	key := kind + `:` + value
+	if _, ok := failures[key]; !ok {
+		return false
+	}
+
+	delete(failures, key)
+	return true
+}
+
+// Lockouts returns the accounts and addresses currently locked out
+func Lockouts() []Lockout {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	now := util.Now()
+	pruneFailures(now)
+
+	ret := []Lockout{}
+	for key, f := range failures {
+		if !f.LockedUntil.After(now) {
+			continue
+		}
+		kind, value, _ := strings.Cut(key, `:`)
+		ret = append(ret, Lockout{Kind: kind, Value: value, Failures: f.Count, Until: f.LockedUntil})
+	}
+
+	slices.SortFunc(ret, func(a, b Lockout) int { return a.Until.Compare(b.Until) })
+
+	return ret
+}
@@ -0,0 +1,102 @@
+package buffs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GoMudEngine/GoMud/internal/changelog"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/fileloader"
+)
+
+func CreateNewBuffFile(newBuffInfo BuffSpec) (int, error) {
+
+	newBuffInfo.BuffId = getNextBuffId()
+
+	if err := newBuffInfo.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := saveBuffFile(&newBuffInfo); err != nil {
+		return 0, err
+	}
+
+	// Save to in-memory cache
+	buffs[newBuffInfo.Id()] = &newBuffInfo
+
+	return newBuffInfo.Id(), nil
+}
+
+// SaveBuffFile overwrites an existing buff spec.
+// If the name changed, the old file and its script are moved to match.
+func SaveBuffFile(buffInfo BuffSpec) error {
+
+	oldBuffInfo := GetBuffSpec(buffInfo.BuffId)
+	if oldBuffInfo == nil {
+		return fmt.Errorf(`buff %d does not exist`, buffInfo.BuffId)
+	}
+
+	if err := buffInfo.Validate(); err != nil {
+		return err
+	}
+
+	if err := saveBuffFile(&buffInfo); err != nil {
+		return err
+	}
+
+	if oldBuffInfo.Filepath() != buffInfo.Filepath() {
+		if err := fileloader.MoveFile(oldBuffInfo.GetScriptPath(), buffInfo.GetScriptPath()); err != nil {
+			return err
+		}
+		if err := fileloader.DeleteFlatFile[*BuffSpec](string(configs.GetFilePathsConfig().DataFiles)+`/buffs`, oldBuffInfo); err != nil {
+			return err
+		}
+	}
+
+	buffs[buffInfo.Id()] = &buffInfo
+
+	return nil
+}
+
+// DeleteBuffFile removes a buff spec and its script.
+// Anything that still has the buff will have it fall off when it is next checked.
+func DeleteBuffFile(buffId int) error {
+
+	buffInfo := GetBuffSpec(buffId)
+	if buffInfo == nil {
+		return fmt.Errorf(`buff %d does not exist`, buffId)
+	}
+
+	if err := fileloader.DeleteFlatFile[*BuffSpec](string(configs.GetFilePathsConfig().DataFiles)+`/buffs`, buffInfo); err != nil {
+		return err
+	}
+
+	changelog.Touch(buffInfo.GetScriptPath())
+	os.Remove(buffInfo.GetScriptPath())
+
+	delete(buffs, buffInfo.BuffId)
+
+	return nil
+}
+
+func saveBuffFile(buffInfo *BuffSpec) error {
+
+	saveModes := []fileloader.SaveOption{}
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	return fileloader.SaveFlatFile[*BuffSpec](string(configs.GetFilePathsConfig().DataFiles)+`/buffs`, buffInfo, saveModes...)
+}
+
+func getNextBuffId() int {
+
+	lowestFreeId := 1
+	for _, bSpec := range buffs {
+		if bSpec.BuffId >= lowestFreeId {
+			lowestFreeId = bSpec.BuffId + 1
+		}
+	}
+
+	return lowestFreeId
+}
@@ -0,0 +1,564 @@
+package changelog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// A record of every change builders make to the world files (rooms, items, mobs, scripts etc.)
+// Each entry says who made the change, when, with what command, and what each file held before and after.
+//
+// Anything that changes world files calls Begin() first and End() when it's done, and the code that writes
+// the files calls Touch() before writing or removing each one. Only files that end up different are kept,
+// and nothing is saved if nothing changed:
+//
+//	changelog.Begin(user.UserId, user.Username, `room edit`)
+//	defer changelog.End()
+//	...
+//	changelog.Touch(roomFilePath)
+//	os.WriteFile(roomFilePath, data, 0777)
+//
+// Undo() and Redo() put the files back and reload them into the running game. Export() writes entries
+// out as a patch that can be applied to another copy of the datafiles.
+//
+// Changes are made with the MUD locked, so only one change set is open at a time.
+//
+
+const (
+	keyDigits = 8 // Entries are kept in order by padding their keys
+)
+
+var (
+	ErrNothingToUndo = errors.New(`nothing to undo`)
+	ErrNothingToRedo = errors.New(`nothing to redo`)
+	ErrEntryNotFound = errors.New(`change not found`)
+
+	lock      = sync.Mutex{}
+	openSet   *changeSet
+	restoring atomic.Bool // Files written by Undo() and Redo() aren't recorded
+	entries   []Entry     // Oldest first. nil until loaded from storage.
+
+	reloaders       = map[string]Reloader{}
+	defaultReloader Reloader
+)
+
+// Entry is one command's worth of changes
+type Entry struct {
+	EntryId  int
+	UserId   int
+	Username string
+	When     time.Time
+	Command  string
+	Changes  []Change
+	Undone   bool      `yaml:",omitempty"`
+	UndoneAt time.Time `yaml:",omitempty"`
+}
+
+// Change is what one file held before and after
+type Change struct {
+	Path    string // Relative to the DataFiles folder, such as "rooms/frostfang/1.yaml"
+	Before  string `yaml:",omitempty"`
+	After   string `yaml:",omitempty"`
+	Created bool   `yaml:",omitempty"` // There was no file before
+	Deleted bool   `yaml:",omitempty"` // There is no file after
+}
+
+// Folder is the top folder of the file, such as "rooms"
+func (c Change) Folder() string {
+	folder, _, _ := strings.Cut(c.Path, `/`)
+	return folder
+}
+
+// Reloader puts files changed by Undo() or Redo() back into the running game.
+// paths are relative to DataFiles, and say whether the file exists after the change.
+type Reloader struct {
+	Check  func(paths map[string]bool) error // Optional. Called before any files are written, to refuse the change.
+	Reload func(paths map[string]bool) error // Called after the files are written
+}
+
+// RegisterReloader sets how files under a top folder of DataFiles (such as "rooms") are reloaded
+func RegisterReloader(folder string, r Reloader) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	reloaders[folder] = r
+}
+
+// SetDefaultReloader is used for every folder without a Reloader of its own. It's called once per Undo() or Redo().
+func SetDefaultReloader(r Reloader) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	defaultReloader = r
+}
+
+type fileState struct {
+	data   []byte
+	exists bool
+}
+
+func readFileState(relPath string) fileState {
+	data, err := os.ReadFile(absolutePath(relPath))
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{data: data, exists: true}
+}
+
+func (f fileState) equals(other fileState) bool {
+	return f.exists == other.exists && bytes.Equal(f.data, other.data)
+}
+
+type changeSet struct {
+	depth   int
+	entry   Entry
+	touched []string
+	before  map[string]fileState
+}
+
+// Begin starts recording changes. Nested calls are part of the outermost change set.
+func Begin(userId int, username string, command string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if openSet != nil {
+		openSet.depth++
+		return
+	}
+
+	openSet = &changeSet{
+		depth: 1,
+		entry: Entry{
+			UserId:   userId,
+			Username: username,
+			When:     time.Now(),
+			Command:  command,
+		},
+		before: map[string]fileState{},
+	}
+}
+
+// Touch must be called before a file is written, removed or renamed (for both the old and new path).
+// It does nothing unless a change set is open, or if the file isn't in the DataFiles folder.
+func Touch(path string) {
+
+	if restoring.Load() {
+		return
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if openSet == nil {
+		return
+	}
+
+	relPath, ok := relativePath(path)
+	if !ok {
+		return
+	}
+
+	if _, ok := openSet.before[relPath]; ok {
+		return
+	}
+
+	openSet.before[relPath] = readFileState(relPath)
+	openSet.touched = append(openSet.touched, relPath)
+}
+
+// End finishes the change set started by Begin(), and saves it if any files changed
+func End() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if openSet == nil {
+		return
+	}
+
+	openSet.depth--
+	if openSet.depth > 0 {
+		return
+	}
+
+	set := openSet
+	openSet = nil
+
+	for _, relPath := range set.touched {
+
+		before := set.before[relPath]
+		after := readFileState(relPath)
+
+		if before.equals(after) {
+			continue
+		}
+
+		set.entry.Changes = append(set.entry.Changes, Change{
+			Path:    relPath,
+			Before:  string(before.data),
+			After:   string(after.data),
+			Created: !before.exists,
+			Deleted: !after.exists,
+		})
+	}
+
+	if len(set.entry.Changes) == 0 {
+		return
+	}
+
+	if err := loadEntries(); err != nil {
+		mudlog.Error("Changelog", "action", "End", "error", err)
+		return
+	}
+
+	set.entry.EntryId = 1
+	if len(entries) > 0 {
+		set.entry.EntryId = entries[len(entries)-1].EntryId + 1
+	}
+
+	if err := saveEntry(set.entry); err != nil {
+		mudlog.Error("Changelog", "action", "End", "entryId", set.entry.EntryId, "error", err)
+		return
+	}
+
+	entries = append(entries, set.entry)
+
+	mudlog.Info("Changelog", "entryId", set.entry.EntryId, "username", set.entry.Username, "command", set.entry.Command, "files", len(set.entry.Changes))
+}
+
+// Entries returns every entry, oldest first
+func Entries() ([]Entry, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := loadEntries(); err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(entries), nil
+}
+
+func Get(entryId int) (Entry, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := loadEntries(); err != nil {
+		return Entry{}, err
+	}
+
+	if idx := entryIndex(entryId); idx >= 0 {
+		return entries[idx], nil
+	}
+
+	return Entry{}, ErrEntryNotFound
+}
+
+// Undo puts back the files of the newest change made by a user that hasn't already been undone.
+// Nothing is changed if any of the files have been changed since.
+func Undo(userId int) (Entry, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := loadEntries(); err != nil {
+		return Entry{}, err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].UserId != userId || entries[i].Undone {
+			continue
+		}
+
+		if err := restore(entries[i], true); err != nil {
+			return entries[i], err
+		}
+
+		return entries[i], setUndone(i, true)
+	}
+
+	return Entry{}, ErrNothingToUndo
+}
+
+// Redo makes the change a user undid most recently again.
+// Once a user makes a new change, the changes they undid before it can't be redone.
+func Redo(userId int) (Entry, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := loadEntries(); err != nil {
+		return Entry{}, err
+	}
+
+	redoIdx := -1
+	lastChange := time.Time{}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+
+		if entries[i].UserId != userId {
+			continue
+		}
+
+		if lastChange.IsZero() {
+			lastChange = entries[i].When
+		}
+
+		if !entries[i].Undone || entries[i].UndoneAt.Before(lastChange) {
+			continue
+		}
+
+		if redoIdx < 0 || entries[i].UndoneAt.After(entries[redoIdx].UndoneAt) {
+			redoIdx = i
+		}
+	}
+
+	if redoIdx < 0 {
+		return Entry{}, ErrNothingToRedo
+	}
+
+	if err := restore(entries[redoIdx], false); err != nil {
+		return entries[redoIdx], err
+	}
+
+	return entries[redoIdx], setUndone(redoIdx, false)
+}
+
+// restore writes the files of an entry as they were before it (undo) or after it (redo), then reloads them
+func restore(e Entry, toBefore bool) error {
+
+	targets := map[string]fileState{}
+	folders := map[string]map[string]bool{}
+
+	for _, c := range e.Changes {
+
+		expected := fileState{data: []byte(c.After), exists: !c.Deleted}
+		target := fileState{data: []byte(c.Before), exists: !c.Created}
+		if !toBefore {
+			expected, target = target, expected
+		}
+
+		if !readFileState(c.Path).equals(expected) {
+			return fmt.Errorf(`%s has been changed since change %d`, c.Path, e.EntryId)
+		}
+
+		targets[c.Path] = target
+
+		if folders[c.Folder()] == nil {
+			folders[c.Folder()] = map[string]bool{}
+		}
+		folders[c.Folder()][c.Path] = target.exists
+	}
+
+	for folder, paths := range folders {
+		if r, ok := reloaders[folder]; ok && r.Check != nil {
+			if err := r.Check(paths); err != nil {
+				return err
+			}
+		}
+	}
+
+	restoring.Store(true)
+	defer restoring.Store(false)
+
+	for _, relPath := range slices.Sorted(maps.Keys(targets)) {
+		if err := writeFileState(relPath, targets[relPath]); err != nil {
+			return err
+		}
+	}
+
+	return reload(folders)
+}
+
+// Reload loads files written straight into DataFiles (rather than saved by the package that owns them)
+// into the running game, the same way Undo() and Redo() do.
+// paths are relative to DataFiles, and say whether the file exists now.
+func Reload(paths map[string]bool) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	folders := map[string]map[string]bool{}
+	for relPath, exists := range paths {
+		folder := Change{Path: relPath}.Folder()
+		if folders[folder] == nil {
+			folders[folder] = map[string]bool{}
+		}
+		folders[folder][relPath] = exists
+	}
+
+	return reload(folders)
+}
+
+// reload calls the Reloader of each folder (folder => path => exists), and the default Reloader once for the rest
+func reload(folders map[string]map[string]bool) error {
+
+	var errs []error
+	useDefault := false
+
+	for _, folder := range slices.Sorted(maps.Keys(folders)) {
+		r, ok := reloaders[folder]
+		if !ok {
+			useDefault = true
+			continue
+		}
+		if r.Reload == nil {
+			continue
+		}
+		if err := r.Reload(folders[folder]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if useDefault && defaultReloader.Reload != nil {
+		if err := defaultReloader.Reload(nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func writeFileState(relPath string, f fileState) error {
+
+	path := absolutePath(relPath)
+
+	if !f.exists {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, f.data, 0644)
+}
+
+func setUndone(idx int, undone bool) error {
+	e := entries[idx]
+	e.Undone = undone
+	e.UndoneAt = time.Time{}
+	if undone {
+		e.UndoneAt = time.Now()
+	}
+	if err := saveEntry(e); err != nil {
+		return err
+	}
+	entries[idx] = e
+	return nil
+}
+
+//
+// Storage
+//
+
+// loadEntries must be called with the lock held
+func loadEntries() error {
+
+	if entries != nil {
+		return nil
+	}
+
+	keys, err := storage.Keys(storage.Changelog)
+	if err != nil {
+		return err
+	}
+
+	loaded := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+
+		data, err := storage.Get(storage.Changelog, key)
+		if err != nil {
+			return err
+		}
+
+		e := Entry{}
+		if err := yaml.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf(`changelog entry %s: %w`, key, err)
+		}
+		loaded = append(loaded, e)
+	}
+
+	slices.SortFunc(loaded, func(a, b Entry) int {
+		return a.EntryId - b.EntryId
+	})
+
+	entries = loaded
+	return nil
+}
+
+func saveEntry(e Entry) error {
+	data, err := yaml.Marshal(&e)
+	if err != nil {
+		return err
+	}
+	return storage.Put(storage.Changelog, storage.Record{Key: entryKey(e.EntryId), Data: data})
+}
+
+func entryKey(entryId int) string {
+	return fmt.Sprintf(`%0*d`, keyDigits, entryId)
+}
+
+func entryIndex(entryId int) int {
+	idx, found := slices.BinarySearchFunc(entries, entryId, func(e Entry, id int) int {
+		return e.EntryId - id
+	})
+	if !found {
+		return -1
+	}
+	return idx
+}
+
+// Reset forgets the entries loaded, so they are read from storage again
+func Reset() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	entries = nil
+}
+
+//
+// Paths
+//
+
+func dataFilesPath() string {
+	return configs.GetFilePathsConfig().DataFiles.String()
+}
+
+func absolutePath(relPath string) string {
+	return filepath.Join(dataFilesPath(), filepath.FromSlash(relPath))
+}
+
+// relativePath returns the path relative to DataFiles, with forward slashes
+func relativePath(path string) (string, bool) {
+
+	base, err := filepath.Abs(dataFilesPath())
+	if err != nil {
+		return ``, false
+	}
+
+	abs, err := filepath.Abs(filepath.FromSlash(path))
+	if err != nil {
+		return ``, false
+	}
+
+	rel, err := filepath.Rel(base, abs)
+	if err != nil || rel == `.` || strings.HasPrefix(rel, `..`) {
+		return ``, false
+	}
+
+	return filepath.ToSlash(rel), true
+}
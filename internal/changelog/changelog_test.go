@@ -0,0 +1,331 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+// testDataFiles points DataFiles at an empty folder and returns it
+func testDataFiles(t *testing.T) string {
+	t.Helper()
+
+	dataFiles := testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.Storage": storage.BackendYAML,
+	})
+	storage.Close()
+	Reset()
+
+	oldReloaders, oldDefaultReloader := reloaders, defaultReloader
+	reloaders = map[string]Reloader{}
+	defaultReloader = Reloader{}
+
+	t.Cleanup(func() {
+		storage.Close()
+		Reset()
+		reloaders, defaultReloader = oldReloaders, oldDefaultReloader
+	})
+
+	return dataFiles
+}
+
+// change writes (or removes, if content is empty) files as part of a change set
+func change(t *testing.T, dataFiles string, userId int, files map[string]string) {
+	t.Helper()
+
+	Begin(userId, `user`, `test`)
+	defer End()
+
+	for relPath, content := range files {
+		path := filepath.Join(dataFiles, relPath)
+		Touch(path)
+		if content == `` {
+			require.NoError(t, os.Remove(path))
+			continue
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func readFile(t *testing.T, dataFiles string, relPath string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dataFiles, relPath))
+	if os.IsNotExist(err) {
+		return `(none)`
+	}
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestBeginEnd(t *testing.T) {
+
+	dataFiles := testDataFiles(t)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dataFiles, `items`), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataFiles, `items/1.yaml`), []byte("name: rock\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataFiles, `items/2.yaml`), []byte("name: stick\n"), 0644))
+
+	Begin(5, `builder`, `item edit`)
+	Begin(5, `builder`, `nested`)
+
+	Touch(filepath.Join(dataFiles, `items/1.yaml`))
+	os.WriteFile(filepath.Join(dataFiles, `items/1.yaml`), []byte("name: boulder\n"), 0644)
+
+	// Written again with the same contents
+	Touch(filepath.Join(dataFiles, `items/2.yaml`))
+	os.WriteFile(filepath.Join(dataFiles, `items/2.yaml`), []byte("name: stick\n"), 0644)
+
+	Touch(filepath.Join(dataFiles, `items/3.yaml`))
+	os.WriteFile(filepath.Join(dataFiles, `items/3.yaml`), []byte("name: pebble\n"), 0644)
+
+	// Outside of DataFiles
+	Touch(filepath.Join(t.TempDir(), `other.yaml`))
+
+	End()
+
+	all, err := Entries()
+	require.NoError(t, err)
+	assert.Empty(t, all, `nothing is saved until the outermost End()`)
+
+	End()
+
+	all, err = Entries()
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+
+	assert.Equal(t, 1, all[0].EntryId)
+	assert.Equal(t, 5, all[0].UserId)
+	assert.Equal(t, `item edit`, all[0].Command)
+	assert.Equal(t, []Change{
+		{Path: `items/1.yaml`, Before: "name: rock\n", After: "name: boulder\n"},
+		{Path: `items/3.yaml`, After: "name: pebble\n", Created: true},
+	}, all[0].Changes)
+
+	// Nothing changed, so nothing is saved
+	change(t, dataFiles, 5, map[string]string{`items/2.yaml`: "name: stick\n"})
+
+	// Touch does nothing without Begin()
+	Touch(filepath.Join(dataFiles, `items/2.yaml`))
+	End()
+
+	// Loaded again from storage
+	Reset()
+	all, err = Entries()
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, `items/3.yaml`, all[0].Changes[1].Path)
+}
+
+func TestUndoRedo(t *testing.T) {
+
+	dataFiles := testDataFiles(t)
+
+	reloaded := map[string]bool{}
+	RegisterReloader(`rooms`, Reloader{
+		Check: func(paths map[string]bool) error {
+			return nil
+		},
+		Reload: func(paths map[string]bool) error {
+			for path, exists := range paths {
+				reloaded[path] = exists
+			}
+			return nil
+		},
+	})
+	defaultReloads := 0
+	SetDefaultReloader(Reloader{Reload: func(map[string]bool) error {
+		defaultReloads++
+		return nil
+	}})
+
+	change(t, dataFiles, 1, map[string]string{`rooms/town/1.yaml`: "title: Square\n"})
+	change(t, dataFiles, 1, map[string]string{`rooms/town/1.yaml`: "title: Town Square\n", `items/1.yaml`: "name: rock\n"})
+	change(t, dataFiles, 2, map[string]string{`mobs/1.yaml`: "name: rat\n"})
+
+	// Undo the newest change by user 1, not user 2
+	e, err := Undo(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, e.EntryId)
+	assert.Equal(t, "title: Square\n", readFile(t, dataFiles, `rooms/town/1.yaml`))
+	assert.Equal(t, `(none)`, readFile(t, dataFiles, `items/1.yaml`))
+	assert.Equal(t, "name: rat\n", readFile(t, dataFiles, `mobs/1.yaml`))
+	assert.Equal(t, map[string]bool{`rooms/town/1.yaml`: true}, reloaded)
+	assert.Equal(t, 1, defaultReloads)
+
+	e, err = Undo(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.EntryId)
+	assert.Equal(t, `(none)`, readFile(t, dataFiles, `rooms/town/1.yaml`))
+	assert.False(t, reloaded[`rooms/town/1.yaml`])
+
+	_, err = Undo(1)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	// Redone in the order they were made
+	e, err = Redo(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.EntryId)
+	assert.Equal(t, "title: Square\n", readFile(t, dataFiles, `rooms/town/1.yaml`))
+
+	// A new change means entry 2 can't be redone
+	change(t, dataFiles, 1, map[string]string{`items/1.yaml`: "name: stone\n"})
+	_, err = Redo(1)
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	// Even after the new change is undone
+	e, err = Undo(1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, e.EntryId)
+	e, err = Redo(1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, e.EntryId)
+	_, err = Redo(1)
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	// Nothing is undone if a file was changed since
+	change(t, dataFiles, 2, map[string]string{`items/1.yaml`: "name: pebble\n"})
+	_, err = Undo(1)
+	assert.ErrorContains(t, err, `items/1.yaml has been changed since change 4`)
+	assert.Equal(t, "name: pebble\n", readFile(t, dataFiles, `items/1.yaml`))
+
+	// Undone is saved
+	Reset()
+	all, err := Entries()
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+	assert.False(t, all[0].Undone)
+	assert.True(t, all[1].Undone)
+	assert.False(t, all[2].Undone)
+}
+
+func TestUndo_Check(t *testing.T) {
+
+	dataFiles := testDataFiles(t)
+
+	RegisterReloader(`rooms`, Reloader{
+		Check: func(paths map[string]bool) error {
+			return assert.AnError
+		},
+		Reload: func(paths map[string]bool) error {
+			return nil
+		},
+	})
+
+	change(t, dataFiles, 1, map[string]string{`rooms/town/1.yaml`: "title: Square\n"})
+
+	_, err := Undo(1)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, "title: Square\n", readFile(t, dataFiles, `rooms/town/1.yaml`))
+}
+
+func TestPatch(t *testing.T) {
+
+	list := []Entry{
+		{EntryId: 1, Username: `ann`, Command: `room edit`, Changes: []Change{
+			{Path: `rooms/town/1.yaml`, Before: "a\nb\nc\n", After: "a\nB\nc\n"},
+			{Path: `items/1.yaml`, Before: "name: rock\n", Deleted: true},
+		}},
+		{EntryId: 2, Username: `bob`, Command: `oops`, Undone: true, Changes: []Change{
+			{Path: `mobs/1.yaml`, After: "name: rat\n", Created: true},
+		}},
+		{EntryId: 3, Username: `ann`, Command: `room edit`, Changes: []Change{
+			{Path: `rooms/town/1.yaml`, Before: "a\nB\nc\n", After: "a\nB\nc\nd"},
+		}},
+	}
+
+	patch := Patch(list)
+
+	assert.Contains(t, patch, "Change 1 by ann")
+	assert.NotContains(t, patch, "Change 2 by bob")
+	assert.NotContains(t, patch, "mobs/1.yaml")
+
+	assert.Contains(t, patch, "\n\n"+
+		"diff --git a/rooms/town/1.yaml b/rooms/town/1.yaml\n"+
+		"--- a/rooms/town/1.yaml\n"+
+		"+++ b/rooms/town/1.yaml\n"+
+		"@@ -1,3 +1,4 @@\n"+
+		" a\n"+
+		"-b\n"+
+		"+B\n"+
+		" c\n"+
+		"+d\n"+
+		"\\ No newline at end of file\n"+
+		"diff --git a/items/1.yaml b/items/1.yaml\n"+
+		"deleted file mode 100644\n"+
+		"--- a/items/1.yaml\n"+
+		"+++ /dev/null\n"+
+		"@@ -1 +0,0 @@\n"+
+		"-name: rock\n")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+
+	lines := func(from int, to int) string {
+		s := ``
+		for i := from; i <= to; i++ {
+			s += string(rune('a'+i-1)) + "\n"
+		}
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		before fileState
+		after  fileState
+		want   string
+	}{
+		{
+			name:   `unchanged`,
+			before: fileState{data: []byte("a\n"), exists: true},
+			after:  fileState{data: []byte("a\n"), exists: true},
+			want:   ``,
+		},
+		{
+			name:  `new file`,
+			after: fileState{data: []byte("a\nb\n"), exists: true},
+			want: "diff --git a/x.yaml b/x.yaml\n" +
+				"new file mode 100644\n" +
+				"--- /dev/null\n" +
+				"+++ b/x.yaml\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n" +
+				"+b\n",
+		},
+		{
+			name:   `two hunks`,
+			before: fileState{data: []byte(lines(1, 20)), exists: true},
+			after:  fileState{data: []byte(lines(2, 19) + "T\n"), exists: true},
+			want: "diff --git a/x.yaml b/x.yaml\n" +
+				"--- a/x.yaml\n" +
+				"+++ b/x.yaml\n" +
+				"@@ -1,4 +1,3 @@\n" +
+				"-a\n" +
+				" b\n" +
+				" c\n" +
+				" d\n" +
+				"@@ -17,4 +16,4 @@\n" +
+				" q\n" +
+				" r\n" +
+				" s\n" +
+				"-t\n" +
+				"+T\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, unifiedDiff(`x.yaml`, tt.before, tt.after))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	diffContextLines = 3
+)
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff returns the change to one file in the format of git diff, or nothing if it didn't change
+func unifiedDiff(path string, before fileState, after fileState) string {
+
+	if before.equals(after) {
+		return ``
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", path, path))
+
+	fromName, toName := `a/`+path, `b/`+path
+	if !before.exists {
+		sb.WriteString("new file mode 100644\n")
+		fromName = `/dev/null`
+	} else if !after.exists {
+		sb.WriteString("deleted file mode 100644\n")
+		toName = `/dev/null`
+	}
+
+	sb.WriteString(`--- ` + fromName + "\n")
+	sb.WriteString(`+++ ` + toName + "\n")
+
+	ops := diffLines(splitLines(string(before.data)), splitLines(string(after.data)))
+
+	for _, h := range hunks(ops) {
+
+		oldStart, newStart := 1, 1
+		for _, op := range ops[:h[0]] {
+			if op.kind != '+' {
+				oldStart++
+			}
+			if op.kind != '-' {
+				newStart++
+			}
+		}
+
+		oldCount, newCount := 0, 0
+		for _, op := range ops[h[0]:h[1]] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount)))
+
+		for _, op := range ops[h[0]:h[1]] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func hunkRange(start int, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	// An empty range points at the line before it
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf(`%d,%d`, start, count)
+}
+
+// splitLines keeps the newline on the end of each line, so a missing one at the end of the file shows up as a change
+func splitLines(s string) []string {
+	if s == `` {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == `` {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines finds the fewest lines to remove and add to turn a into b (longest common subsequence)
+func diffLines(a []string, b []string) []diffOp {
+
+	// Most changes are small, so skip the lines that are the same at each end
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	lcs := make([][]int32, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops
+}
+
+// hunks returns the [start, end) of each group of changes, with the lines of context around them
+func hunks(ops []diffOp) [][2]int {
+
+	result := [][2]int{}
+
+	for idx, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+
+		start := max(0, idx-diffContextLines)
+		end := min(len(ops), idx+diffContextLines+1)
+
+		// Changes close enough to share context lines go in the same hunk
+		if last := len(result) - 1; last >= 0 && start <= result[last][1] {
+			result[last][1] = end
+			continue
+		}
+
+		result = append(result, [2]int{start, end})
+	}
+
+	return result
+}
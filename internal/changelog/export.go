@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PatchFolder = `patches` // Inside DataFiles
+)
+
+// Diff is the change in the format of git diff
+func (c Change) Diff() string {
+	return unifiedDiff(c.Path,
+		fileState{data: []byte(c.Before), exists: !c.Created},
+		fileState{data: []byte(c.After), exists: !c.Deleted},
+	)
+}
+
+// Patch returns a list of the entries followed by one diff with all of their changes, which can be applied
+// from inside another DataFiles folder with "git apply" or "patch -p1".
+// Entries that were undone are left out. A file changed by more than one entry gets a single diff,
+// from before the first change to after the last.
+func Patch(list []Entry) string {
+
+	sb := strings.Builder{}
+
+	paths := []string{}
+	first := map[string]fileState{}
+	last := map[string]fileState{}
+
+	for _, e := range list {
+
+		if e.Undone {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("Change %d by %s at %s: %s\n", e.EntryId, e.Username, e.When.Format(`2006-01-02 15:04:05`), e.Command))
+
+		for _, c := range e.Changes {
+			if _, ok := first[c.Path]; !ok {
+				first[c.Path] = fileState{data: []byte(c.Before), exists: !c.Created}
+				paths = append(paths, c.Path)
+			}
+			last[c.Path] = fileState{data: []byte(c.After), exists: !c.Deleted}
+		}
+	}
+
+	sb.WriteString("\n")
+
+	for _, path := range paths {
+		sb.WriteString(unifiedDiff(path, first[path], last[path]))
+	}
+
+	return sb.String()
+}
+
+// Export writes the patch of the entries from fromId to toId (inclusive) to the patches folder of DataFiles.
+// It returns the path of the file and how many entries were in it.
+func Export(fromId int, toId int) (string, int, error) {
+
+	all, err := Entries()
+	if err != nil {
+		return ``, 0, err
+	}
+
+	list := []Entry{}
+	for _, e := range all {
+		if e.EntryId >= fromId && e.EntryId <= toId && !e.Undone {
+			list = append(list, e)
+		}
+	}
+
+	if len(list) == 0 {
+		return ``, 0, fmt.Errorf(`no changes between %d and %d`, fromId, toId)
+	}
+
+	folder := filepath.Join(dataFilesPath(), PatchFolder)
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return ``, 0, err
+	}
+
+	path := filepath.Join(folder, fmt.Sprintf(`changes-%d-%d.patch`, list[0].EntryId, list[len(list)-1].EntryId))
+	if err := os.WriteFile(path, []byte(Patch(list)), 0644); err != nil {
+		return ``, 0, err
+	}
+
+	return path, len(list), nil
+}
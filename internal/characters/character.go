@@ -124,7 +124,7 @@ func New() *Character {
 		MiscData:       make(map[string]any),
 		roomHistory:    make([]int, 0, 10),
 		KeyRing:        make(map[string]string),
-		Created:        time.Now(),
+		Created:        util.Now(),
 		PlayerDamage:   map[int]int{},
 	}
 }
@@ -1486,7 +1486,7 @@ func (c *Character) Validate(recalcPermaBuffs ...bool) error {
 	}
 
 	if c.Created.IsZero() {
-		c.Created = time.Now()
+		c.Created = util.Now()
 	}
 
 	if c.Pet.Exists() {
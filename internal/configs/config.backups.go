@@ -0,0 +1,33 @@
+package configs
+
+import "strings"
+
+type Backups struct {
+	Period   ConfigString `yaml:"Period"`   // How often to take a snapshot, as a gametime period such as "1 day" or "6 real hours". Empty for never.
+	Keep     ConfigInt    `yaml:"Keep"`     // The newest snapshots always kept
+	KeepDays ConfigInt    `yaml:"KeepDays"` // Also keeps the newest snapshot from each of this many days
+}
+
+func (b *Backups) Validate() {
+
+	b.Period = ConfigString(strings.TrimSpace(string(b.Period)))
+
+	if b.Keep < 1 {
+		b.Keep = 1 // default
+	}
+
+	if b.KeepDays < 0 {
+		b.KeepDays = 0
+	}
+
+}
+
+func GetBackupsConfig() Backups {
+	configDataLock.RLock()
+	defer configDataLock.RUnlock()
+
+	if !configData.validated {
+		configData.Validate()
+	}
+	return configData.Backups
+}
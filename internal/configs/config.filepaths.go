@@ -1,5 +1,7 @@
 package configs
 
+import "strings"
+
 type FilePaths struct {
 	WebDomain        ConfigString `yaml:"WebDomain"`
 	WebCDNLocation   ConfigString `yaml:"WebCDNLocation"`
@@ -9,6 +11,7 @@ type FilePaths struct {
 	HttpsCertFile    ConfigString `yaml:"HttpsCertFile"`
 	HttpsKeyFile     ConfigString `yaml:"HttpsKeyFile"`
 	CarefulSaveFiles ConfigBool   `yaml:"CarefulSaveFiles"`
+	Storage          ConfigString `yaml:"Storage"`
 }
 
 func (f *FilePaths) Validate() {
@@ -23,6 +26,11 @@ func (f *FilePaths) Validate() {
 		f.DataFiles = `_datafiles/world/default` // default
 	}
 
+	f.Storage = ConfigString(strings.ToLower(string(f.Storage)))
+	if f.Storage != `yaml` && f.Storage != `sqlite` {
+		f.Storage = `yaml` // default
+	}
+
 }
 
 func GetFilePathsConfig() FilePaths {
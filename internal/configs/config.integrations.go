@@ -11,20 +11,70 @@ type IntegrationsDiscord struct {
 }
 
 type IntegrationsLLM struct {
-	Enabled          ConfigBool   `yaml:"Enabled"`          // Whether LLM integration is enabled
-	Provider         ConfigString `yaml:"Provider"`         // The LLM provider (e.g., "ollama")
-	Model            ConfigString `yaml:"Model"`            // The model to use (e.g., "llama2")
-	BaseURL          ConfigString `yaml:"BaseURL"`          // Base URL for the LLM API
-	Temperature      ConfigFloat  `yaml:"Temperature"`      // Temperature for response generation (0.0-1.0)
-	MaxContextLength ConfigInt    `yaml:"MaxContextLength"` // Maximum number of conversation turns to include in context
+	Enabled             ConfigBool                        `yaml:"Enabled"`             // Whether LLM integration is enabled
+	MaxContextLength    ConfigInt                         `yaml:"MaxContextLength"`    // Maximum number of conversation turns to include in context
+	Workers             ConfigInt                         `yaml:"Workers"`             // Number of background workers sending requests to the LLM
+	QueueSize           ConfigInt                         `yaml:"QueueSize"`           // Maximum number of requests waiting for a worker
+	RequestTimeout      ConfigInt                         `yaml:"RequestTimeout"`      // Seconds before a request (queued or in flight) is abandoned
+	Streaming           ConfigBool                        `yaml:"Streaming"`           // Whether NPCs speak a sentence at a time as their reply is generated
+	MaxRetries          ConfigInt                         `yaml:"MaxRetries"`          // How many times a failed request is retried before giving up
+	RetryDelay          ConfigInt                         `yaml:"RetryDelay"`          // Milliseconds before the first retry, doubling with each attempt
+	BackoffSeconds      ConfigInt                         `yaml:"BackoffSeconds"`      // Seconds a profile is rested after it runs out of retries
+	ConversationProfile ConfigString                      `yaml:"ConversationProfile"` // Profile used by NPC conversations unless the conversation names one
+	Profiles            map[string]IntegrationsLLMProfile `yaml:"Profiles"`            // Named provider/model/endpoint combinations
+	ToolAuditFile       ConfigString                      `yaml:"ToolAuditFile"`       // Optional file every action an NPC takes through the LLM is appended to
+	MemoryRecall        ConfigInt                         `yaml:"MemoryRecall"`        // How many past conversations an NPC recalls when replying
+	MemoryMaxEntries    ConfigInt                         `yaml:"MemoryMaxEntries"`    // How many past conversations an NPC remembers per character
+	Quotas              IntegrationsLLMQuotas             `yaml:"Quotas"`              // Limits on how much of the LLM users and the server may use
+}
+
+// IntegrationsLLMQuotas limits LLM usage per user, per role and for the whole server.
+type IntegrationsLLMQuotas struct {
+	Enabled      ConfigBool                      `yaml:"Enabled"`      // Whether quotas are enforced
+	AlertPercent ConfigInt                       `yaml:"AlertPercent"` // Percentage of a quota that raises an early warning
+	Server       IntegrationsLLMQuota            `yaml:"Server"`       // Limits for all usage combined
+	User         IntegrationsLLMQuota            `yaml:"User"`         // Limits for each user
+	Roles        map[string]IntegrationsLLMQuota `yaml:"Roles"`        // Limits for each user with a given role, instead of User
+}
+
+// IntegrationsLLMQuota is a set of limits. Zero means unlimited.
+type IntegrationsLLMQuota struct {
+	DailyTokens     ConfigInt `yaml:"DailyTokens"`     // Tokens (input and output) per day
+	MonthlyTokens   ConfigInt `yaml:"MonthlyTokens"`   // Tokens (input and output) per month
+	DailyRequests   ConfigInt `yaml:"DailyRequests"`   // Requests per day
+	MonthlyRequests ConfigInt `yaml:"MonthlyRequests"` // Requests per month
+}
+
+func (q *IntegrationsLLMQuota) Validate() {
+	if q.DailyTokens < 0 {
+		q.DailyTokens = 0
+	}
+	if q.MonthlyTokens < 0 {
+		q.MonthlyTokens = 0
+	}
+	if q.DailyRequests < 0 {
+		q.DailyRequests = 0
+	}
+	if q.MonthlyRequests < 0 {
+		q.MonthlyRequests = 0
+	}
+}
+
+// IntegrationsLLMProfile is a named provider/model/endpoint combination.
+// Features pick a profile by name rather than carrying their own connection details.
+type IntegrationsLLMProfile struct {
+	Provider    ConfigString `yaml:"Provider"`    // "ollama", "openai" (any OpenAI compatible API) or "recording"
+	Model       ConfigString `yaml:"Model"`       // The model to use (e.g., "llama3.3")
+	BaseURL     ConfigString `yaml:"BaseURL"`     // Base URL for the API
+	APIKey      ConfigSecret `yaml:"APIKey"`      // API key (if needed)
+	Temperature ConfigFloat  `yaml:"Temperature"` // Temperature for response generation (0.0-1.0)
+	MaxTokens   ConfigInt    `yaml:"MaxTokens"`   // Maximum tokens to generate per response
 }
 
 type IntegrationsLLMHelp struct {
 	Enabled       ConfigBool   `yaml:"Enabled"`       // Whether LLM help system is enabled
 	SystemPrompt  ConfigString `yaml:"SystemPrompt"`  // System prompt for the help LLM
-	EndpointURL   ConfigString `yaml:"EndpointURL"`   // URL for the LLM API (if different from the main LLM)
-	APIKey        ConfigSecret `yaml:"APIKey"`        // API key (if needed)
-	Model         ConfigString `yaml:"Model"`         // Model to use (if different from the main LLM)
+	Profile       ConfigString `yaml:"Profile"`       // Which LLM profile answers help requests
 	TemplatePath  ConfigString `yaml:"TemplatePath"`  // Path to store generated templates
 	SaveResponses ConfigBool   `yaml:"SaveResponses"` // Whether to save responses as templates
 }
@@ -34,28 +84,75 @@ func (i *Integrations) Validate() {
 	// Ignore Discord
 
 	// Validate LLM settings
-	if i.LLM.Temperature < 0.0 {
-		i.LLM.Temperature = 0.7 // Default temperature
-	} else if i.LLM.Temperature > 1.0 {
-		i.LLM.Temperature = 1.0 // Cap at 1.0
-	}
-
 	if i.LLM.MaxContextLength < 1 {
 		i.LLM.MaxContextLength = 10 // Default context length
 	} else if i.LLM.MaxContextLength > 50 {
 		i.LLM.MaxContextLength = 50 // Cap at 50 turns
 	}
 
-	if i.LLM.Provider == "" {
-		i.LLM.Provider = "ollama" // Default provider
+	if i.LLM.Workers < 1 {
+		i.LLM.Workers = 2 // Default worker count
+	}
+
+	if i.LLM.QueueSize < 1 {
+		i.LLM.QueueSize = 32 // Default queue size
+	}
+
+	if i.LLM.RequestTimeout < 1 {
+		i.LLM.RequestTimeout = 30 // Default timeout in seconds
+	}
+
+	if i.LLM.MaxRetries < 0 {
+		i.LLM.MaxRetries = 0
 	}
 
-	if i.LLM.Model == "" {
-		i.LLM.Model = "llama3.3" // Default model
+	if i.LLM.RetryDelay < 1 {
+		i.LLM.RetryDelay = 500 // Default first retry delay in milliseconds
 	}
 
-	if i.LLM.BaseURL == "" {
-		i.LLM.BaseURL = "http://localhost:11434" // Default Ollama URL
+	if i.LLM.BackoffSeconds < 1 {
+		i.LLM.BackoffSeconds = 30 // Default backoff in seconds
+	}
+
+	if i.LLM.Quotas.AlertPercent < 1 || i.LLM.Quotas.AlertPercent >= 100 {
+		i.LLM.Quotas.AlertPercent = 80 // Default early warning
+	}
+	i.LLM.Quotas.Server.Validate()
+	i.LLM.Quotas.User.Validate()
+	for role, quota := range i.LLM.Quotas.Roles {
+		quota.Validate()
+		i.LLM.Quotas.Roles[role] = quota
+	}
+
+	if i.LLM.MemoryRecall < 1 {
+		i.LLM.MemoryRecall = 3 // Default past conversations recalled
+	}
+
+	if i.LLM.MemoryMaxEntries < 1 {
+		i.LLM.MemoryMaxEntries = 50 // Default past conversations remembered
+	} else if i.LLM.MemoryMaxEntries < i.LLM.MemoryRecall {
+		i.LLM.MemoryMaxEntries = i.LLM.MemoryRecall
+	}
+
+	if i.LLM.ConversationProfile == "" {
+		i.LLM.ConversationProfile = "default"
+	}
+
+	if i.LLM.Profiles == nil {
+		i.LLM.Profiles = map[string]IntegrationsLLMProfile{}
+	}
+
+	if _, ok := i.LLM.Profiles["default"]; !ok {
+		i.LLM.Profiles["default"] = IntegrationsLLMProfile{
+			Provider: "ollama",
+			Model:    "llama3.3",
+			BaseURL:  "http://localhost:11434",
+		}
+	}
+
+	for name, profile := range i.LLM.Profiles {
+		profile.Validate()
+		i.LLM.Profiles[name] = profile
 	}
 
 	// Validate LLMHelp settings
@@ -67,13 +164,21 @@ func (i *Integrations) Validate() {
 		i.LLMHelp.TemplatePath = "templates/help" // Default template path
 	}
 
-	// Use main LLM settings as defaults if not specified
-	if string(i.LLMHelp.EndpointURL) == "" && i.LLM.Enabled {
-		i.LLMHelp.EndpointURL = i.LLM.BaseURL
+	if i.LLMHelp.Profile == "" {
+		i.LLMHelp.Profile = "default"
+	}
+}
+
+func (p *IntegrationsLLMProfile) Validate() {
+
+	if p.Temperature < 0.0 {
+		p.Temperature = 0.7 // Default temperature
+	} else if p.Temperature > 1.0 {
+		p.Temperature = 1.0 // Cap at 1.0
 	}
 
-	if string(i.LLMHelp.Model) == "" && i.LLM.Enabled {
-		i.LLMHelp.Model = i.LLM.Model
+	if p.MaxTokens < 1 {
+		p.MaxTokens = 300 // Default response length
 	}
 }
 
@@ -0,0 +1,149 @@
+package configs
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// Before LLM profiles existed the connection details lived directly under
+// Integrations.LLM and Integrations.LLMHelp. Those keys are no longer part of
+// the config, so they are read separately here and turned into profiles,
+// otherwise older config files would quietly lose their LLM settings.
+//
+
+type legacyLLMConfig struct {
+	Integrations struct {
+		LLM struct {
+			Provider    ConfigString `yaml:"Provider"`
+			Model       ConfigString `yaml:"Model"`
+			BaseURL     ConfigString `yaml:"BaseURL"`
+			Temperature *ConfigFloat `yaml:"Temperature"`
+		} `yaml:"LLM"`
+		LLMHelp struct {
+			EndpointURL ConfigString `yaml:"EndpointURL"`
+			APIKey      ConfigSecret `yaml:"APIKey"`
+			Model       ConfigString `yaml:"Model"`
+		} `yaml:"LLMHelp"`
+	} `yaml:"Integrations"`
+}
+
+// read merges any legacy keys found in a yaml document. Later documents win.
+func (l *legacyLLMConfig) read(yamlBytes []byte) {
+	found := legacyLLMConfig{}
+	if err := yaml.Unmarshal(yamlBytes, &found); err != nil {
+		return
+	}
+
+	llm, help := found.Integrations.LLM, found.Integrations.LLMHelp
+
+	if llm.Provider != `` {
+		l.Integrations.LLM.Provider = llm.Provider
+	}
+	if llm.Model != `` {
+		l.Integrations.LLM.Model = llm.Model
+	}
+	if llm.BaseURL != `` {
+		l.Integrations.LLM.BaseURL = llm.BaseURL
+	}
+	if llm.Temperature != nil {
+		l.Integrations.LLM.Temperature = llm.Temperature
+	}
+	if help.EndpointURL != `` {
+		l.Integrations.LLMHelp.EndpointURL = help.EndpointURL
+	}
+	if help.APIKey != `` {
+		l.Integrations.LLMHelp.APIKey = help.APIKey
+	}
+	if help.Model != `` {
+		l.Integrations.LLMHelp.Model = help.Model
+	}
+}
+
+func (l legacyLLMConfig) hasLLM() bool {
+	llm := l.Integrations.LLM
+	return llm.Provider != `` || llm.Model != `` || llm.BaseURL != `` || llm.Temperature != nil
+}
+
+func (l legacyLLMConfig) hasHelp() bool {
+	help := l.Integrations.LLMHelp
+	return help.EndpointURL != `` || help.Model != ``
+}
+
+// migrate turns the legacy keys into the "default" and "help" profiles, unless those profiles are already configured.
+// It must run before Validate(), which fills in a "default" profile of its own.
+func (l legacyLLMConfig) migrate(c *Config) {
+
+	if !l.hasLLM() && !l.hasHelp() && l.Integrations.LLMHelp.APIKey == `` {
+		return
+	}
+
+	if c.Integrations.LLM.Profiles == nil {
+		c.Integrations.LLM.Profiles = map[string]IntegrationsLLMProfile{}
+	}
+
+	legacyLLM := l.Integrations.LLM
+	legacyHelp := l.Integrations.LLMHelp
+
+	defaultProfile, hasDefault := c.Integrations.LLM.Profiles[`default`]
+	if !hasDefault {
+
+		defaultProfile = IntegrationsLLMProfile{
+			Provider: `ollama`,
+			Model:    `llama3.3`,
+			BaseURL:  `http://localhost:11434`,
+			APIKey:   legacyHelp.APIKey, // The API key used to be shared by everything
+		}
+		if legacyLLM.Provider != `` {
+			defaultProfile.Provider = legacyLLM.Provider
+		}
+		if legacyLLM.Model != `` {
+			defaultProfile.Model = legacyLLM.Model
+		}
+		if legacyLLM.BaseURL != `` {
+			defaultProfile.BaseURL = legacyLLM.BaseURL
+		}
+		if legacyLLM.Temperature != nil {
+			defaultProfile.Temperature = *legacyLLM.Temperature
+		}
+
+		if l.hasLLM() || legacyHelp.APIKey != `` {
+			c.Integrations.LLM.Profiles[`default`] = defaultProfile
+			mudlog.Warn("Config", "deprecated", "Integrations.LLM Provider, Model, BaseURL and Temperature", "migratedTo", "Integrations.LLM.Profiles.default")
+		}
+
+	} else if l.hasLLM() {
+		mudlog.Warn("Config", "deprecated", "Integrations.LLM Provider, Model, BaseURL and Temperature", "ignored", "Integrations.LLM.Profiles.default is already set")
+	}
+
+	if !l.hasHelp() {
+		return
+	}
+
+	helpName := string(c.Integrations.LLMHelp.Profile)
+	if helpName == `` || helpName == `default` {
+		helpName = `help`
+	}
+
+	if _, ok := c.Integrations.LLM.Profiles[helpName]; ok {
+		mudlog.Warn("Config", "deprecated", "Integrations.LLMHelp EndpointURL, APIKey and Model", "ignored", "Integrations.LLM.Profiles."+helpName+" is already set")
+		return
+	}
+
+	// The help system used to only swap the endpoint and model of the main settings
+	helpProfile := defaultProfile
+	if legacyHelp.EndpointURL != `` {
+		helpProfile.BaseURL = legacyHelp.EndpointURL
+	}
+	if legacyHelp.Model != `` {
+		helpProfile.Model = legacyHelp.Model
+	}
+	if legacyHelp.APIKey != `` {
+		helpProfile.APIKey = legacyHelp.APIKey
+	}
+
+	c.Integrations.LLM.Profiles[helpName] = helpProfile
+	c.Integrations.LLMHelp.Profile = ConfigString(helpName)
+
+	mudlog.Warn("Config", "deprecated", "Integrations.LLMHelp EndpointURL, APIKey and Model", "migratedTo", "Integrations.LLM.Profiles."+helpName)
+}
@@ -0,0 +1,89 @@
+package configs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+func TestMain(m *testing.M) {
+	mudlog.SetupLogger(nil, `LOW`, ``, false)
+	os.Exit(m.Run())
+}
+
+func TestLegacyLLMMigrate(t *testing.T) {
+
+	legacy := legacyLLMConfig{}
+	legacy.read([]byte(`
+Integrations:
+  LLM:
+    Enabled: true
+    Provider: "openai"
+    Model: "gpt-4o-mini"
+    BaseURL: "https://api.openai.com/v1"
+    Temperature: 0.3
+  LLMHelp:
+    Enabled: true
+    EndpointURL: "http://localhost:11434"
+    APIKey: "secret"
+    Model: "llama3.3"
+`))
+
+	cfg := Config{}
+	legacy.migrate(&cfg)
+
+	def := cfg.Integrations.LLM.Profiles[`default`]
+	if def.Provider != `openai` || def.Model != `gpt-4o-mini` || def.BaseURL != `https://api.openai.com/v1` || def.Temperature != 0.3 || def.APIKey != `secret` {
+		t.Errorf("Unexpected default profile: %+v", def)
+	}
+
+	help := cfg.Integrations.LLM.Profiles[`help`]
+	if help.Provider != `openai` || help.Model != `llama3.3` || help.BaseURL != `http://localhost:11434` || help.APIKey != `secret` {
+		t.Errorf("Unexpected help profile: %+v", help)
+	}
+
+	if cfg.Integrations.LLMHelp.Profile != `help` {
+		t.Errorf("Expected LLMHelp.Profile to be \"help\", got \"%s\"", cfg.Integrations.LLMHelp.Profile)
+	}
+}
+
+func TestLegacyLLMMigrate_KeepsProfiles(t *testing.T) {
+
+	legacy := legacyLLMConfig{}
+	legacy.read([]byte(`
+Integrations:
+  LLM:
+    Model: "from-config"
+`))
+	// Dot syntax overrides are read the same way once unflattened, and win
+	legacy.read([]byte(`
+Integrations:
+  LLM:
+    Model: "from-overrides"
+`))
+
+	cfg := Config{}
+	cfg.Integrations.LLM.Profiles = map[string]IntegrationsLLMProfile{
+		`default`: {Provider: `ollama`, Model: `configured`},
+	}
+	legacy.migrate(&cfg)
+
+	if cfg.Integrations.LLM.Profiles[`default`].Model != `configured` {
+		t.Errorf("Expected the configured default profile to be kept, got %+v", cfg.Integrations.LLM.Profiles[`default`])
+	}
+
+	cfg = Config{}
+	legacy.migrate(&cfg)
+
+	if cfg.Integrations.LLM.Profiles[`default`].Model != `from-overrides` {
+		t.Errorf("Expected the override to win, got %+v", cfg.Integrations.LLM.Profiles[`default`])
+	}
+
+	// Nothing legacy, nothing touched
+	cfg = Config{}
+	legacyLLMConfig{}.migrate(&cfg)
+	if cfg.Integrations.LLM.Profiles != nil {
+		t.Errorf("Expected no profiles, got %+v", cfg.Integrations.LLM.Profiles)
+	}
+}
@@ -3,6 +3,7 @@ package configs
 type Network struct {
 	MaxTelnetConnections ConfigInt         `yaml:"MaxTelnetConnections"` // Maximum number of telnet connections to accept
 	TelnetPort           ConfigSliceString `yaml:"TelnetPort"`           // One or more Ports used to accept telnet connections
+	TelnetTLSPort        ConfigSliceString `yaml:"TelnetTLSPort"`        // One or more Ports used to accept TLS encrypted telnet connections
 	LocalPort            ConfigInt         `yaml:"LocalPort"`            // Port used for admin connections, localhost only
 	HttpPort             ConfigInt         `yaml:"HttpPort"`             // Port used for web requests
 	HttpsPort            ConfigInt         `yaml:"HttpsPort"`            // Port used for web https requests
@@ -12,13 +13,20 @@ type Network struct {
 	TimeoutMods          ConfigBool        `yaml:"TimeoutMods"`          // Whether to kick admin/mods when idle too long.
 	ZombieSeconds        ConfigInt         `yaml:"ZombieSeconds"`        // How many seconds a player will be a zombie allowing them to reconnect.
 	LogoutRounds         ConfigInt         `yaml:"LogoutRounds"`         // How many rounds of uninterrupted meditation must be completed to log out.
+	MCCP2                ConfigBool        `yaml:"MCCP2"`                // Offer to compress output to telnet clients
+	MCCP3                ConfigBool        `yaml:"MCCP3"`                // Offer to accept compressed input from telnet clients
+	MSSP                 ConfigBool        `yaml:"MSSP"`                 // Report server status to MUD listing crawlers
 }
 
 func (n *Network) Validate() {
 
 	// Ignore TelnetPort
+	// Ignore TelnetTLSPort
 	// Ignore LocalPort
 	// Ignore TimeoutMods
+	// Ignore MCCP2
+	// Ignore MCCP3
+	// Ignore MSSP
 
 	if n.MaxTelnetConnections < 1 {
 		n.MaxTelnetConnections = 50 // default
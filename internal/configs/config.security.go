@@ -0,0 +1,80 @@
+package configs
+
+const (
+	PasswordHashArgon2id = `argon2id`
+	PasswordHashBcrypt   = `bcrypt`
+)
+
+type Security struct {
+	PasswordHash   ConfigString `yaml:"PasswordHash"`   // Algorithm new passwords are hashed with: argon2id or bcrypt
+	BcryptCost     ConfigInt    `yaml:"BcryptCost"`     // bcrypt work factor (4-31)
+	Argon2Time     ConfigInt    `yaml:"Argon2Time"`     // argon2id passes over memory
+	Argon2MemoryKB ConfigInt    `yaml:"Argon2MemoryKB"` // argon2id memory used per hash, in KiB
+	Argon2Threads  ConfigInt    `yaml:"Argon2Threads"`  // argon2id parallelism
+
+	MaxLoginFailures  ConfigInt `yaml:"MaxLoginFailures"`  // Failed logins allowed (per account and per address) before locking them out
+	LockoutSeconds    ConfigInt `yaml:"LockoutSeconds"`    // First lockout length, doubled for every failure after that
+	MaxLockoutSeconds ConfigInt `yaml:"MaxLockoutSeconds"` // Longest lockout. Failures are also forgotten after this long without another.
+
+	AdminRequireSecure ConfigBool `yaml:"AdminRequireSecure"` // Admins may only log in over TLS or from localhost
+
+	RecordingMaxKB    ConfigInt `yaml:"RecordingMaxKB"`    // Size a session recording file can grow to before a new one is started
+	RecordingMaxFiles ConfigInt `yaml:"RecordingMaxFiles"` // Most recording files kept, the oldest are deleted
+}
+
+func (s *Security) Validate() {
+
+	if s.PasswordHash != PasswordHashBcrypt {
+		s.PasswordHash = PasswordHashArgon2id // default
+	}
+
+	if s.BcryptCost < 4 || s.BcryptCost > 31 {
+		s.BcryptCost = 12 // default
+	}
+
+	if s.Argon2Time < 1 {
+		s.Argon2Time = 2 // default
+	}
+
+	if s.Argon2MemoryKB < 8 {
+		s.Argon2MemoryKB = 19456 // default
+	}
+
+	if s.Argon2Threads < 1 {
+		s.Argon2Threads = 1 // default
+	}
+	if s.Argon2Threads > 255 {
+		s.Argon2Threads = 255
+	}
+
+	if s.MaxLoginFailures < 0 {
+		s.MaxLoginFailures = 0 // default (no lockouts)
+	}
+
+	if s.LockoutSeconds < 1 {
+		s.LockoutSeconds = 30 // default
+	}
+
+	if s.MaxLockoutSeconds < s.LockoutSeconds {
+		s.MaxLockoutSeconds = s.LockoutSeconds
+	}
+
+	if s.RecordingMaxKB < 1 {
+		s.RecordingMaxKB = 2048 // default
+	}
+
+	if s.RecordingMaxFiles < 1 {
+		s.RecordingMaxFiles = 100 // default
+	}
+
+}
+
+func GetSecurityConfig() Security {
+	configDataLock.RLock()
+	defer configDataLock.RUnlock()
+
+	if !configData.validated {
+		configData.Validate()
+	}
+	return configData.Security
+}
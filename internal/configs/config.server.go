@@ -1,13 +1,16 @@
 package configs
 
+import "strings"
+
 type Server struct {
-	MudName         ConfigString      `yaml:"MudName"`         // Name of the MUD
-	Seed            ConfigSecret      `yaml:"Seed"`            // Seed that may be used for generating content
-	MaxCPUCores     ConfigInt         `yaml:"MaxCPUCores"`     // How many cores to allow for multi-core operations
-	OnLoginCommands ConfigSliceString `yaml:"OnLoginCommands"` // Commands to run when a user logs in
-	Motd            ConfigString      `yaml:"Motd"`            // Message of the day to display when a user logs in
-	NextRoomId      ConfigInt         `yaml:"NextRoomId"`      // The next room id to use when creating a new room
-	Locked          ConfigSliceString `yaml:"Locked"`          // List of locked config properties that cannot be changed without editing the file directly.
+	MudName         ConfigString            `yaml:"MudName"`         // Name of the MUD
+	Seed            ConfigSecret            `yaml:"Seed"`            // Seed that may be used for generating content
+	MaxCPUCores     ConfigInt               `yaml:"MaxCPUCores"`     // How many cores to allow for multi-core operations
+	OnLoginCommands ConfigSliceString       `yaml:"OnLoginCommands"` // Commands to run when a user logs in
+	Motd            ConfigString            `yaml:"Motd"`            // Message of the day to display when a user logs in
+	NextRoomId      ConfigInt               `yaml:"NextRoomId"`      // The next room id to use when creating a new room
+	Locked          ConfigSliceString       `yaml:"Locked"`          // List of locked config properties that cannot be changed without editing the file directly.
+	MSSP            map[string]ConfigString `yaml:"MSSP"`            // Extra variables reported to MUD listing crawlers, such as CONTACT or WEBSITE
 }
 
 func (s *Server) Validate() {
@@ -26,6 +29,13 @@ func (s *Server) Validate() {
 		s.MaxCPUCores = 0 // default
 	}
 
+	// MSSP variable names are always uppercase
+	mssp := map[string]ConfigString{}
+	for name, value := range s.MSSP {
+		mssp[strings.ToUpper(name)] = value
+	}
+	s.MSSP = mssp
+
 }
 
 func GetServerConfig() Server {
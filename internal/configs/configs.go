@@ -39,6 +39,7 @@ type Config struct {
 	LootGoblin   LootGoblin   `yaml:"LootGoblin"`
 	Timing       Timing       `yaml:"Timing"`
 	FilePaths    FilePaths    `yaml:"FilePaths"`
+	Backups      Backups      `yaml:"Backups"`
 	GamePlay     GamePlay     `yaml:"GamePlay"`
 	Integrations Integrations `yaml:"Integrations"`
 	TextFormats  TextFormats  `yaml:"TextFormats"`
@@ -47,6 +48,7 @@ type Config struct {
 	Scripting    Scripting    `yaml:"Scripting"`
 	SpecialRooms SpecialRooms `yaml:"SpecialRooms"`
 	Validation   Validation   `yaml:"Validation"`
+	Security     Security     `yaml:"Security"`
 	Roles        Roles        `yaml:"Roles"`
 	// Plugins is a special case
 	Modules Modules `yaml:"Modules"`
@@ -189,6 +191,7 @@ func (c *Config) Validate() {
 	c.LootGoblin.Validate()
 	c.Timing.Validate()
 	c.FilePaths.Validate()
+	c.Backups.Validate()
 	c.GamePlay.Validate()
 	c.Integrations.Validate()
 	c.TextFormats.Validate()
@@ -197,6 +200,7 @@ func (c *Config) Validate() {
 	c.Scripting.Validate()
 	c.SpecialRooms.Validate()
 	c.Validation.Validate()
+	c.Security.Validate()
 	c.Modules.Validate()
 	c.Roles.Validate()
 
@@ -354,6 +358,9 @@ func ReloadConfig() error {
 		return err
 	}
 
+	legacyLLM := legacyLLMConfig{}
+	legacyLLM.read(bytes)
+
 	// Build a special lookup to attempt to match old data or even some minor typos
 	keyLookups = map[string]string{}
 	typeLookups = map[string]string{}
@@ -408,11 +415,17 @@ func ReloadConfig() error {
 			}
 
 			tmpConfigData.SetOverrides(tmpOverrides)
+
+			if overrideYaml, err := yaml.Marshal(unflattenMap(tmpOverrides)); err == nil {
+				legacyLLM.read(overrideYaml)
+			}
 		}
 	} else {
 		mudlog.Info("ReloadConfig()", "Loading overrides", false)
 	}
 
+	legacyLLM.migrate(&tmpConfigData)
+
 	tmpConfigData.setEnvAssignments(false)
 
 	tmpConfigData.Validate()
@@ -0,0 +1,47 @@
+package configs
+
+import (
+	"maps"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Snapshot is a copy of the config and its overrides, so they can be put back later.
+// Tests use it to undo any overrides they add.
+type Snapshot struct {
+	configYaml  []byte
+	overrides   map[string]any
+	keyLookups  map[string]string
+	typeLookups map[string]string
+}
+
+func TakeSnapshot() Snapshot {
+	configDataLock.RLock()
+	defer configDataLock.RUnlock()
+
+	// The config holds maps that overlays write into, so a plain copy would change along with it
+	configYaml, _ := yaml.Marshal(configData)
+
+	return Snapshot{
+		configYaml:  configYaml,
+		overrides:   maps.Clone(overrides),
+		keyLookups:  maps.Clone(keyLookups),
+		typeLookups: maps.Clone(typeLookups),
+	}
+}
+
+// Restore puts the config back the way it was when the snapshot was taken
+func (s Snapshot) Restore() {
+
+	restored := Config{}
+	yaml.Unmarshal(s.configYaml, &restored)
+
+	configDataLock.Lock()
+	defer configDataLock.Unlock()
+
+	// Anything not kept in yaml is worked out again by Validate()
+	configData = restored
+	overrides = maps.Clone(s.overrides)
+	keyLookups = maps.Clone(s.keyLookups)
+	typeLookups = maps.Clone(s.typeLookups)
+}
@@ -0,0 +1,44 @@
+package configs
+
+import (
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+
+	defer TakeSnapshot().Restore()
+
+	AddOverlayOverrides(map[string]any{
+		"FilePaths.DataFiles":                             "before",
+		"Integrations.LLM.Profiles.default.Model":         "before-model",
+		"Integrations.LLM.Quotas.Roles.admin.DailyTokens": 5,
+	})
+
+	snapshot := TakeSnapshot()
+
+	AddOverlayOverrides(map[string]any{
+		"FilePaths.DataFiles":                     "after",
+		"Integrations.LLM.Profiles.default.Model": "after-model",
+		"Integrations.LLM.Profiles.extra.Model":   "extra",
+	})
+
+	if GetConfig().FilePaths.DataFiles != "after" {
+		t.Fatalf("Expected the override to apply, got \"%s\"", GetConfig().FilePaths.DataFiles)
+	}
+
+	snapshot.Restore()
+
+	c := GetConfig()
+	if c.FilePaths.DataFiles != "before" {
+		t.Errorf("Expected DataFiles to be \"before\", got \"%s\"", c.FilePaths.DataFiles)
+	}
+	if c.Integrations.LLM.Profiles["default"].Model != "before-model" {
+		t.Errorf("Expected the default profile model to be \"before-model\", got \"%s\"", c.Integrations.LLM.Profiles["default"].Model)
+	}
+	if _, ok := c.Integrations.LLM.Profiles["extra"]; ok {
+		t.Errorf("Expected the extra profile to be gone")
+	}
+	if GetOverrides()["FilePaths.DataFiles"] != "before" {
+		t.Errorf("Expected the overrides to be restored, got %v", GetOverrides()["FilePaths.DataFiles"])
+	}
+}
@@ -1,10 +1,18 @@
 package connections
 
+import (
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/term"
+)
+
 const (
 	// DefaultScreenWidth is the default width of the screen
 	DefaultScreenWidth = 80
 	// DefaultScreenHeight is the default height of the screen
 	DefaultScreenHeight = 24
+	// maxTTypeReplies is how many times to ask a client for its terminal type, in case it never repeats itself
+	maxTTypeReplies = 5
 )
 
 type ClientSettings struct {
@@ -12,12 +20,25 @@ type ClientSettings struct {
 	// Is MSP enabled?
 	MSPEnabled        bool // Do they accept sound in their client?
 	SendTelnetGoAhead bool // Defaults false, should we send a IAC GA after prompts?
+	Terminal          TerminalSettings
 }
 
 func (c ClientSettings) IsMsp() bool {
 	return c.MSPEnabled
 }
 
+// TerminalSettings are what the client reported about itself through TTYPE/MTTS
+type TerminalSettings struct {
+	ClientName    string          // First TTYPE reply, such as MUDLET
+	TerminalType  string          // Second TTYPE reply, such as XTERM-256COLOR
+	MTTS          term.MTTS       // Bitflags from the MTTS reply, if any
+	TTypeReplies  []string        // Every TTYPE reply so far, to know when the client has run out
+	ColorDepth    term.ColorDepth // How many colors to send, colors are downgraded to fit
+	UTF8          bool
+	ScreenReader  bool
+	MouseTracking bool
+}
+
 type DisplaySettings struct {
 	ScreenWidth  uint32
 	ScreenHeight uint32
@@ -37,3 +58,58 @@ func (c DisplaySettings) GetScreenHeight() int {
 	}
 	return int(c.ScreenHeight)
 }
+
+// AddTTypeReply records a TTYPE reply from the client, returning whether to ask for the next one.
+// Clients cycle through their name, terminal type and MTTS flags, then repeat the last reply once they run out.
+func (t *TerminalSettings) AddTTypeReply(value string) (askAgain bool) {
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	if len(t.TTypeReplies) > 0 && t.TTypeReplies[len(t.TTypeReplies)-1] == value {
+		return false
+	}
+
+	t.TTypeReplies = append(t.TTypeReplies, value)
+
+	// MTTS is always the last reply
+	if mtts, ok := term.ParseMTTS(value); ok {
+		t.MTTS = mtts
+		t.ColorDepth = mtts.ColorDepth()
+		t.UTF8 = mtts.Has(term.MttsUTF8)
+		t.ScreenReader = mtts.Has(term.MttsScreenReader)
+		t.MouseTracking = mtts.Has(term.MttsMouseTracking)
+		return false
+	}
+
+	switch len(t.TTypeReplies) {
+	case 1:
+		t.ClientName = value
+	case 2:
+		t.TerminalType = value
+	}
+
+	// Without MTTS the terminal type is the best guess there is
+	if depth := term.TerminalColorDepth(value); depth != term.ColorDepthUnknown {
+		t.ColorDepth = depth
+	}
+
+	return len(t.TTypeReplies) < maxTTypeReplies
+}
+
+// NegotiatedColorDepth is the color depth the client's TTYPE/MTTS replies called for,
+// for going back to after it was changed by hand.
+func (t TerminalSettings) NegotiatedColorDepth() term.ColorDepth {
+
+	depth := term.ColorDepthUnknown
+
+	for _, value := range t.TTypeReplies {
+		if mtts, ok := term.ParseMTTS(value); ok {
+			return mtts.ColorDepth()
+		}
+		if d := term.TerminalColorDepth(value); d != term.ColorDepthUnknown {
+			depth = d
+		}
+	}
+
+	return depth
+}
@@ -0,0 +1,88 @@
+package connections
+
+import (
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTTypeReply(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		replies       []string
+		asked         int // How many replies were asked for before stopping
+		clientName    string
+		terminalType  string
+		colorDepth    term.ColorDepth
+		screenReader  bool
+		mouseTracking bool
+	}{
+		{
+			name:         "MTTS",
+			replies:      []string{"Mudlet", "XTERM-256COLOR", "MTTS 141"},
+			asked:        3,
+			clientName:   "MUDLET",
+			terminalType: "XTERM-256COLOR",
+			colorDepth:   term.ColorDepth256,
+		},
+		{
+			name:          "MTTS truecolor and screen reader",
+			replies:       []string{"tintin++", "XTERM", "MTTS 343"},
+			asked:         3,
+			clientName:    "TINTIN++",
+			terminalType:  "XTERM",
+			colorDepth:    term.ColorDepthTrue,
+			screenReader:  true,
+			mouseTracking: true,
+		},
+		{
+			name:         "No MTTS",
+			replies:      []string{"xterm-256color", "xterm-256color"},
+			asked:        2,
+			clientName:   "XTERM-256COLOR",
+			terminalType: "",
+			colorDepth:   term.ColorDepth256,
+		},
+		{
+			name:         "Dumb terminal",
+			replies:      []string{"dumb", "dumb"},
+			asked:        2,
+			clientName:   "DUMB",
+			terminalType: "",
+			colorDepth:   term.ColorDepthMono,
+		},
+		{
+			name:         "Never repeats",
+			replies:      []string{"a", "b", "c", "d", "e", "f", "g"},
+			asked:        maxTTypeReplies,
+			clientName:   "A",
+			terminalType: "B",
+			colorDepth:   term.ColorDepthUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ts := TerminalSettings{}
+
+			asked := 0
+			for _, reply := range tt.replies {
+				asked++
+				if !ts.AddTTypeReply(reply) {
+					break
+				}
+			}
+
+			assert.Equal(t, tt.asked, asked)
+			assert.Equal(t, tt.clientName, ts.ClientName)
+			assert.Equal(t, tt.terminalType, ts.TerminalType)
+			assert.Equal(t, tt.colorDepth, ts.ColorDepth)
+			assert.Equal(t, tt.colorDepth, ts.NegotiatedColorDepth())
+			assert.Equal(t, tt.screenReader, ts.ScreenReader)
+			assert.Equal(t, tt.mouseTracking, ts.MouseTracking)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package connections
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+
+	"github.com/GoMudEngine/GoMud/internal/term"
+)
+
+//
+// MCCP (MUD Client Compression Protocol)
+// v2 compresses what the server sends, v3 what the client sends.
+// Both are a zlib stream that starts right after a telnet subnegotiation.
+//
+
+var (
+	ErrCompressionWebsocket = errors.New("websocket connections are not compressed")
+)
+
+// StartCompression sends the MCCP2 start sequence, then compresses everything written after it.
+func (cd *ConnectionDetails) StartCompression() error {
+
+	if cd.wsConn != nil {
+		return ErrCompressionWebsocket
+	}
+
+	cd.writeLock.Lock()
+	defer cd.writeLock.Unlock()
+
+	if cd.compressor != nil {
+		return nil
+	}
+
+	// The start sequence is the last thing sent uncompressed
+	if _, err := cd.conn.Write(term.Mccp2Start.BytesWithPayload(nil)); err != nil {
+		return err
+	}
+
+	cd.compressor = zlib.NewWriter(cd.conn)
+
+	return nil
+}
+
+// StopCompression ends the compressed stream, anything written after it is sent uncompressed.
+func (cd *ConnectionDetails) StopCompression() error {
+
+	cd.writeLock.Lock()
+	defer cd.writeLock.Unlock()
+
+	return cd.stopCompression()
+}
+
+// stopCompression expects writeLock to already be held
+func (cd *ConnectionDetails) stopCompression() error {
+
+	if cd.compressor == nil {
+		return nil
+	}
+
+	err := cd.compressor.Close()
+	cd.compressor = nil
+
+	return err
+}
+
+// Compressed returns whether output is currently being compressed (MCCP2)
+func (cd *ConnectionDetails) Compressed() bool {
+	cd.writeLock.Lock()
+	defer cd.writeLock.Unlock()
+
+	return cd.compressor != nil
+}
+
+// AllowInputCompression sets whether the client may start compressing what it sends (MCCP3)
+func (cd *ConnectionDetails) AllowInputCompression(allow bool) {
+	cd.inputCompressionAllowed.Store(allow)
+}
+
+// InputCompressionAllowed returns whether the client agreed to MCCP3
+func (cd *ConnectionDetails) InputCompressionAllowed() bool {
+	return cd.inputCompressionAllowed.Load()
+}
+
+// writeCompressed expects writeLock to already be held
+func (cd *ConnectionDetails) writeCompressed(p []byte) (int, error) {
+
+	if _, err := cd.compressor.Write(p); err != nil {
+		return 0, err
+	}
+
+	// Flush so the client gets it now, rather than when the buffer fills up
+	if err := cd.compressor.Flush(); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// readTelnet reads from the connection, inflating the input once the client has started MCCP3.
+// Only the goroutine reading from the connection touches the reader state.
+func (cd *ConnectionDetails) readTelnet(p []byte) (int, error) {
+
+	if cd.inflateSrc != nil {
+
+		if cd.inflater == nil {
+			z, err := zlib.NewReader(cd.inflateSrc)
+			if err != nil {
+				return 0, err
+			}
+			cd.inflater = z
+		}
+
+		n, err := cd.inflater.Read(p)
+		if err == io.EOF {
+			// The client stopped compressing, anything after the stream is uncompressed
+			cd.inflater.Close()
+			cd.inflater = nil
+			cd.rawSrc = cd.inflateSrc
+			cd.inflateSrc = nil
+			err = nil
+		}
+
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+
+	var src io.Reader = cd.conn
+	if cd.rawSrc != nil {
+		src = cd.rawSrc
+	}
+
+	n, err := src.Read(p)
+	if err != nil || !cd.inputCompressionAllowed.Load() {
+		return n, err
+	}
+
+	before, compressed, found := term.SplitMccp3Start(p[:n])
+	if !found {
+		return n, nil
+	}
+
+	// Whatever followed the start sequence is already compressed, so it's read back through the inflater.
+	// bufio.Reader is an io.ByteReader, so the inflater won't read past the end of the stream.
+	pending := bytes.Clone(compressed)
+	cd.inflateSrc = bufio.NewReader(io.MultiReader(bytes.NewReader(pending), src))
+	cd.rawSrc = nil
+	cd.inputWasCompressed.Store(true)
+
+	if len(before) == 0 {
+		return cd.readTelnet(p)
+	}
+
+	return len(before), nil
+}
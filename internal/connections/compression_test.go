@@ -0,0 +1,122 @@
+package connections
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPipeConnection(t *testing.T) (*ConnectionDetails, net.Conn) {
+	t.Helper()
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+
+	return NewConnectionDetails(1, server, nil, nil), client
+}
+
+func TestCompression_MCCP2(t *testing.T) {
+
+	cd, client := newPipeConnection(t)
+
+	written := make(chan error, 1)
+	go func() {
+		written <- func() error {
+			if _, err := cd.Write([]byte("before\n")); err != nil {
+				return err
+			}
+			if err := cd.StartCompression(); err != nil {
+				return err
+			}
+			if _, err := cd.Write([]byte("You hit the rat!\nThe rat hits you!\n")); err != nil {
+				return err
+			}
+			if err := cd.StopCompression(); err != nil {
+				return err
+			}
+			_, err := cd.Write([]byte("after"))
+			return err
+		}()
+	}()
+
+	r := bufio.NewReader(client)
+
+	buf := make([]byte, len("before\r\n")+len(term.Mccp2Start.Chars))
+	_, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte("before\r\n"), term.Mccp2Start.Chars...), buf)
+
+	zr, err := zlib.NewReader(r)
+	require.NoError(t, err)
+
+	// Each write is flushed, so it can be read before the stream ends
+	buf = make([]byte, len("You hit the rat!\r\nThe rat hits you!\r\n"))
+	_, err = io.ReadFull(zr, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "You hit the rat!\r\nThe rat hits you!\r\n", string(buf))
+
+	rest, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+	require.NoError(t, zr.Close())
+
+	buf = make([]byte, len("after"))
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "after", string(buf))
+
+	require.NoError(t, <-written)
+	assert.False(t, cd.Compressed())
+}
+
+func TestCompression_MCCP3(t *testing.T) {
+
+	cd, client := newPipeConnection(t)
+	cd.AllowInputCompression(true)
+
+	compressed := bytes.Buffer{}
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte("say hi\r\n"))
+	zw.Close()
+
+	// The start sequence and compressed input can arrive in the same read as uncompressed input
+	input := []byte("look\r\n")
+	input = append(input, term.Mccp3Start.Chars...)
+	input = append(input, compressed.Bytes()...)
+	input = append(input, []byte("quit\r\n")...)
+
+	go client.Write(input)
+
+	got := []byte{}
+	buf := make([]byte, 64)
+	for len(got) < len("look\r\nsay hi\r\nquit\r\n") {
+		n, err := cd.Read(buf)
+		require.NoError(t, err)
+		got = append(got, buf[:n]...)
+	}
+
+	assert.Equal(t, "look\r\nsay hi\r\nquit\r\n", string(got))
+}
+
+func TestCompression_MCCP3NotAgreed(t *testing.T) {
+
+	cd, client := newPipeConnection(t)
+
+	input := append([]byte("look"), term.Mccp3Start.Chars...)
+	go client.Write(input)
+
+	buf := make([]byte, 64)
+	n, err := cd.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, input, buf[:n])
+}
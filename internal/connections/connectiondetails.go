@@ -1,8 +1,13 @@
 package connections
 
 import (
+	"bufio"
+	"compress/zlib"
+	"crypto/tls"
 	"errors"
+	"io"
 	"net"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,6 +27,10 @@ const (
 	MaxHistory = 10
 )
 
+var (
+	ErrNotTransferable = errors.New("connection can't be handed to another process")
+)
+
 type InputHistory struct {
 	inhistory bool
 	position  int
@@ -124,6 +133,18 @@ type ConnectionDetails struct {
 	inputDisabled     bool
 	clientSettings    ClientSettings
 	heartbeat         *heartbeatManager
+	// MCCP compression (telnet only)
+	writeLock               sync.Mutex
+	compressor              *zlib.Writer  // Set while output is compressed (MCCP2)
+	inputCompressionAllowed atomic.Bool   // Client agreed to MCCP3
+	inflateSrc              *bufio.Reader // Raw input once the client starts compressing (MCCP3)
+	inflater                io.ReadCloser
+	rawSrc                  *bufio.Reader // Input left over after the client stops compressing
+	inputWasCompressed      atomic.Bool   // Set once the client starts compressing, since its input may be buffered from then on
+	// Session recording
+	recorderLock sync.Mutex
+	recorder     SessionRecorder
+	inputMasked  atomic.Bool // Set while a password prompt is waiting for a reply
 }
 
 func (cd *ConnectionDetails) IsLocal() bool {
@@ -153,10 +174,34 @@ func (cd *ConnectionDetails) IsLocal() bool {
 	return ip.IsLoopback()
 }
 
+// IsSecure returns whether the connection is encrypted, either a TLS telnet port or the web client over https
+func (cd *ConnectionDetails) IsSecure() bool {
+
+	conn := cd.conn
+	if cd.wsConn != nil {
+		conn = cd.wsConn.NetConn()
+	}
+
+	_, ok := conn.(*tls.Conn)
+	return ok
+}
+
 func (cd *ConnectionDetails) IsWebSocket() bool {
 	return cd.wsConn != nil
 }
 
+// Protocol returns telnet or websocket, with -tls on the end when the connection is encrypted
+func (cd *ConnectionDetails) Protocol() string {
+	protocol := `telnet`
+	if cd.IsWebSocket() {
+		protocol = `websocket`
+	}
+	if cd.IsSecure() {
+		protocol += `-tls`
+	}
+	return protocol
+}
+
 // If HandleInput receives an error, we shouldn't pass input to the game logic
 func (cd *ConnectionDetails) HandleInput(ci *ClientInput, handlerState map[string]any) (doNextHandler bool, lastHandler string, err error) {
 	cd.handlerMutex.Lock()
@@ -213,6 +258,7 @@ func (cd *ConnectionDetails) AddInputHandler(name string, newInputHandler InputH
 	cd.inputHandlers = append(cd.inputHandlers, newInputHandler)
 }
 
+// Write expects the connections lock to already be held, which SendTo() and Broadcast() do.
 func (cd *ConnectionDetails) Write(p []byte) (n int, err error) {
 
 	p = []byte(strings.ReplaceAll(string(p), "\n", "\r\n"))
@@ -221,6 +267,8 @@ func (cd *ConnectionDetails) Write(p []byte) (n int, err error) {
 		return 0, nil
 	}
 
+	cd.recordOutput(p)
+
 	if cd.wsConn != nil {
 		cd.wsLock.Lock()
 		defer cd.wsLock.Unlock()
@@ -241,6 +289,13 @@ func (cd *ConnectionDetails) Write(p []byte) (n int, err error) {
 		return len(p), nil
 	}
 
+	cd.writeLock.Lock()
+	defer cd.writeLock.Unlock()
+
+	if cd.compressor != nil {
+		return cd.writeCompressed(p)
+	}
+
 	return cd.conn.Write(p)
 }
 
@@ -252,11 +307,14 @@ func (cd *ConnectionDetails) Read(p []byte) (n int, err error) {
 		if err != nil {
 			return 0, err
 		}
-		copy(p, message)
+		n = copy(p, message)
+		cd.recordInput(p[:n])
 		return len(message), nil
 	}
 
-	return cd.conn.Read(p)
+	n, err = cd.readTelnet(p)
+	cd.recordInput(p[:n])
+	return n, err
 }
 
 func (cd *ConnectionDetails) Close() {
@@ -264,13 +322,46 @@ func (cd *ConnectionDetails) Close() {
 		cd.heartbeat.stop()
 	}
 
+	cd.StopRecording()
+
 	if cd.wsConn != nil {
 		cd.wsConn.Close()
 		return
 	}
+
+	// Finish the compressed stream so the client doesn't see it as corrupt,
+	// without waiting around on a client that has stopped reading.
+	cd.writeLock.Lock()
+	if cd.compressor != nil {
+		cd.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		cd.stopCompression()
+	}
+	cd.writeLock.Unlock()
+
 	cd.conn.Close()
 }
 
+// File returns a copy of the telnet socket, so it can be handed to another process.
+// Encrypted and websocket connections can't be, and neither can one the client has compressed input on,
+// since some of it may already be buffered here.
+func (cd *ConnectionDetails) File() (*os.File, error) {
+
+	if cd.wsConn != nil {
+		return nil, ErrNotTransferable
+	}
+
+	if cd.inputWasCompressed.Load() {
+		return nil, ErrNotTransferable
+	}
+
+	fc, ok := cd.conn.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, ErrNotTransferable
+	}
+
+	return fc.File()
+}
+
 func (cd *ConnectionDetails) RemoteAddr() net.Addr {
 	if cd.wsConn != nil {
 		return cd.wsConn.RemoteAddr()
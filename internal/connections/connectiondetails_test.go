@@ -0,0 +1,26 @@
+package connections
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecure(t *testing.T) {
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+
+	plain := NewConnectionDetails(1, server, nil, nil)
+	assert.False(t, plain.IsSecure())
+	assert.Equal(t, `telnet`, plain.Protocol())
+
+	secure := NewConnectionDetails(2, tls.Server(server, &tls.Config{}), nil, nil)
+	assert.True(t, secure.IsSecure())
+	assert.Equal(t, `telnet-tls`, secure.Protocol())
+}
@@ -61,6 +61,31 @@ func Add(conn net.Conn, wsConn *websocket.Conn) *ConnectionDetails {
 	return connDetails
 }
 
+// Restore adds a connection carried over from before a copyover, keeping its id and settings
+func Restore(id ConnectionId, conn net.Conn, cs ClientSettings) *ConnectionDetails {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	// New connections must never be given an id that is already taken
+	if id > connectCounter {
+		connectCounter = id
+	}
+
+	connDetails := NewConnectionDetails(
+		id,
+		conn,
+		nil,
+		nil,
+	)
+
+	connDetails.clientSettings = cs
+
+	netConnections[id] = connDetails
+
+	return connDetails
+}
+
 // Returns the total number of connections
 func Get(id ConnectionId) *ConnectionDetails {
 	lock.Lock()
@@ -0,0 +1,18 @@
+package connections
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+)
+
+func init() {
+	metrics.NewGaugeVecFunc(`gomud_connections`, `Open connections, by protocol.`, `protocol`, func() map[string]float64 {
+		lock.RLock()
+		defer lock.RUnlock()
+
+		counts := map[string]float64{`telnet`: 0, `websocket`: 0}
+		for _, cd := range netConnections {
+			counts[cd.Protocol()]++
+		}
+		return counts
+	})
+}
@@ -0,0 +1,68 @@
+package connections
+
+// SessionRecorder receives a copy of everything sent to and received from a connection
+type SessionRecorder interface {
+	RecordOutput(p []byte)
+	// masked is set while the player is answering a password prompt, so what they type isn't kept
+	RecordInput(p []byte, masked bool)
+	Close() error
+}
+
+// StartRecording attaches a recorder, replacing (and closing) any recorder already attached
+func (cd *ConnectionDetails) StartRecording(r SessionRecorder) {
+	cd.recorderLock.Lock()
+	old := cd.recorder
+	cd.recorder = r
+	cd.recorderLock.Unlock()
+
+	if old != nil && old != r {
+		old.Close()
+	}
+}
+
+// StopRecording detaches and closes the recorder, returning false if there wasn't one
+func (cd *ConnectionDetails) StopRecording() bool {
+	cd.recorderLock.Lock()
+	old := cd.recorder
+	cd.recorder = nil
+	cd.recorderLock.Unlock()
+
+	if old == nil {
+		return false
+	}
+
+	old.Close()
+	return true
+}
+
+func (cd *ConnectionDetails) IsRecording() bool {
+	cd.recorderLock.Lock()
+	defer cd.recorderLock.Unlock()
+	return cd.recorder != nil
+}
+
+func (cd *ConnectionDetails) recordOutput(p []byte) {
+	cd.recorderLock.Lock()
+	r := cd.recorder
+	cd.recorderLock.Unlock()
+
+	if r != nil && len(p) > 0 {
+		r.RecordOutput(p)
+	}
+}
+
+func (cd *ConnectionDetails) recordInput(p []byte) {
+	cd.recorderLock.Lock()
+	r := cd.recorder
+	cd.recorderLock.Unlock()
+
+	if r != nil && len(p) > 0 {
+		r.RecordInput(p, cd.inputMasked.Load())
+	}
+}
+
+// SetInputMasked marks whether the player is answering a password prompt.
+// Whatever they type meanwhile is masked in any recording.
+func (cd *ConnectionDetails) SetInputMasked(masked bool) {
+	cd.inputMasked.Store(masked)
+}
@@ -0,0 +1,56 @@
+package connections
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"time"
+)
+
+// VirtualConn stands in for a network connection for players the server drives itself,
+// such as test harness players and simulated adventurers.
+// Nothing is ever read from it, input is given straight to the world.
+// Whatever is written is kept, unless Discard is set.
+type VirtualConn struct {
+	Discard bool // Throw away anything written, for when nobody will look at it
+
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (c *VirtualConn) Write(p []byte) (int, error) {
+	if c.Discard {
+		return len(p), nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.buf.Write(p)
+}
+
+// Written is a copy of everything written since the last Reset
+func (c *VirtualConn) Written() []byte {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return bytes.Clone(c.buf.Bytes())
+}
+
+func (c *VirtualConn) Reset() {
+	c.lock.Lock()
+	c.buf.Reset()
+	c.lock.Unlock()
+}
+
+func (c *VirtualConn) Read(p []byte) (int, error) { return 0, net.ErrClosed }
+func (c *VirtualConn) Close() error               { return nil }
+func (c *VirtualConn) LocalAddr() net.Addr        { return virtualAddr{} }
+func (c *VirtualConn) RemoteAddr() net.Addr       { return virtualAddr{} }
+
+func (c *VirtualConn) SetDeadline(t time.Time) error      { return nil }
+func (c *VirtualConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *VirtualConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type virtualAddr struct{}
+
+func (virtualAddr) Network() string { return `virtual` }
+func (virtualAddr) String() string  { return `virtual` }
@@ -14,8 +14,12 @@ type LLMConversationConfig struct {
 	Greeting string `yaml:"greeting,omitempty"`
 	// Optional farewell message
 	Farewell string `yaml:"farewell,omitempty"`
+	// Optional LLM profile to use instead of the configured ConversationProfile
+	Profile string `yaml:"profile,omitempty"`
 	// Time in seconds before conversation times out
 	IdleTimeout int `yaml:"idletimeout,omitempty"`
+	// Actions the NPC may choose to take while talking with a player
+	Tools []ConversationTool `yaml:"tools,omitempty"`
 }
 
 type ConversationData struct {
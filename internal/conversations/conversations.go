@@ -1,15 +1,19 @@
 package conversations
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/configs"
-	"github.com/GoMudEngine/GoMud/internal/integrations/llm"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/llm"
 	"github.com/GoMudEngine/GoMud/internal/mobinterfaces"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
@@ -25,26 +29,20 @@ var (
 	conversationMutex    sync.RWMutex  // Mutex for conversations map
 	shutdownChan         chan struct{} // Channel to signal shutdown
 	shutdownOnce         sync.Once     // Ensure shutdown is called only once
-	// Add conversation memory storage
-	conversationMemory = map[string]map[string]*ConversationMemory{} // Map of mobname -> playername -> memory
-	memoryMutex        sync.RWMutex                                  // Mutex for conversationMemory map
+	pendingDestroy       []int         // Conversations waiting on DestroyPending()
+	pendingDestroyMutex  sync.Mutex
 )
 
-// ConversationMemory stores persistent information between conversations
-type ConversationMemory struct {
-	LastInteraction time.Time         // When the last conversation ended
-	RecentTopics    []string          // Topics discussed in recent conversations
-	Context         []string          // Recent conversation context that was important
-	CustomData      map[string]string // Any custom data to remember
-	ExpirationTime  time.Duration     // How long to remember this conversation
-}
+// Tags identifying conversation requests sent to the LLM
+const (
+	LLMTagReply    = `conversation-reply`
+	LLMTagFarewell = `conversation-farewell`
+	LLMTagSummary  = `conversation-summary`
+)
 
 // Init initializes the conversations package
 func Init() {
 	shutdownChan = make(chan struct{})
-
-	// Start memory cleanup goroutine
-	go cleanupMemory()
 }
 
 // Shutdown gracefully shuts down the conversations package
@@ -77,11 +75,12 @@ func destroyConversation(conversationId int) {
 		return
 	}
 
-	// Check if conversation is valid before storing memory
-	if conv.MobInstanceId1 > 0 || conv.MobInstanceId2 > 0 {
-		// Store conversation memory before destroying it
-		// This is a separate function that has its own safety checks
-		storeConversationMemory(conv)
+	// Conversations that didn't end gracefully are still worth remembering
+	conv.memorize()
+
+	// No point waiting on a reply nobody will hear
+	if conv.PendingJobId > 0 {
+		llm.Cancel(conv.PendingJobId)
 	}
 
 	// Only clear conversation IDs from mob instances, not from players
@@ -118,21 +117,32 @@ func Destroy(conversationId int) {
 		// During shutdown, just call destroyConversation directly
 		destroyConversation(conversationId)
 	default:
-		// During normal operation, use a goroutine but check shutdown
-		go func() {
-			select {
-			case <-shutdownChan:
-				return
-			default:
-				destroyConversation(conversationId)
-			}
-		}()
+		// During normal operation this is often called with the conversation lock held,
+		// so the conversation is only queued up here, and torn down by DestroyPending()
+		pendingDestroyMutex.Lock()
+		pendingDestroy = append(pendingDestroy, conversationId)
+		pendingDestroyMutex.Unlock()
+	}
+}
+
+// DestroyPending tears down conversations queued by Destroy(), in the order they were queued.
+// Called from the round loop, so they always end at the same point in the game.
+// Must not be called with the conversation lock held.
+func DestroyPending() {
+	pendingDestroyMutex.Lock()
+	ids := pendingDestroy
+	pendingDestroy = nil
+	pendingDestroyMutex.Unlock()
+
+	for _, id := range ids {
+		destroyConversation(id)
 	}
 }
 
 // Conversation represents an active conversation between two entities
 type Conversation struct {
 	Id             int
+	MobId          int    // Template id of mob1 (if it's a mob)
 	MobInstanceId1 int    // For mob1 (if it's a mob)
 	MobInstanceId2 int    // For mob2 (if it's a mob)
 	PlayerName1    string // For participant1 (if it's a player)
@@ -151,6 +161,11 @@ type Conversation struct {
 	HasGreeted    bool // Track if initial greeting has been given
 	HasFarewelled bool // Track if farewell has been given
 	Active        bool // Whether the conversation is currently active
+	// Request currently waiting on the LLM
+	PendingJobId    uint64
+	PendingInput    string // The player input the pending request is responding to
+	PendingGreeting bool   // Whether the pending request is the first reply (greeting)
+	Memorized       bool   // Whether the conversation has been sent off to be remembered
 }
 
 // Returns a non empty ConversationId if successful
@@ -291,39 +306,17 @@ func AttemptConversation(initiatorMobId int, initatorInstanceId int, initiatorNa
 
 	// mudlog.Debug("AttemptConversation()", "info", fmt.Sprintf("Created dynamic conversation: %+v", conversations[conversationUniqueId]))
 
-	// Check for previous conversation memory and incorporate it
-	var memory *ConversationMemory
 	if !isPlayer1 {
-		memory = getConversationMemory(mob1Name, mob2Name)
-	} else {
-		memory = getConversationMemory(mob2Name, mob1Name)
-	}
-
-	// If we have memory, add it to the conversation context
-	if memory != nil && len(memory.Context) > 0 {
-		lastInteraction := memory.LastInteraction.Format("15:04")
-		timeAgo := time.Since(memory.LastInteraction).Round(time.Minute)
-
-		// Add memory context to new conversation
-		if llmConfig.IncludeNames {
-			conversations[conversationUniqueId].Context = append(
-				conversations[conversationUniqueId].Context,
-				fmt.Sprintf("Previous conversation %v ago around %s:",
-					timeAgo, lastInteraction))
-		}
-
-		// Add recent topics if available
-		if len(memory.RecentTopics) > 0 {
-			topics := strings.Join(memory.RecentTopics, ", ")
-			conversations[conversationUniqueId].Context = append(
-				conversations[conversationUniqueId].Context,
-				fmt.Sprintf("You previously discussed: %s", topics))
+		conversations[conversationUniqueId].MobId = initiatorMobId
+
+		// Bring what the mob knows about the player up to date
+		if isPlayer2 {
+			mob := mobs.GetInstance(initatorInstanceId)
+			user := users.GetByUserId(participantInstanceId)
+			if mob != nil && user != nil {
+				refreshMemoryFacts(mob, user)
+			}
 		}
-
-		// Add previous context
-		conversations[conversationUniqueId].Context = append(
-			conversations[conversationUniqueId].Context,
-			memory.Context...)
 	}
 
 	return conversationUniqueId
@@ -372,19 +365,19 @@ func GetNextActions(convId int) (mob1Id int, mob2Id int, actions []string) {
 		mob1Interface := mobinterfaces.GetInstance(c.MobInstanceId1)
 		if mob1Interface == nil {
 			mudlog.Error("Conversation", "error", fmt.Sprintf("Mob1 became invalid between validation and use in conversation %d (ID: %d)", convId, c.MobInstanceId1))
-			go func() { Destroy(convId) }()
+			Destroy(convId)
 			return 0, 0, []string{}
 		}
 		mob1Concrete, ok := mob1Interface.(*mobs.Mob)
 		if !ok || mob1Concrete == nil {
 			mudlog.Error("Conversation", "error", fmt.Sprintf("Mob1 type changed between validation and use in conversation %d (ID: %d)", convId, c.MobInstanceId1))
-			go func() { Destroy(convId) }()
+			Destroy(convId)
 			return 0, 0, []string{}
 		}
 		id1 = mob1Concrete.GetInstanceId()
 		if id1 == 0 {
 			mudlog.Error("Conversation", "error", fmt.Sprintf("Mob1 instance ID became 0 between validation and use in conversation %d", convId))
-			go func() { Destroy(convId) }()
+			Destroy(convId)
 			return 0, 0, []string{}
 		}
 	} else {
@@ -396,19 +389,19 @@ func GetNextActions(convId int) (mob1Id int, mob2Id int, actions []string) {
 		mob2Interface := mobinterfaces.GetInstance(c.MobInstanceId2)
 		if mob2Interface == nil {
 			mudlog.Error("Conversation", "error", fmt.Sprintf("Mob2 became invalid between validation and use in conversation %d (ID: %d)", convId, c.MobInstanceId2))
-			go func() { Destroy(convId) }()
+			Destroy(convId)
 			return 0, 0, []string{}
 		}
 		mob2Concrete, ok := mob2Interface.(*mobs.Mob)
 		if !ok || mob2Concrete == nil {
 			mudlog.Error("Conversation", "error", fmt.Sprintf("Mob2 type changed between validation and use in conversation %d (ID: %d)", convId, c.MobInstanceId2))
-			go func() { Destroy(convId) }()
+			Destroy(convId)
 			return 0, 0, []string{}
 		}
 		id2 = mob2Concrete.GetInstanceId()
 		if id2 == 0 {
 			mudlog.Error("Conversation", "error", fmt.Sprintf("Mob2 instance ID became 0 between validation and use in conversation %d", convId))
-			go func() { Destroy(convId) }()
+			Destroy(convId)
 			return 0, 0, []string{}
 		}
 	} else {
@@ -479,14 +472,14 @@ func (c *Conversation) validateMobInstances() (mob1 *mobs.Mob, mob2 *mobs.Mob, v
 
 			if mob1Interface == nil {
 				mudlog.Error("Conversation", "error", fmt.Sprintf("Mob1 instance not found in conversation %d (ID: %d)", c.Id, c.MobInstanceId1))
-				go func() { Destroy(c.Id) }()
+				Destroy(c.Id)
 				return nil, nil, false
 			}
 
 			mob1Concrete, ok := mob1Interface.(*mobs.Mob)
 			if !ok || mob1Concrete == nil {
 				mudlog.Error("Conversation", "error", fmt.Sprintf("Invalid mob1 type in conversation %d (ID: %d)", c.Id, c.MobInstanceId1))
-				go func() { Destroy(c.Id) }()
+				Destroy(c.Id)
 				return nil, nil, false
 			}
 			mob1 = mob1Concrete
@@ -499,14 +492,14 @@ func (c *Conversation) validateMobInstances() (mob1 *mobs.Mob, mob2 *mobs.Mob, v
 
 			if mob2Interface == nil {
 				mudlog.Error("Conversation", "error", fmt.Sprintf("Mob2 instance not found in conversation %d (ID: %d)", c.Id, c.MobInstanceId2))
-				go func() { Destroy(c.Id) }()
+				Destroy(c.Id)
 				return nil, nil, false
 			}
 
 			mob2Concrete, ok := mob2Interface.(*mobs.Mob)
 			if !ok || mob2Concrete == nil {
 				mudlog.Error("Conversation", "error", fmt.Sprintf("Invalid mob2 type in conversation %d (ID: %d)", c.Id, c.MobInstanceId2))
-				go func() { Destroy(c.Id) }()
+				Destroy(c.Id)
 				return nil, nil, false
 			}
 			mob2 = mob2Concrete
@@ -517,7 +510,7 @@ func (c *Conversation) validateMobInstances() (mob1 *mobs.Mob, mob2 *mobs.Mob, v
 			name := mob1.GetName()
 			if name == "" {
 				mudlog.Error("Conversation", "error", fmt.Sprintf("Invalid mob1 name in conversation %d (ID: %d)", c.Id, c.MobInstanceId1))
-				go func() { Destroy(c.Id) }()
+				Destroy(c.Id)
 				return nil, nil, false
 			}
 		}
@@ -526,7 +519,7 @@ func (c *Conversation) validateMobInstances() (mob1 *mobs.Mob, mob2 *mobs.Mob, v
 			name := mob2.GetName()
 			if name == "" {
 				mudlog.Error("Conversation", "error", fmt.Sprintf("Invalid mob2 name in conversation %d (ID: %d)", c.Id, c.MobInstanceId2))
-				go func() { Destroy(c.Id) }()
+				Destroy(c.Id)
 				return nil, nil, false
 			}
 		}
@@ -556,25 +549,13 @@ func getConversation(conversationId int) *Conversation {
 		// Only do maintenance if not shutting down
 		if util.Rand(50) == 0 { // 2% chance to do a quick maintenance
 			rNow := util.GetRoundCount()
-			for id, info := range conversations {
+			for _, id := range slices.Sorted(maps.Keys(conversations)) {
+				info := conversations[id]
 				// Check if mobs are still valid
 				mob1 := mobinterfaces.GetInstance(info.MobInstanceId1)
 				mob2 := mobinterfaces.GetInstance(info.MobInstanceId2)
 				if mob1 == nil || mob2 == nil || rNow-info.LastRound > 10 {
-					// During shutdown, destroy directly
-					select {
-					case <-shutdownChan:
-						destroyConversation(id)
-					default:
-						go func(id int) {
-							select {
-							case <-shutdownChan:
-								destroyConversation(id)
-							default:
-								Destroy(id)
-							}
-						}(id)
-					}
+					Destroy(id)
 				}
 			}
 		}
@@ -595,6 +576,10 @@ func GetConversation(conversationId int) *Conversation {
 }
 
 // ProcessPlayerInput handles a player's input in an active conversation
+// The LLM request is queued and the reply arrives later as an events.LLMResponse
+// which should be handed to HandleLLMResponse().
+// A non-empty string is returned only when there is something to say right away
+// without waiting on the LLM (such as a static greeting fallback)
 func ProcessPlayerInput(conversationId int, playerInput string) (string, error) {
 	conversationMutex.RLock()
 	conv := getConversation(conversationId)
@@ -613,8 +598,8 @@ func ProcessPlayerInput(conversationId int, playerInput string) (string, error)
 	// Update last activity
 	conv.LastActivity = time.Now()
 
-	// Check cooldown
-	if time.Since(conv.LastLLMTime) < conv.LLMCooldown {
+	// Check cooldown, and whether we're still waiting on a previous reply
+	if time.Since(conv.LastLLMTime) < conv.LLMCooldown || conv.PendingJobId > 0 {
 		return "", fmt.Errorf("please wait before speaking again")
 	}
 
@@ -625,7 +610,7 @@ func ProcessPlayerInput(conversationId int, playerInput string) (string, error)
 	}
 
 	// Build context for the LLM
-	context, err := conv.buildLLMContext(conv.PlayerName1, conv.PlayerName2)
+	context, err := conv.buildLLMContext(conv.PlayerName1, conv.PlayerName2, playerInput)
 	if err != nil {
 		return "", fmt.Errorf("failed to build conversation context: %v", err)
 	}
@@ -636,13 +621,14 @@ func ProcessPlayerInput(conversationId int, playerInput string) (string, error)
 	// Determine if this is the first message (needs greeting)
 	isFirstMessage := !conv.HasGreeted && conv.LLMConfig.Greeting != ""
 
+	prompt := "Respond to the player's input in character, maintaining your personality and knowledge."
+
 	// Special handling for first message
 	if isFirstMessage {
 		// Set greeting as given
 		conv.HasGreeted = true
 
 		// Generate a response that incorporates both greeting and an answer
-		var prompt string
 		if strings.Contains(conv.LLMConfig.Greeting, "*") {
 			// If greeting contains action indicators (like *looks up*), extract character's first words
 			parts := strings.Split(conv.LLMConfig.Greeting, "\"")
@@ -662,41 +648,75 @@ func ProcessPlayerInput(conversationId int, playerInput string) (string, error)
 				"The player has just approached you and said: \"%s\". Start your response with your greeting: \"%s\" and then naturally address their question or statement.",
 				playerInput, conv.LLMConfig.Greeting)
 		}
+	}
 
-		// Generate the combined greeting + response
-		// Get player user ID if this is a player
-		var userId int
-		if conv.IsPlayer2 && conv.PlayerName2 != "" {
-			// Only track token usage if this is a player (not mob-to-mob)
-			userId = conv.MobInstanceId2 // Player's user ID
-		}
-
-		response := llm.GenerateResponse(prompt, context, userId)
-		if response.Error != nil {
-			// If LLM fails, fall back to static greeting
+	jobId, err := llm.QueueRequest(conv.newLLMJob(LLMTagReply, prompt, context))
+	if err != nil {
+		// If LLM is unavailable, fall back to static greeting
+		if isFirstMessage {
 			return conv.LLMConfig.Greeting, nil
 		}
-
-		// Update conversation state
-		conv.Context = append(conv.Context, playerInput, response.Text)
-		if len(conv.Context) > conv.LLMConfig.MaxContextTurns*2 {
-			conv.Context = conv.Context[len(conv.Context)-conv.LLMConfig.MaxContextTurns*2:]
+		if errors.Is(err, llm.ErrQuotaExceeded) {
+			return conv.quotaFarewell(), nil
 		}
-		conv.LastLLMTime = time.Now()
+		return "", fmt.Errorf("failed to generate response: %v", err)
+	}
+
+	conv.PendingJobId = jobId
+	conv.PendingInput = playerInput
+	conv.PendingGreeting = isFirstMessage
+	conv.LastLLMTime = time.Now()
+
+	return "", nil
+}
+
+// quotaFarewell ends a conversation the LLM can no longer take part in,
+// returning the static farewell (if any) for the NPC to say
+func (c *Conversation) quotaFarewell() string {
+	c.Active = false
+	c.HasFarewelled = true
+	return c.LLMConfig.Farewell
+}
+
+// IsPendingResponse returns whether a conversation is still waiting on a particular request
+func IsPendingResponse(conversationId int, jobId uint64) bool {
+	conv := GetConversation(conversationId)
+	return conv != nil && conv.PendingJobId != 0 && conv.PendingJobId == jobId
+}
+
+// HandleLLMResponse completes a request queued by ProcessPlayerInput()
+// Returns the text the NPC should say
+func HandleLLMResponse(response events.LLMResponse) (string, error) {
+	conv := GetConversation(response.ConversationId)
+	if conv == nil {
+		return "", fmt.Errorf("conversation not found")
+	}
 
-		return response.Text, nil
+	if conv.PendingJobId != response.JobId {
+		return "", fmt.Errorf("response %d does not match pending request %d", response.JobId, conv.PendingJobId)
 	}
 
-	// For regular (non-first) messages, proceed as before
-	// Get player user ID if this is a player
-	var userId int
-	if conv.IsPlayer2 && conv.PlayerName2 != "" {
-		// Only track token usage if this is a player (not mob-to-mob)
-		userId = conv.MobInstanceId2 // Player's user ID
+	playerInput := conv.PendingInput
+	wasGreeting := conv.PendingGreeting
+
+	conv.PendingJobId = 0
+	conv.PendingInput = ""
+	conv.PendingGreeting = false
+
+	// Part of a streamed reply may have already been spoken before it failed.
+	// That's what the player heard, so that's what gets remembered.
+	if response.Error != nil && response.Streamed && response.Text != "" {
+		response.Error = nil
 	}
 
-	response := llm.GenerateResponse("Respond to the player's input in character, maintaining your personality and knowledge.", context, userId)
 	if response.Error != nil {
+		// If LLM fails, fall back to static greeting
+		if wasGreeting {
+			return conv.LLMConfig.Greeting, nil
+		}
+		if errors.Is(response.Error, llm.ErrQuotaExceeded) {
+			return conv.quotaFarewell(), nil
+		}
 		return "", fmt.Errorf("failed to generate response: %v", response.Error)
 	}
 
@@ -711,6 +731,8 @@ func ProcessPlayerInput(conversationId int, playerInput string) (string, error)
 }
 
 // EndConversation gracefully ends a conversation
+// If no static farewell is configured, one is requested from the LLM and arrives
+// later as an events.LLMResponse tagged with LLMTagFarewell
 func EndConversation(conversationId int) (string, error) {
 	conversationMutex.RLock()
 	conv := getConversation(conversationId)
@@ -727,33 +749,63 @@ func EndConversation(conversationId int) (string, error) {
 	conv.Active = false
 	conv.HasFarewelled = true
 
+	conv.memorize()
+
 	if conv.LLMConfig.Farewell != "" {
 		return conv.LLMConfig.Farewell, nil
 	}
 
 	// Generate a farewell using LLM if no static farewell is defined
-	context, err := conv.buildLLMContext(conv.PlayerName1, conv.PlayerName2)
+	context, err := conv.buildLLMContext(conv.PlayerName1, conv.PlayerName2, "")
 	if err != nil {
 		return "", fmt.Errorf("failed to build farewell context: %v", err)
 	}
 
-	// Get player user ID if this is a player
-	var userId int
-	if conv.IsPlayer2 && conv.PlayerName2 != "" {
-		// Only track token usage if this is a player (not mob-to-mob)
-		userId = conv.MobInstanceId2 // Player's user ID
+	if _, err := llm.QueueRequest(conv.newLLMJob(LLMTagFarewell, "The conversation is ending. Provide a natural farewell that matches your character.", context)); err != nil {
+		return "", fmt.Errorf("failed to generate farewell: %v", err)
 	}
 
-	response := llm.GenerateResponse("The conversation is ending. Provide a natural farewell that matches your character.", context, userId)
-	if response.Error != nil {
-		return "", fmt.Errorf("failed to generate farewell: %v", response.Error)
+	return "", nil
+}
+
+// newLLMJob prepares a request on behalf of this conversation
+func (c *Conversation) newLLMJob(tag string, prompt string, context []string) llm.Job {
+	llmConfig := configs.GetIntegrationsConfig().LLM
+
+	job := llm.Job{
+		ConversationId: c.Id,
+		Tag:            tag,
+		Profile:        string(llmConfig.ConversationProfile),
+		Stream:         bool(llmConfig.Streaming),
+		Prompt:         prompt,
+		Context:        context,
+		Timeout:        time.Duration(llmConfig.RequestTimeout) * time.Second,
 	}
 
-	return response.Text, nil
+	if c.LLMConfig != nil && c.LLMConfig.Profile != "" {
+		job.Profile = c.LLMConfig.Profile
+	}
+
+	if !c.IsPlayer1 {
+		job.MobInstanceId = c.MobInstanceId1
+	}
+
+	// Farewells are just words, only replies get to act
+	if tag == LLMTagReply {
+		job.Tools = c.llmTools()
+	}
+
+	// Only track token usage if this is a player (not mob-to-mob)
+	if c.IsPlayer2 && c.PlayerName2 != "" {
+		job.UserId = c.MobInstanceId2 // Player's user ID
+	}
+
+	return job
 }
 
 // buildLLMContext creates the context for the LLM based on conversation history
-func (c *Conversation) buildLLMContext(mob1Name string, mob2Name string) ([]string, error) {
+// query is what the player just said, and decides which memories are most relevant
+func (c *Conversation) buildLLMContext(mob1Name string, mob2Name string, query string) ([]string, error) {
 	// Validate mob names
 	if mob1Name == "" || mob2Name == "" {
 		return nil, fmt.Errorf("invalid mob names in buildLLMContext: mob1=%q, mob2=%q", mob1Name, mob2Name)
@@ -774,242 +826,30 @@ func (c *Conversation) buildLLMContext(mob1Name string, mob2Name string) ([]stri
 		mudlog.Debug("LLM", "context", fmt.Sprintf("Added NPC names: %s, %s", mob1Name, mob2Name))
 	}
 
-	// Add conversation history
-	context = append(context, c.Context...)
-	if len(c.Context) > 0 {
-		mudlog.Debug("LLM", "context", fmt.Sprintf("Added conversation history: %v", c.Context))
-	}
-
-	// Add conversation memory if it exists
-	var memory *ConversationMemory
-	memory = getConversationMemory(mob1Name, mob2Name)
-
-	if memory != nil && c.LLMConfig != nil && c.LLMConfig.IncludeNames {
-		// Add memory reminder to system prompt
-		if len(memory.RecentTopics) > 0 {
-			topics := strings.Join(memory.RecentTopics, ", ")
-			reminderContext := fmt.Sprintf("You previously spoke with %s about: %s",
-				mob2Name, topics)
-			context = append(context, reminderContext)
-		}
-	}
-
-	return context, nil
-}
-
-// Store memory from a conversation that's ending
-func storeConversationMemory(conv *Conversation) {
-	if conv == nil {
-		return
-	}
-
-	memoryMutex.Lock()
-	defer memoryMutex.Unlock()
-
-	// Get mob and player names
-	var mobName, playerName string
-
-	// First participant (mob1 or player1)
-	if !conv.IsPlayer1 {
-		// If it's a mob, try to get its name safely
-		mobInterface := mobinterfaces.GetInstance(conv.MobInstanceId1)
-		if mobInterface == nil {
-			mudlog.Debug("ConversationMemory", "skip", fmt.Sprintf("Mob1 instance %d not found, skipping memory storage", conv.MobInstanceId1))
-			return // Mob no longer exists
-		}
-
-		// Check if the interface contains a nil concrete value
-		mob, ok := mobInterface.(*mobs.Mob)
-		if !ok || mob == nil {
-			mudlog.Debug("ConversationMemory", "skip", fmt.Sprintf("Mob1 instance %d has invalid type or nil value", conv.MobInstanceId1))
-			return
-		}
-
-		// Extra safety check - get the name before using it
-		name := mob.GetName()
-		if name == "" {
-			mudlog.Debug("ConversationMemory", "skip", "Mob1 has empty name, skipping memory storage")
-			return
-		}
-
-		mobName = strings.ToLower(name)
-	} else {
-		// It's a player, use the stored name
-		if conv.PlayerName1 == "" {
-			mudlog.Debug("ConversationMemory", "skip", "Player1 has empty name, skipping memory storage")
-			return
-		}
-		mobName = strings.ToLower(conv.PlayerName1)
-	}
-
-	// Second participant (mob2 or player2)
-	if !conv.IsPlayer2 {
-		// If it's a mob, try to get its name safely
-		mobInterface := mobinterfaces.GetInstance(conv.MobInstanceId2)
-		if mobInterface == nil {
-			mudlog.Debug("ConversationMemory", "skip", fmt.Sprintf("Mob2 instance %d not found, skipping memory storage", conv.MobInstanceId2))
-			return // Mob no longer exists
-		}
-
-		// Check if the interface contains a nil concrete value
-		mob, ok := mobInterface.(*mobs.Mob)
-		if !ok || mob == nil {
-			mudlog.Debug("ConversationMemory", "skip", fmt.Sprintf("Mob2 instance %d has invalid type or nil value", conv.MobInstanceId2))
-			return
-		}
-
-		// Extra safety check - get the name before using it
-		name := mob.GetName()
-		if name == "" {
-			mudlog.Debug("ConversationMemory", "skip", "Mob2 has empty name, skipping memory storage")
-			return
-		}
-
-		playerName = strings.ToLower(name)
-	} else {
-		// It's a player, use the stored name
-		if conv.PlayerName2 == "" {
-			mudlog.Debug("ConversationMemory", "skip", "Player2 has empty name, skipping memory storage")
-			return
-		}
-		playerName = strings.ToLower(conv.PlayerName2)
-	}
-
-	// Ensure we have valid names before proceeding
-	if mobName == "" || playerName == "" {
-		mudlog.Debug("ConversationMemory", "skip", "Either mob or player name is empty, skipping memory storage")
-		return
-	}
-
-	// Get or create memory map for this mob
-	if _, ok := conversationMemory[mobName]; !ok {
-		conversationMemory[mobName] = make(map[string]*ConversationMemory)
-	}
-
-	// Get or create memory for this player
-	if _, ok := conversationMemory[mobName][playerName]; !ok {
-		conversationMemory[mobName][playerName] = &ConversationMemory{
-			LastInteraction: time.Now(),
-			RecentTopics:    []string{},
-			Context:         []string{},
-			CustomData:      make(map[string]string),
-			ExpirationTime:  24 * time.Hour, // Default to 24 hours
-		}
-	}
-
-	// Store recent conversation context
-	memory := conversationMemory[mobName][playerName]
-	memory.LastInteraction = time.Now()
-
-	// Store the most important parts of the conversation context
-	if len(conv.Context) > 0 {
-		// Store last few interactions as context
-		maxContextItems := 3
-		if len(conv.Context) <= maxContextItems*2 {
-			memory.Context = conv.Context
-		} else {
-			// Keep only the most recent items
-			memory.Context = conv.Context[len(conv.Context)-(maxContextItems*2):]
+	// Add what the mob remembers about the player from earlier conversations
+	if c.MobId > 0 && c.IsPlayer2 {
+		// Recent turns help pick out relevant memories too
+		recent := c.Context
+		if len(recent) > 4 {
+			recent = recent[len(recent)-4:]
 		}
-	}
+		query = strings.Join(recent, " ") + " " + query
 
-	// Extract potential topics from conversation
-	for _, message := range conv.Context {
-		// Simple topic extraction - just store the first few words of player messages
-		if strings.HasPrefix(message, "Player: ") {
-			content := strings.TrimPrefix(message, "Player: ")
-			words := strings.Fields(content)
-			if len(words) > 3 {
-				words = words[:3]
-			}
-			topic := strings.Join(words, " ")
-
-			// Add to recent topics if not already present
-			topicExists := false
-			for _, existingTopic := range memory.RecentTopics {
-				if existingTopic == topic {
-					topicExists = true
-					break
-				}
-			}
-
-			if !topicExists {
-				memory.RecentTopics = append(memory.RecentTopics, topic)
-				// Keep only the 5 most recent topics
-				if len(memory.RecentTopics) > 5 {
-					memory.RecentTopics = memory.RecentTopics[len(memory.RecentTopics)-5:]
-				}
+		memories := RecallMemories(c.MobId, mob2Name, query, int(configs.GetIntegrationsConfig().LLM.MemoryRecall))
+		if len(memories) > 0 {
+			context = append(context, fmt.Sprintf("Things you remember about %s:", mob2Name))
+			for _, m := range memories {
+				context = append(context, "- "+m.Text)
 			}
+			mudlog.Debug("LLM", "context", fmt.Sprintf("Added %d memories", len(memories)))
 		}
 	}
-}
-
-// Get conversation memory for a specific NPC-player pair
-func getConversationMemory(mobName, playerName string) *ConversationMemory {
-	memoryMutex.RLock()
-	defer memoryMutex.RUnlock()
 
-	mobName = strings.ToLower(mobName)
-	playerName = strings.ToLower(playerName)
-
-	// Check if memory exists and hasn't expired
-	if mobMemory, ok := conversationMemory[mobName]; ok {
-		if memory, ok := mobMemory[playerName]; ok {
-			// Check if memory has expired
-			if time.Since(memory.LastInteraction) > memory.ExpirationTime {
-				return nil
-			}
-			return memory
-		}
+	// Add conversation history
+	context = append(context, c.Context...)
+	if len(c.Context) > 0 {
+		mudlog.Debug("LLM", "context", fmt.Sprintf("Added conversation history: %v", c.Context))
 	}
 
-	return nil
-}
-
-// CleanupMemory periodically removes expired conversation memories
-func cleanupMemory() {
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-shutdownChan:
-			return
-		case <-ticker.C:
-			memoryMutex.Lock()
-			now := time.Now()
-
-			// Count before cleanup
-			totalBefore := 0
-			for _, playerMap := range conversationMemory {
-				totalBefore += len(playerMap)
-			}
-
-			// Iterate through all memories and remove expired ones
-			for mobName, playerMap := range conversationMemory {
-				for playerName, memory := range playerMap {
-					if now.Sub(memory.LastInteraction) > memory.ExpirationTime {
-						delete(playerMap, playerName)
-					}
-				}
-
-				// If no more players for this mob, remove the mob entry
-				if len(conversationMemory[mobName]) == 0 {
-					delete(conversationMemory, mobName)
-				}
-			}
-
-			// Count after cleanup
-			totalAfter := 0
-			for _, playerMap := range conversationMemory {
-				totalAfter += len(playerMap)
-			}
-
-			if totalBefore > totalAfter {
-				mudlog.Debug("ConversationMemory", "cleanup", fmt.Sprintf("Removed %d expired conversation memories", totalBefore-totalAfter))
-			}
-
-			memoryMutex.Unlock()
-		}
-	}
+	return context, nil
 }
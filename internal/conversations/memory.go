@@ -0,0 +1,421 @@
+package conversations
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/GoMudEngine/GoMud/internal/characters"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/llm"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/quests"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of things an NPC can remember
+const (
+	MemorySummary = `summary` // A summary of a past conversation
+	MemoryFact    = `fact`    // Something the game knows to be true, such as a completed quest
+)
+
+// How many NPC/character memories are kept in memory.
+// Every change is saved right away, so one that falls out is just read from disk again.
+const npcMemoryCacheSize = 512
+
+var (
+	npcMemories, _  = lru.New[string, *NPCMemory](npcMemoryCacheSize) // "mobId/charactername" -> memory
+	npcMemoriesLock sync.Mutex
+
+	// Summaries waiting on the LLM, by job id
+	pendingSummaries     = map[uint64]pendingSummary{}
+	pendingSummariesLock sync.Mutex
+)
+
+// A small set of words too common to say anything about relevance
+var memoryStopWords = map[string]struct{}{
+	`the`: {}, `and`: {}, `you`: {}, `your`: {}, `for`: {}, `are`: {}, `was`: {}, `with`: {},
+	`that`: {}, `this`: {}, `have`: {}, `has`: {}, `had`: {}, `what`: {}, `who`: {}, `about`: {},
+	`they`: {}, `them`: {}, `their`: {}, `not`: {}, `but`: {}, `from`: {}, `can`: {}, `will`: {},
+	`would`: {}, `there`: {}, `here`: {}, `any`: {}, `some`: {}, `how`: {}, `why`: {}, `when`: {},
+}
+
+// MemoryEntry is a single thing an NPC remembers about a character
+type MemoryEntry struct {
+	Kind    string    `yaml:"kind"`          // summary or fact
+	Key     string    `yaml:"key,omitempty"` // Facts with the same key replace one another
+	Text    string    `yaml:"text"`
+	Created time.Time `yaml:"created"`
+}
+
+// NPCMemory is everything a mob template remembers about a character.
+// It is saved under the datafiles folder in npcmemory/{MobId}/{charactername}.yaml
+type NPCMemory struct {
+	MobId         int           `yaml:"mobid"`
+	CharacterName string        `yaml:"charactername"`
+	Entries       []MemoryEntry `yaml:"entries,omitempty"`
+}
+
+type pendingSummary struct {
+	MobId         int
+	CharacterName string
+	Fallback      string // Used if the LLM can't summarize
+}
+
+func memoryKey(mobId int, characterName string) string {
+	return strconv.Itoa(mobId) + `/` + strings.ToLower(characterName)
+}
+
+func memoryFilePath(mobId int, characterName string) string {
+	return util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `npcmemory`, `/`, strconv.Itoa(mobId), `/`, filepath.Base(strings.ToLower(characterName))+`.yaml`)
+}
+
+// getNPCMemory loads a memory from cache or disk.
+// Caller must hold npcMemoriesLock.
+func getNPCMemory(mobId int, characterName string) *NPCMemory {
+
+	key := memoryKey(mobId, characterName)
+	if mem, ok := npcMemories.Get(key); ok {
+		return mem
+	}
+
+	mem := &NPCMemory{MobId: mobId, CharacterName: strings.ToLower(characterName)}
+
+	if bytes, err := os.ReadFile(memoryFilePath(mobId, characterName)); err == nil {
+		if err := yaml.Unmarshal(bytes, mem); err != nil {
+			mudlog.Error("NPCMemory", "error", "Problem unmarshalling memory for mob "+strconv.Itoa(mobId)+": "+err.Error())
+		}
+	}
+
+	npcMemories.Add(key, mem)
+
+	return mem
+}
+
+// save writes a memory to disk.
+// Caller must hold npcMemoriesLock.
+func (m *NPCMemory) save() error {
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	path := memoryFilePath(m.MobId, m.CharacterName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return util.Save(path, data, bool(configs.GetFilePathsConfig().CarefulSaveFiles))
+}
+
+// RememberSummary stores a summary of a conversation a mob had with a character.
+// Only the most recent summaries are kept.
+func RememberSummary(mobId int, characterName string, text string) {
+
+	text = strings.TrimSpace(text)
+	if text == `` {
+		return
+	}
+
+	npcMemoriesLock.Lock()
+	defer npcMemoriesLock.Unlock()
+
+	mem := getNPCMemory(mobId, characterName)
+	mem.Entries = append(mem.Entries, MemoryEntry{Kind: MemorySummary, Text: text, Created: time.Now()})
+
+	// Drop the oldest summaries beyond the limit. Facts are never dropped.
+	maxSummaries := int(configs.GetIntegrationsConfig().LLM.MemoryMaxEntries)
+	summaryCt := 0
+	for i := len(mem.Entries) - 1; i >= 0; i-- {
+		if mem.Entries[i].Kind != MemorySummary {
+			continue
+		}
+		summaryCt++
+		if summaryCt > maxSummaries {
+			mem.Entries = slices.Delete(mem.Entries, i, i+1)
+		}
+	}
+
+	if err := mem.save(); err != nil {
+		mudlog.Error("NPCMemory", "error", "Could not save memory for mob "+strconv.Itoa(mobId)+": "+err.Error())
+	}
+}
+
+// RememberFact stores something the game knows about a character,
+// replacing any previous fact with the same key.
+func RememberFact(mobId int, characterName string, key string, text string) {
+
+	npcMemoriesLock.Lock()
+	defer npcMemoriesLock.Unlock()
+
+	mem := getNPCMemory(mobId, characterName)
+
+	for i, entry := range mem.Entries {
+		if entry.Kind == MemoryFact && entry.Key == key {
+			if entry.Text == text {
+				return
+			}
+			mem.Entries[i].Text = text
+			mem.Entries[i].Created = time.Now()
+			if err := mem.save(); err != nil {
+				mudlog.Error("NPCMemory", "error", "Could not save memory for mob "+strconv.Itoa(mobId)+": "+err.Error())
+			}
+			return
+		}
+	}
+
+	mem.Entries = append(mem.Entries, MemoryEntry{Kind: MemoryFact, Key: key, Text: text, Created: time.Now()})
+
+	if err := mem.save(); err != nil {
+		mudlog.Error("NPCMemory", "error", "Could not save memory for mob "+strconv.Itoa(mobId)+": "+err.Error())
+	}
+}
+
+// RecallMemories returns what a mob remembers about a character: every fact,
+// plus the summaries most relevant to the query (most recent first when nothing stands out).
+func RecallMemories(mobId int, characterName string, query string, limit int) []MemoryEntry {
+
+	npcMemoriesLock.Lock()
+	defer npcMemoriesLock.Unlock()
+
+	mem := getNPCMemory(mobId, characterName)
+
+	facts := []MemoryEntry{}
+	summaries := []MemoryEntry{}
+	for _, entry := range mem.Entries {
+		if entry.Kind == MemoryFact {
+			facts = append(facts, entry)
+		} else {
+			summaries = append(summaries, entry)
+		}
+	}
+
+	summaries = rankMemories(summaries, query)
+	if len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+
+	return append(facts, summaries...)
+}
+
+// rankMemories orders entries by how similar they are to the query,
+// using tf-idf weighted cosine similarity with a small nudge towards recent entries.
+func rankMemories(entries []MemoryEntry, query string) []MemoryEntry {
+
+	if len(entries) == 0 {
+		return entries
+	}
+
+	docs := make([]map[string]float64, len(entries))
+	docFreq := map[string]int{}
+	for i, entry := range entries {
+		docs[i] = termFrequencies(entry.Text)
+		for term := range docs[i] {
+			docFreq[term]++
+		}
+	}
+
+	idf := func(term string) float64 {
+		return math.Log(1 + float64(len(entries))/float64(1+docFreq[term]))
+	}
+
+	queryVec := termFrequencies(query)
+	for term, tf := range queryVec {
+		queryVec[term] = tf * idf(term)
+	}
+
+	type scored struct {
+		entry MemoryEntry
+		score float64
+	}
+
+	results := make([]scored, len(entries))
+	for i, entry := range entries {
+		docVec := docs[i]
+		for term, tf := range docVec {
+			docVec[term] = tf * idf(term)
+		}
+		// Entries are stored oldest first
+		recency := 0.05 * float64(i+1) / float64(len(entries))
+		results[i] = scored{entry: entry, score: cosineSimilarity(queryVec, docVec) + recency}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].score > results[b].score
+	})
+
+	ranked := make([]MemoryEntry, len(results))
+	for i, r := range results {
+		ranked[i] = r.entry
+	}
+
+	return ranked
+}
+
+func termFrequencies(text string) map[string]float64 {
+	tf := map[string]float64{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if len(word) < 3 {
+			continue
+		}
+		if _, ok := memoryStopWords[word]; ok {
+			continue
+		}
+		tf[word]++
+	}
+	return tf
+}
+
+func cosineSimilarity(a map[string]float64, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, v := range a {
+		dot += v * b[term]
+		normA += v * v
+	}
+	for _, v := range b {
+		normB += v * v
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// refreshMemoryFacts records what the game knows about a character that a mob would care about:
+// their alignment, quests they've completed and how many of the mob's kin they've killed.
+func refreshMemoryFacts(mob *mobs.Mob, user *users.UserRecord) {
+
+	mobId := int(mob.MobId)
+	char := user.Character
+	name := char.Name
+
+	RememberFact(mobId, name, `alignment`,
+		fmt.Sprintf(`%s is %s.`, name, characters.AlignmentToString(char.Alignment)))
+
+	for questId, step := range char.GetQuestProgress() {
+		if step != `end` {
+			continue
+		}
+		quest := quests.GetQuest(quests.PartsToToken(questId, `all+`))
+		if quest == nil || quest.Secret {
+			continue
+		}
+		RememberFact(mobId, name, `quest-`+strconv.Itoa(questId),
+			fmt.Sprintf(`%s has completed the quest "%s".`, name, quest.Name))
+	}
+
+	if len(mob.Groups) == 0 {
+		if kills := char.KD.GetMobKills(mobId); kills > 0 {
+			RememberFact(mobId, name, `kills`,
+				fmt.Sprintf(`%s has killed %d of your kind (%s).`, name, kills, mob.Character.Name))
+		}
+		return
+	}
+
+	for _, group := range mob.Groups {
+		kills := 0
+		for killedMobId, ct := range char.KD.Kills {
+			if spec := mobs.GetMobSpec(mobs.MobId(killedMobId)); spec != nil && slices.Contains(spec.Groups, group) {
+				kills += ct
+			}
+		}
+		if kills > 0 {
+			RememberFact(mobId, name, `kills-`+group,
+				fmt.Sprintf(`%s has killed %d of the %s.`, name, kills, group))
+		}
+	}
+}
+
+// memorize sends a finished conversation off to be summarized and remembered.
+// Only conversations between a mob and a player are remembered.
+func (c *Conversation) memorize() {
+
+	if c.Memorized || c.IsPlayer1 || !c.IsPlayer2 || c.MobId == 0 || c.PlayerName2 == `` {
+		return
+	}
+	c.Memorized = true
+
+	// What the player said, in case the LLM can't do better
+	topics := []string{}
+	for i := 0; i < len(c.Context); i += 2 {
+		words := strings.Fields(c.Context[i])
+		if len(words) > 12 {
+			words = append(words[:12], `...`)
+		}
+		topics = append(topics, strings.Join(words, ` `))
+	}
+
+	if len(topics) == 0 {
+		return
+	}
+
+	summary := pendingSummary{
+		MobId:         c.MobId,
+		CharacterName: c.PlayerName2,
+		Fallback:      fmt.Sprintf(`%s talked with you about: %s`, c.PlayerName2, strings.Join(topics, `; `)),
+	}
+
+	context := []string{}
+	if c.LLMConfig != nil && c.LLMConfig.SystemPrompt != `` {
+		context = append(context, c.LLMConfig.SystemPrompt)
+	}
+	for i, line := range c.Context {
+		if i%2 == 0 {
+			context = append(context, fmt.Sprintf(`%s: %s`, c.PlayerName2, line))
+		} else {
+			context = append(context, fmt.Sprintf(`You: %s`, line))
+		}
+	}
+
+	job := c.newLLMJob(LLMTagSummary,
+		fmt.Sprintf(`The conversation above with %s has ended. In one or two sentences, written as your own memory of it, summarize what was said. Note anything you learned about them, promised them or should remember next time.`, c.PlayerName2),
+		context)
+	job.Stream = false
+
+	jobId, err := llm.QueueRequest(job)
+	if err != nil {
+		RememberSummary(summary.MobId, summary.CharacterName, summary.Fallback)
+		return
+	}
+
+	pendingSummariesLock.Lock()
+	pendingSummaries[jobId] = summary
+	pendingSummariesLock.Unlock()
+}
+
+// HandleSummaryResponse stores a conversation summary requested when a conversation ended
+func HandleSummaryResponse(response events.LLMResponse) {
+
+	pendingSummariesLock.Lock()
+	summary, ok := pendingSummaries[response.JobId]
+	delete(pendingSummaries, response.JobId)
+	pendingSummariesLock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	text := strings.TrimSpace(response.Text)
+	if response.Error != nil || text == `` {
+		text = summary.Fallback
+	}
+
+	RememberSummary(summary.MobId, summary.CharacterName, text)
+}
@@ -0,0 +1,147 @@
+package conversations
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+// useMemoryDir points the datafiles folder somewhere temporary and forgets anything cached
+func useMemoryDir(t *testing.T, maxEntries int) string {
+	t.Helper()
+
+	dir := testsupport.UseDataFiles(t, map[string]any{
+		"Integrations.LLM.MemoryMaxEntries":    maxEntries,
+		"Integrations.LLM.MemoryRecall":        3,
+		"FilePaths.CarefulSaveFiles":           false,
+		"Integrations.LLM.ToolAuditFile":       "",
+		"Integrations.LLM.ConversationProfile": "default",
+		"Integrations.LLM.RequestTimeout":      1,
+	})
+
+	npcMemoriesLock.Lock()
+	npcMemories.Purge()
+	npcMemoriesLock.Unlock()
+
+	return dir
+}
+
+func TestRankMemories(t *testing.T) {
+
+	entries := []MemoryEntry{
+		{Text: "Bob asked about the frozen lake and the runes carved into the ice."},
+		{Text: "Bob wanted to buy a sword, but had no gold."},
+		{Text: "Bob told you his sister went missing near the old mill."},
+	}
+
+	ranked := rankMemories(entries, "Did you ever find out what the runes mean?")
+	assert.Equal(t, entries[0].Text, ranked[0].Text)
+
+	ranked = rankMemories(entries, "Any news about my sister?")
+	assert.Equal(t, entries[2].Text, ranked[0].Text)
+
+	// Nothing stands out, so the most recent comes first
+	ranked = rankMemories(entries, "Hello!")
+	assert.Equal(t, entries[2].Text, ranked[0].Text)
+	assert.Equal(t, entries[0].Text, ranked[2].Text)
+}
+
+func TestRememberAndRecall(t *testing.T) {
+
+	dir := useMemoryDir(t, 2)
+
+	RememberFact(39, "Bob", "alignment", "Bob is good.")
+	RememberFact(39, "Bob", "alignment", "Bob is evil.")
+	RememberSummary(39, "Bob", "Bob asked about the runes.")
+	RememberSummary(39, "Bob", "Bob asked about his missing sister.")
+	RememberSummary(39, "Bob", "Bob bragged about killing wolves.")
+
+	assert.FileExists(t, dir+"/npcmemory/39/bob.yaml")
+
+	// Forget the cache so everything comes back from disk
+	npcMemoriesLock.Lock()
+	npcMemories.Purge()
+	npcMemoriesLock.Unlock()
+
+	memories := RecallMemories(39, "bob", "what about my sister", 1)
+	require.Len(t, memories, 2)
+	assert.Equal(t, MemoryEntry{Kind: MemoryFact, Key: "alignment", Text: "Bob is evil.", Created: memories[0].Created}, memories[0])
+	assert.Equal(t, "Bob asked about his missing sister.", memories[1].Text)
+
+	// Only the 2 most recent summaries are kept
+	memories = RecallMemories(39, "bob", "", 10)
+	require.Len(t, memories, 3)
+	assert.Equal(t, "Bob bragged about killing wolves.", memories[1].Text)
+	assert.Equal(t, "Bob asked about his missing sister.", memories[2].Text)
+
+	// Other characters and mobs are kept apart
+	assert.Empty(t, RecallMemories(39, "alice", "", 10))
+	assert.Empty(t, RecallMemories(40, "bob", "", 10))
+}
+
+func TestMemorize_Fallback(t *testing.T) {
+
+	useMemoryDir(t, 10)
+
+	conv := &Conversation{
+		MobId:       39,
+		PlayerName2: "bob",
+		IsPlayer2:   true,
+		LLMConfig:   &LLMConversationConfig{},
+		Context:     []string{"Where can I find the frost runes?", "Beneath the frozen lake."},
+	}
+
+	// The LLM isn't running, so the summary falls back on what was said
+	conv.memorize()
+	conv.memorize()
+
+	memories := RecallMemories(39, "bob", "", 10)
+	require.Len(t, memories, 1)
+	assert.Equal(t, "bob talked with you about: Where can I find the frost runes?", memories[0].Text)
+}
+
+func TestHandleSummaryResponse(t *testing.T) {
+
+	useMemoryDir(t, 10)
+
+	pendingSummariesLock.Lock()
+	pendingSummaries[12] = pendingSummary{MobId: 39, CharacterName: "bob", Fallback: "fallback"}
+	pendingSummaries[13] = pendingSummary{MobId: 39, CharacterName: "bob", Fallback: "Bob said hello."}
+	pendingSummariesLock.Unlock()
+
+	HandleSummaryResponse(events.LLMResponse{JobId: 12, Text: " Bob is looking for his sister. "})
+	HandleSummaryResponse(events.LLMResponse{JobId: 13, Error: assert.AnError})
+	HandleSummaryResponse(events.LLMResponse{JobId: 14, Text: "Not a summary we asked for"})
+
+	memories := RecallMemories(39, "bob", "", 10)
+	require.Len(t, memories, 2)
+	assert.Equal(t, "Bob said hello.", memories[0].Text)
+	assert.Equal(t, "Bob is looking for his sister.", memories[1].Text)
+}
+
+func TestNPCMemoryCacheIsBounded(t *testing.T) {
+
+	useMemoryDir(t, 10)
+
+	for i := 0; i < npcMemoryCacheSize+10; i++ {
+		RememberFact(39, "char"+strconv.Itoa(i), "alignment", "Neutral.")
+	}
+
+	npcMemoriesLock.Lock()
+	cached := npcMemories.Len()
+	npcMemoriesLock.Unlock()
+	assert.Equal(t, npcMemoryCacheSize, cached)
+
+	// The oldest fell out of the cache but is still remembered
+	memories := RecallMemories(39, "char0", "", 1)
+	require.Len(t, memories, 1)
+	assert.Equal(t, "Neutral.", memories[0].Text)
+}
@@ -0,0 +1,31 @@
+package conversations
+
+import (
+	"fmt"
+
+	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+// Say has a mob say something to the user it is talking with.
+// speaking indicates more is still to come.
+func Say(mob *mobs.Mob, user *users.UserRecord, room *rooms.Room, text string, speaking bool) {
+
+	if !user.Character.HasBuffFlag(buffs.Hidden) {
+		room.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to <ansi fg="username">%s</ansi>, "<ansi fg="saytext">%s</ansi>"`, mob.Character.Name, user.Character.Name, text), user.UserId)
+	} else {
+		room.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to someone, "<ansi fg="saytext">%s</ansi>"`, mob.Character.Name, text), user.UserId)
+	}
+	user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to you, "<ansi fg="saytext">%s</ansi>"`, mob.Character.Name, text))
+
+	events.AddToQueue(events.Communication{
+		SourceMobInstanceId: mob.InstanceId,
+		CommType:            `say`,
+		Name:                mob.Character.Name,
+		Message:             text,
+		Speaking:            speaking,
+	})
+}
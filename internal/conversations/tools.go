@@ -0,0 +1,482 @@
+package conversations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/items"
+	"github.com/GoMudEngine/GoMud/internal/llm"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/quests"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+// Names of the actions a conversation can allow the LLM to take.
+// Each maps onto the mob command of the same name.
+const (
+	ToolGiveQuest = `givequest`
+	ToolGive      = `give`
+	ToolShow      = `show`
+	ToolPathTo    = `pathto`
+)
+
+// The most actions carried out for a single reply
+const maxToolCallsPerReply = 3
+
+var (
+	errToolNotAllowed = errors.New("tool is not allowed in this conversation")
+	toolAuditLock     sync.Mutex
+)
+
+// ConversationTool whitelists an action the NPC may take on its own during a conversation.
+// Arguments the LLM provides are checked against these limits and against what
+// the mob actually has at the time the action is taken.
+type ConversationTool struct {
+	// One of givequest, give, show or pathto
+	Name string `yaml:"name"`
+	// Optional description shown to the LLM, replacing the default
+	Description string `yaml:"description,omitempty"`
+	// givequest: quest tokens that may be given (defaults to all of the mob's questflags)
+	Quests []string `yaml:"quests,omitempty,flow"`
+	// give/show: item ids that may be handed over or shown (defaults to anything carried)
+	ItemIds []int `yaml:"itemids,omitempty,flow"`
+	// give: most gold that may be given at once (0 means none)
+	MaxGold int `yaml:"maxgold,omitempty"`
+	// pathto: room ids the mob may walk to. "home" is always allowed.
+	RoomIds []int `yaml:"roomids,omitempty,flow"`
+}
+
+// ToolAudit is a record of a single action the LLM asked for
+type ToolAudit struct {
+	Time           time.Time      `json:"time"`
+	ConversationId int            `json:"conversationId"`
+	MobId          int            `json:"mobId"`
+	MobInstanceId  int            `json:"mobInstanceId"`
+	MobName        string         `json:"mobName"`
+	UserId         int            `json:"userId"`
+	Tool           string         `json:"tool"`
+	Arguments      map[string]any `json:"arguments,omitempty"`
+	Allowed        bool           `json:"allowed"`
+	Command        string         `json:"command,omitempty"`
+	Reason         string         `json:"reason,omitempty"`
+}
+
+type toolHandler struct {
+	// Describes the tool to the LLM, or returns nil if it has nothing to offer right now
+	describe func(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord) *llm.Tool
+	// Validates the arguments and returns the mob command to run
+	command func(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord, args map[string]any) (string, error)
+}
+
+var toolHandlers = map[string]toolHandler{
+	ToolGiveQuest: {describe: describeGiveQuest, command: commandGiveQuest},
+	ToolGive:      {describe: describeGive, command: commandGive},
+	ToolShow:      {describe: describeShow, command: commandShow},
+	ToolPathTo:    {describe: describePathTo, command: commandPathTo},
+}
+
+// llmTools returns the tools the LLM may call for the next reply.
+// Only conversations between a mob and a player can use tools.
+func (c *Conversation) llmTools() []llm.Tool {
+
+	if c.LLMConfig == nil || len(c.LLMConfig.Tools) == 0 {
+		return nil
+	}
+
+	if c.IsPlayer1 || !c.IsPlayer2 {
+		return nil
+	}
+
+	mob := mobs.GetInstance(c.MobInstanceId1)
+	user := users.GetByUserId(c.MobInstanceId2)
+	if mob == nil || user == nil {
+		return nil
+	}
+
+	tools := []llm.Tool{}
+	for _, tool := range c.LLMConfig.Tools {
+		handler, ok := toolHandlers[tool.Name]
+		if !ok {
+			continue
+		}
+		if t := handler.describe(tool, mob, user); t != nil {
+			if tool.Description != `` {
+				t.Description = tool.Description
+			}
+			tools = append(tools, *t)
+		}
+	}
+
+	return tools
+}
+
+// HandleToolCalls carries out the actions the LLM asked for in a reply.
+// Every call is validated against the conversation whitelist and the mob's
+// current inventory and quests, and audited whether it was allowed or not.
+func HandleToolCalls(response events.LLMResponse) {
+
+	if len(response.ToolCalls) == 0 {
+		return
+	}
+
+	conv := GetConversation(response.ConversationId)
+	if conv == nil || conv.LLMConfig == nil {
+		return
+	}
+
+	mob := mobs.GetInstance(response.MobInstanceId)
+	if mob == nil {
+		return
+	}
+
+	for i, call := range response.ToolCalls {
+
+		audit := ToolAudit{
+			Time:           time.Now(),
+			ConversationId: conv.Id,
+			MobId:          int(mob.MobId),
+			MobInstanceId:  mob.InstanceId,
+			MobName:        mob.Character.Name,
+			UserId:         response.UserId,
+			Tool:           call.Name,
+			Arguments:      call.Arguments,
+		}
+
+		cmd, err := conv.toolCommand(call, mob, response.UserId)
+		if err == nil && i >= maxToolCallsPerReply {
+			err = fmt.Errorf("more than %d actions in one reply", maxToolCallsPerReply)
+		}
+
+		if err != nil {
+			audit.Reason = err.Error()
+		} else {
+			audit.Allowed = true
+			audit.Command = cmd
+			mob.Command(cmd)
+		}
+
+		auditToolCall(audit)
+	}
+}
+
+// toolCommand validates a call and returns the mob command that carries it out
+func (c *Conversation) toolCommand(call events.LLMToolCall, mob *mobs.Mob, userId int) (string, error) {
+
+	idx := slices.IndexFunc(c.LLMConfig.Tools, func(t ConversationTool) bool { return t.Name == call.Name })
+	if idx < 0 {
+		return ``, errToolNotAllowed
+	}
+
+	handler, ok := toolHandlers[call.Name]
+	if !ok {
+		return ``, errToolNotAllowed
+	}
+
+	if c.IsPlayer1 || !c.IsPlayer2 || c.MobInstanceId1 != mob.InstanceId || c.MobInstanceId2 != userId {
+		return ``, errors.New("conversation is not between this mob and player")
+	}
+
+	user := users.GetByUserId(userId)
+	if user == nil {
+		return ``, errors.New("player is not online")
+	}
+
+	if user.Character.RoomId != mob.Character.RoomId {
+		return ``, errors.New("player is not in the room")
+	}
+
+	return handler.command(c.LLMConfig.Tools[idx], mob, user, call.Arguments)
+}
+
+func describeGiveQuest(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord) *llm.Tool {
+
+	tokens := []string{}
+	names := []string{}
+	for _, token := range allowedQuests(tool, mob) {
+		if user.Character.HasQuest(token) {
+			continue
+		}
+		tokens = append(tokens, token)
+		names = append(names, fmt.Sprintf(`%s (%s)`, token, quests.GetQuest(token).Name))
+	}
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	return &llm.Tool{
+		Name:        ToolGiveQuest,
+		Description: `Give the player a quest. Only do this when the conversation leads to it. Quests: ` + strings.Join(names, `, `),
+		Parameters: map[string]any{
+			`type`: `object`,
+			`properties`: map[string]any{
+				`quest`: map[string]any{`type`: `string`, `enum`: tokens, `description`: `The quest to give`},
+			},
+			`required`: []string{`quest`},
+		},
+	}
+}
+
+func commandGiveQuest(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord, args map[string]any) (string, error) {
+
+	token := toolArgString(args, `quest`)
+	if !slices.Contains(allowedQuests(tool, mob), token) {
+		return ``, fmt.Errorf("quest %q is not one this mob can give", token)
+	}
+
+	if user.Character.HasQuest(token) {
+		return ``, fmt.Errorf("player already has quest %q", token)
+	}
+
+	return fmt.Sprintf(`givequest %s %s`, token, user.Character.Name), nil
+}
+
+func describeGive(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord) *llm.Tool {
+
+	names := allowedItemNames(tool, mob)
+	maxGold := min(tool.MaxGold, mob.Character.Gold)
+
+	if len(names) == 0 && maxGold < 1 {
+		return nil
+	}
+
+	properties := map[string]any{}
+	if len(names) > 0 {
+		properties[`item`] = map[string]any{`type`: `string`, `enum`: names, `description`: `The item to hand over`}
+	}
+	if maxGold > 0 {
+		properties[`gold`] = map[string]any{`type`: `integer`, `minimum`: 1, `maximum`: maxGold, `description`: `How much gold to hand over`}
+	}
+
+	return &llm.Tool{
+		Name:        ToolGive,
+		Description: `Give the player an item or some gold you are carrying.`,
+		Parameters: map[string]any{
+			`type`:       `object`,
+			`properties`: properties,
+		},
+	}
+}
+
+func commandGive(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord, args map[string]any) (string, error) {
+
+	if gold := toolArgInt(args, `gold`); gold != 0 {
+		if gold < 0 || gold > tool.MaxGold {
+			return ``, fmt.Errorf("gold amount %d is outside the allowed 1-%d", gold, tool.MaxGold)
+		}
+		if gold > mob.Character.Gold {
+			return ``, fmt.Errorf("mob only has %d gold", mob.Character.Gold)
+		}
+		return fmt.Sprintf(`give %d gold %s`, gold, user.Character.Name), nil
+	}
+
+	itm, err := allowedItem(tool, mob, toolArgString(args, `item`))
+	if err != nil {
+		return ``, err
+	}
+
+	return fmt.Sprintf(`give %s %s`, itm.ShorthandId(), user.Character.Name), nil
+}
+
+func describeShow(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord) *llm.Tool {
+
+	names := allowedItemNames(tool, mob)
+	if len(names) == 0 {
+		return nil
+	}
+
+	return &llm.Tool{
+		Name:        ToolShow,
+		Description: `Show the player an item you are carrying, without giving it to them.`,
+		Parameters: map[string]any{
+			`type`: `object`,
+			`properties`: map[string]any{
+				`item`: map[string]any{`type`: `string`, `enum`: names, `description`: `The item to show`},
+			},
+			`required`: []string{`item`},
+		},
+	}
+}
+
+func commandShow(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord, args map[string]any) (string, error) {
+
+	itm, err := allowedItem(tool, mob, toolArgString(args, `item`))
+	if err != nil {
+		return ``, err
+	}
+
+	return fmt.Sprintf(`show %s %s`, itm.ShorthandId(), user.Character.Name), nil
+}
+
+func describePathTo(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord) *llm.Tool {
+
+	destinations := []string{`home`}
+	names := []string{`home (where you belong)`}
+
+	for _, roomId := range tool.RoomIds {
+		if room := rooms.LoadRoom(roomId); room != nil {
+			destinations = append(destinations, strconv.Itoa(roomId))
+			names = append(names, fmt.Sprintf(`%d (%s)`, roomId, room.Title))
+		}
+	}
+
+	return &llm.Tool{
+		Name:        ToolPathTo,
+		Description: `Walk somewhere, such as leading the player there. Destinations: ` + strings.Join(names, `, `),
+		Parameters: map[string]any{
+			`type`: `object`,
+			`properties`: map[string]any{
+				`destination`: map[string]any{`type`: `string`, `enum`: destinations, `description`: `Where to walk to`},
+			},
+			`required`: []string{`destination`},
+		},
+	}
+}
+
+func commandPathTo(tool ConversationTool, mob *mobs.Mob, user *users.UserRecord, args map[string]any) (string, error) {
+
+	destination := strings.ToLower(toolArgString(args, `destination`))
+	if destination == `home` {
+		return `pathto home`, nil
+	}
+
+	roomId, err := strconv.Atoi(destination)
+	if err != nil || !slices.Contains(tool.RoomIds, roomId) {
+		return ``, fmt.Errorf("destination %q is not allowed", destination)
+	}
+
+	return fmt.Sprintf(`pathto %d`, roomId), nil
+}
+
+// allowedQuests returns the whitelisted quests that the mob is actually able to give
+func allowedQuests(tool ConversationTool, mob *mobs.Mob) []string {
+	ret := []string{}
+	for _, token := range mob.QuestFlags {
+		if len(tool.Quests) > 0 && !slices.Contains(tool.Quests, token) {
+			continue
+		}
+		if quests.GetQuest(token) == nil {
+			continue
+		}
+		ret = append(ret, token)
+	}
+	return ret
+}
+
+// allowedItemNames returns the names of whitelisted items the mob is carrying
+func allowedItemNames(tool ConversationTool, mob *mobs.Mob) []string {
+	ret := []string{}
+	for _, itm := range mob.Character.GetAllBackpackItems() {
+		if len(tool.ItemIds) > 0 && !slices.Contains(tool.ItemIds, itm.ItemId) {
+			continue
+		}
+		name := itm.Name()
+		if !slices.Contains(ret, name) {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// allowedItem finds a whitelisted item the mob is carrying
+func allowedItem(tool ConversationTool, mob *mobs.Mob, itemName string) (items.Item, error) {
+
+	if itemName == `` {
+		return items.Item{}, errors.New("no item given")
+	}
+
+	for _, itm := range mob.Character.GetAllBackpackItems() {
+		if !strings.EqualFold(itm.Name(), itemName) {
+			continue
+		}
+		if len(tool.ItemIds) > 0 && !slices.Contains(tool.ItemIds, itm.ItemId) {
+			return items.Item{}, fmt.Errorf("item %q is not allowed", itemName)
+		}
+		return itm, nil
+	}
+
+	return items.Item{}, fmt.Errorf("mob is not carrying %q", itemName)
+}
+
+func toolArgString(args map[string]any, name string) string {
+	switch v := args[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.Itoa(int(v))
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ``
+}
+
+// toolArgInt reads a number, which models sometimes send as a string
+func toolArgInt(args map[string]any, name string) int {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
+
+// auditToolCall records a tool call in the log, and the audit file if one is configured
+func auditToolCall(audit ToolAudit) {
+
+	mudlog.Info("LLM Tool",
+		"conversation", audit.ConversationId,
+		"mob", audit.MobName,
+		"userId", audit.UserId,
+		"tool", audit.Tool,
+		"arguments", audit.Arguments,
+		"allowed", audit.Allowed,
+		"command", audit.Command,
+		"reason", audit.Reason,
+	)
+
+	auditFile := string(configs.GetIntegrationsConfig().LLM.ToolAuditFile)
+	if auditFile == `` {
+		return
+	}
+
+	line, err := json.Marshal(audit)
+	if err != nil {
+		mudlog.Error("LLM Tool", "error", err)
+		return
+	}
+
+	toolAuditLock.Lock()
+	defer toolAuditLock.Unlock()
+
+	auditFile = util.FilePath(auditFile)
+	if err := os.MkdirAll(filepath.Dir(auditFile), 0755); err != nil {
+		mudlog.Error("LLM Tool", "error", err)
+		return
+	}
+
+	f, err := os.OpenFile(auditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		mudlog.Error("LLM Tool", "error", err)
+		return
+	}
+	defer f.Close()
+
+	f.Write(append(line, '\n'))
+}
@@ -0,0 +1,96 @@
+package conversations
+
+import (
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/characters"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandGive_Gold(t *testing.T) {
+
+	mob := &mobs.Mob{Character: characters.Character{Gold: 20}}
+	user := &users.UserRecord{Character: &characters.Character{Name: `Bob`}}
+
+	tests := []struct {
+		name    string
+		tool    ConversationTool
+		args    map[string]any
+		want    string
+		wantErr bool
+	}{
+		{name: "within limits", tool: ConversationTool{Name: ToolGive, MaxGold: 10}, args: map[string]any{`gold`: float64(10)}, want: `give 10 gold Bob`},
+		{name: "number as string", tool: ConversationTool{Name: ToolGive, MaxGold: 10}, args: map[string]any{`gold`: `5`}, want: `give 5 gold Bob`},
+		{name: "over the limit", tool: ConversationTool{Name: ToolGive, MaxGold: 10}, args: map[string]any{`gold`: float64(11)}, wantErr: true},
+		{name: "more than the mob has", tool: ConversationTool{Name: ToolGive, MaxGold: 50}, args: map[string]any{`gold`: float64(30)}, wantErr: true},
+		{name: "gold not allowed", tool: ConversationTool{Name: ToolGive}, args: map[string]any{`gold`: float64(1)}, wantErr: true},
+		{name: "negative", tool: ConversationTool{Name: ToolGive, MaxGold: 10}, args: map[string]any{`gold`: float64(-5)}, wantErr: true},
+		{name: "item not carried", tool: ConversationTool{Name: ToolGive}, args: map[string]any{`item`: `sword`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := commandGive(tt.tool, mob, user, tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cmd)
+		})
+	}
+}
+
+func TestCommandPathTo(t *testing.T) {
+
+	tool := ConversationTool{Name: ToolPathTo, RoomIds: []int{1, 59}}
+
+	cmd, err := commandPathTo(tool, nil, nil, map[string]any{`destination`: `Home`})
+	assert.NoError(t, err)
+	assert.Equal(t, `pathto home`, cmd)
+
+	cmd, err = commandPathTo(tool, nil, nil, map[string]any{`destination`: float64(59)})
+	assert.NoError(t, err)
+	assert.Equal(t, `pathto 59`, cmd)
+
+	_, err = commandPathTo(tool, nil, nil, map[string]any{`destination`: `60`})
+	assert.Error(t, err)
+
+	_, err = commandPathTo(tool, nil, nil, map[string]any{})
+	assert.Error(t, err)
+}
+
+func TestCommandGiveQuest_NotOffered(t *testing.T) {
+
+	mob := &mobs.Mob{QuestFlags: []string{`1-start`}}
+	user := &users.UserRecord{Character: &characters.Character{Name: `Bob`}}
+
+	// 2-start isn't one of the mob's quests, whatever the whitelist says
+	_, err := commandGiveQuest(ConversationTool{Name: ToolGiveQuest, Quests: []string{`2-start`}}, mob, user, map[string]any{`quest`: `2-start`})
+	assert.Error(t, err)
+}
+
+func TestToolCommand_NotWhitelisted(t *testing.T) {
+
+	mob := &mobs.Mob{InstanceId: 3}
+	conv := &Conversation{
+		MobInstanceId1: 3,
+		MobInstanceId2: 7,
+		IsPlayer2:      true,
+		LLMConfig:      &LLMConversationConfig{Tools: []ConversationTool{{Name: ToolShow}}},
+	}
+
+	_, err := conv.toolCommand(events.LLMToolCall{Name: ToolGive, Arguments: map[string]any{`gold`: float64(1)}}, mob, 7)
+	assert.ErrorIs(t, err, errToolNotAllowed)
+
+	_, err = conv.toolCommand(events.LLMToolCall{Name: `attack`}, mob, 7)
+	assert.ErrorIs(t, err, errToolNotAllowed)
+
+	// Not the player in this conversation
+	_, err = conv.toolCommand(events.LLMToolCall{Name: ToolShow}, mob, 8)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errToolNotAllowed)
+}
@@ -0,0 +1,275 @@
+package copyover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+//
+// Copyover (hot reboot) swaps in a new server binary without disconnecting anyone.
+// The running server saves the world, writes down who is on which connection,
+// then execs the new binary in its place. The listening and player sockets stay open
+// across the exec, and the new process reattaches everyone to their characters.
+//
+
+const (
+	// EnvStateFile tells the new process where to find the saved state
+	EnvStateFile = `GOMUD_COPYOVER`
+
+	stateFileName = `copyover.json`
+)
+
+var (
+	ErrUnsupported = errors.New("copyover is not supported on this platform")
+
+	lock        = sync.Mutex{}
+	inherited   = map[string]*os.File{}     // Listening sockets handed over by the previous process, by address
+	listeners   = map[string]net.Listener{} // Listening sockets to hand over to the next process, by address
+	requestedBy = 0
+)
+
+// State is everything the new process needs to pick up where the old one left off
+type State struct {
+	Started     time.Time
+	RequestedBy int // UserId of the admin who asked for it
+	Listeners   []Listener
+	Connections []Connection
+
+	files []*os.File
+}
+
+type Listener struct {
+	Address string
+	Fd      uintptr
+}
+
+type Connection struct {
+	ConnectionId            uint64
+	Fd                      uintptr
+	Username                string // Empty if they hadn't finished logging in
+	ClientSettings          connections.ClientSettings
+	Compressed              bool // MCCP2 was on, so is turned back on
+	InputCompressionAllowed bool // The client agreed to MCCP3
+	Plugins                 map[string][]byte
+}
+
+// Request asks the server to copyover, which happens outside of the game loop
+func Request(userId int) {
+
+	lock.Lock()
+	requestedBy = userId
+	lock.Unlock()
+
+	go connections.SignalShutdown(Signal)
+}
+
+// NewState starts the state for a copyover
+func NewState() *State {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	return &State{
+		Started:     time.Now(),
+		RequestedBy: requestedBy,
+		Listeners:   []Listener{},
+		Connections: []Connection{},
+	}
+}
+
+// Listen returns the listening socket for an address, reusing the one inherited from before a copyover if there is one.
+// Either way it is remembered so that it can be handed to the next process.
+func Listen(address string) (net.Listener, error) {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	var l net.Listener
+	var err error
+
+	if f, ok := inherited[address]; ok {
+		delete(inherited, address)
+		l, err = net.FileListener(f)
+		f.Close()
+	}
+
+	if l == nil {
+		if l, err = net.Listen(`tcp`, address); err != nil {
+			return nil, err
+		}
+	}
+
+	listeners[address] = l
+
+	return l, nil
+}
+
+// CloseUnclaimed closes inherited listening sockets that nothing asked for, such as a port removed from the config
+func CloseUnclaimed() {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	for address, f := range inherited {
+		f.Close()
+		delete(inherited, address)
+	}
+}
+
+// Resume loads the state left by the previous process, returning false if this isn't a copyover
+func Resume() (State, bool) {
+
+	state := State{}
+
+	path := os.Getenv(EnvStateFile)
+	if path == `` {
+		return state, false
+	}
+
+	// Nothing started from this process should think it is a copyover
+	os.Unsetenv(EnvStateFile)
+
+	data, err := os.ReadFile(path)
+	os.Remove(path)
+	if err != nil {
+		return state, false
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, l := range state.Listeners {
+		inherited[l.Address] = os.NewFile(l.Fd, l.Address)
+	}
+
+	return state, true
+}
+
+// AddConnection hands a connection over to the next process.
+// Output compression is ended here since the stream can't be carried over, and restarted afterwards.
+func (s *State) AddConnection(cd *connections.ConnectionDetails, username string, pluginData map[string][]byte) error {
+
+	f, err := cd.File()
+	if err != nil {
+		return err
+	}
+
+	compressed := cd.Compressed()
+	if compressed {
+		cd.StopCompression()
+	}
+
+	s.files = append(s.files, f)
+
+	s.Connections = append(s.Connections, Connection{
+		ConnectionId:            cd.ConnectionId(),
+		Fd:                      f.Fd(),
+		Username:                username,
+		ClientSettings:          connections.GetClientSettings(cd.ConnectionId()),
+		Compressed:              compressed,
+		InputCompressionAllowed: cd.InputCompressionAllowed(),
+		Plugins:                 pluginData,
+	})
+
+	return nil
+}
+
+// Exec replaces this process with the executable, which inherits the listening sockets and connections.
+// It only returns if something went wrong, in which case the server can carry on as it was.
+func (s *State) Exec(executable string) error {
+
+	// Returning at all means the connections carry on with this process
+	defer s.restartCompression()
+
+	lock.Lock()
+	for address, l := range listeners {
+
+		fl, ok := l.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+
+		f, err := fl.File()
+		if err != nil {
+			lock.Unlock()
+			s.closeFiles()
+			return fmt.Errorf("listener %s: %w", address, err)
+		}
+
+		s.files = append(s.files, f)
+		s.Listeners = append(s.Listeners, Listener{Address: address, Fd: f.Fd()})
+	}
+	lock.Unlock()
+
+	for _, f := range s.files {
+		if err := inheritable(f); err != nil {
+			s.closeFiles()
+			return err
+		}
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		s.closeFiles()
+		return err
+	}
+
+	path := util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, stateFileName)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		s.closeFiles()
+		return err
+	}
+
+	os.Setenv(EnvStateFile, path)
+
+	err = execute(executable, os.Args, os.Environ())
+
+	// Still here, so the exec failed
+	os.Unsetenv(EnvStateFile)
+	os.Remove(path)
+	s.closeFiles()
+
+	return err
+}
+
+// restartCompression turns output compression back on where AddConnection() ended it
+func (s *State) restartCompression() {
+	for _, c := range s.Connections {
+		if !c.Compressed {
+			continue
+		}
+		if cd := connections.Get(c.ConnectionId); cd != nil {
+			cd.StartCompression()
+		}
+	}
+}
+
+func (s *State) closeFiles() {
+	for _, f := range s.files {
+		f.Close()
+	}
+	s.files = nil
+	s.Listeners = []Listener{}
+}
+
+// Conn reopens a connection inherited from the previous process
+func (c Connection) Conn() (net.Conn, error) {
+
+	f := os.NewFile(c.Fd, fmt.Sprintf(`connection-%d`, c.ConnectionId))
+	defer f.Close()
+
+	return net.FileConn(f)
+}
@@ -0,0 +1,29 @@
+//go:build !unix
+
+package copyover
+
+import (
+	"os"
+)
+
+type copyoverSignal struct{}
+
+func (copyoverSignal) String() string { return `copyover` }
+func (copyoverSignal) Signal()        {}
+
+// Signal starts a copyover, which always fails on this platform
+var Signal os.Signal = copyoverSignal{}
+
+func Supported() bool {
+	return false
+}
+
+func Notify(c chan<- os.Signal) {}
+
+func inheritable(f *os.File) error {
+	return ErrUnsupported
+}
+
+func execute(executable string, args []string, env []string) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,136 @@
+//go:build unix
+
+package copyover
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeState(t *testing.T, state State) {
+	t.Helper()
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), stateFileName)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	t.Setenv(EnvStateFile, path)
+}
+
+func TestResume_NotACopyover(t *testing.T) {
+	t.Setenv(EnvStateFile, ``)
+
+	_, ok := Resume()
+	assert.False(t, ok)
+}
+
+func TestResume_InheritsListener(t *testing.T) {
+
+	original, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	require.NoError(t, err)
+	defer original.Close()
+
+	f, err := original.(*net.TCPListener).File()
+	require.NoError(t, err)
+
+	address := original.Addr().String()
+
+	writeState(t, State{
+		RequestedBy: 5,
+		Listeners:   []Listener{{Address: address, Fd: f.Fd()}},
+	})
+
+	state, ok := Resume()
+	require.True(t, ok)
+	assert.Equal(t, 5, state.RequestedBy)
+	assert.Empty(t, os.Getenv(EnvStateFile), "the state is only for this process")
+
+	// The inherited socket is used, rather than binding the address again
+	l, err := Listen(address)
+	require.NoError(t, err)
+	defer l.Close()
+
+	assert.Equal(t, address, l.Addr().String())
+
+	client, err := net.Dial(`tcp`, address)
+	require.NoError(t, err)
+	defer client.Close()
+
+	server, err := l.Accept()
+	require.NoError(t, err)
+	server.Close()
+
+	lock.Lock()
+	delete(listeners, address)
+	lock.Unlock()
+}
+
+func TestConnection_Conn(t *testing.T) {
+
+	l, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	require.NoError(t, err)
+	defer l.Close()
+
+	client, err := net.Dial(`tcp`, l.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	server, err := l.Accept()
+	require.NoError(t, err)
+
+	f, err := server.(*net.TCPConn).File()
+	require.NoError(t, err)
+	server.Close()
+
+	// The copy keeps the connection open after the original is closed
+	conn, err := Connection{ConnectionId: 1, Fd: f.Fd()}.Conn()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = client.Write([]byte(`hello`))
+	require.NoError(t, err)
+
+	buf := make([]byte, 5)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, `hello`, string(buf))
+}
+
+func TestExec_FailedRestartsCompression(t *testing.T) {
+
+	testsupport.UseDataFiles(t, nil)
+
+	l, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	require.NoError(t, err)
+	defer l.Close()
+
+	client, err := net.Dial(`tcp`, l.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	server, err := l.Accept()
+	require.NoError(t, err)
+
+	compressed := connections.Add(server, nil)
+	defer connections.Remove(compressed.ConnectionId())
+	require.NoError(t, compressed.StartCompression())
+
+	state := NewState()
+	require.NoError(t, state.AddConnection(compressed, `someone`, nil))
+	assert.False(t, compressed.Compressed(), "compression ends before handing over")
+
+	require.Error(t, state.Exec(filepath.Join(t.TempDir(), `missing`)))
+
+	// Carrying on as it was
+	assert.True(t, compressed.Compressed())
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package copyover
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Signal starts a copyover, whether sent by an admin in game or with `kill -USR2`
+var Signal os.Signal = syscall.SIGUSR2
+
+func Supported() bool {
+	return true
+}
+
+// Notify relays copyover requests from outside the server to c
+func Notify(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR2)
+}
+
+// inheritable keeps the file open in the executed process.
+// Go opens everything close-on-exec.
+func inheritable(f *os.File) error {
+	_, err := unix.FcntlInt(f.Fd(), unix.F_SETFD, 0)
+	return err
+}
+
+func execute(executable string, args []string, env []string) error {
+	return unix.Exec(executable, args, env)
+}
@@ -199,6 +199,8 @@ func ProcessEvents() {
 	}
 	requeues = requeues[:0]
 
+	queueDepthMetric.Set(float64(globalQueue.Len()))
+
 	var evtResult ListenerReturn
 	for {
 
@@ -220,7 +222,9 @@ func ProcessEvents() {
 
 		qLock.Unlock()
 
+		evtStart := time.Now()
 		evtResult = DoListeners(pe.event)
+		eventSecondsMetric.ObserveSince(evtStart, pe.event.Type())
 		if evtResult == CancelAndRequeue {
 			addToRequeue(pe.event, pe.priority)
 		}
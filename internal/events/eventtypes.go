@@ -94,6 +94,7 @@ type Communication struct {
 	CommType            string // say, party, broadcast, whisper, shout
 	Name                string
 	Message             string
+	Speaking            bool // More of this message is still being generated
 }
 
 func (m Communication) Type() string { return `Communication` }
@@ -122,6 +123,11 @@ type MSP struct {
 	SoundFile string
 	Volume    int    // 1-100
 	Category  string // special category/type for MSP string
+	Tag       string // Groups sounds so they can be stopped together
+	Priority  int    // 1-100, only for clients that support it
+	Loops     int    // -1 to repeat forever, only for clients that support it
+	FadeMs    int    // Milliseconds to fade in/out, only for clients that support it
+	Stop      bool   // Stops whatever is playing with Tag (everything if Tag is empty) instead of playing SoundFile
 }
 
 func (m MSP) Type() string { return `MSP` }
@@ -360,6 +366,14 @@ func (r RebuildMap) UniqueID() string {
 	return `RebuildMap-` + strconv.Itoa(r.MapRootRoomId) + `-` + strconv.FormatBool(r.SkipIfExists)
 }
 
+// Saves everything, then takes a backup snapshot
+type Backup struct {
+	UserId int // Who asked for it, or 0 if it was scheduled
+}
+
+func (b Backup) Type() string     { return `Backup` }
+func (b Backup) UniqueID() string { return `Backup` }
+
 type RedrawPrompt struct {
 	UserId        int
 	OnlyIfChanged bool
@@ -367,3 +381,65 @@ type RedrawPrompt struct {
 
 func (l RedrawPrompt) Type() string     { return `RedrawPrompt` }
 func (l RedrawPrompt) UniqueID() string { return `RedrawPrompt-` + strconv.Itoa(l.UserId) }
+
+// Result of a background LLM request
+// Error will be set if the request failed, timed out or was cancelled
+type LLMResponse struct {
+	JobId          uint64
+	UserId         int
+	MobInstanceId  int
+	ConversationId int
+	Tag            string
+	Text           string
+	Error          error
+	Duration       time.Duration
+	Streamed       bool // Text was already delivered as LLMResponseChunk events
+	ToolCalls      []LLMToolCall
+}
+
+// A function the model asked to have called
+type LLMToolCall struct {
+	Name      string
+	Arguments map[string]any
+}
+
+func (l LLMResponse) Type() string { return `LLMResponse` }
+
+// A sentence sized piece of a streaming LLM request
+// The full text still arrives afterwards in an LLMResponse
+type LLMResponseChunk struct {
+	JobId          uint64
+	UserId         int
+	MobInstanceId  int
+	ConversationId int
+	Tag            string
+	Text           string
+	Final          bool // No more chunks will follow
+}
+
+func (l LLMResponseChunk) Type() string { return `LLMResponseChunk` }
+
+// LLM usage has crossed a warning threshold, or run into a quota
+type LLMQuotaThreshold struct {
+	UserId  int    // 0 for the server-wide quota
+	Period  string // daily or monthly
+	Metric  string // tokens or requests
+	Used    int
+	Limit   int
+	Percent int // The threshold crossed. 100 means the quota is used up.
+}
+
+func (l LLMQuotaThreshold) Type() string { return `LLMQuotaThreshold` }
+
+// A ban was added or lifted, or failed logins locked out an account or address
+type BanChange struct {
+	Action   string // ban, unban or lockout
+	Kind     string // username, ip or cidr (lockouts are username or address)
+	Value    string
+	Reason   string
+	By       string
+	Until    time.Time // When a ban or lockout ends. Zero for never.
+	Failures int       // Failed logins that caused a lockout
+}
+
+func (b BanChange) Type() string { return `BanChange` }
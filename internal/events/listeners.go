@@ -2,6 +2,7 @@ package events
 
 import (
 	"sync"
+	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 )
@@ -12,6 +13,7 @@ type ListenerId uint64
 
 type ListenerWrapper struct {
 	id       ListenerId
+	name     string
 	listener Listener
 	isFinal  bool
 }
@@ -79,6 +81,7 @@ func RegisterListener(emptyEvent any, cbFunc Listener, qFlag ...QueueFlag) Liste
 
 	listenerDetails := ListenerWrapper{
 		id:       listenerCt,
+		name:     listenerName(cbFunc),
 		listener: cbFunc,
 		isFinal:  len(qFlag) > 0 && qFlag[0] == Last,
 	}
@@ -109,7 +112,7 @@ func RegisterListener(emptyEvent any, cbFunc Listener, qFlag ...QueueFlag) Liste
 	}
 
 	// Write it to debug out
-	//mudlog.Debug("Listener Registered", "Event", eType, "Function", runtime.FuncForPC(reflect.ValueOf(cbFunc).Pointer()).Name())
+	//mudlog.Debug("Listener Registered", "Event", eType, "Function", listenerDetails.name)
 
 	if eType == `*` {
 		hasWildcardListener = true
@@ -148,6 +151,14 @@ func UnregisterListener(emptyEvent Event, id ListenerId) bool {
 
 }
 
+// run calls the listener and records how long it took
+func (lw ListenerWrapper) run(e Event) ListenerReturn {
+	start := time.Now()
+	result := lw.listener(e)
+	listenerSecondsMetric.ObserveSince(start, e.Type(), lw.name)
+	return result
+}
+
 func DoListeners(e Event) ListenerReturn {
 
 	listenerLock.Lock()
@@ -163,7 +174,7 @@ func DoListeners(e Event) ListenerReturn {
 		if vals, ok := eventListeners[`*`]; ok {
 			listenerFound = true
 			for _, lw := range vals {
-				if result := lw.listener(e); result != Continue {
+				if result := lw.run(e); result != Continue {
 					return result
 				}
 			}
@@ -174,7 +185,7 @@ func DoListeners(e Event) ListenerReturn {
 	if vals, ok := eventListeners[e.Type()]; ok {
 		listenerFound = true
 		for _, lw := range vals {
-			if result := lw.listener(e); result != Continue {
+			if result := lw.run(e); result != Continue {
 				return result
 			}
 		}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+)
+
+var (
+	queueDepthMetric = metrics.NewGauge(
+		`gomud_event_queue_depth`,
+		`Events waiting in the queue the last time it was processed.`,
+	)
+	eventSecondsMetric = metrics.NewHistogram(
+		`gomud_event_duration_seconds`,
+		`Time spent running all of the listeners for an event.`,
+		metrics.DurationBuckets,
+		`event`,
+	)
+	listenerSecondsMetric = metrics.NewHistogram(
+		`gomud_listener_duration_seconds`,
+		`Time spent in each listener (hook), by the function that handles it.`,
+		metrics.DurationBuckets,
+		`event`, `listener`,
+	)
+)
+
+// listenerName returns a short name for a listener func, such as "hooks.AutoHeal" or "gmcp.(*GMCPModule).dispatchGMCP"
+func listenerName(cbFunc Listener) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(cbFunc).Pointer())
+	if fn == nil {
+		return `unknown`
+	}
+	name := fn.Name()
+	if idx := strings.LastIndex(name, `/`); idx >= 0 {
+		name = name[idx+1:]
+	}
+	// Methods passed as funcs end in -fm
+	return strings.TrimSuffix(name, `-fm`)
+}
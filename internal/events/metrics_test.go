@@ -0,0 +1,18 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testModule struct{}
+
+func (m *testModule) onEvent(e Event) ListenerReturn { return Continue }
+
+func testListener(e Event) ListenerReturn { return Continue }
+
+func TestListenerName(t *testing.T) {
+	assert.Equal(t, `events.testListener`, listenerName(testListener))
+	assert.Equal(t, `events.(*testModule).onEvent`, listenerName((&testModule{}).onEvent))
+}
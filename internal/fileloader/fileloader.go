@@ -13,6 +13,7 @@ import (
 
 	"sync/atomic"
 
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
@@ -304,6 +305,8 @@ func SaveFlatFile[T LoadableSimple](basePath string, dataUnit T, saveOptions ...
 		return errors.Wrap(err, "failed to marshal data for file: "+filePath)
 	}
 
+	changelog.Touch(filePath)
+
 	useCarefulSave := false
 	for _, opt := range saveOptions {
 		if opt == SaveCareful {
@@ -415,6 +418,48 @@ func SaveAllFlatFiles[K comparable, T Loadable[K]](basePath string, data map[K]T
 	return int(atomic.LoadInt32(&filesSaved)), nil
 }
 
+// DeleteFlatFile removes the file a data unit was saved to.
+// A file that is already gone is not an error.
+func DeleteFlatFile[T LoadableSimple](basePath string, dataUnit T) error {
+	filePath := filepath.FromSlash(filepath.Join(basePath, dataUnit.Filepath()))
+
+	changelog.Touch(filePath)
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to delete file: "+filePath)
+	}
+
+	return nil
+}
+
+// MoveFile renames src to dst, creating any missing folders for dst.
+// A missing src is not an error, since things like scripts are optional.
+func MoveFile(src, dst string) error {
+	src = filepath.FromSlash(src)
+	dst = filepath.FromSlash(dst)
+
+	if src == dst {
+		return nil
+	}
+
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return errors.Wrap(err, "failed to create directory for moving file: "+dst)
+	}
+
+	changelog.Touch(src)
+	changelog.Touch(dst)
+
+	if err := os.Rename(src, dst); err != nil {
+		return errors.Wrap(err, "failed to move file: "+src)
+	}
+
+	return nil
+}
+
 // CopyFileContents copies the contents of the file named src to the file named
 // by dst. The file will be created if it does not already exist. If the
 // destination file exists, all it's contents will be replaced by the contents
@@ -425,6 +470,7 @@ func CopyFileContents(src, dst string) (err error) {
 		return
 	}
 	defer in.Close()
+	changelog.Touch(dst)
 	out, err := os.Create(filepath.FromSlash(dst))
 	if err != nil {
 		return
@@ -11,10 +11,21 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 )
 
+var (
+	simulateRounds int
+	migrateStorage string
+	restore        string
+	restoreOnly    string
+)
+
 func HandleFlags() {
 	var portsearch string
 
 	flag.StringVar(&portsearch, "port-search", "", "Search for the first 10 open ports: -port-search=30000-40000")
+	flag.IntVar(&simulateRounds, "simulate", 0, "Run the world for a number of rounds without any listeners, then print a summary: -simulate=1000")
+	flag.StringVar(&migrateStorage, "migrate-storage", "", "Copy everything saved into another storage backend (yaml or sqlite), then exit: -migrate-storage=sqlite")
+	flag.StringVar(&restore, "restore", "", "Restore a backup snapshot (or latest) while the server is stopped, then exit: -restore=latest")
+	flag.StringVar(&restoreOnly, "restore-only", "", "Only restore one user or zone with -restore: -restore-only=user:bob or -restore-only=zone:Frostfang")
 
 	flag.Parse()
 
@@ -66,3 +77,20 @@ func isPortInUse(port int) bool {
 	ln.Close()
 	return false
 }
+
+// SimulateRounds is how many rounds -simulate asked for, or 0 if the server should run normally
+func SimulateRounds() int {
+	return simulateRounds
+}
+
+// MigrateStorage is the backend -migrate-storage asked to copy saved data into, or empty
+func MigrateStorage() string {
+	return migrateStorage
+}
+
+// Restore is the backup snapshot -restore asked for, or empty.
+// RestoreOnly is what kind of thing to restore ("user" or "zone") and its name, or empty for everything.
+func Restore() (snapshot string, only string, name string) {
+	only, name, _ = strings.Cut(restoreOnly, `:`)
+	return restore, strings.ToLower(only), name
+}
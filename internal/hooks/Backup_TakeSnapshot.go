@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/backups"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/plugins"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+//
+// Saves everything in memory, then snapshots it
+//
+
+func TakeSnapshot(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.Backup)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "Backup", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	start := time.Now()
+	defer func() {
+		util.TrackTime(`TakeSnapshot`, time.Since(start).Seconds())
+	}()
+
+	users.SaveAllUsers(true)
+	rooms.SaveAllRooms()
+	plugins.Save()
+	util.SaveRoundCount(configs.GetFilePathsConfig().DataFiles.String() + `/` + util.RoundCountFilename)
+
+	snap, err := backups.Create()
+
+	if err != nil {
+		mudlog.Error("Backup", "error", err)
+	} else {
+		mudlog.Info("Backup", "snapshot", snap.Name, "size", snap.Size, "time taken", time.Since(start))
+	}
+
+	if evt.UserId == 0 {
+		return events.Continue
+	}
+
+	if user := users.GetByUserId(evt.UserId); user != nil {
+		if err != nil {
+			user.SendText(fmt.Sprintf(`<ansi fg="red">Backup failed:</ansi> %s`, err.Error()))
+		} else {
+			user.SendText(fmt.Sprintf(`Saved snapshot <ansi fg="yellow">%s</ansi> (%.1fKB).`, snap.Name, float64(snap.Size)/1024))
+		}
+	}
+
+	return events.Continue
+}
@@ -18,16 +18,6 @@ func Broadcast_SendToAll(e events.Event) events.ListenerReturn {
 		return events.Continue
 	}
 
-	textOut := ``
-	if len(broadcast.Text) > 0 {
-		textOut = templates.AnsiParse(broadcast.Text)
-	}
-
-	textOutSR := ``
-	if len(broadcast.TextScreenReader) > 0 {
-		textOutSR = templates.AnsiParse(broadcast.TextScreenReader)
-	}
-
 	for _, u := range users.GetAllActiveUsers() {
 
 		if broadcast.IsCommunication {
@@ -38,9 +28,12 @@ func Broadcast_SendToAll(e events.Event) events.ListenerReturn {
 
 		events.AddToQueue(events.RedrawPrompt{UserId: u.UserId}, 100)
 
+		// Parsed for each connection, since each can show a different number of colors
 		if u.ScreenReader {
 
-			if len(textOutSR) > 0 {
+			if len(broadcast.TextScreenReader) > 0 {
+
+				textOutSR := templates.AnsiParse(broadcast.TextScreenReader, u.ConnectionId())
 
 				if broadcast.SkipLineRefresh {
 					connections.SendTo(
@@ -59,6 +52,8 @@ func Broadcast_SendToAll(e events.Event) events.ListenerReturn {
 
 		}
 
+		textOut := templates.AnsiParse(broadcast.Text, u.ConnectionId())
+
 		if broadcast.SkipLineRefresh {
 			connections.SendTo(
 				[]byte(textOut),
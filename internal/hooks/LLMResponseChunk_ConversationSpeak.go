@@ -0,0 +1,57 @@
+package hooks
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/conversations"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+//
+// Speaks streamed NPC replies a sentence at a time as they're generated
+//
+
+func ConversationSpeak(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.LLMResponseChunk)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "LLMResponseChunk", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	if evt.Tag != conversations.LLMTagReply && evt.Tag != conversations.LLMTagFarewell {
+		return events.Continue
+	}
+
+	// The mob may have died or despawned while we were waiting
+	mob := mobs.GetInstance(evt.MobInstanceId)
+	if mob == nil {
+		return events.Continue
+	}
+
+	room := rooms.LoadRoom(mob.Character.RoomId)
+	if room == nil {
+		return events.Continue
+	}
+
+	if evt.Tag == conversations.LLMTagFarewell {
+		conversationFarewell(mob, room, evt.Text, !evt.Final)
+		return events.Continue
+	}
+
+	// Ignore anything from a request the conversation has given up on
+	if !conversations.IsPendingResponse(evt.ConversationId, evt.JobId) {
+		return events.Continue
+	}
+
+	user := users.GetByUserId(evt.UserId)
+	if user == nil || user.Character.RoomId != mob.Character.RoomId {
+		return events.Continue
+	}
+
+	conversations.Say(mob, user, room, evt.Text, !evt.Final)
+
+	return events.Continue
+}
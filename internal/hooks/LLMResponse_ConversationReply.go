@@ -0,0 +1,88 @@
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/GoMudEngine/GoMud/internal/conversations"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+//
+// Delivers NPC replies that were generated in the background
+//
+
+func ConversationReply(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.LLMResponse)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "LLMResponse", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	if evt.Tag != conversations.LLMTagReply && evt.Tag != conversations.LLMTagFarewell {
+		return events.Continue
+	}
+
+	// The mob may have died or despawned while we were waiting
+	mob := mobs.GetInstance(evt.MobInstanceId)
+	if mob == nil {
+		return events.Continue
+	}
+
+	room := rooms.LoadRoom(mob.Character.RoomId)
+	if room == nil {
+		return events.Continue
+	}
+
+	if evt.Tag == conversations.LLMTagFarewell {
+		// Streamed farewells have already been said
+		if evt.Error == nil && evt.Text != "" && !evt.Streamed {
+			conversationFarewell(mob, room, evt.Text, false)
+		}
+		return events.Continue
+	}
+
+	response, err := conversations.HandleLLMResponse(evt)
+	if err != nil {
+		mudlog.Error("ConversationReply", "error", fmt.Sprintf("Error processing player input: %v", err))
+		return events.Continue
+	}
+
+	// Any actions the NPC decided to take follow what it said
+	defer conversations.HandleToolCalls(evt)
+
+	// Streamed replies have already been said, a sentence at a time
+	if response == "" || evt.Streamed {
+		return events.Continue
+	}
+
+	user := users.GetByUserId(evt.UserId)
+	if user == nil || user.Character.RoomId != mob.Character.RoomId {
+		return events.Continue
+	}
+
+	conversations.Say(mob, user, room, response, false)
+
+	mudlog.Debug("ConversationReply", "response", fmt.Sprintf("NPC response: %s", response), "waited", evt.Duration)
+
+	return events.Continue
+}
+
+// conversationFarewell has a mob say goodbye to the room.
+// speaking indicates more is still to come.
+func conversationFarewell(mob *mobs.Mob, room *rooms.Room, text string, speaking bool) {
+
+	room.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> says, "<ansi fg="saytext">%s</ansi>"`, mob.Character.Name, text))
+
+	events.AddToQueue(events.Communication{
+		SourceMobInstanceId: mob.InstanceId,
+		CommType:            `say`,
+		Name:                mob.Character.Name,
+		Message:             text,
+		Speaking:            speaking,
+	})
+}
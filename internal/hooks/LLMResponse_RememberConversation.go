@@ -0,0 +1,28 @@
+package hooks
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/conversations"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+//
+// Stores the summary of a finished conversation in the NPC's memory
+//
+
+func RememberConversation(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.LLMResponse)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "LLMResponse", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	if evt.Tag != conversations.LLMTagSummary {
+		return events.Continue
+	}
+
+	conversations.HandleSummaryResponse(evt)
+
+	return events.Continue
+}
@@ -0,0 +1,26 @@
+package hooks
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/llm"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+//
+// Copies token usage from background requests onto the player record
+//
+
+func SaveLLMTokenUsage(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.LLMResponse)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "LLMResponse", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	if evt.UserId > 0 && evt.Error == nil {
+		llm.SaveTokenUsageToPlayer(evt.UserId)
+	}
+
+	return events.Continue
+}
@@ -3,6 +3,7 @@ package hooks
 import (
 	"strconv"
 
+	"github.com/GoMudEngine/GoMud/internal/audio"
 	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
@@ -11,7 +12,8 @@ import (
 )
 
 //
-// Checks for quests on the item
+// Plays sounds and music over MSP.
+// Clients that support GMCP Client.Media are handled by the gmcp module before this.
 //
 
 func PlaySound(e events.Event) events.ListenerReturn {
@@ -26,6 +28,11 @@ func PlaySound(e events.Event) events.ListenerReturn {
 		return events.Continue
 	}
 
+	if evt.Stop {
+		stopSound(evt)
+		return events.Continue
+	}
+
 	if evt.SoundFile == `` {
 		return events.Continue
 	}
@@ -99,3 +106,34 @@ func PlaySound(e events.Event) events.ListenerReturn {
 
 	return events.Continue
 }
+
+// MSP can't stop by tag, so stopping music stops all music, and anything else stops all sounds
+func stopSound(evt events.MSP) {
+
+	user := users.GetByUserId(evt.UserId)
+	if user == nil {
+		return
+	}
+
+	msgs := [][]byte{}
+	if evt.Tag != audio.MusicTag {
+		msgs = append(msgs, []byte("!!SOUND(Off)"))
+	}
+	if evt.Tag == audio.MusicTag || evt.Tag == `` {
+		msgs = append(msgs, []byte("!!MUSIC(Off)"))
+		user.LastMusic = ``
+	}
+
+	for _, msg := range msgs {
+
+		if connections.IsWebsocket(user.ConnectionId()) {
+			connections.SendTo(msg, user.ConnectionId())
+			continue
+		}
+
+		connections.SendTo(
+			term.MspCommand.BytesWithPayload(msg),
+			user.ConnectionId(),
+		)
+	}
+}
@@ -32,7 +32,7 @@ func Message_SendMessage(e events.Event) events.ListenerReturn {
 				return events.Continue
 			}
 
-			textOut := templates.AnsiParse(message.Text)
+			textOut := templates.AnsiParse(message.Text, user.ConnectionId())
 			if user.ScreenReader {
 				textOut = util.StripCharsForScreenReaders(textOut)
 			}
@@ -85,7 +85,7 @@ func Message_SendMessage(e events.Event) events.ListenerReturn {
 					}
 				}
 
-				textOut := templates.AnsiParse(message.Text)
+				textOut := templates.AnsiParse(message.Text, user.ConnectionId())
 				if user.ScreenReader {
 					textOut = util.StripCharsForScreenReaders(textOut)
 				}
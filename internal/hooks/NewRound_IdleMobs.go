@@ -24,6 +24,9 @@ import (
 
 func IdleMobs(e events.Event) events.ListenerReturn {
 
+	// Finish off any conversations that ended since last round
+	conversations.DestroyPending()
+
 	mc := configs.GetMemoryConfig()
 
 	maxBoredom := uint8(mc.MaxMobBoredom)
@@ -0,0 +1,41 @@
+package hooks
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/gametime"
+)
+
+//
+// Queues a backup every Backups.Period
+//
+
+var (
+	nextBackupRound uint64
+)
+
+func ScheduleBackups(e events.Event) events.ListenerReturn {
+
+	evt := e.(events.NewRound)
+
+	period := configs.GetBackupsConfig().Period.String()
+	if period == `` {
+		return events.Continue
+	}
+
+	// The first one is a full period after starting
+	if nextBackupRound == 0 {
+		nextBackupRound = gametime.GetDate(evt.RoundNumber).AddPeriod(period)
+		return events.Continue
+	}
+
+	if evt.RoundNumber < nextBackupRound {
+		return events.Continue
+	}
+
+	nextBackupRound = gametime.GetDate(evt.RoundNumber).AddPeriod(period)
+
+	events.AddToQueue(events.Backup{})
+
+	return events.Continue
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/llm"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/plugins"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
@@ -63,6 +64,8 @@ func AutoSave(e events.Event) events.ListenerReturn {
 		events.AddToQueue(events.Broadcast{Text: `Saving other...`})
 		// Save plugin states if applicable
 		plugins.Save()
+		// LLM usage is counted in memory as requests complete
+		llm.SaveQuotaUsage()
 
 		events.AddToQueue(events.Broadcast{
 			Text:            `Done.` + term.CRLFStr,
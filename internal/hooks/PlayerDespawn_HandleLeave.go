@@ -7,6 +7,7 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/llm"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/parties"
@@ -40,6 +41,9 @@ func HandleLeave(e events.Event) events.ListenerReturn {
 		users.RemoveZombieUser(evt.UserId)
 	}
 
+	// Nobody left to hear any replies
+	llm.CancelUser(evt.UserId)
+
 	room := rooms.LoadRoom(user.Character.RoomId)
 
 	if currentParty := parties.Get(evt.UserId); currentParty != nil {
@@ -60,7 +64,7 @@ func HandleLeave(e events.Event) events.ListenerReturn {
 	}
 
 	tplTxt, _ := templates.Process("goodbye", nil, evt.UserId)
-	connections.SendTo([]byte(templates.AnsiParse(tplTxt)), connId)
+	connections.SendTo([]byte(templates.AnsiParse(tplTxt, connId)), connId)
 
 	if err := users.LogOutUserByConnectionId(connId); err != nil {
 		mudlog.Error("Log Out Error", "connectionId", connId, "error", err)
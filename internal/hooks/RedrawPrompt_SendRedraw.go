@@ -35,7 +35,7 @@ func RedrawPrompt_SendRedraw(e events.Event) events.ListenerReturn {
 
 		}
 
-		pTxt := templates.AnsiParse(newCmdPrompt)
+		pTxt := templates.AnsiParse(newCmdPrompt, user.ConnectionId())
 		connections.SendTo([]byte(pTxt), user.ConnectionId())
 
 	}
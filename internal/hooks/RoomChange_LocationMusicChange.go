@@ -1,6 +1,7 @@
 package hooks
 
 import (
+	"github.com/GoMudEngine/GoMud/internal/audio"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
@@ -45,14 +46,15 @@ func LocationMusicChange(e events.Event) events.ListenerReturn {
 
 	// If this zone has music, play it.
 	// Room music takes priority.
+	// Clients that support it crossfade between the old and new music.
 	if newRoom.MusicFile != `` {
-		user.PlayMusic(newRoom.MusicFile)
+		user.PlayMusic(newRoom.MusicFile, audio.ZoneCrossfadeMs)
 	} else {
 		zoneInfo := rooms.GetZoneConfig(newRoom.Zone)
 		if zoneInfo.MusicFile != `` {
-			user.PlayMusic(zoneInfo.MusicFile)
+			user.PlayMusic(zoneInfo.MusicFile, audio.ZoneCrossfadeMs)
 		} else if oldRoom.MusicFile != `` {
-			user.PlayMusic(`Off`)
+			user.PlayMusic(`Off`, audio.ZoneCrossfadeMs)
 		}
 	}
 
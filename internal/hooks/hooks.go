@@ -21,6 +21,7 @@ func RegisterListeners() {
 	events.RegisterListener(events.NewRound{}, UpdateZoneMutators)
 	events.RegisterListener(events.NewRound{}, CheckNewDay)
 	events.RegisterListener(events.NewRound{}, SpawnLootGoblin)
+	events.RegisterListener(events.NewRound{}, ScheduleBackups)
 	events.RegisterListener(events.NewRound{}, UserRoundTick)
 	events.RegisterListener(events.NewRound{}, MobRoundTick)
 	events.RegisterListener(events.NewRound{}, HandleRespawns)
@@ -79,6 +80,15 @@ func RegisterListeners() {
 
 	events.RegisterListener(events.RebuildMap{}, HandleMapRebuild)
 
+	// Backups
+	events.RegisterListener(events.Backup{}, TakeSnapshot)
+
+	// Background LLM replies
+	events.RegisterListener(events.LLMResponse{}, SaveLLMTokenUsage)
+	events.RegisterListener(events.LLMResponseChunk{}, ConversationSpeak)
+	events.RegisterListener(events.LLMResponse{}, ConversationReply)
+	events.RegisterListener(events.LLMResponse{}, RememberConversation)
+
 	// Log tee to users
 	events.RegisterListener(events.Log{}, FollowLogs)
 
@@ -5,6 +5,7 @@ import (
 
 	"fmt"
 
+	"github.com/GoMudEngine/GoMud/internal/bans"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/language"
@@ -23,8 +24,63 @@ func FinalizeLoginOrCreate(results map[string]string, sharedState map[string]any
 	if username != `new` {
 		userExists := users.Exists(username)
 
+		remoteIP := connectionIP(clientInput.ConnectionId)
+		secure, local := false, false
+		if connDetails := connections.Get(clientInput.ConnectionId); connDetails != nil {
+			secure = connDetails.IsSecure()
+			local = connDetails.IsLocal()
+		}
+
+		// Don't even check the password while locked out
+		if err := bans.CheckLogin(username, remoteIP); err != nil {
+			mudlog.Warn("Login refused", "username", username, "remoteAddr", remoteIP, "error", err)
+			connections.SendTo([]byte(err.Error()), clientInput.ConnectionId)
+			connections.SendTo(term.CRLF, clientInput.ConnectionId)
+			connections.Remove(clientInput.ConnectionId)
+			return false // Indicate failure, connection removed
+		}
+
 		if userExists {
 
+			// Existing User Login Logic (No changes needed)
+			tmpUser, err := users.LoadUser(username)
+			if err != nil {
+				mudlog.Error("Failed to load existing user during login", "username", username, "error", err)
+				connections.SendTo([]byte(language.T("Error.LoginFailedGeneric")), clientInput.ConnectionId)
+				connections.SendTo(term.CRLF, clientInput.ConnectionId)
+				connections.Remove(clientInput.ConnectionId)
+				return false // Indicate failure, connection removed
+			}
+
+			if !tmpUser.LoginPasswordMatches(password) {
+				bans.LoginFailed(username, remoteIP)
+				connections.SendTo([]byte(`Nope. Bye!`), clientInput.ConnectionId)
+				connections.SendTo(term.CRLF, clientInput.ConnectionId)
+				connections.Remove(clientInput.ConnectionId)
+				return false // Indicate failure, connection removed
+			}
+
+			bans.LoginSucceeded(username, remoteIP)
+
+			// Only tell them they're banned once they've proven who they are
+			if err := bans.CheckUsername(username); err != nil {
+				mudlog.Warn("Login refused", "username", username, "remoteAddr", remoteIP, "error", err)
+				connections.SendTo([]byte(err.Error()), clientInput.ConnectionId)
+				connections.SendTo(term.CRLF, clientInput.ConnectionId)
+				connections.Remove(clientInput.ConnectionId)
+				return false // Indicate failure, connection removed
+			}
+
+			// Admins can be required to use an encrypted connection
+			if tmpUser.Role == users.RoleAdmin && bool(configs.GetSecurityConfig().AdminRequireSecure) && !secure && !local {
+				mudlog.Warn("Login refused", "username", username, "remoteAddr", remoteIP, "error", "admin login over an insecure connection")
+				connections.SendTo([]byte(`Admins must log in over a secure (TLS) connection.`), clientInput.ConnectionId)
+				connections.SendTo(term.CRLF, clientInput.ConnectionId)
+				connections.Remove(clientInput.ConnectionId)
+				return false // Indicate failure, connection removed
+			}
+
+			// Only once they've passed every check, so a refused login can't knock the real player off
 			if results["kickuser"] == "y" {
 
 				connDetails := connections.Get(clientInput.ConnectionId)
@@ -37,30 +93,13 @@ func FinalizeLoginOrCreate(results map[string]string, sharedState map[string]any
 
 				// Send a goodbye message to the currently connected user
 				tplTxt, _ := templates.Process("goodbye", nil)
-				connections.SendTo([]byte(templates.AnsiParse(tplTxt)), existingConnectionId)
+				connections.SendTo([]byte(templates.AnsiParse(tplTxt, existingConnectionId)), existingConnectionId)
 
 				users.SetZombieUser(userid)
 				connections.Kick(existingConnectionId, fmt.Sprintf(`Duplicate login (ip: %s)`, connDetails.RemoteAddr()))
 
 			}
 
-			// Existing User Login Logic (No changes needed)
-			tmpUser, err := users.LoadUser(username)
-			if err != nil {
-				mudlog.Error("Failed to load existing user during login", "username", username, "error", err)
-				connections.SendTo([]byte(language.T("Error.LoginFailedGeneric")), clientInput.ConnectionId)
-				connections.SendTo(term.CRLF, clientInput.ConnectionId)
-				connections.Remove(clientInput.ConnectionId)
-				return false // Indicate failure, connection removed
-			}
-
-			if !tmpUser.PasswordMatches(password) {
-				connections.SendTo([]byte(`Nope. Bye!`), clientInput.ConnectionId)
-				connections.SendTo(term.CRLF, clientInput.ConnectionId)
-				connections.Remove(clientInput.ConnectionId)
-				return false // Indicate failure, connection removed
-			}
-
 			loggedInUser, msg, err := users.LoginUser(tmpUser, clientInput.ConnectionId)
 			if err != nil {
 				connections.SendTo([]byte(msg), clientInput.ConnectionId)
@@ -75,10 +114,11 @@ func FinalizeLoginOrCreate(results map[string]string, sharedState map[string]any
 				connections.SendTo([]byte(msg), clientInput.ConnectionId)
 				connections.SendTo(term.CRLF, clientInput.ConnectionId)
 			}
-			mudlog.Info("User logged in", "username", username, "connectionId", clientInput.ConnectionId)
+			mudlog.Info("User logged in", "username", username, "connectionId", clientInput.ConnectionId, "secure", secure)
 			return true // Indicate success, handler can be removed
 
 		} else {
+			bans.LoginFailed(username, remoteIP)
 			connections.SendTo([]byte(`Invalid login.`), clientInput.ConnectionId)
 			connections.SendTo(term.CRLF, clientInput.ConnectionId)
 			connections.Remove(clientInput.ConnectionId)
@@ -149,6 +189,28 @@ func FinalizeLoginOrCreate(results map[string]string, sharedState map[string]any
 	}
 }
 
+// connectionIP is the address logins from a connection are tracked (and locked out) by
+func connectionIP(connectionId connections.ConnectionId) string {
+	if connDetails := connections.Get(connectionId); connDetails != nil {
+		return bans.AddressIP(connDetails.RemoteAddr())
+	}
+	return ``
+}
+
+// askToKick is true if the user logging in is already online and gave the right password.
+// Whether they're asked shows whether the password was right, so nobody locked out is asked.
+func askToKick(results map[string]string, connectionId connections.ConnectionId) bool {
+	if results["username"] == `new` {
+		return false
+	}
+
+	userid := users.FindUserId(results["username"])
+
+	user := users.GetByUserId(userid)
+
+	return user != nil && bans.CheckLogin(results["username"], connectionIP(connectionId)) == nil && user.PasswordMatches(results["password"])
+}
+
 func GetLoginPromptHandler() connections.InputHandler {
 
 	// Define the steps for the login process
@@ -168,7 +230,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			MaskInput:      true,
 			MaskTemplate:   "login/password.mask", // Optional: specify if different from "*"
 			Validator:      ValidatePassword,
-			Condition:      func(results map[string]string) bool { return results["username"] != `new` }, // Only run if username was not "new"
+			Condition:      func(results map[string]string, _ connections.ConnectionId) bool { return results["username"] != `new` }, // Only run if username was not "new"
 		},
 		{
 			ID:             "kickuser",
@@ -183,17 +245,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			},
 			MaskInput: false,
 			Validator: ValidateYesNo,
-			Condition: func(results map[string]string) bool {
-				if results["username"] == `new` {
-					return false
-				}
-
-				userid := users.FindUserId(results["username"])
-
-				user := users.GetByUserId(userid)
-
-				return user != nil && user.PasswordMatches(results["password"])
-			}, // Only run if username was not "new", password matches, and user is currently online.
+			Condition: askToKick, // Only run if username was not "new", password matches, and user is currently online.
 		},
 		//////////////////////////////////////////////////
 		// End If NOT a new user signup (Just a login)
@@ -206,7 +258,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			PromptTemplate: "login/username-new.prompt",
 			MaskInput:      false,
 			Validator:      ValidateUsername,
-			Condition:      func(results map[string]string) bool { return results["username"] == `new` }, // Only run if username was "new"
+			Condition:      func(results map[string]string, _ connections.ConnectionId) bool { return results["username"] == `new` }, // Only run if username was "new"
 		},
 		{
 			ID:             "password-new",
@@ -214,7 +266,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			MaskInput:      true,
 			MaskTemplate:   "login/password.mask", // Optional: specify if different from "*"
 			Validator:      ValidatePassword,
-			Condition:      func(results map[string]string) bool { return results["username"] == `new` }, // Only run if username was "new"
+			Condition:      func(results map[string]string, _ connections.ConnectionId) bool { return results["username"] == `new` }, // Only run if username was "new"
 		},
 		{
 			ID:             "password-new-verify",
@@ -222,7 +274,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			MaskInput:      true,
 			MaskTemplate:   "login/password.mask", // Optional: specify if different from "*"
 			Validator:      ValidatePassword2,
-			Condition:      func(results map[string]string) bool { return results["username"] == `new` }, // Only run if username was "new"
+			Condition:      func(results map[string]string, _ connections.ConnectionId) bool { return results["username"] == `new` }, // Only run if username was "new"
 		},
 		{
 			ID:             "email-new",
@@ -235,7 +287,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			},
 			MaskInput: false,
 			Validator: ValidateEmail,
-			Condition: func(results map[string]string) bool {
+			Condition: func(results map[string]string, _ connections.ConnectionId) bool {
 				return results["username"] == `new` && configs.GetValidationConfig().EmailOnJoin != `none` // Only run if username was "new" and email is enabled
 			},
 		},
@@ -252,7 +304,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			},
 			MaskInput: false,
 			Validator: ValidateYesNo,
-			Condition: func(results map[string]string) bool { return results["username"] == `new` }, // Only run if username was "new"
+			Condition: func(results map[string]string, _ connections.ConnectionId) bool { return results["username"] == `new` }, // Only run if username was "new"
 		},
 		{
 			ID:             "confirm_create",
@@ -269,7 +321,7 @@ func GetLoginPromptHandler() connections.InputHandler {
 			},
 			MaskInput: false,
 			Validator: ValidateYesNo,
-			Condition: func(results map[string]string) bool { return results["username"] == `new` }, // Only run if username was "new"
+			Condition: func(results map[string]string, _ connections.ConnectionId) bool { return results["username"] == `new` }, // Only run if username was "new"
 		},
 		//////////////////////////////////////////////////
 		// End If a new user signup
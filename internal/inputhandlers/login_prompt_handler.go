@@ -45,7 +45,7 @@ type ValidationFunc func(input string, results map[string]string) (string, error
 
 // ConditionFunc defines a function type to determine if a step should be executed.
 // It takes the accumulated results so far.
-type ConditionFunc func(results map[string]string) bool
+type ConditionFunc func(results map[string]string, connectionId connections.ConnectionId) bool
 
 // DataFunc generates dynamic data for a prompt step based on prior results.
 type DataFunc func(results map[string]string) map[string]any
@@ -63,7 +63,7 @@ type PromptStep struct {
 }
 
 // AlwaysRun is a default ConditionFunc that always returns true.
-func AlwaysRun(_ map[string]string) bool {
+func AlwaysRun(_ map[string]string, _ connections.ConnectionId) bool {
 	return true
 }
 
@@ -220,7 +220,7 @@ func CreatePromptHandler(steps []*PromptStep, onComplete CompletionFunc) connect
 							mudlog.Error("Mask template error", "template", currentStep.MaskTemplate, "error", err)
 							state.maskTemplate = "*" // Fallback mask
 						} else {
-							state.maskTemplate = templates.AnsiParse(maskStr)
+							state.maskTemplate = templates.AnsiParse(maskStr, clientInput.ConnectionId)
 						}
 
 					} else if state.maskTemplate == "" {
@@ -337,9 +337,14 @@ func sendPrompt(step *PromptStep, clientInput *connections.ClientInput, results
 		promptTxt = fmt.Sprintf("Error generating prompt '%s'", step.ID) // Fallback
 	}
 
-	parsedPrompt := templates.AnsiParse(promptTxt)
+	parsedPrompt := templates.AnsiParse(promptTxt, clientInput.ConnectionId)
 	connections.SendTo([]byte(parsedPrompt), clientInput.ConnectionId)
 
+	// Keep masked replies (passwords) out of session recordings
+	if cd := connections.Get(clientInput.ConnectionId); cd != nil {
+		cd.SetInputMasked(step.MaskInput)
+	}
+
 	// Handle websocket masking command
 	if connections.IsWebsocket(clientInput.ConnectionId) {
 		maskCmd := "TEXTMASK:false"
@@ -370,7 +375,7 @@ func advanceAndSendPrompt(state *PromptHandlerState, clientInput *connections.Cl
 		}
 
 		// Check condition against current results
-		if condition(state.Results) {
+		if condition(state.Results, clientInput.ConnectionId) {
 			// Condition met, send this prompt
 			sendPrompt(step, clientInput, state.Results)
 			return false // Not complete, waiting for input for this step
@@ -383,5 +388,9 @@ func advanceAndSendPrompt(state *PromptHandlerState, clientInput *connections.Cl
 	}
 
 	// No more steps left
+	if cd := connections.Get(clientInput.ConnectionId); cd != nil {
+		cd.SetInputMasked(false)
+	}
+
 	return true // Sequence is complete
 }
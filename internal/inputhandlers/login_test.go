@@ -0,0 +1,70 @@
+package inputhandlers
+
+import (
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/bans"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+func TestAskToKick_LockedOutIP(t *testing.T) {
+
+	testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.Storage":          storage.BackendSQLite,
+		"Security.PasswordHash":      configs.PasswordHashBcrypt,
+		"Security.BcryptCost":        bcrypt.MinCost,
+		"Security.MaxLoginFailures":  3,
+		"Security.LockoutSeconds":    30,
+		"Validation.PasswordSizeMin": 4,
+		"Validation.PasswordSizeMax": 16,
+	})
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	// The player already online
+	connDetails := connections.Add(&connections.VirtualConn{}, nil)
+
+	user := users.NewUserRecord(9001, connDetails.ConnectionId())
+	user.Username = `kicktester`
+	user.Character.Name = user.Username
+	require.NoError(t, user.SetPassword(`password123`))
+	require.NoError(t, users.SaveUser(*user))
+
+	_, _, err := users.LoginUser(user, connDetails.ConnectionId())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		users.LogOutUserByConnectionId(connDetails.ConnectionId())
+		connections.Remove(connDetails.ConnectionId())
+	})
+
+	// Someone logging in as them from another connection
+	otherConn := connections.Add(&connections.VirtualConn{}, nil)
+	t.Cleanup(func() { connections.Remove(otherConn.ConnectionId()) })
+
+	results := map[string]string{`username`: `kicktester`, `password`: `password123`}
+
+	assert.True(t, askToKick(results, otherConn.ConnectionId()))
+	assert.False(t, askToKick(map[string]string{`username`: `kicktester`, `password`: `wrong`}, otherConn.ConnectionId()))
+
+	// Lock out the address with failures against some other account
+	ip := connectionIP(otherConn.ConnectionId())
+	for i := 0; i < 3; i++ {
+		bans.LoginFailed(`someoneelse`, ip)
+	}
+	t.Cleanup(func() { bans.LoginSucceeded(`someoneelse`, ip) })
+
+	require.NoError(t, bans.CheckLogin(`kicktester`, ``))
+	assert.False(t, askToKick(results, otherConn.ConnectionId()))
+}
@@ -0,0 +1,34 @@
+package inputhandlers
+
+import (
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/mssp"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/term"
+)
+
+// MSSPRequestHandler answers crawlers that send MSSP-REQUEST as plain text instead of using telnet negotiation.
+// They only want the status, so the connection is closed afterwards.
+// It must come after CleanserInputHandler and before the login prompt.
+func MSSPRequestHandler(clientInput *connections.ClientInput, sharedState map[string]any) (nextHandler bool) {
+
+	if !clientInput.EnterPressed || !bool(configs.GetNetworkConfig().MSSP) {
+		return true
+	}
+
+	if strings.TrimSpace(string(clientInput.Buffer)) != term.MsspRequest {
+		return true
+	}
+
+	mudlog.Info("MSSP", "connectionId", clientInput.ConnectionId, "type", "plain text")
+
+	connections.SendTo(term.MsspPlainText(mssp.Status()), clientInput.ConnectionId)
+	connections.Remove(clientInput.ConnectionId)
+
+	clientInput.Buffer = clientInput.Buffer[:0]
+
+	return false
+}
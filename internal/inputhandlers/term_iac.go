@@ -3,6 +3,7 @@ package inputhandlers
 import (
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/mssp"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/term"
 )
@@ -95,6 +96,103 @@ func TelnetIACHandler(clientInput *connections.ClientInput, sharedState map[stri
 			continue
 		}
 
+		if term.IsMCCPCommand(iacCmd) {
+
+			cd := connections.Get(clientInput.ConnectionId)
+			if cd == nil {
+				continue
+			}
+
+			if ok, _ := term.Matches(iacCmd, term.Mccp2Accept); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-MCCP2 Accept)")
+
+				if err := cd.StartCompression(); err != nil {
+					mudlog.Warn("MCCP2", "connectionId", clientInput.ConnectionId, "error", err)
+				}
+
+				continue
+			}
+
+			if ok, _ := term.Matches(iacCmd, term.Mccp2Refuse); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-MCCP2 Refuse)")
+
+				// Clients may also ask to stop part way through
+				if err := cd.StopCompression(); err != nil {
+					mudlog.Warn("MCCP2", "connectionId", clientInput.ConnectionId, "error", err)
+				}
+
+				continue
+			}
+
+			if ok, _ := term.Matches(iacCmd, term.Mccp3Accept); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-MCCP3 Accept)")
+				cd.AllowInputCompression(true)
+				continue
+			}
+
+			if ok, _ := term.Matches(iacCmd, term.Mccp3Refuse); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-MCCP3 Refuse)")
+				cd.AllowInputCompression(false)
+				continue
+			}
+
+			continue
+		}
+
+		if term.IsMSSPCommand(iacCmd) {
+
+			if ok, _ := term.Matches(iacCmd, term.MsspAccept); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-MSSP Accept)")
+
+				if configs.GetNetworkConfig().MSSP {
+					connections.SendTo(
+						term.MsspStatus.BytesWithPayload(term.MsspPayload(mssp.Status())),
+						clientInput.ConnectionId,
+					)
+				}
+
+				continue
+			}
+
+			if ok, _ := term.Matches(iacCmd, term.MsspRefuse); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-MSSP Refuse)")
+				continue
+			}
+
+			continue
+		}
+
+		if term.IsTTYPECommand(iacCmd) {
+
+			if ok, _ := term.Matches(iacCmd, term.TtypeAccept); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-TTYPE Accept)")
+				connections.SendTo(term.TtypeSend.BytesWithPayload(nil), clientInput.ConnectionId)
+				continue
+			}
+
+			if ok, _ := term.Matches(iacCmd, term.TtypeRefuse); ok {
+				mudlog.Debug("Received", "type", "IAC (Client-TTYPE Refuse)")
+				continue
+			}
+
+			if ok, payload := term.Matches(iacCmd, term.TtypeIs); ok {
+
+				cs := connections.GetClientSettings(clientInput.ConnectionId)
+				askAgain := cs.Terminal.AddTTypeReply(string(payload))
+				connections.OverwriteClientSettings(clientInput.ConnectionId, cs)
+
+				mudlog.Debug("Received", "type", "IAC (Client-TTYPE)", "value", string(payload), "colorDepth", cs.Terminal.ColorDepth.String())
+
+				if askAgain {
+					connections.SendTo(term.TtypeSend.BytesWithPayload(nil), clientInput.ConnectionId)
+				}
+
+				continue
+			}
+
+			continue
+		}
+
 		if ok, payload := term.Matches(iacCmd, term.TelnetAcceptedChangeCharset); ok {
 			mudlog.Debug("Received", "type", "IAC (TelnetAcceptedChangeCharset)", "data", term.BytesString(payload))
 			continue
@@ -54,6 +54,8 @@ func registerListeners() {
 	events.RegisterListener(events.LevelUp{}, HandleLevelup)
 	events.RegisterListener(events.PlayerDeath{}, HandleDeath)
 	events.RegisterListener(events.Broadcast{}, HandleBroadcast)
+	events.RegisterListener(events.LLMQuotaThreshold{}, HandleLLMQuotaThreshold)
+	events.RegisterListener(events.BanChange{}, HandleBanChange)
 	events.RegisterListener(`AuctionUpdate`, HandleAuctionUpdate)
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/events"
@@ -113,6 +114,66 @@ func HandleDeath(e events.Event) events.ListenerReturn {
 	return events.Continue
 }
 
+// A ban was added or lifted, or failed logins locked someone out
+func HandleBanChange(e events.Event) events.ListenerReturn {
+	evt, typeOk := e.(events.BanChange)
+	if !typeOk {
+		return events.Cancel
+	}
+
+	var message string
+
+	switch evt.Action {
+	case `ban`:
+		message = fmt.Sprintf(`:hammer: %s **%s** was banned by %s`, evt.Kind, evt.Value, evt.By)
+		if evt.Reason != `` {
+			message += fmt.Sprintf(` (%s)`, evt.Reason)
+		}
+		if evt.Until.IsZero() {
+			message += `, forever`
+		} else {
+			message += fmt.Sprintf(`, until %s`, evt.Until.Format(time.RFC1123))
+		}
+	case `unban`:
+		message = fmt.Sprintf(`:hammer: %s **%s** was unbanned by %s`, evt.Kind, evt.Value, evt.By)
+	case `lockout`:
+		message = fmt.Sprintf(`:hammer: %s **%s** is locked out after %d failed logins, until %s`, evt.Kind, evt.Value, evt.Failures, evt.Until.Format(time.RFC1123))
+	default:
+		return events.Continue
+	}
+
+	SendRichMessage(message, Orange)
+
+	return events.Continue
+}
+
+// LLM usage crossed a warning threshold or ran into a quota
+func HandleLLMQuotaThreshold(e events.Event) events.ListenerReturn {
+	evt, typeOk := e.(events.LLMQuotaThreshold)
+	if !typeOk {
+		return events.Cancel
+	}
+
+	who := `The server`
+	if evt.UserId > 0 {
+		who = fmt.Sprintf(`User #%d`, evt.UserId)
+		if user := users.GetByUserId(evt.UserId); user != nil {
+			who = fmt.Sprintf(`**%s** (%s)`, user.Character.Name, user.Username)
+		}
+	}
+
+	if evt.Percent >= 100 {
+		message := fmt.Sprintf(`:no_entry: %s has reached the %s LLM %s quota (%d of %d)`, who, evt.Period, evt.Metric, evt.Used, evt.Limit)
+		SendRichMessage(message, Red)
+		return events.Continue
+	}
+
+	message := fmt.Sprintf(`:warning: %s has used %d%% of the %s LLM %s quota (%d of %d)`, who, evt.Percent, evt.Period, evt.Metric, evt.Used, evt.Limit)
+	SendRichMessage(message, Orange)
+
+	return events.Continue
+}
+
 func HandleBroadcast(e events.Event) events.ListenerReturn {
 	evt, typeOk := e.(events.Broadcast)
 	if !typeOk {
@@ -0,0 +1,65 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useWebhook points the client at a fake discord, returning whatever gets posted to it
+func useWebhook(t *testing.T) <-chan webHookPayload {
+	t.Helper()
+
+	posted := make(chan webHookPayload, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := webHookPayload{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		posted <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	WebhookUrl = srv.URL
+	initialized = true
+
+	t.Cleanup(func() {
+		srv.Close()
+		WebhookUrl = ``
+		initialized = false
+	})
+
+	return posted
+}
+
+func waitForPost(t *testing.T, posted <-chan webHookPayload) string {
+	t.Helper()
+
+	select {
+	case payload := <-posted:
+		require.Len(t, payload.Embeds, 1)
+		return payload.Embeds[0].Description
+	case <-time.After(3 * time.Second):
+		t.Fatal("nothing was posted to discord")
+	}
+	return ``
+}
+
+func TestHandleBanChange(t *testing.T) {
+
+	posted := useWebhook(t)
+	until := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	HandleBanChange(events.BanChange{Action: `ban`, Kind: `username`, Value: `bob`, Reason: `griefing`, By: `admin`})
+	assert.Equal(t, ":hammer: username **bob** was banned by admin (griefing), forever", waitForPost(t, posted))
+
+	HandleBanChange(events.BanChange{Action: `unban`, Kind: `ip`, Value: `10.0.0.1`, By: `admin`})
+	assert.Equal(t, ":hammer: ip **10.0.0.1** was unbanned by admin", waitForPost(t, posted))
+
+	HandleBanChange(events.BanChange{Action: `lockout`, Kind: `username`, Value: `bob`, Failures: 3, Until: until})
+	assert.Equal(t, ":hammer: username **bob** is locked out after 3 failed logins, until "+until.Format(time.RFC1123), waitForPost(t, posted))
+}
@@ -2,7 +2,10 @@ package items
 
 import (
 	"errors"
+	"fmt"
+	"os"
 
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/fileloader"
 )
@@ -44,6 +47,71 @@ func CreateNewItemFile(newItemInfo ItemSpec) (int, error) {
 	return newItemInfo.Id(), nil
 }
 
+// SaveItemFile overwrites an existing item spec.
+// If the new name or type moves the file, the old file and its script are moved along with it.
+func SaveItemFile(itemInfo ItemSpec) error {
+
+	oldItemInfo := GetItemSpec(itemInfo.ItemId)
+	if oldItemInfo == nil {
+		return fmt.Errorf(`item %d does not exist`, itemInfo.ItemId)
+	}
+
+	if err := itemInfo.Validate(); err != nil {
+		return err
+	}
+
+	// Same as a new item, the damage info can be derrived from the diceroll.
+	saveInfo := itemInfo
+	saveInfo.Damage.Attacks = 0
+	saveInfo.Damage.DiceCount = 0
+	saveInfo.Damage.SideCount = 0
+
+	saveModes := []fileloader.SaveOption{}
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	basePath := configs.GetFilePathsConfig().DataFiles.String() + `/items`
+
+	if err := fileloader.SaveFlatFile[*ItemSpec](basePath, &saveInfo, saveModes...); err != nil {
+		return err
+	}
+
+	if oldItemInfo.Filepath() != itemInfo.Filepath() {
+		if err := fileloader.MoveFile(oldItemInfo.GetScriptPath(), itemInfo.GetScriptPath()); err != nil {
+			return err
+		}
+		if err := fileloader.DeleteFlatFile[*ItemSpec](basePath, oldItemInfo); err != nil {
+			return err
+		}
+	}
+
+	items[itemInfo.Id()] = &itemInfo
+
+	return nil
+}
+
+// DeleteItemFile removes an item spec and its script.
+// Items already in the world that use this spec are left alone.
+func DeleteItemFile(itemId int) error {
+
+	itemInfo := GetItemSpec(itemId)
+	if itemInfo == nil {
+		return fmt.Errorf(`item %d does not exist`, itemId)
+	}
+
+	if err := fileloader.DeleteFlatFile[*ItemSpec](configs.GetFilePathsConfig().DataFiles.String()+`/items`, itemInfo); err != nil {
+		return err
+	}
+
+	changelog.Touch(itemInfo.GetScriptPath())
+	os.Remove(itemInfo.GetScriptPath())
+
+	delete(items, itemId)
+
+	return nil
+}
+
 func getNextItemId(t ItemType) int {
 
 	rangeMin := 0
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/keywords"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/templates"
 	"github.com/GoMudEngine/GoMud/internal/users"
@@ -17,9 +19,7 @@ import (
 type HelpLLMConfig struct {
 	Enabled       bool   `yaml:"enabled"`
 	SystemPrompt  string `yaml:"systemprompt"`
-	EndpointURL   string `yaml:"endpoint_url"`
-	APIKey        string `yaml:"api_key"`
-	Model         string `yaml:"model"`
+	Profile       string `yaml:"profile"`
 	TemplatePath  string `yaml:"template_path"`
 	SaveResponses bool   `yaml:"save_responses"`
 }
@@ -34,9 +34,7 @@ func InitHelpLLM() {
 	helpLLMConfig = HelpLLMConfig{
 		Enabled:       bool(intConfig.LLMHelp.Enabled),
 		SystemPrompt:  string(intConfig.LLMHelp.SystemPrompt),
-		EndpointURL:   string(intConfig.LLMHelp.EndpointURL),
-		APIKey:        string(intConfig.LLMHelp.APIKey),
-		Model:         string(intConfig.LLMHelp.Model),
+		Profile:       string(intConfig.LLMHelp.Profile),
 		TemplatePath:  string(intConfig.LLMHelp.TemplatePath),
 		SaveResponses: bool(intConfig.LLMHelp.SaveResponses),
 	}
@@ -49,9 +47,82 @@ func InitHelpLLM() {
 	if helpLLMConfig.TemplatePath == "" {
 		helpLLMConfig.TemplatePath = "templates/help"
 	}
+}
+
+// GetHelpResponse generates a response to a help query using the LLM
+// This blocks until the request completes.
+func GetHelpResponse(query string, availableCommands []string, userId ...int) (string, error) {
+	if !helpLLMConfig.Enabled {
+		return "", fmt.Errorf("LLM help system is not enabled")
+	}
+
+	// Create a formatted list of commands grouped by type
+	var commandsByType = make(map[string][]string)
+
+	for _, cmd := range keywords.GetAllHelpTopicInfo() {
+		cmdType := "command"
+		if cmd.Type == "skill" {
+			cmdType = "skill"
+		} else if cmd.AdminOnly {
+			cmdType = "admin"
+		}
+
+		commandsByType[cmdType] = append(commandsByType[cmdType], cmd.Command)
+	}
+
+	// Build a help context with information about available commands
+	var contextBuilder strings.Builder
+	contextBuilder.WriteString("VERIFIED AVAILABLE COMMANDS LIST:\n")
+	contextBuilder.WriteString("===================================\n")
+	contextBuilder.WriteString("ONLY suggest commands from this list. DO NOT suggest any commands that aren't listed here.\n\n")
+
+	for cmdType, cmds := range commandsByType {
+		contextBuilder.WriteString(fmt.Sprintf("✓ %s commands: %s\n\n",
+			strings.Title(cmdType),
+			strings.Join(cmds, ", ")))
+	}
+
+	contextBuilder.WriteString("===================================\n")
+	contextBuilder.WriteString("Remember: Players can ONLY use the commands listed above. Never suggest a command that isn't in this list.\n")
+
+	// Create messages for the LLM API
+	messages := []Message{
+		{
+			Role:    RoleSystem,
+			Content: helpLLMConfig.SystemPrompt,
+		},
+		{
+			Role: RoleUser,
+			Content: fmt.Sprintf("I need help with: %s\n\n%s",
+				query,
+				contextBuilder.String()),
+		},
+	}
+
+	mudlog.Info("GetHelpResponse", "messages", messages)
+
+	resp, err := Complete(context.Background(), helpLLMConfig.Profile, Request{Messages: messages}, userId...)
+	if err != nil {
+		return "", err
+	}
+
+	// Help requests are answered from the main loop, so the player record can be updated now
+	if len(userId) > 0 && userId[0] > 0 {
+		SaveTokenUsageToPlayer(userId[0])
+		mudlog.Info("help-llm", "tokens_recorded",
+			fmt.Sprintf("Recorded token usage for user %d: ~%d input, ~%d output",
+				userId[0], resp.InputTokens, resp.OutputTokens))
+	}
+
+	// Save the response as a template file if configured to do so
+	if helpLLMConfig.SaveResponses {
+		err = saveHelpResponse(query, resp.Text)
+		if err != nil {
+			mudlog.Warn("llm-help", "error", "Failed to save response", "query", query, "err", err)
+		}
+	}
 
-	// Also initialize the shared LLM config
-	globalConfig.SystemPrompt = helpLLMConfig.SystemPrompt
+	return resp.Text, nil
 }
 
 // saveHelpResponse saves the LLM's response as a template file
@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+const (
+	// DefaultSystemPrompt is used for requests that don't provide their own
+	DefaultSystemPrompt = "You are a helpful AI assistant in a fantasy MUD game."
+)
+
+var (
+	ErrDisabled       = errors.New("LLM integration is disabled")
+	ErrPlayerDisabled = errors.New("LLM features are disabled for this player")
+
+	initialized bool
+
+	// Requests are bounded by their context, not the client
+	client = &http.Client{}
+)
+
+// Init starts the LLM service
+func Init() {
+	if initialized {
+		return
+	}
+
+	config := configs.GetIntegrationsConfig().LLM
+	StartWorkers(int(config.Workers), int(config.QueueSize))
+
+	InitHelpLLM()
+
+	initialized = true
+	mudlog.Info("LLM", "info", "integration initialized", "providers", GetProviderNames())
+}
+
+// Shutdown stops the request workers, abandoning anything still pending, and saves usage
+func Shutdown() {
+	if !initialized {
+		return
+	}
+
+	StopWorkers()
+	SaveQuotaUsage()
+	initialized = false
+}
+
+// Complete sends a request to the provider behind a named profile, applying the
+// shared retry/backoff policy and recording token usage against userId (if provided).
+// This blocks until the request completes. Code running in the main loop should
+// use QueueRequest() instead. When given a userId it must still be called from the
+// main loop, since the user's role is looked up to apply their quota.
+func Complete(ctx context.Context, profileName string, req Request, userId ...int) (Response, error) {
+	return complete(ctx, profileName, req, nil, lookupQuotaUser(userId...))
+}
+
+// CompleteStream is the same as Complete, but onChunk is handed each piece of the
+// response as soon as it arrives. Providers that can't stream deliver the whole
+// response as a single chunk.
+// Once a chunk has been delivered the request is no longer retried, since
+// whoever received it can't take it back.
+func CompleteStream(ctx context.Context, profileName string, req Request, onChunk func(text string), userId ...int) (Response, error) {
+	return complete(ctx, profileName, req, onChunk, lookupQuotaUser(userId...))
+}
+
+func complete(ctx context.Context, profileName string, req Request, onChunk func(text string), qu quotaUser) (Response, error) {
+
+	if !bool(configs.GetIntegrationsConfig().LLM.Enabled) {
+		return Response{}, ErrDisabled
+	}
+
+	if qu.UserId > 0 && IsLLMDisabledForPlayer(qu.UserId) {
+		return Response{}, ErrPlayerDisabled
+	}
+
+	if err := CheckQuota(qu.UserId, qu.Role); err != nil {
+		return Response{}, err
+	}
+
+	profile, err := GetProfile(profileName)
+	if err != nil {
+		return Response{}, err
+	}
+
+	provider, ok := GetProvider(profile.Provider)
+	if !ok {
+		return Response{}, fmt.Errorf("%w: %s (profile %s)", ErrUnknownProvider, profile.Provider, profile.Name)
+	}
+
+	if InBackoff(profile.Name) {
+		mudlog.Warn("LLM", "info", "profile is in backoff", "profile", profile.Name)
+		return Response{}, ErrBackoff
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(configs.GetIntegrationsConfig().LLM.RequestTimeout)*time.Second)
+		defer cancel()
+	}
+
+	mudlog.Debug("LLM", "request", "sending request", "profile", profile.Name, "provider", profile.Provider, "model", profile.Model)
+
+	policy := GetRetryPolicy()
+
+	streamer, canStream := provider.(StreamProvider)
+	delivered := false
+
+	var resp Response
+	start := time.Now()
+	err = policy.Do(ctx, func() error {
+		var reqErr error
+
+		if onChunk != nil && canStream {
+			resp, reqErr = streamer.Stream(ctx, profile, req, func(text string) {
+				delivered = true
+				onChunk(text)
+			})
+		} else {
+			resp, reqErr = provider.Complete(ctx, profile, req)
+		}
+
+		if reqErr != nil && !IsCancelled(reqErr) {
+			mudlog.Warn("LLM", "profile", profile.Name, "error", reqErr)
+		}
+
+		if reqErr != nil && delivered {
+			return noRetry(reqErr)
+		}
+		return reqErr
+	})
+
+	requestSecondsMetric.ObserveSince(start, profile.Name, profile.Provider, requestResult(err))
+
+	if err != nil {
+		// A cancelled or timed out request is not the fault of the LLM service
+		if !IsCancelled(err) {
+			mudlog.Error("LLM", "error", "request failed, backing off", "profile", profile.Name, "backoff", policy.Backoff, "error", err)
+			startBackoff(profile.Name, policy.Backoff)
+		}
+		return Response{}, err
+	}
+
+	// Not every provider reports usage, so fall back to an estimate
+	if resp.InputTokens == 0 {
+		for _, m := range req.Messages {
+			resp.InputTokens += EstimateTokenCount(m.Content)
+		}
+	}
+	if resp.OutputTokens == 0 {
+		resp.OutputTokens = EstimateTokenCount(resp.Text)
+	}
+	if resp.Model == `` {
+		resp.Model = profile.Model
+	}
+
+	if qu.UserId > 0 {
+		RecordTokenUsage(qu.UserId, resp.Model, resp.InputTokens, resp.OutputTokens)
+	}
+	recordQuotaUsage(qu, resp.InputTokens+resp.OutputTokens)
+
+	if onChunk != nil && !canStream {
+		onChunk(resp.Text)
+	}
+
+	return resp, nil
+}
+
+// PromptRequest builds a request from a prompt, preceded by some context lines
+func PromptRequest(prompt string, context []string) Request {
+
+	fullPrompt := ``
+	if len(context) > 0 {
+		fullPrompt = strings.Join(context, "\n") + "\n\n"
+	}
+	fullPrompt += prompt
+
+	return Request{
+		Messages: []Message{
+			{Role: RoleSystem, Content: DefaultSystemPrompt},
+			{Role: RoleUser, Content: fullPrompt},
+		},
+	}
+}
+
+func requestTemperature(profile Profile, req Request) float64 {
+	if req.Temperature > 0 {
+		return req.Temperature
+	}
+	return profile.Temperature
+}
+
+func requestMaxTokens(profile Profile, req Request) int {
+	if req.MaxTokens > 0 {
+		return req.MaxTokens
+	}
+	return profile.MaxTokens
+}
@@ -0,0 +1,31 @@
+package llm
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+)
+
+var (
+	requestSecondsMetric = metrics.NewHistogram(
+		`gomud_llm_request_duration_seconds`,
+		`Time taken by LLM requests, including any retries.`,
+		metrics.SlowDurationBuckets,
+		`profile`, `provider`, `result`,
+	)
+)
+
+func init() {
+	metrics.NewGaugeFunc(`gomud_llm_pending_jobs`, `LLM jobs waiting in the queue.`, func() float64 {
+		return float64(PendingCount())
+	})
+}
+
+// requestResult labels a finished request as ok, error or cancelled
+func requestResult(err error) string {
+	if err == nil {
+		return `ok`
+	}
+	if IsCancelled(err) {
+		return `cancelled`
+	}
+	return `error`
+}
@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+var (
+	ErrUnknownProfile  = errors.New("unknown LLM profile")
+	ErrUnknownProvider = errors.New("unknown LLM provider")
+
+	providersLock sync.RWMutex
+	providers     = map[string]Provider{}
+)
+
+// Provider sends a completion request to an LLM service.
+// Implementations must be safe to call from multiple goroutines.
+type Provider interface {
+	Complete(ctx context.Context, profile Profile, req Request) (Response, error)
+}
+
+// StreamProvider is implemented by providers that can hand back a response
+// piece by piece as it is generated.
+// onChunk is called from the goroutine making the request, in order.
+type StreamProvider interface {
+	Provider
+	Stream(ctx context.Context, profile Profile, req Request, onChunk func(text string)) (Response, error)
+}
+
+// Message is a single chat message
+type Message struct {
+	Role    string `json:"role" yaml:"role"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// Tool is a function the model may ask to have called
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any // JSON schema describing the arguments
+}
+
+// ToolCall is the model asking for a Tool to be called
+type ToolCall struct {
+	Name      string         `yaml:"name"`
+	Arguments map[string]any `yaml:"arguments,omitempty"`
+}
+
+// Request is a provider agnostic completion request
+type Request struct {
+	Messages    []Message
+	Tools       []Tool  // Optional functions the model may call
+	Temperature float64 // Overrides the profile temperature when greater than zero
+	MaxTokens   int     // Overrides the profile max tokens when greater than zero
+}
+
+// Response is a provider agnostic completion response
+type Response struct {
+	Text         string
+	ToolCalls    []ToolCall
+	Model        string
+	InputTokens  int // Zero if the provider didn't report it
+	OutputTokens int // Zero if the provider didn't report it
+}
+
+// Profile is a named provider/model/endpoint combination from the config
+type Profile struct {
+	Name        string
+	Provider    string
+	Model       string
+	BaseURL     string
+	APIKey      string
+	Temperature float64
+	MaxTokens   int
+}
+
+// StatusError is returned by providers when the service answers with a non 200 status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// apiTool is the OpenAI tool format, which Ollama also uses
+type apiTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description,omitempty"`
+		Parameters  map[string]any `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+func toAPITools(tools []Tool) []apiTool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	result := make([]apiTool, len(tools))
+	for i, t := range tools {
+		result[i].Type = `function`
+		result[i].Function.Name = t.Name
+		result[i].Function.Description = t.Description
+		result[i].Function.Parameters = t.Parameters
+	}
+	return result
+}
+
+// RegisterProvider makes a provider available to profiles by name.
+// Registering a name a second time replaces the previous provider.
+func RegisterProvider(name string, p Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+
+	providers[strings.ToLower(name)] = p
+}
+
+// GetProvider returns the provider registered under name
+func GetProvider(name string) (Provider, bool) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	p, ok := providers[strings.ToLower(name)]
+	return p, ok
+}
+
+// GetProviderNames returns the names of all registered providers, sorted
+func GetProviderNames() []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile looks up a named profile in the config
+func GetProfile(name string) (Profile, error) {
+
+	cfg, ok := configs.GetIntegrationsConfig().LLM.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+
+	cfg.Validate()
+
+	p := Profile{
+		Name:        name,
+		Provider:    strings.ToLower(string(cfg.Provider)),
+		Model:       string(cfg.Model),
+		BaseURL:     string(cfg.BaseURL),
+		APIKey:      string(cfg.APIKey),
+		Temperature: float64(cfg.Temperature),
+		MaxTokens:   int(cfg.MaxTokens),
+	}
+
+	// Guess from the url when the provider was left blank
+	if p.Provider == `` {
+		if strings.Contains(p.BaseURL, `ollama`) || strings.Contains(p.BaseURL, `:11434`) {
+			p.Provider = `ollama`
+		} else {
+			p.Provider = `openai`
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	RegisterProvider(`ollama`, OllamaProvider{})
+}
+
+// OllamaProvider talks to the Ollama /api/chat endpoint
+type OllamaProvider struct{}
+
+type ollamaRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Tools    []apiTool `json:"tools,omitempty"`
+	Stream   bool      `json:"stream"`
+	Options  struct {
+		Temperature float64 `json:"temperature"`
+		NumPredict  int     `json:"num_predict,omitempty"`
+	} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Model   string `json:"model"`
+	Message struct {
+		Role      string `json:"role"`
+		Content   string `json:"content"`
+		ToolCalls []struct {
+			Function struct {
+				Name      string         `json:"name"`
+				Arguments map[string]any `json:"arguments"`
+			} `json:"function"`
+		} `json:"tool_calls"`
+	} `json:"message"`
+	Response        string `json:"response"` // Only set by the older /api/generate endpoint
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error,omitempty"`
+}
+
+func (o OllamaProvider) Complete(ctx context.Context, profile Profile, req Request) (Response, error) {
+	return o.send(ctx, profile, req, false, func(string) {})
+}
+
+// Stream reads a newline delimited JSON response, one object per delta
+func (o OllamaProvider) Stream(ctx context.Context, profile Profile, req Request, onChunk func(text string)) (Response, error) {
+	return o.send(ctx, profile, req, true, onChunk)
+}
+
+func (OllamaProvider) send(ctx context.Context, profile Profile, req Request, stream bool, onChunk func(text string)) (Response, error) {
+
+	ollamaReq := ollamaRequest{
+		Model:    profile.Model,
+		Messages: req.Messages,
+		Tools:    toAPITools(req.Tools),
+		Stream:   stream,
+	}
+	ollamaReq.Options.Temperature = requestTemperature(profile, req)
+	ollamaReq.Options.NumPredict = requestMaxTokens(profile, req)
+
+	body, err := json.Marshal(ollamaReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("error marshaling request for Ollama: %w", err)
+	}
+
+	// Ensure the correct endpoint URL for Ollama
+	endpoint := profile.BaseURL
+	if !strings.HasSuffix(endpoint, `/api/chat`) {
+		endpoint = strings.TrimSuffix(endpoint, `/`) + `/api/chat`
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return Response{}, fmt.Errorf("error creating HTTP request for Ollama: %w", err)
+	}
+	httpReq.Header.Set(`Content-Type`, `application/json`)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("error sending request to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return Response{}, StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	// Even when not asked to stream, Ollama may still send newline delimited
+	// JSON objects, so always piece them together
+	result := Response{Model: profile.Model}
+	var text strings.Builder
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == `` {
+			continue
+		}
+
+		var part ollamaResponse
+		if err := json.Unmarshal([]byte(line), &part); err != nil {
+			return Response{}, fmt.Errorf("error parsing Ollama response: %w", err)
+		}
+
+		if part.Error != `` {
+			return Response{}, fmt.Errorf("Ollama API error: %s", part.Error)
+		}
+
+		if delta := part.Message.Content + part.Response; delta != `` {
+			text.WriteString(delta)
+			onChunk(delta)
+		}
+
+		for _, c := range part.Message.ToolCalls {
+			result.ToolCalls = append(result.ToolCalls, ToolCall{Name: c.Function.Name, Arguments: c.Function.Arguments})
+		}
+
+		if part.Model != `` {
+			result.Model = part.Model
+		}
+		result.InputTokens += part.PromptEvalCount
+		result.OutputTokens += part.EvalCount
+
+		if part.Done {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Response{}, fmt.Errorf("error reading Ollama response: %w", err)
+	}
+
+	if text.Len() == 0 && len(result.ToolCalls) == 0 {
+		return Response{}, fmt.Errorf("could not extract valid response from Ollama API")
+	}
+
+	result.Text = text.String()
+
+	return result, nil
+}
@@ -0,0 +1,268 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	RegisterProvider(`openai`, OpenAIProvider{})
+}
+
+// OpenAIProvider talks to OpenAI or any service exposing an OpenAI compatible
+// /chat/completions endpoint.
+type OpenAIProvider struct{}
+
+type openAIRequest struct {
+	Model         string    `json:"model"`
+	Messages      []Message `json:"messages"`
+	Temperature   float64   `json:"temperature"`
+	MaxTokens     int       `json:"max_tokens,omitempty"`
+	Tools         []apiTool `json:"tools,omitempty"`
+	Stream        bool      `json:"stream,omitempty"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"message"`
+		Delta struct {
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"delta"` // Only set when streaming
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// openAIToolCall carries its arguments as a JSON encoded string.
+// When streaming, the name and arguments arrive in pieces, matched up by Index.
+type openAIToolCall struct {
+	Index    int `json:"index"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func (c openAIToolCall) toToolCall() (ToolCall, error) {
+	call := ToolCall{Name: c.Function.Name}
+	if strings.TrimSpace(c.Function.Arguments) != `` {
+		if err := json.Unmarshal([]byte(c.Function.Arguments), &call.Arguments); err != nil {
+			return call, fmt.Errorf("error parsing arguments to %s: %w", c.Function.Name, err)
+		}
+	}
+	return call, nil
+}
+
+func (o OpenAIProvider) Complete(ctx context.Context, profile Profile, req Request) (Response, error) {
+
+	resp, err := o.send(ctx, profile, req, false)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("error reading response: %w", err)
+	}
+
+	var apiResp openAIResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return Response{}, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	if apiResp.Error != nil {
+		return Response{}, fmt.Errorf("LLM API error: %s", apiResp.Error.Message)
+	}
+
+	if len(apiResp.Choices) == 0 {
+		return Response{}, fmt.Errorf("no response from LLM")
+	}
+
+	model := apiResp.Model
+	if model == `` {
+		model = profile.Model
+	}
+
+	result := Response{
+		Text:         apiResp.Choices[0].Message.Content,
+		Model:        model,
+		InputTokens:  apiResp.Usage.PromptTokens,
+		OutputTokens: apiResp.Usage.CompletionTokens,
+	}
+
+	for _, c := range apiResp.Choices[0].Message.ToolCalls {
+		call, err := c.toToolCall()
+		if err != nil {
+			return Response{}, err
+		}
+		result.ToolCalls = append(result.ToolCalls, call)
+	}
+
+	return result, nil
+}
+
+// The most tool calls a streamed response can make
+const maxStreamToolCalls = 16
+
+// Stream reads a server-sent events response, one "data:" line per delta
+func (o OpenAIProvider) Stream(ctx context.Context, profile Profile, req Request, onChunk func(text string)) (Response, error) {
+
+	resp, err := o.send(ctx, profile, req, true)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	result := Response{Model: profile.Model}
+	var text strings.Builder
+	toolCalls := []openAIToolCall{}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		data, isData := strings.CutPrefix(line, `data:`)
+		if !isData {
+			continue // Blank lines, comments and other fields
+		}
+
+		data = strings.TrimSpace(data)
+		if data == `[DONE]` {
+			break
+		}
+
+		var part openAIResponse
+		if err := json.Unmarshal([]byte(data), &part); err != nil {
+			return Response{}, fmt.Errorf("error parsing stream: %w", err)
+		}
+
+		if part.Error != nil {
+			return Response{}, fmt.Errorf("LLM API error: %s", part.Error.Message)
+		}
+
+		if part.Model != `` {
+			result.Model = part.Model
+		}
+
+		// Usage arrives in a final chunk with no choices
+		if part.Usage.PromptTokens > 0 || part.Usage.CompletionTokens > 0 {
+			result.InputTokens = part.Usage.PromptTokens
+			result.OutputTokens = part.Usage.CompletionTokens
+		}
+
+		for _, choice := range part.Choices {
+			if choice.Delta.Content != `` {
+				text.WriteString(choice.Delta.Content)
+				onChunk(choice.Delta.Content)
+			}
+
+			for _, delta := range choice.Delta.ToolCalls {
+				// The index comes from the service, so it can't be trusted to size anything
+				if delta.Index < 0 || delta.Index >= maxStreamToolCalls {
+					return Response{}, fmt.Errorf("error parsing stream: tool call index %d out of range", delta.Index)
+				}
+				for len(toolCalls) <= delta.Index {
+					toolCalls = append(toolCalls, openAIToolCall{Index: len(toolCalls)})
+				}
+				toolCalls[delta.Index].Function.Name += delta.Function.Name
+				toolCalls[delta.Index].Function.Arguments += delta.Function.Arguments
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Response{}, fmt.Errorf("error reading stream: %w", err)
+	}
+
+	for _, c := range toolCalls {
+		call, err := c.toToolCall()
+		if err != nil {
+			return Response{}, err
+		}
+		result.ToolCalls = append(result.ToolCalls, call)
+	}
+
+	if text.Len() == 0 && len(result.ToolCalls) == 0 {
+		return Response{}, fmt.Errorf("no response from LLM")
+	}
+
+	result.Text = text.String()
+
+	return result, nil
+}
+
+// send posts the request, returning the response only if the status was OK
+func (OpenAIProvider) send(ctx context.Context, profile Profile, req Request, stream bool) (*http.Response, error) {
+
+	apiReq := openAIRequest{
+		Model:       profile.Model,
+		Messages:    req.Messages,
+		Temperature: requestTemperature(profile, req),
+		MaxTokens:   requestMaxTokens(profile, req),
+		Tools:       toAPITools(req.Tools),
+		Stream:      stream,
+	}
+
+	if stream {
+		// Without this, usage isn't reported for streamed responses
+		apiReq.StreamOptions = &struct {
+			IncludeUsage bool `json:"include_usage"`
+		}{IncludeUsage: true}
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	// Ensure the endpoint includes the completions path
+	endpoint := profile.BaseURL
+	if !strings.HasSuffix(endpoint, `/chat/completions`) {
+		endpoint = strings.TrimSuffix(endpoint, `/`) + `/chat/completions`
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	httpReq.Header.Set(`Content-Type`, `application/json`)
+	if profile.APIKey != `` {
+		httpReq.Header.Set(`Authorization`, `Bearer `+profile.APIKey)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,189 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrNoCannedResponse = errors.New("no canned response matches the request")
+)
+
+func init() {
+	RegisterProvider(`recording`, NewRecordingProvider())
+}
+
+// CannedResponse is a reply the RecordingProvider hands back instead of calling a real service.
+type CannedResponse struct {
+	Match        string     `yaml:"match,omitempty"` // Case insensitive substring of the last user message. Empty matches anything.
+	Text         string     `yaml:"text,omitempty"`
+	ToolCalls    []ToolCall `yaml:"toolcalls,omitempty"`
+	Error        string     `yaml:"error,omitempty"` // If set, the request fails with this error instead
+	InputTokens  int        `yaml:"inputtokens,omitempty"`
+	OutputTokens int        `yaml:"outputtokens,omitempty"`
+}
+
+// RecordedRequest is a request the RecordingProvider received
+type RecordedRequest struct {
+	Profile  string
+	Messages []Message
+	Tools    []Tool
+}
+
+// RecordingProvider replays canned responses so features can be tested without a
+// live LLM. Every request it receives is kept for inspection.
+//
+// If Upstream is set, requests without a matching canned response are passed through
+// to it and the reply is kept as a new canned response. SaveFile() then writes them out
+// so a session against a real service can be replayed later.
+type RecordingProvider struct {
+	Upstream Provider
+
+	lock      sync.Mutex
+	responses []CannedResponse
+	requests  []RecordedRequest
+}
+
+func NewRecordingProvider(responses ...CannedResponse) *RecordingProvider {
+	return &RecordingProvider{
+		responses: responses,
+	}
+}
+
+// AddResponse adds canned responses. They are checked in the order they were added.
+func (r *RecordingProvider) AddResponse(responses ...CannedResponse) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.responses = append(r.responses, responses...)
+}
+
+// Requests returns every request received so far
+func (r *RecordingProvider) Requests() []RecordedRequest {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]RecordedRequest{}, r.requests...)
+}
+
+// Reset forgets all canned responses and recorded requests
+func (r *RecordingProvider) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.responses = nil
+	r.requests = nil
+}
+
+// LoadFile adds the canned responses found in a yaml file
+func (r *RecordingProvider) LoadFile(path string) error {
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	loaded := []CannedResponse{}
+	if err := yaml.Unmarshal(bytes, &loaded); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	r.AddResponse(loaded...)
+
+	return nil
+}
+
+// SaveFile writes all canned responses (including any recorded from Upstream) to a yaml file
+func (r *RecordingProvider) SaveFile(path string) error {
+	r.lock.Lock()
+	bytes, err := yaml.Marshal(r.responses)
+	r.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bytes, 0644)
+}
+
+func (r *RecordingProvider) Complete(ctx context.Context, profile Profile, req Request) (Response, error) {
+
+	lastUserMessage := ``
+	for _, m := range req.Messages {
+		if m.Role == RoleUser {
+			lastUserMessage = m.Content
+		}
+	}
+
+	r.lock.Lock()
+	r.requests = append(r.requests, RecordedRequest{
+		Profile:  profile.Name,
+		Messages: append([]Message{}, req.Messages...),
+		Tools:    append([]Tool{}, req.Tools...),
+	})
+
+	for _, canned := range r.responses {
+		if canned.Match != `` && !strings.Contains(strings.ToLower(lastUserMessage), strings.ToLower(canned.Match)) {
+			continue
+		}
+
+		r.lock.Unlock()
+
+		if canned.Error != `` {
+			return Response{}, errors.New(canned.Error)
+		}
+
+		return Response{
+			Text:         canned.Text,
+			ToolCalls:    canned.ToolCalls,
+			Model:        profile.Model,
+			InputTokens:  canned.InputTokens,
+			OutputTokens: canned.OutputTokens,
+		}, nil
+	}
+
+	upstream := r.Upstream
+	r.lock.Unlock()
+
+	if upstream == nil {
+		return Response{}, fmt.Errorf("%w: %q", ErrNoCannedResponse, lastUserMessage)
+	}
+
+	resp, err := upstream.Complete(ctx, profile, req)
+	if err != nil {
+		return resp, err
+	}
+
+	r.AddResponse(CannedResponse{
+		Match:        lastUserMessage,
+		Text:         resp.Text,
+		ToolCalls:    resp.ToolCalls,
+		InputTokens:  resp.InputTokens,
+		OutputTokens: resp.OutputTokens,
+	})
+
+	return resp, nil
+}
+
+// Stream replays a canned response a word at a time, the way a real service would
+func (r *RecordingProvider) Stream(ctx context.Context, profile Profile, req Request, onChunk func(text string)) (Response, error) {
+
+	resp, err := r.Complete(ctx, profile, req)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, word := range strings.SplitAfter(resp.Text, ` `) {
+		if word != `` {
+			onChunk(word)
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,314 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessages = []Message{
+	{Role: RoleSystem, Content: "You are a gate guard."},
+	{Role: RoleUser, Content: "Open the gate!"},
+}
+
+func TestOpenAIProvider(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sekrit", r.Header.Get("Authorization"))
+
+		req := openAIRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-model", req.Model)
+		assert.Equal(t, 150, req.MaxTokens)
+		assert.Equal(t, testMessages, req.Messages)
+
+		w.Write([]byte(`{"model":"test-model-0613","choices":[{"message":{"content":"Come back in the morning."}}],"usage":{"prompt_tokens":12,"completion_tokens":6}}`))
+	}))
+	defer srv.Close()
+
+	profile := Profile{Name: "test", Model: "test-model", BaseURL: srv.URL + "/v1", APIKey: "sekrit", MaxTokens: 300}
+
+	resp, err := OpenAIProvider{}.Complete(context.Background(), profile, Request{Messages: testMessages, MaxTokens: 150})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Text: "Come back in the morning.", Model: "test-model-0613", InputTokens: 12, OutputTokens: 6}, resp)
+}
+
+func TestOllamaProvider_Stream(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+
+		w.Write([]byte(`{"message":{"content":"Come back "},"done":false}` + "\n" +
+			`{"message":{"content":"in the morning."},"done":true,"prompt_eval_count":12,"eval_count":6}` + "\n"))
+	}))
+	defer srv.Close()
+
+	profile := Profile{Name: "test", Model: "test-model", BaseURL: srv.URL + "/"}
+
+	resp, err := OllamaProvider{}.Complete(context.Background(), profile, Request{Messages: testMessages})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Text: "Come back in the morning.", Model: "test-model", InputTokens: 12, OutputTokens: 6}, resp)
+}
+
+var testTools = []Tool{
+	{
+		Name:        "givequest",
+		Description: "Give the player a quest.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"quest": map[string]any{"type": "string", "enum": []any{"1-start"}}},
+		},
+	},
+}
+
+func TestOpenAIProvider_ToolCalls(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := openAIRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Tools, 1)
+		assert.Equal(t, "function", req.Tools[0].Type)
+		assert.Equal(t, "givequest", req.Tools[0].Function.Name)
+		assert.Equal(t, testTools[0].Parameters, req.Tools[0].Function.Parameters)
+
+		w.Write([]byte(`{"choices":[{"message":{"content":"Find my locket.","tool_calls":[{"type":"function","function":{"name":"givequest","arguments":"{\"quest\":\"1-start\"}"}}]}}]}`))
+	}))
+	defer srv.Close()
+
+	profile := Profile{Name: "test", Model: "test-model", BaseURL: srv.URL}
+
+	resp, err := OpenAIProvider{}.Complete(context.Background(), profile, Request{Messages: testMessages, Tools: testTools})
+	require.NoError(t, err)
+	assert.Equal(t, "Find my locket.", resp.Text)
+	assert.Equal(t, []ToolCall{{Name: "givequest", Arguments: map[string]any{"quest": "1-start"}}}, resp.ToolCalls)
+}
+
+func TestOllamaProvider_ToolCalls(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":{"content":"","tool_calls":[{"function":{"name":"give","arguments":{"gold":5}}}]},"done":true}` + "\n"))
+	}))
+	defer srv.Close()
+
+	profile := Profile{Name: "test", Model: "test-model", BaseURL: srv.URL}
+
+	resp, err := OllamaProvider{}.Complete(context.Background(), profile, Request{Messages: testMessages, Tools: testTools})
+	require.NoError(t, err)
+	assert.Equal(t, []ToolCall{{Name: "give", Arguments: map[string]any{"gold": float64(5)}}}, resp.ToolCalls)
+}
+
+func TestComplete_RetryThenBackoff(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		statuses    []int
+		wantHits    int32
+		wantErr     bool
+		wantBackoff bool
+	}{
+		{name: "success", statuses: []int{200}, wantHits: 1},
+		{name: "recovers after retries", statuses: []int{503, 429, 200}, wantHits: 3},
+		{name: "retries exhausted", statuses: []int{500, 502, 503, 200}, wantHits: 3, wantErr: true, wantBackoff: true},
+		{name: "client errors are not retried", statuses: []int{400, 200}, wantHits: 1, wantErr: true, wantBackoff: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var hits int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[atomic.AddInt32(&hits, 1)-1]
+				w.WriteHeader(status)
+				w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+			}))
+			defer srv.Close()
+
+			useProfile(t, "retrytest", "openai", srv.URL)
+
+			resp, err := Complete(context.Background(), "retrytest", Request{Messages: testMessages})
+			assert.Equal(t, tt.wantHits, atomic.LoadInt32(&hits))
+			assert.Equal(t, tt.wantBackoff, InBackoff("retrytest"))
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, "ok", resp.Text)
+				return
+			}
+
+			var statusErr StatusError
+			require.True(t, errors.As(err, &statusErr), "expected a StatusError, got %v", err)
+
+			// While in backoff the service isn't contacted at all
+			_, err = Complete(context.Background(), "retrytest", Request{Messages: testMessages})
+			assert.ErrorIs(t, err, ErrBackoff)
+			assert.Equal(t, tt.wantHits, atomic.LoadInt32(&hits))
+		})
+	}
+}
+
+func TestComplete_UnknownProfile(t *testing.T) {
+	useProfile(t, "default", "recording", "")
+
+	_, err := Complete(context.Background(), "no-such-profile", Request{Messages: testMessages})
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+}
+
+func TestRecordingProvider_Replay(t *testing.T) {
+	useProfile(t, "recorded", "recording", "")
+
+	tokenUsageMutex.Lock()
+	delete(tokenUsage, 7)
+	tokenUsageMutex.Unlock()
+
+	rec := NewRecordingProvider(
+		CannedResponse{Match: "gate", Text: "The gate stays shut.", InputTokens: 9, OutputTokens: 4},
+		CannedResponse{Match: "bribe", Error: "the guard is offended"},
+		CannedResponse{Text: "Move along."},
+	)
+	RegisterProvider("recording", rec)
+	defer RegisterProvider("recording", NewRecordingProvider())
+
+	resp, err := Complete(context.Background(), "recorded", Request{Messages: testMessages}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "The gate stays shut.", resp.Text)
+	assert.Equal(t, "test-model", resp.Model)
+
+	resp, err = Complete(context.Background(), "recorded", Request{Messages: []Message{{Role: RoleUser, Content: "Hello"}}}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Move along.", resp.Text)
+	assert.Equal(t, 2, resp.OutputTokens, "Missing usage should be estimated")
+
+	_, err = Complete(context.Background(), "recorded", Request{Messages: []Message{{Role: RoleUser, Content: "A bribe?"}}})
+	assert.EqualError(t, err, "the guard is offended")
+
+	requests := rec.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "recorded", requests[0].Profile)
+	assert.Equal(t, testMessages, requests[0].Messages)
+
+	usage := GetTokenUsage(7)
+	assert.Equal(t, 2, usage.TotalCalls)
+	assert.Equal(t, 10, usage.InputTokens)
+	assert.Equal(t, 6, usage.OutputTokens)
+}
+
+func TestRecordingProvider_RecordUpstream(t *testing.T) {
+
+	upstream := NewRecordingProvider(CannedResponse{Text: "Halt! Who goes there?", OutputTokens: 5})
+
+	rec := NewRecordingProvider()
+	rec.Upstream = upstream
+
+	resp, err := rec.Complete(context.Background(), Profile{}, Request{Messages: testMessages})
+	require.NoError(t, err)
+	assert.Equal(t, "Halt! Who goes there?", resp.Text)
+
+	path := filepath.Join(t.TempDir(), "canned.yaml")
+	require.NoError(t, rec.SaveFile(path))
+
+	// Replaying the saved file no longer needs the upstream provider
+	replay := NewRecordingProvider()
+	require.NoError(t, replay.LoadFile(path))
+
+	resp, err = replay.Complete(context.Background(), Profile{}, Request{Messages: testMessages})
+	require.NoError(t, err)
+	assert.Equal(t, "Halt! Who goes there?", resp.Text)
+	assert.Equal(t, 5, resp.OutputTokens)
+
+	_, err = replay.Complete(context.Background(), Profile{}, Request{Messages: []Message{{Role: RoleUser, Content: "Something else"}}})
+	assert.ErrorIs(t, err, ErrNoCannedResponse)
+}
+
+func TestOpenAIProvider_Stream(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := openAIRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": keep-alive\n\n" +
+			`data: {"model":"test-model","choices":[{"delta":{"role":"assistant"}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"content":"Come back "}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"content":"in the morning."}}]}` + "\n\n" +
+			`data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":6}}` + "\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	chunks := []string{}
+	resp, err := OpenAIProvider{}.Stream(context.Background(), Profile{Model: "test-model", BaseURL: srv.URL}, Request{Messages: testMessages}, func(text string) {
+		chunks = append(chunks, text)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Come back ", "in the morning."}, chunks)
+	assert.Equal(t, Response{Text: "Come back in the morning.", Model: "test-model", InputTokens: 12, OutputTokens: 6}, resp)
+}
+
+func TestOpenAIProvider_StreamToolCalls(t *testing.T) {
+
+	stream := func(index string) string {
+		return `data: {"choices":[{"delta":{"tool_calls":[{"index":` + index + `,"function":{"name":"givequest","arguments":""}}]}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":` + index + `,"function":{"arguments":"{\"quest\":\"1-start\"}"}}]}}]}` + "\n\n" +
+			"data: [DONE]\n\n"
+	}
+
+	tests := []struct {
+		name    string
+		index   string
+		wantErr bool
+	}{
+		{name: "first", index: "0"},
+		{name: "negative", index: "-1", wantErr: true},
+		{name: "huge", index: "2000000000", wantErr: true},
+		{name: "past the cap", index: "16", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte(stream(tt.index)))
+			}))
+			defer srv.Close()
+
+			resp, err := OpenAIProvider{}.Stream(context.Background(), Profile{Model: "test-model", BaseURL: srv.URL}, Request{Messages: testMessages, Tools: testTools}, func(text string) {})
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "out of range")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []ToolCall{{Name: "givequest", Arguments: map[string]any{"quest": "1-start"}}}, resp.ToolCalls)
+		})
+	}
+}
+
+func TestComplete_StreamNotRetriedOnceDelivered(t *testing.T) {
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(`{"message":{"content":"Come back "},"done":false}` + "\n" + `{"message":`))
+	}))
+	defer srv.Close()
+
+	useProfile(t, "streamtest", "ollama", srv.URL)
+
+	chunks := []string{}
+	_, err := CompleteStream(context.Background(), "streamtest", Request{Messages: testMessages}, func(text string) {
+		chunks = append(chunks, text)
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"Come back "}, chunks)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "A partly delivered response should not be retried")
+}
@@ -0,0 +1,311 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+const (
+	DefaultWorkerCount    = 2
+	DefaultQueueSize      = 32
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+var (
+	ErrQueueFull    = errors.New("LLM request queue is full")
+	ErrQueueStopped = errors.New("LLM request queue is not running")
+)
+
+var (
+	queueMutex   sync.Mutex
+	jobQueue     chan *Job
+	pendingJobs  = map[uint64]*Job{}
+	lastJobId    uint64
+	workersGroup sync.WaitGroup
+)
+
+// Job is a single LLM request handled by the background worker pool.
+// When the job finishes (successfully, with an error, cancelled or timed out)
+// an events.LLMResponse is added to the event queue carrying the same Id and Tag.
+type Job struct {
+	Id             uint64        // Assigned by QueueRequest()
+	UserId         int           // Player the request is on behalf of (token tracking + cancellation)
+	UserRole       string        // Role of the player, which picks their quota. Filled in by QueueRequest()
+	MobInstanceId  int           // Mob the request is on behalf of (cancellation)
+	ConversationId int           // Optional conversation this request belongs to
+	Tag            string        // Free form label so listeners can tell requests apart
+	Profile        string        // Named LLM profile to send the request to, "default" if empty
+	Stream         bool          // Deliver the response a sentence at a time as events.LLMResponseChunk
+	Prompt         string        // The prompt to send
+	Context        []string      // Context lines sent ahead of the prompt
+	Tools          []Tool        // Optional functions the model may call
+	Timeout        time.Duration // Time allowed, including time spent waiting in the queue
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	start  time.Time
+}
+
+// StartWorkers spins up the pool of goroutines that process queued LLM requests.
+// Calling it while the pool is already running does nothing.
+func StartWorkers(workerCount int, queueSize int) {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	if jobQueue != nil {
+		return
+	}
+
+	if workerCount < 1 {
+		workerCount = DefaultWorkerCount
+	}
+	if queueSize < 1 {
+		queueSize = DefaultQueueSize
+	}
+
+	jobQueue = make(chan *Job, queueSize)
+
+	for i := 0; i < workerCount; i++ {
+		workersGroup.Add(1)
+		go worker(jobQueue)
+	}
+
+	mudlog.Info("LLM", "info", "request workers started", "workers", workerCount, "queueSize", queueSize)
+}
+
+// StopWorkers cancels all pending requests and waits for the workers to exit.
+func StopWorkers() {
+	queueMutex.Lock()
+	if jobQueue == nil {
+		queueMutex.Unlock()
+		return
+	}
+
+	for _, job := range pendingJobs {
+		job.cancel()
+	}
+
+	close(jobQueue)
+	jobQueue = nil
+	queueMutex.Unlock()
+
+	workersGroup.Wait()
+
+	mudlog.Info("LLM", "info", "request workers stopped")
+}
+
+// QueueRequest hands a request off to the worker pool and returns immediately.
+// Returns the id of the job, which will be present in the resulting events.LLMResponse
+func QueueRequest(job Job) (uint64, error) {
+
+	// Workers can't read user records, so the role is looked up now
+	if job.UserId > 0 && job.UserRole == `` {
+		job.UserRole = lookupQuotaUser(job.UserId).Role
+	}
+
+	// Fail now, so the caller can fall back on something static
+	if err := CheckQuota(job.UserId, job.UserRole); err != nil {
+		return 0, err
+	}
+
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	if jobQueue == nil {
+		return 0, ErrQueueStopped
+	}
+
+	if job.Profile == `` {
+		job.Profile = `default`
+	}
+
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultRequestTimeout
+	}
+
+	lastJobId++
+	job.Id = lastJobId
+	job.start = time.Now()
+	job.ctx, job.cancel = context.WithTimeout(context.Background(), job.Timeout)
+
+	select {
+	case jobQueue <- &job:
+	default:
+		job.cancel()
+		return 0, ErrQueueFull
+	}
+
+	pendingJobs[job.Id] = &job
+
+	return job.Id, nil
+}
+
+// Cancel abandons a single queued or in-flight request.
+// Returns true if the job was found.
+func Cancel(jobId uint64) bool {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	if job, ok := pendingJobs[jobId]; ok {
+		job.cancel()
+		return true
+	}
+	return false
+}
+
+// CancelUser abandons all requests made on behalf of a user.
+// Returns the number of jobs cancelled.
+func CancelUser(userId int) int {
+	return cancelMatching(func(j *Job) bool { return j.UserId == userId })
+}
+
+// CancelMob abandons all requests made on behalf of a mob instance.
+// Returns the number of jobs cancelled.
+func CancelMob(mobInstanceId int) int {
+	return cancelMatching(func(j *Job) bool { return j.MobInstanceId == mobInstanceId })
+}
+
+// PendingCount returns how many jobs are waiting or currently in flight.
+func PendingCount() int {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+	return len(pendingJobs)
+}
+
+func cancelMatching(match func(*Job) bool) int {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	ct := 0
+	for _, job := range pendingJobs {
+		if match(job) {
+			job.cancel()
+			ct++
+		}
+	}
+	return ct
+}
+
+func worker(jobs <-chan *Job) {
+	defer workersGroup.Done()
+
+	for job := range jobs {
+		runJob(job)
+	}
+}
+
+func runJob(job *Job) {
+
+	var response Response
+	var err error
+	streamed := false
+
+	// It may have been cancelled or timed out while waiting in the queue
+	if err = job.ctx.Err(); err == nil {
+		if job.Stream {
+			response, streamed, err = streamJob(job)
+		} else {
+			response, err = complete(job.ctx, job.Profile, job.request(), nil, job.quotaUser())
+		}
+	}
+
+	job.cancel()
+
+	queueMutex.Lock()
+	delete(pendingJobs, job.Id)
+	queueMutex.Unlock()
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		mudlog.Warn("LLM", "info", "request timed out", "jobId", job.Id, "tag", job.Tag, "timeout", job.Timeout)
+	}
+
+	events.AddToQueue(events.LLMResponse{
+		JobId:          job.Id,
+		UserId:         job.UserId,
+		MobInstanceId:  job.MobInstanceId,
+		ConversationId: job.ConversationId,
+		Tag:            job.Tag,
+		Text:           response.Text,
+		Error:          err,
+		Duration:       time.Since(job.start),
+		Streamed:       streamed,
+		ToolCalls:      toEventToolCalls(response.ToolCalls),
+	})
+}
+
+func (j *Job) quotaUser() quotaUser {
+	return quotaUser{UserId: j.UserId, Role: j.UserRole}
+}
+
+func (j *Job) request() Request {
+	req := PromptRequest(j.Prompt, j.Context)
+	req.Tools = j.Tools
+	return req
+}
+
+func toEventToolCalls(calls []ToolCall) []events.LLMToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	result := make([]events.LLMToolCall, len(calls))
+	for i, c := range calls {
+		result[i] = events.LLMToolCall{Name: c.Name, Arguments: c.Arguments}
+	}
+	return result
+}
+
+// streamJob sends the request, queueing an events.LLMResponseChunk for each sentence as it completes.
+// Each sentence is held back until the next one arrives so the last one can be flagged as Final.
+// If the request fails part way through, what was delivered is returned along with the error.
+func streamJob(job *Job) (Response, bool, error) {
+
+	splitter := sentenceSplitter{}
+	delivered := []string{}
+	held := ``
+
+	send := func(text string, final bool) {
+		events.AddToQueue(events.LLMResponseChunk{
+			JobId:          job.Id,
+			UserId:         job.UserId,
+			MobInstanceId:  job.MobInstanceId,
+			ConversationId: job.ConversationId,
+			Tag:            job.Tag,
+			Text:           text,
+			Final:          final,
+		})
+		delivered = append(delivered, text)
+	}
+
+	response, err := complete(job.ctx, job.Profile, job.request(), func(text string) {
+		for _, sentence := range splitter.Write(text) {
+			if held != `` {
+				send(held, false)
+			}
+			held = sentence
+		}
+	}, job.quotaUser())
+
+	if rest := splitter.Flush(); rest != `` {
+		if held != `` {
+			send(held, false)
+		}
+		held = rest
+	}
+
+	if held != `` {
+		send(held, true)
+	}
+
+	if err != nil {
+		response.Text = strings.Join(delivered, ` `)
+	}
+
+	return response, len(delivered) > 0, err
+}
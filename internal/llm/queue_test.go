@@ -0,0 +1,251 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	mudlog.SetupLogger(nil, `LOW`, ``, false)
+
+	// Usage is saved to the datafiles folder, so keep it out of the real one
+	dataDir, err := os.MkdirTemp(``, `llmtest`)
+	if err != nil {
+		panic(err)
+	}
+	configs.AddOverlayOverrides(map[string]any{
+		"FilePaths.DataFiles":        dataDir,
+		"FilePaths.CarefulSaveFiles": false,
+	})
+
+	code := m.Run()
+	os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+// useProfile points a named profile at a provider and url
+func useProfile(t *testing.T, name string, provider string, baseURL string) {
+	t.Helper()
+
+	testsupport.UseConfig(t, map[string]any{
+		"Integrations.LLM.Enabled":    true,
+		"Integrations.LLM.MaxRetries": 2,
+		"Integrations.LLM.RetryDelay": 1,
+		"Integrations.LLM.Profiles." + name: map[string]any{
+			"Provider": provider,
+			"Model":    "test-model",
+			"BaseURL":  baseURL,
+		},
+	})
+
+	ResetBackoff()
+	t.Cleanup(ResetBackoff)
+}
+
+// startFakeLLM starts an Ollama style endpoint that won't answer until release is closed.
+func startFakeLLM(t *testing.T, release chan struct{}) {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Well met, traveler."},"done":true,"prompt_eval_count":10,"eval_count":5}`))
+	}))
+
+	useProfile(t, "default", "ollama", srv.URL)
+
+	Init()
+	t.Cleanup(func() {
+		Shutdown()
+		srv.Close()
+		// Drain any responses from abandoned jobs so they don't leak into the next test
+		events.ProcessEvents()
+	})
+}
+
+// collectResponses captures every LLMResponse event that passes through the queue.
+func collectResponses(t *testing.T) *[]events.LLMResponse {
+	t.Helper()
+
+	results := &[]events.LLMResponse{}
+	id := events.RegisterListener(events.LLMResponse{}, func(e events.Event) events.ListenerReturn {
+		*results = append(*results, e.(events.LLMResponse))
+		return events.Continue
+	})
+	t.Cleanup(func() { events.UnregisterListener(events.LLMResponse{}, id) })
+
+	return results
+}
+
+// runTurns simulates the main loop, processing events until done returns true or we run out of time.
+func runTurns(done func() bool, maxWait time.Duration) {
+	deadline := time.Now().Add(maxWait)
+	for !done() && time.Now().Before(deadline) {
+		turnCt := util.IncrementTurnCount()
+		events.AddToQueue(events.NewTurn{TurnNumber: turnCt, TimeNow: time.Now()})
+		events.ProcessEvents()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueueRequest_LoopKeepsAdvancing(t *testing.T) {
+	release := make(chan struct{})
+	startFakeLLM(t, release)
+	results := collectResponses(t)
+
+	turns := 0
+	turnListener := events.RegisterListener(events.NewTurn{}, func(e events.Event) events.ListenerReturn {
+		turns++
+		return events.Continue
+	})
+	defer events.UnregisterListener(events.NewTurn{}, turnListener)
+
+	jobId, err := QueueRequest(Job{ConversationId: 7, Tag: `test`, Prompt: `hello`})
+	require.NoError(t, err)
+	assert.NotZero(t, jobId)
+
+	// The request is stuck waiting on the endpoint, but turns keep ticking
+	runTurns(func() bool { return turns >= 25 }, 5*time.Second)
+	assert.Equal(t, 25, turns)
+	assert.Empty(t, *results, "No response should arrive while the endpoint is stalled")
+	assert.Equal(t, 1, PendingCount())
+
+	close(release)
+
+	runTurns(func() bool { return len(*results) > 0 }, 5*time.Second)
+	require.Len(t, *results, 1)
+
+	res := (*results)[0]
+	assert.NoError(t, res.Error)
+	assert.Equal(t, jobId, res.JobId)
+	assert.Equal(t, 7, res.ConversationId)
+	assert.Equal(t, `test`, res.Tag)
+	assert.Equal(t, `Well met, traveler.`, res.Text)
+	assert.Equal(t, 0, PendingCount())
+}
+
+func TestQueueRequest_CancelUserAndMob(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	startFakeLLM(t, release)
+	results := collectResponses(t)
+
+	userJob, err := QueueRequest(Job{UserId: 5, Prompt: `hello`})
+	require.NoError(t, err)
+	mobJob, err := QueueRequest(Job{MobInstanceId: 42, Prompt: `hello`})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, CancelMob(42))
+	assert.Equal(t, 0, CancelMob(43))
+	assert.Equal(t, 1, CancelUser(5))
+
+	runTurns(func() bool { return len(*results) >= 2 }, 5*time.Second)
+	require.Len(t, *results, 2)
+
+	for _, res := range *results {
+		assert.Contains(t, []uint64{userJob, mobJob}, res.JobId)
+		assert.True(t, errors.Is(res.Error, context.Canceled), "expected cancellation, got %v", res.Error)
+	}
+}
+
+func TestQueueRequest_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	startFakeLLM(t, release)
+	results := collectResponses(t)
+
+	_, err := QueueRequest(Job{UserId: 0, Prompt: `hello`, Timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	runTurns(func() bool { return len(*results) > 0 }, 5*time.Second)
+	require.Len(t, *results, 1)
+	assert.True(t, errors.Is((*results)[0].Error, context.DeadlineExceeded), "expected timeout, got %v", (*results)[0].Error)
+	assert.False(t, InBackoff("default"), "A timeout should not put the profile into backoff")
+}
+
+func TestQueueRequest_QueueFullAndStopped(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	startFakeLLM(t, release)
+
+	// Replace the default pool with a single worker and a single slot
+	StopWorkers()
+	StartWorkers(1, 1)
+
+	_, err := QueueRequest(Job{Prompt: `in flight`})
+	require.NoError(t, err)
+
+	// Wait for the worker to pick up the first job, freeing the slot
+	for i := 0; i < 100 && len(jobQueue) > 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = QueueRequest(Job{Prompt: `waiting`})
+	require.NoError(t, err)
+
+	_, err = QueueRequest(Job{Prompt: `overflow`})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	StopWorkers()
+
+	_, err = QueueRequest(Job{Prompt: `stopped`})
+	assert.ErrorIs(t, err, ErrQueueStopped)
+}
+
+func TestQueueRequest_Stream(t *testing.T) {
+	useProfile(t, "default", "recording", "")
+
+	RegisterProvider("recording", NewRecordingProvider(CannedResponse{Text: "Well met, traveler. What brings you here? Speak up"}))
+	defer RegisterProvider("recording", NewRecordingProvider())
+
+	StartWorkers(1, 1)
+	defer StopWorkers()
+
+	results := collectResponses(t)
+
+	chunks := []events.LLMResponseChunk{}
+	id := events.RegisterListener(events.LLMResponseChunk{}, func(e events.Event) events.ListenerReturn {
+		chunks = append(chunks, e.(events.LLMResponseChunk))
+		return events.Continue
+	})
+	defer events.UnregisterListener(events.LLMResponseChunk{}, id)
+
+	jobId, err := QueueRequest(Job{ConversationId: 3, Tag: `test`, Prompt: `hello`, Stream: true})
+	require.NoError(t, err)
+
+	runTurns(func() bool { return len(*results) > 0 }, 5*time.Second)
+	require.Len(t, *results, 1)
+
+	res := (*results)[0]
+	assert.NoError(t, res.Error)
+	assert.True(t, res.Streamed)
+	assert.Equal(t, `Well met, traveler. What brings you here? Speak up`, res.Text)
+
+	// Every chunk arrives before the final response
+	require.Len(t, chunks, 3)
+	assert.Equal(t, `Well met, traveler.`, chunks[0].Text)
+	assert.Equal(t, `What brings you here?`, chunks[1].Text)
+	assert.Equal(t, `Speak up`, chunks[2].Text)
+	for i, c := range chunks {
+		assert.Equal(t, jobId, c.JobId)
+		assert.Equal(t, 3, c.ConversationId)
+		assert.Equal(t, i == len(chunks)-1, c.Final)
+	}
+}
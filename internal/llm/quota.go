@@ -0,0 +1,393 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	QuotaDaily   = `daily`
+	QuotaMonthly = `monthly`
+
+	QuotaTokens   = `tokens`
+	QuotaRequests = `requests`
+)
+
+var (
+	ErrQuotaExceeded = errors.New("LLM quota exceeded")
+
+	quotaUsage     = quotaLedger{}
+	quotaLoaded    bool
+	quotaDirty     bool // Usage changed since it was last saved
+	quotaUsageLock sync.Mutex
+)
+
+// QuotaUsage is how much of the LLM has been used in the current day and month
+type QuotaUsage struct {
+	Day             string   `yaml:"day"`   // 2006-01-02
+	Month           string   `yaml:"month"` // 2006-01
+	DailyTokens     int      `yaml:"dailytokens"`
+	DailyRequests   int      `yaml:"dailyrequests"`
+	MonthlyTokens   int      `yaml:"monthlytokens"`
+	MonthlyRequests int      `yaml:"monthlyrequests"`
+	Alerted         []string `yaml:"alerted,omitempty"` // Thresholds already announced this period
+}
+
+// quotaLedger is saved under the datafiles folder so usage survives a restart
+type quotaLedger struct {
+	Server QuotaUsage          `yaml:"server"`
+	Users  map[int]*QuotaUsage `yaml:"users,omitempty"`
+}
+
+// roll starts a new day or month if one has begun since the usage was last touched
+func (q *QuotaUsage) roll(now time.Time) {
+
+	day := now.Format(`2006-01-02`)
+	month := now.Format(`2006-01`)
+
+	if q.Day != day {
+		q.Day = day
+		q.DailyTokens = 0
+		q.DailyRequests = 0
+		q.Alerted = slices.DeleteFunc(q.Alerted, func(a string) bool { return strings.HasPrefix(a, QuotaDaily) })
+	}
+
+	if q.Month != month {
+		q.Month = month
+		q.MonthlyTokens = 0
+		q.MonthlyRequests = 0
+		q.Alerted = slices.DeleteFunc(q.Alerted, func(a string) bool { return strings.HasPrefix(a, QuotaMonthly) })
+	}
+}
+
+// exceeded returns which limit has been reached, if any
+func (q *QuotaUsage) exceeded(limits configs.IntegrationsLLMQuota) (period string, metric string, ok bool) {
+	for _, c := range q.counts(limits) {
+		if c.limit > 0 && c.used >= c.limit {
+			return c.period, c.metric, true
+		}
+	}
+	return ``, ``, false
+}
+
+type quotaCount struct {
+	period string
+	metric string
+	used   int
+	limit  int
+}
+
+func (q *QuotaUsage) counts(limits configs.IntegrationsLLMQuota) []quotaCount {
+	return []quotaCount{
+		{QuotaDaily, QuotaTokens, q.DailyTokens, int(limits.DailyTokens)},
+		{QuotaDaily, QuotaRequests, q.DailyRequests, int(limits.DailyRequests)},
+		{QuotaMonthly, QuotaTokens, q.MonthlyTokens, int(limits.MonthlyTokens)},
+		{QuotaMonthly, QuotaRequests, q.MonthlyRequests, int(limits.MonthlyRequests)},
+	}
+}
+
+// thresholds returns events for any thresholds crossed that haven't been announced yet
+func (q *QuotaUsage) thresholds(userId int, limits configs.IntegrationsLLMQuota, alertPercent int) []events.LLMQuotaThreshold {
+
+	crossed := []events.LLMQuotaThreshold{}
+
+	for _, c := range q.counts(limits) {
+		if c.limit < 1 {
+			continue
+		}
+		for _, pct := range []int{alertPercent, 100} {
+			if c.used*100 < c.limit*pct {
+				continue
+			}
+			key := fmt.Sprintf(`%s-%s-%d`, c.period, c.metric, pct)
+			if slices.Contains(q.Alerted, key) {
+				continue
+			}
+			q.Alerted = append(q.Alerted, key)
+			crossed = append(crossed, events.LLMQuotaThreshold{
+				UserId:  userId,
+				Period:  c.period,
+				Metric:  c.metric,
+				Used:    c.used,
+				Limit:   c.limit,
+				Percent: pct,
+			})
+		}
+	}
+
+	return crossed
+}
+
+func quotaFilePath() string {
+	return util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `llmusage.yaml`)
+}
+
+// loadQuotaUsage expects quotaUsageLock to already be held
+func loadQuotaUsage() {
+
+	if quotaLoaded {
+		return
+	}
+	quotaLoaded = true
+
+	quotaUsage = quotaLedger{}
+
+	bytes, err := os.ReadFile(quotaFilePath())
+	if err == nil {
+		if err := yaml.Unmarshal(bytes, &quotaUsage); err != nil {
+			mudlog.Error("LLM", "error", "Problem unmarshalling quota usage: "+err.Error())
+		}
+	}
+
+	if quotaUsage.Users == nil {
+		quotaUsage.Users = map[int]*QuotaUsage{}
+	}
+
+	// Nobody needs to know about usage from before this month
+	month := util.Now().Format(`2006-01`)
+	for userId, usage := range quotaUsage.Users {
+		if usage.Month != month {
+			delete(quotaUsage.Users, userId)
+		}
+	}
+}
+
+// SaveQuotaUsage writes the usage ledger to disk if anything has changed since it was last saved.
+// Usage is only counted in memory as requests complete, so this is called on autosave and shutdown.
+func SaveQuotaUsage() {
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	if quotaDirty {
+		saveQuotaUsage()
+	}
+}
+
+// saveQuotaUsage expects quotaUsageLock to already be held
+func saveQuotaUsage() {
+
+	quotaDirty = false
+
+	data, err := yaml.Marshal(&quotaUsage)
+	if err != nil {
+		mudlog.Error("LLM", "error", "Could not marshal quota usage: "+err.Error())
+		return
+	}
+
+	if err := util.Save(quotaFilePath(), data, bool(configs.GetFilePathsConfig().CarefulSaveFiles)); err != nil {
+		mudlog.Error("LLM", "error", "Could not save quota usage: "+err.Error())
+	}
+}
+
+// userQuotaUsage expects quotaUsageLock to already be held
+func userQuotaUsage(userId int) *QuotaUsage {
+	usage, ok := quotaUsage.Users[userId]
+	if !ok {
+		usage = &QuotaUsage{}
+		quotaUsage.Users[userId] = usage
+	}
+	return usage
+}
+
+// quotaUser is who a request counts against. The role is looked up on the main loop,
+// since the workers sending requests can't safely read user records.
+type quotaUser struct {
+	UserId int
+	Role   string
+}
+
+// lookupQuotaUser finds the role of a user (if any) so their quota can be applied.
+// Must be called from the main loop.
+func lookupQuotaUser(userId ...int) quotaUser {
+
+	if len(userId) == 0 || userId[0] < 1 {
+		return quotaUser{}
+	}
+
+	qu := quotaUser{UserId: userId[0]}
+	if user := users.GetByUserId(qu.UserId); user != nil {
+		qu.Role = user.Role
+	}
+
+	return qu
+}
+
+// GetRoleQuota returns the limits that apply to a user with a given role
+func GetRoleQuota(role string) configs.IntegrationsLLMQuota {
+
+	quotas := configs.GetIntegrationsConfig().LLM.Quotas
+
+	if quota, ok := quotas.Roles[role]; ok && role != `` {
+		return quota
+	}
+
+	return quotas.User
+}
+
+// CheckQuota returns ErrQuotaExceeded if the server or user (0 for none) has used up a quota.
+// role is the role of the user, which decides their limits.
+func CheckQuota(userId int, role string) error {
+
+	quotas := configs.GetIntegrationsConfig().LLM.Quotas
+	if !bool(quotas.Enabled) {
+		return nil
+	}
+
+	userLimits := configs.IntegrationsLLMQuota{}
+	if userId > 0 {
+		userLimits = GetRoleQuota(role)
+	}
+
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	loadQuotaUsage()
+
+	now := util.Now()
+
+	quotaUsage.Server.roll(now)
+	if period, metric, ok := quotaUsage.Server.exceeded(quotas.Server); ok {
+		return fmt.Errorf("%w: server %s %s", ErrQuotaExceeded, period, metric)
+	}
+
+	if userId > 0 {
+		usage := userQuotaUsage(userId)
+		usage.roll(now)
+		if period, metric, ok := usage.exceeded(userLimits); ok {
+			return fmt.Errorf("%w: user %s %s", ErrQuotaExceeded, period, metric)
+		}
+	}
+
+	return nil
+}
+
+// recordQuotaUsage counts a completed request against the server and user (0 for none),
+// announcing any thresholds crossed.
+// Usage is counted whether or not quotas are enforced, so turning them on starts from real figures.
+func recordQuotaUsage(qu quotaUser, tokens int) {
+
+	quotas := configs.GetIntegrationsConfig().LLM.Quotas
+	alertPercent := int(quotas.AlertPercent)
+
+	userId := qu.UserId
+	userLimits := configs.IntegrationsLLMQuota{}
+	if userId > 0 {
+		userLimits = GetRoleQuota(qu.Role)
+	}
+
+	quotaUsageLock.Lock()
+
+	loadQuotaUsage()
+
+	now := util.Now()
+
+	tally := func(q *QuotaUsage) {
+		q.roll(now)
+		q.DailyTokens += tokens
+		q.MonthlyTokens += tokens
+		q.DailyRequests++
+		q.MonthlyRequests++
+	}
+
+	tally(&quotaUsage.Server)
+	crossed := []events.LLMQuotaThreshold{}
+	if bool(quotas.Enabled) {
+		crossed = append(crossed, quotaUsage.Server.thresholds(0, quotas.Server, alertPercent)...)
+	}
+
+	if userId > 0 {
+		usage := userQuotaUsage(userId)
+		tally(usage)
+		if bool(quotas.Enabled) {
+			crossed = append(crossed, usage.thresholds(userId, userLimits, alertPercent)...)
+		}
+	}
+
+	quotaDirty = true
+
+	quotaUsageLock.Unlock()
+
+	for _, evt := range crossed {
+		mudlog.Warn("LLM", "quota", "threshold crossed", "userId", evt.UserId, "period", evt.Period, "metric", evt.Metric, "used", evt.Used, "limit", evt.Limit, "percent", evt.Percent)
+		events.AddToQueue(evt)
+	}
+}
+
+// GetServerQuotaUsage returns a copy of the server-wide usage
+func GetServerQuotaUsage() QuotaUsage {
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	loadQuotaUsage()
+	quotaUsage.Server.roll(util.Now())
+
+	usage := quotaUsage.Server
+	usage.Alerted = slices.Clone(usage.Alerted)
+	return usage
+}
+
+// GetUserQuotaUsage returns a copy of a user's usage
+func GetUserQuotaUsage(userId int) QuotaUsage {
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	loadQuotaUsage()
+	usage := userQuotaUsage(userId)
+	usage.roll(util.Now())
+
+	ret := *usage
+	ret.Alerted = slices.Clone(usage.Alerted)
+	return ret
+}
+
+// GetQuotaUserIds returns the users with usage recorded this month
+func GetQuotaUserIds() []int {
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	loadQuotaUsage()
+
+	month := util.Now().Format(`2006-01`)
+
+	userIds := []int{}
+	for userId, usage := range quotaUsage.Users {
+		if usage.Month == month && usage.MonthlyRequests > 0 {
+			userIds = append(userIds, userId)
+		}
+	}
+	slices.Sort(userIds)
+
+	return userIds
+}
+
+// ResetUserQuotaUsage clears a user's usage for the current day and month
+func ResetUserQuotaUsage(userId int) {
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	loadQuotaUsage()
+	delete(quotaUsage.Users, userId)
+	saveQuotaUsage()
+}
+
+// ResetServerQuotaUsage clears the server-wide usage for the current day and month
+func ResetServerQuotaUsage() {
+	quotaUsageLock.Lock()
+	defer quotaUsageLock.Unlock()
+
+	loadQuotaUsage()
+	quotaUsage.Server = QuotaUsage{}
+	saveQuotaUsage()
+}
@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useQuotas turns on quota enforcement with the given limits, starts from an empty ledger and freezes the clock
+func useQuotas(t *testing.T, server map[string]any, user map[string]any) *util.SimClock {
+	t.Helper()
+
+	testsupport.UseConfig(t, map[string]any{
+		"Integrations.LLM.Quotas": map[string]any{
+			"Enabled":      true,
+			"AlertPercent": 50,
+			"Server":       server,
+			"User":         user,
+		},
+	})
+
+	quotaUsageLock.Lock()
+	quotaUsage = quotaLedger{Users: map[int]*QuotaUsage{}}
+	quotaLoaded = true
+	quotaDirty = false
+	quotaUsageLock.Unlock()
+
+	return testsupport.FreezeClock(t, time.Now())
+}
+
+func TestQuotaUsage_Roll(t *testing.T) {
+
+	q := QuotaUsage{
+		Day:             `2025-03-30`,
+		Month:           `2025-03`,
+		DailyTokens:     100,
+		DailyRequests:   2,
+		MonthlyTokens:   500,
+		MonthlyRequests: 9,
+		Alerted:         []string{`daily-tokens-80`, `monthly-tokens-80`},
+	}
+
+	q.roll(time.Date(2025, 3, 31, 8, 0, 0, 0, time.Local))
+	assert.Equal(t, 0, q.DailyTokens)
+	assert.Equal(t, 0, q.DailyRequests)
+	assert.Equal(t, 500, q.MonthlyTokens)
+	assert.Equal(t, []string{`monthly-tokens-80`}, q.Alerted)
+
+	q.roll(time.Date(2025, 4, 1, 8, 0, 0, 0, time.Local))
+	assert.Equal(t, 0, q.MonthlyTokens)
+	assert.Equal(t, 0, q.MonthlyRequests)
+	assert.Empty(t, q.Alerted)
+}
+
+func TestCheckQuota_User(t *testing.T) {
+
+	useProfile(t, "quotatest", "recording", "")
+	clock := useQuotas(t, map[string]any{}, map[string]any{"DailyRequests": 2})
+
+	RegisterProvider("recording", NewRecordingProvider(CannedResponse{Text: "Halt!"}))
+	defer RegisterProvider("recording", NewRecordingProvider())
+
+	thresholds := []events.LLMQuotaThreshold{}
+	id := events.RegisterListener(events.LLMQuotaThreshold{}, func(e events.Event) events.ListenerReturn {
+		thresholds = append(thresholds, e.(events.LLMQuotaThreshold))
+		return events.Continue
+	})
+	defer events.UnregisterListener(events.LLMQuotaThreshold{}, id)
+
+	for i := 0; i < 2; i++ {
+		_, err := Complete(context.Background(), "quotatest", Request{Messages: testMessages}, 7)
+		require.NoError(t, err)
+	}
+
+	_, err := Complete(context.Background(), "quotatest", Request{Messages: testMessages}, 7)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	_, err = QueueRequest(Job{UserId: 7, Prompt: "Hello"})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// Someone else still has their own allowance
+	assert.NoError(t, CheckQuota(8, ``))
+
+	// Each threshold is only announced once
+	events.ProcessEvents()
+	require.Len(t, thresholds, 2)
+	assert.Equal(t, events.LLMQuotaThreshold{UserId: 7, Period: QuotaDaily, Metric: QuotaRequests, Used: 1, Limit: 2, Percent: 50}, thresholds[0])
+	assert.Equal(t, events.LLMQuotaThreshold{UserId: 7, Period: QuotaDaily, Metric: QuotaRequests, Used: 2, Limit: 2, Percent: 100}, thresholds[1])
+
+	// A new day brings a fresh allowance
+	clock.Advance(24 * time.Hour)
+	assert.NoError(t, CheckQuota(7, ``))
+
+	ResetUserQuotaUsage(7)
+	assert.Equal(t, 0, GetUserQuotaUsage(7).DailyRequests)
+}
+
+func TestCheckQuota_Server(t *testing.T) {
+
+	useQuotas(t, map[string]any{"MonthlyTokens": 10}, map[string]any{})
+
+	assert.NoError(t, CheckQuota(0, ``))
+
+	recordQuotaUsage(quotaUser{UserId: 3}, 6)
+	recordQuotaUsage(quotaUser{}, 4)
+
+	assert.ErrorIs(t, CheckQuota(0, ``), ErrQuotaExceeded)
+	assert.ErrorIs(t, CheckQuota(3, ``), ErrQuotaExceeded)
+	assert.Equal(t, 10, GetServerQuotaUsage().MonthlyTokens)
+	assert.Equal(t, []int{3}, GetQuotaUserIds())
+
+	ResetServerQuotaUsage()
+	assert.NoError(t, CheckQuota(3, ``))
+}
+
+func TestCheckQuota_Disabled(t *testing.T) {
+
+	useQuotas(t, map[string]any{"DailyRequests": 1}, map[string]any{})
+	testsupport.UseConfig(t, map[string]any{"Integrations.LLM.Quotas.Enabled": false})
+
+	recordQuotaUsage(quotaUser{}, 1)
+	recordQuotaUsage(quotaUser{}, 1)
+
+	// Still counted, just not enforced
+	assert.NoError(t, CheckQuota(0, ``))
+	assert.Equal(t, 2, GetServerQuotaUsage().DailyRequests)
+}
+
+func TestCheckQuota_Role(t *testing.T) {
+
+	useQuotas(t, map[string]any{}, map[string]any{"DailyRequests": 1})
+	testsupport.UseConfig(t, map[string]any{"Integrations.LLM.Quotas.Roles.admin.DailyRequests": 0})
+
+	recordQuotaUsage(quotaUser{UserId: 5, Role: `admin`}, 1)
+
+	// The role is carried with the request, so workers never look up the user
+	assert.NoError(t, CheckQuota(5, `admin`))
+	assert.ErrorIs(t, CheckQuota(5, `user`), ErrQuotaExceeded)
+	assert.ErrorIs(t, CheckQuota(5, ``), ErrQuotaExceeded)
+}
+
+func TestSaveQuotaUsage(t *testing.T) {
+
+	useQuotas(t, map[string]any{}, map[string]any{})
+	os.Remove(quotaFilePath())
+
+	recordQuotaUsage(quotaUser{UserId: 3}, 6)
+	recordQuotaUsage(quotaUser{}, 4)
+
+	// Nothing is written until usage is saved
+	assert.NoFileExists(t, quotaFilePath())
+
+	SaveQuotaUsage()
+	require.FileExists(t, quotaFilePath())
+
+	quotaUsageLock.Lock()
+	quotaLoaded = false
+	quotaUsageLock.Unlock()
+
+	assert.Equal(t, 10, GetServerQuotaUsage().DailyTokens)
+	assert.Equal(t, 6, GetUserQuotaUsage(3).DailyTokens)
+}
@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+)
+
+var (
+	ErrBackoff = errors.New("LLM profile is in backoff")
+
+	backoffLock  sync.RWMutex
+	backoffUntil = map[string]time.Time{}
+)
+
+// RetryPolicy is the single retry/backoff policy shared by every provider.
+// Transient failures are retried with an exponentially growing delay. If the
+// retries run out, the profile is rested for Backoff before it's tried again.
+type RetryPolicy struct {
+	MaxRetries int
+	Delay      time.Duration // Delay before the first retry, doubled for each one after
+	Backoff    time.Duration
+}
+
+// GetRetryPolicy returns the policy as currently configured
+func GetRetryPolicy() RetryPolicy {
+	c := configs.GetIntegrationsConfig().LLM
+	return RetryPolicy{
+		MaxRetries: int(c.MaxRetries),
+		Delay:      time.Duration(c.RetryDelay) * time.Millisecond,
+		Backoff:    time.Duration(c.BackoffSeconds) * time.Second,
+	}
+}
+
+// Do calls fn until it succeeds, fails with an error that isn't worth retrying,
+// runs out of retries, or ctx is done.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+
+	delay := p.Delay
+
+	for attempt := 0; ; attempt++ {
+
+		err := fn()
+		if err == nil || attempt >= p.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// noRetryError marks an error that must not be retried, whatever caused it
+type noRetryError struct {
+	error
+}
+
+func (e noRetryError) Unwrap() error { return e.error }
+
+func noRetry(err error) error {
+	return noRetryError{err}
+}
+
+// IsRetryable reports whether an error is likely to go away on its own:
+// network errors, rate limiting and server side errors.
+func IsRetryable(err error) bool {
+
+	if err == nil || IsCancelled(err) {
+		return false
+	}
+
+	if errors.As(err, &noRetryError{}) {
+		return false
+	}
+
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	// Connection level problems (refused, reset, dns, etc.)
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// IsCancelled reports whether an error was caused by the caller giving up,
+// rather than the service failing.
+func IsCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// InBackoff reports whether a profile is currently being rested
+func InBackoff(profileName string) bool {
+	backoffLock.RLock()
+	defer backoffLock.RUnlock()
+
+	return time.Now().Before(backoffUntil[profileName])
+}
+
+func startBackoff(profileName string, d time.Duration) {
+	backoffLock.Lock()
+	defer backoffLock.Unlock()
+
+	backoffUntil[profileName] = time.Now().Add(d)
+}
+
+// ResetBackoff clears the backoff on every profile
+func ResetBackoff() {
+	backoffLock.Lock()
+	defer backoffLock.Unlock()
+
+	backoffUntil = map[string]time.Time{}
+}
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Chunks longer than this are broken at a space even without a sentence ending
+	MaxChunkLength = 200
+)
+
+// sentenceSplitter gathers streamed tokens and hands them back a sentence at a time,
+// so a reply reads naturally when delivered incrementally.
+type sentenceSplitter struct {
+	buf strings.Builder
+}
+
+// Write adds streamed text, returning any sentences it completed
+func (s *sentenceSplitter) Write(text string) []string {
+	s.buf.WriteString(text)
+
+	sentences := []string{}
+
+	for {
+		pending := s.buf.String()
+		end := sentenceEnd(pending)
+		if end < 0 {
+			break
+		}
+
+		if sentence := strings.TrimSpace(pending[:end]); sentence != `` {
+			sentences = append(sentences, sentence)
+		}
+
+		s.buf.Reset()
+		s.buf.WriteString(pending[end:])
+	}
+
+	return sentences
+}
+
+// Flush returns whatever is left over, even if it isn't a complete sentence
+func (s *sentenceSplitter) Flush() string {
+	rest := strings.TrimSpace(s.buf.String())
+	s.buf.Reset()
+	return rest
+}
+
+// sentenceEnd returns the index just past the first complete sentence, or -1.
+// A sentence ends with punctuation followed by whitespace, or a line break.
+// The final sentence can't be known to have ended until more text arrives, or Flush() is called.
+func sentenceEnd(text string) int {
+
+	for i, r := range text {
+		switch r {
+		case '\n':
+			return i + 1
+		case '.', '!', '?':
+			// Include closing quotes/brackets and any repeated punctuation ("...", "?!")
+			j := i + 1
+			for j < len(text) && strings.ContainsRune(`.!?"')]*`, rune(text[j])) {
+				j++
+			}
+			if j < len(text) && unicode.IsSpace(rune(text[j])) {
+				return j
+			}
+		}
+	}
+
+	if len(text) > MaxChunkLength {
+		if lastSpace := strings.LastIndexByte(text[:MaxChunkLength], ' '); lastSpace > 0 {
+			return lastSpace + 1
+		}
+		// No space to break at, so just don't split a character
+		end := MaxChunkLength
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		return end
+	}
+
+	return -1
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentenceSplitter(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		tokens []string
+		want   []string
+	}{
+		{
+			name:   "sentences across tokens",
+			tokens: []string{"Well", " met", ", traveler", ". What", " brings you", " here?", " The roads", " are dangerous"},
+			want:   []string{"Well met, traveler.", "What brings you here?", "The roads are dangerous"},
+		},
+		{
+			name:   "trailing punctuation and quotes",
+			tokens: []string{`He said "Begone!" and left... `, `Truly?! Yes.`},
+			want:   []string{`He said "Begone!"`, `and left...`, `Truly?!`, `Yes.`},
+		},
+		{
+			name:   "line breaks",
+			tokens: []string{"*looks up*\nHello there"},
+			want:   []string{"*looks up*", "Hello there"},
+		},
+		{
+			name:   "no split inside numbers",
+			tokens: []string{"That costs 3.50 gold."},
+			want:   []string{"That costs 3.50 gold."},
+		},
+		{
+			name:   "long text without punctuation",
+			tokens: []string{strings.Repeat("word ", 60)},
+			want:   []string{strings.TrimSpace(strings.Repeat("word ", 40)), strings.TrimSpace(strings.Repeat("word ", 20))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := sentenceSplitter{}
+			got := []string{}
+			for _, token := range tt.tokens {
+				got = append(got, s.Write(token)...)
+			}
+			if rest := s.Flush(); rest != `` {
+				got = append(got, rest)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
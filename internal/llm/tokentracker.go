@@ -29,27 +29,50 @@ type TokenUsage struct {
 
 var (
 	// Track token usage per user
-	tokenUsage      = make(map[int]*TokenUsage) // Map userId -> TokenUsage
+	tokenUsage      = make(map[int]*trackedUsage) // Map userId -> usage
 	tokenUsageMutex sync.RWMutex
 )
 
+// trackedUsage is the usage for a user, and whether the totals saved on their
+// player record have been folded in yet. Usage can be recorded by a background
+// worker before the player record has been read.
+type trackedUsage struct {
+	TokenUsage
+	loaded bool
+}
+
 // LoadTokenUsageFromPlayer loads token usage stats from a player's saved data
+// Player records are not safe to touch outside of the main loop, so neither is this.
 func LoadTokenUsageFromPlayer(userId int) {
 	tokenUsageMutex.Lock()
 	defer tokenUsageMutex.Unlock()
 
+	loadTokenUsage(userId)
+}
+
+// loadTokenUsage expects tokenUsageMutex to already be held
+func loadTokenUsage(userId int) *trackedUsage {
+
+	usage, exists := tokenUsage[userId]
+	if !exists {
+		usage = &trackedUsage{}
+		tokenUsage[userId] = usage
+	}
+
 	// Skip if already loaded
-	if _, exists := tokenUsage[userId]; exists {
-		return
+	if usage.loaded {
+		return usage
 	}
 
 	// Get player
 	player := users.GetByUserId(userId)
 	if player == nil {
 		mudlog.Debug("LLM", "debug", "Cannot load token usage for player: player not found", "userId", userId)
-		return
+		return usage
 	}
 
+	usage.loaded = true
+
 	// Get token usage from player data
 	llmData := player.GetTempData("LLMUsage")
 	if llmData == nil {
@@ -61,18 +84,14 @@ func LoadTokenUsageFromPlayer(userId int) {
 			}
 		}
 
-		// If still no data, initialize with defaults
 		if llmData == nil {
-			tokenUsage[userId] = &TokenUsage{
-				LastUsed: time.Time{}, // Zero value
-			}
-			return
+			return usage
 		}
 	}
 
 	// Process map data to TokenUsage
 	if usageMap, ok := llmData.(map[string]interface{}); ok {
-		processUsageMap(userId, usageMap)
+		processUsageMap(usage, usageMap)
 	} else if usageMap, ok := llmData.(map[interface{}]interface{}); ok {
 		// Handle YAML unmarshalled format (keys are interface{})
 		convertedMap := make(map[string]interface{})
@@ -81,48 +100,47 @@ func LoadTokenUsageFromPlayer(userId int) {
 				convertedMap[ks] = v
 			}
 		}
-		processUsageMap(userId, convertedMap)
+		processUsageMap(usage, convertedMap)
 	}
+
+	mudlog.Debug("LLM", "debug", "Loaded token usage for player", "userId", userId, "calls", usage.TotalCalls, "inputTokens", usage.InputTokens)
+
+	return usage
 }
 
-// Helper function to process usage map data
-func processUsageMap(userId int, usageMap map[string]interface{}) {
-	usage := &TokenUsage{}
+// Helper function to fold saved usage data into the running totals
+func processUsageMap(usage *trackedUsage, usageMap map[string]interface{}) {
 
 	if calls, ok := usageMap["total_calls"].(int); ok {
-		usage.TotalCalls = calls
+		usage.TotalCalls += calls
 	}
 	if input, ok := usageMap["input_tokens"].(int); ok {
-		usage.InputTokens = input
+		usage.InputTokens += input
 	}
 	if output, ok := usageMap["output_tokens"].(int); ok {
-		usage.OutputTokens = output
+		usage.OutputTokens += output
 	}
 	if cost, ok := usageMap["total_cost"].(float64); ok {
-		usage.TotalCost = cost
+		usage.TotalCost += cost
 	}
-	if lastUsed, ok := usageMap["last_used"].(time.Time); ok {
+	if lastUsed, ok := usageMap["last_used"].(time.Time); ok && lastUsed.After(usage.LastUsed) {
 		usage.LastUsed = lastUsed
 	}
-
-	tokenUsage[userId] = usage
-	mudlog.Debug("LLM", "debug", "Loaded token usage for player", "userId", userId, "calls", usage.TotalCalls, "inputTokens", usage.InputTokens)
 }
 
 // SaveTokenUsageToPlayer saves the current token usage to the player's data
+// Player records are not safe to touch outside of the main loop, so neither is this.
 func SaveTokenUsageToPlayer(userId int) {
-	tokenUsageMutex.RLock()
-	usage, exists := tokenUsage[userId]
-	tokenUsageMutex.RUnlock()
+	tokenUsageMutex.Lock()
+	usage := *loadTokenUsage(userId)
+	tokenUsageMutex.Unlock()
 
-	if !exists || usage == nil {
+	if !usage.loaded {
 		return
 	}
 
-	// Get player
 	player := users.GetByUserId(userId)
 	if player == nil {
-		mudlog.Debug("LLM", "debug", "Cannot save token usage: player not found", "userId", userId)
 		return
 	}
 
@@ -139,26 +157,19 @@ func SaveTokenUsageToPlayer(userId int) {
 }
 
 // RecordTokenUsage records token usage for a user
+// This is safe to call from any goroutine. The totals reach the player record
+// the next time SaveTokenUsageToPlayer() is called.
 func RecordTokenUsage(userId int, model string, inputTokens, outputTokens int) {
 	tokenUsageMutex.Lock()
 	defer tokenUsageMutex.Unlock()
 
-	// Load usage from player data if not already loaded
-	if _, exists := tokenUsage[userId]; !exists {
-		tokenUsageMutex.Unlock()
-		LoadTokenUsageFromPlayer(userId)
-		tokenUsageMutex.Lock()
-	}
-
-	// Create usage record if not exists
-	if _, exists := tokenUsage[userId]; !exists {
-		tokenUsage[userId] = &TokenUsage{
-			LastUsed: time.Now(),
-		}
+	usage, exists := tokenUsage[userId]
+	if !exists {
+		usage = &trackedUsage{}
+		tokenUsage[userId] = usage
 	}
 
 	// Update stats
-	usage := tokenUsage[userId]
 	usage.TotalCalls++
 	usage.InputTokens += inputTokens
 	usage.OutputTokens += outputTokens
@@ -175,33 +186,15 @@ func RecordTokenUsage(userId int, model string, inputTokens, outputTokens int) {
 	// Add more models as needed
 
 	usage.TotalCost += inputCost + outputCost
-
-	// Save updated usage to player data
-	tokenUsageMutex.Unlock()
-	SaveTokenUsageToPlayer(userId)
-	tokenUsageMutex.Lock()
 }
 
-// GetTokenUsage gets token usage for a user
+// GetTokenUsage gets a copy of the token usage for a user
 func GetTokenUsage(userId int) *TokenUsage {
-	tokenUsageMutex.RLock()
-	defer tokenUsageMutex.RUnlock()
-
-	// Load usage from player data if not already loaded
-	if _, exists := tokenUsage[userId]; !exists {
-		tokenUsageMutex.RUnlock()
-		LoadTokenUsageFromPlayer(userId)
-		tokenUsageMutex.RLock()
-	}
-
-	if usage, exists := tokenUsage[userId]; exists {
-		return usage
-	}
+	tokenUsageMutex.Lock()
+	defer tokenUsageMutex.Unlock()
 
-	// Return empty usage if not found
-	return &TokenUsage{
-		LastUsed: time.Time{}, // Zero value
-	}
+	usage := loadTokenUsage(userId).TokenUsage
+	return &usage
 }
 
 // EstimateTokenCount gives a rough estimate of token count based on text length
@@ -214,11 +207,13 @@ func EstimateTokenCount(text string) int {
 // We need to also save token usage when a player logs out or the server shuts down
 func SaveAllTokenUsage() {
 	tokenUsageMutex.RLock()
-	defer tokenUsageMutex.RUnlock()
-
+	userIds := make([]int, 0, len(tokenUsage))
 	for userId := range tokenUsage {
-		tokenUsageMutex.RUnlock()
+		userIds = append(userIds, userId)
+	}
+	tokenUsageMutex.RUnlock()
+
+	for _, userId := range userIds {
 		SaveTokenUsageToPlayer(userId)
-		tokenUsageMutex.RLock()
 	}
 }
@@ -121,6 +121,16 @@ func GetDirectionDeltaNames() []string {
 	return ret
 }
 
+// GetDirectionName finds the map direction that moves by the given amount, ignoring gap directions.
+func GetDirectionName(x, y, z int) (string, bool) {
+	for name, delta := range posDeltas {
+		if delta.x == x && delta.y == y && delta.z == z && !strings.Contains(name, `-gap`) {
+			return name, true
+		}
+	}
+	return ``, false
+}
+
 func IsValidDirection(directionName string) bool {
 	_, ok := posDeltas[directionName]
 	return ok
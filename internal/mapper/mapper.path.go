@@ -4,7 +4,9 @@ import (
 	"container/heap"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/rooms"
@@ -165,8 +167,10 @@ func (r *mapper) findPath(startRoom, goalRoom int) ([]pathStep, error) {
 		}
 
 		// expand neighbors
+		// (in exit name order, so that when paths tie the same one wins every time)
 		node := r.crawledRooms[current.roomId]
-		for exitName, exitInfo := range node.Exits {
+		for _, exitName := range slices.Sorted(maps.Keys(node.Exits)) {
+			exitInfo := node.Exits[exitName]
 			neighbor := exitInfo.RoomId
 			tentativeG := gScore[current.roomId] + 1 // uniform cost
 
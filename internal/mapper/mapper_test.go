@@ -0,0 +1,33 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDirectionName(t *testing.T) {
+
+	tests := []struct {
+		x, y, z  int
+		expected string
+		found    bool
+	}{
+		{0, -1, 0, `north`, true},
+		{1, 1, 0, `southeast`, true},
+		{-2, 0, 0, `west-x2`, true},
+		{3, -3, 0, `northeast-x3`, true},
+		{0, 0, 1, `up`, true},
+		{1, 2, 0, ``, false},
+		{0, 0, 0, ``, false},
+	}
+
+	for _, tt := range tests {
+		// Gap directions share deltas with the regular ones, and must never be picked
+		for range 20 {
+			name, found := GetDirectionName(tt.x, tt.y, tt.z)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, name)
+		}
+	}
+}
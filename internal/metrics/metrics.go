@@ -0,0 +1,446 @@
+// Package metrics keeps counters, gauges and histograms about the running server,
+// and writes them out in the Prometheus text format.
+//
+// Metrics are created once, usually as package variables, and are safe to update from any goroutine:
+//
+//	var eventSeconds = metrics.NewHistogram(`gomud_event_duration_seconds`, `Time spent handling events`, metrics.DurationBuckets, `event`)
+//	...
+//	eventSeconds.ObserveSince(start, e.Type())
+//
+// Values that are cheaper to look up than to track (such as how many rooms are loaded) use NewGaugeFunc,
+// which is only called when the metrics are written.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MetricType string
+
+const (
+	TypeCounter   MetricType = `counter`
+	TypeGauge     MetricType = `gauge`
+	TypeHistogram MetricType = `histogram`
+)
+
+var (
+	// DurationBuckets suit handlers that normally finish within a turn (seconds)
+	DurationBuckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+	// SlowDurationBuckets suit requests to other services (seconds)
+	SlowDurationBuckets = []float64{.1, .25, .5, 1, 2.5, 5, 10, 20, 30, 60}
+
+	registryLock = sync.Mutex{}
+	registry     = map[string]collector{}
+)
+
+type collector interface {
+	family() Family
+}
+
+// Family is a snapshot of one metric and all of its label combinations
+type Family struct {
+	Name    string
+	Help    string
+	Type    MetricType
+	Samples []Sample
+}
+
+type Sample struct {
+	Labels map[string]string `json:",omitempty"`
+	Value  float64           // Counters and gauges
+	// Histograms only
+	Count   uint64            `json:",omitempty"`
+	Sum     float64           `json:",omitempty"`
+	Buckets map[string]uint64 `json:",omitempty"` // Upper bound => cumulative count
+}
+
+// Mean is the average of the values a histogram has observed
+func (s Sample) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// Quantile estimates the value below which q (0-1) of a histogram's observations fall.
+// Like Prometheus, it assumes values are spread evenly within each bucket.
+func (s Sample) Quantile(q float64) float64 {
+
+	if s.Count == 0 {
+		return 0
+	}
+
+	bounds := make([]float64, 0, len(s.Buckets))
+	for bound := range s.Buckets {
+		bounds = append(bounds, parseBound(bound))
+	}
+	sort.Float64s(bounds)
+
+	rank := q * float64(s.Count)
+	lowerBound, lowerCount := 0.0, uint64(0)
+
+	for _, bound := range bounds {
+		count := s.Buckets[formatFloat(bound)]
+		if float64(count) >= rank {
+			// Anything past the last real bucket can only be reported as that bucket
+			if math.IsInf(bound, 1) {
+				return lowerBound
+			}
+			if count == lowerCount {
+				return bound
+			}
+			return lowerBound + (bound-lowerBound)*(rank-float64(lowerCount))/float64(count-lowerCount)
+		}
+		lowerBound, lowerCount = bound, count
+	}
+
+	return lowerBound
+}
+
+// series holds the values of a metric for each combination of label values
+type series struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64 // Histograms only
+	lock       sync.Mutex
+	values     map[string]*seriesValue
+}
+
+type seriesValue struct {
+	labelValues []string
+	value       float64
+	count       uint64
+	sum         float64
+	buckets     []uint64
+}
+
+func newSeries(name string, help string, labelNames []string) *series {
+	return &series{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		values:     map[string]*seriesValue{},
+	}
+}
+
+// get must be called with the lock held
+func (s *series) get(labelValues []string) *seriesValue {
+
+	if len(labelValues) != len(s.labelNames) {
+		panic(fmt.Sprintf(`metrics: %s expects %d label values, got %d`, s.name, len(s.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	v, ok := s.values[key]
+	if !ok {
+		v = &seriesValue{labelValues: append([]string{}, labelValues...)}
+		s.values[key] = v
+	}
+	return v
+}
+
+func (s *series) labels(v *seriesValue) map[string]string {
+	if len(s.labelNames) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(s.labelNames))
+	for i, name := range s.labelNames {
+		labels[name] = v.labelValues[i]
+	}
+	return labels
+}
+
+func register(name string, c collector) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic(`metrics: ` + name + ` is already registered`)
+	}
+	registry[name] = c
+}
+
+//
+// Counters
+//
+
+// Counter only goes up, such as a count of timeouts
+type Counter struct {
+	*series
+}
+
+func NewCounter(name string, help string, labelNames ...string) *Counter {
+	c := &Counter{newSeries(name, help, labelNames)}
+	register(name, c)
+	return c
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(amount float64, labelValues ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.get(labelValues).value += amount
+}
+
+func (c *Counter) family() Family {
+	return c.snapshot(TypeCounter)
+}
+
+//
+// Gauges
+//
+
+// Gauge is a value that can go up and down, such as a queue length
+type Gauge struct {
+	*series
+}
+
+func NewGauge(name string, help string, labelNames ...string) *Gauge {
+	g := &Gauge{newSeries(name, help, labelNames)}
+	register(name, g)
+	return g
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.get(labelValues).value = value
+}
+
+func (g *Gauge) family() Family {
+	return g.snapshot(TypeGauge)
+}
+
+type gaugeFunc struct {
+	name      string
+	help      string
+	labelName string
+	fn        func() map[string]float64
+}
+
+// NewGaugeFunc adds a gauge whose value is looked up each time the metrics are written.
+func NewGaugeFunc(name string, help string, fn func() float64) {
+	register(name, &gaugeFunc{name: name, help: help, fn: func() map[string]float64 {
+		return map[string]float64{``: fn()}
+	}})
+}
+
+// NewGaugeVecFunc is the same as NewGaugeFunc, but fn returns a value for each value of one label.
+func NewGaugeVecFunc(name string, help string, labelName string, fn func() map[string]float64) {
+	register(name, &gaugeFunc{name: name, help: help, labelName: labelName, fn: fn})
+}
+
+func (g *gaugeFunc) family() Family {
+
+	f := Family{Name: g.name, Help: g.help, Type: TypeGauge}
+
+	for labelValue, value := range g.fn() {
+		s := Sample{Value: value}
+		if g.labelName != `` {
+			s.Labels = map[string]string{g.labelName: labelValue}
+		}
+		f.Samples = append(f.Samples, s)
+	}
+
+	return f
+}
+
+//
+// Histograms
+//
+
+// Histogram counts observations (such as how long something took) into buckets
+type Histogram struct {
+	*series
+}
+
+func NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	h := &Histogram{newSeries(name, help, labelNames)}
+	h.buckets = append([]float64{}, buckets...)
+	sort.Float64s(h.buckets)
+	register(name, h)
+	return h
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	v := h.get(labelValues)
+	if v.buckets == nil {
+		v.buckets = make([]uint64, len(h.buckets))
+	}
+
+	v.count++
+	v.sum += value
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			v.buckets[i]++
+		}
+	}
+}
+
+// ObserveSince records the seconds since start
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *Histogram) family() Family {
+	f := h.snapshot(TypeHistogram)
+	for i := range f.Samples {
+		f.Samples[i].Buckets[`+Inf`] = f.Samples[i].Count
+	}
+	return f
+}
+
+func (s *series) snapshot(metricType MetricType) Family {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f := Family{Name: s.name, Help: s.help, Type: metricType}
+
+	for _, v := range s.values {
+		sample := Sample{Labels: s.labels(v), Value: v.value, Count: v.count, Sum: v.sum}
+		if metricType == TypeHistogram {
+			sample.Value = 0
+			sample.Buckets = map[string]uint64{}
+			for i, count := range v.buckets {
+				sample.Buckets[formatFloat(s.buckets[i])] = count
+			}
+		}
+		f.Samples = append(f.Samples, sample)
+	}
+
+	return f
+}
+
+//
+// Output
+//
+
+// Gather returns a snapshot of every metric, sorted by name
+func Gather() []Family {
+
+	registryLock.Lock()
+	collectors := make([]collector, 0, len(registry))
+	for _, c := range registry {
+		collectors = append(collectors, c)
+	}
+	registryLock.Unlock()
+
+	families := make([]Family, 0, len(collectors))
+	for _, c := range collectors {
+		f := c.family()
+		sort.Slice(f.Samples, func(i, j int) bool {
+			return labelString(f.Samples[i].Labels) < labelString(f.Samples[j].Labels)
+		})
+		families = append(families, f)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+
+	return families
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func WriteText(w io.Writer) error {
+
+	sb := strings.Builder{}
+
+	for _, f := range Gather() {
+
+		sb.WriteString(`# HELP ` + f.Name + ` ` + escapeHelp(f.Help) + "\n")
+		sb.WriteString(`# TYPE ` + f.Name + ` ` + string(f.Type) + "\n")
+
+		for _, s := range f.Samples {
+
+			if f.Type != TypeHistogram {
+				sb.WriteString(f.Name + labelString(s.Labels) + ` ` + formatFloat(s.Value) + "\n")
+				continue
+			}
+
+			bounds := make([]string, 0, len(s.Buckets))
+			for bound := range s.Buckets {
+				bounds = append(bounds, bound)
+			}
+			sort.Slice(bounds, func(i, j int) bool {
+				return parseBound(bounds[i]) < parseBound(bounds[j])
+			})
+
+			for _, bound := range bounds {
+				labels := map[string]string{`le`: bound}
+				for k, v := range s.Labels {
+					labels[k] = v
+				}
+				sb.WriteString(f.Name + `_bucket` + labelString(labels) + ` ` + strconv.FormatUint(s.Buckets[bound], 10) + "\n")
+			}
+			sb.WriteString(f.Name + `_sum` + labelString(s.Labels) + ` ` + formatFloat(s.Sum) + "\n")
+			sb.WriteString(f.Name + `_count` + labelString(s.Labels) + ` ` + strconv.FormatUint(s.Count, 10) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func labelString(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ``
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+`="`+escapeLabel(labels[name])+`"`)
+	}
+
+	return `{` + strings.Join(parts, `,`) + `}`
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return `+Inf`
+	case math.IsInf(f, -1):
+		return `-Inf`
+	case math.IsNaN(f):
+		return `NaN`
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func parseBound(s string) float64 {
+	if s == `+Inf` {
+		return math.Inf(1)
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
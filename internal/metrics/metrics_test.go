@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteText(t *testing.T) {
+
+	counter := NewCounter(`test_timeouts_total`, `Scripts that ran too long`, `kind`)
+	counter.Inc(`room`)
+	counter.Add(2, `room`)
+	counter.Inc(`mob "with" quotes`)
+
+	gauge := NewGauge(`test_queue_depth`, `Events waiting`)
+	gauge.Set(7)
+	gauge.Set(4)
+
+	NewGaugeVecFunc(`test_connections`, `Connections by protocol`, `protocol`, func() map[string]float64 {
+		return map[string]float64{`telnet`: 3, `websocket`: 1}
+	})
+
+	hist := NewHistogram(`test_seconds`, `How long things took`, []float64{1, 0.1}, `event`)
+	hist.Observe(0.05, `Input`)
+	hist.Observe(0.5, `Input`)
+	hist.Observe(5, `Input`)
+
+	sb := strings.Builder{}
+	assert.NoError(t, WriteText(&sb))
+	out := sb.String()
+
+	assert.Contains(t, out, "# HELP test_timeouts_total Scripts that ran too long\n# TYPE test_timeouts_total counter\n")
+	assert.Contains(t, out, "test_timeouts_total{kind=\"mob \\\"with\\\" quotes\"} 1\ntest_timeouts_total{kind=\"room\"} 3\n")
+	assert.Contains(t, out, "# TYPE test_queue_depth gauge\ntest_queue_depth 4\n")
+	assert.Contains(t, out, "test_connections{protocol=\"telnet\"} 3\ntest_connections{protocol=\"websocket\"} 1\n")
+
+	// Buckets are cumulative and sorted, with +Inf last
+	assert.Contains(t, out, "# TYPE test_seconds histogram\n"+
+		"test_seconds_bucket{event=\"Input\",le=\"0.1\"} 1\n"+
+		"test_seconds_bucket{event=\"Input\",le=\"1\"} 2\n"+
+		"test_seconds_bucket{event=\"Input\",le=\"+Inf\"} 3\n"+
+		"test_seconds_sum{event=\"Input\"} 5.55\n"+
+		"test_seconds_count{event=\"Input\"} 3\n")
+
+	// Sorted by name
+	assert.Less(t, strings.Index(out, `test_connections`), strings.Index(out, `test_queue_depth`))
+}
+
+func TestLabelCount(t *testing.T) {
+
+	counter := NewCounter(`test_label_count_total`, `Wrong number of labels`, `kind`)
+
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { counter.Inc(`room`, `extra`) })
+	assert.Panics(t, func() { NewCounter(`test_label_count_total`, `Registered twice`) })
+}
+
+func TestQuantile(t *testing.T) {
+
+	hist := NewHistogram(`test_quantile_seconds`, `Quantiles`, []float64{0.1, 1})
+
+	for i := 0; i < 8; i++ {
+		hist.Observe(0.05)
+	}
+	hist.Observe(0.5)
+	hist.Observe(50)
+
+	s := hist.family().Samples[0]
+
+	assert.InDelta(t, 5.09, s.Mean(), 0.0001)
+	assert.InDelta(t, 0.05, s.Quantile(0.4), 0.0001)
+	assert.InDelta(t, 0.1, s.Quantile(0.8), 0.0001)
+	assert.InDelta(t, 0.55, s.Quantile(0.85), 0.0001)
+	// Past the last bucket
+	assert.Equal(t, 1.0, s.Quantile(0.99))
+
+	assert.Equal(t, 0.0, Sample{}.Quantile(0.5))
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"runtime"
+)
+
+// The go runtime is worth watching on any server, so it is always included
+func init() {
+	NewGaugeFunc(`go_goroutines`, `Goroutines that currently exist.`, func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	NewGaugeFunc(`go_memstats_heap_alloc_bytes`, `Bytes of allocated heap objects.`, func() float64 {
+		m := runtime.MemStats{}
+		runtime.ReadMemStats(&m)
+		return float64(m.HeapAlloc)
+	})
+}
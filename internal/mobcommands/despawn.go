@@ -1,6 +1,7 @@
 package mobcommands
 
 import (
+	"github.com/GoMudEngine/GoMud/internal/llm"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
@@ -10,6 +11,9 @@ func Despawn(rest string, mob *mobs.Mob, room *rooms.Room) (bool, error) {
 
 	mudlog.Info("despawn", "mobname", mob.Character.Name, "reason", rest)
 
+	// Abandon anything they were waiting to say
+	llm.CancelMob(mob.InstanceId)
+
 	// Destroy any record of this mob.
 	mobs.DestroyInstance(mob.InstanceId)
 
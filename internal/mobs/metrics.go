@@ -0,0 +1,14 @@
+package mobs
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+)
+
+func init() {
+	metrics.NewGaugeFunc(`gomud_mob_instances`, `Mobs spawned in the world.`, func() float64 {
+		mobInstancesMutex.RLock()
+		defer mobInstancesMutex.RUnlock()
+
+		return float64(len(mobInstances))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -269,6 +270,8 @@ func GetAllMobInstanceIds() []int {
 	for id := range mobInstances {
 		ids = append(ids, id)
 	}
+	// Always the same order, so that a seeded run plays out the same way
+	slices.Sort(ids)
 	return ids
 }
 
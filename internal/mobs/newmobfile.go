@@ -2,9 +2,12 @@ package mobs
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/fileloader"
 	"github.com/GoMudEngine/GoMud/internal/util"
@@ -69,6 +72,76 @@ func CreateNewMobFile(newMobInfo Mob, copyScript string) (MobId, error) {
 	return newMobInfo.MobId, nil
 }
 
+// SaveMobFile overwrites an existing mob spec.
+// The file keeps the name it was loaded with, but moves (with its script) if the zone changed.
+func SaveMobFile(mobInfo Mob) error {
+
+	oldMobInfo := GetMobSpec(mobInfo.MobId)
+	if oldMobInfo == nil {
+		return fmt.Errorf(`mob %d does not exist`, mobInfo.MobId)
+	}
+
+	if err := mobInfo.Validate(); err != nil {
+		return err
+	}
+
+	saveModes := []fileloader.SaveOption{}
+
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	basePath := configs.GetFilePathsConfig().DataFiles.String() + `/mobs`
+
+	if err := fileloader.SaveFlatFile[*Mob](basePath, &mobInfo, saveModes...); err != nil {
+		return err
+	}
+
+	if oldMobInfo.Filepath() != mobInfo.Filepath() {
+		if err := fileloader.MoveFile(oldMobInfo.GetScriptPath(), mobInfo.GetScriptPath()); err != nil {
+			return err
+		}
+		if err := fileloader.DeleteFlatFile[*Mob](basePath, oldMobInfo); err != nil {
+			return err
+		}
+	}
+
+	// Update the in-memory cache
+	if oldMobInfo.Character.Name != mobInfo.Character.Name {
+		if idx := slices.Index(allMobNames, oldMobInfo.Character.Name); idx >= 0 {
+			allMobNames[idx] = mobInfo.Character.Name
+		}
+	}
+	mobs[mobInfo.Id()] = &mobInfo
+
+	return nil
+}
+
+// DeleteMobFile removes a mob spec and its script.
+// Mobs already spawned from this spec are left alone.
+func DeleteMobFile(mobId MobId) error {
+
+	mobInfo := GetMobSpec(mobId)
+	if mobInfo == nil {
+		return fmt.Errorf(`mob %d does not exist`, mobId)
+	}
+
+	if err := fileloader.DeleteFlatFile[*Mob](configs.GetFilePathsConfig().DataFiles.String()+`/mobs`, mobInfo); err != nil {
+		return err
+	}
+
+	changelog.Touch(mobInfo.GetScriptPath())
+	os.Remove(mobInfo.GetScriptPath())
+
+	if idx := slices.Index(allMobNames, mobInfo.Character.Name); idx >= 0 {
+		allMobNames = slices.Delete(allMobNames, idx, idx+1)
+	}
+	delete(mobNameCache, mobId)
+	delete(mobs, int(mobId))
+
+	return nil
+}
+
 func getNextMobId() MobId {
 
 	lowestFreeId := MobId(0)
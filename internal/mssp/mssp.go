@@ -0,0 +1,150 @@
+package mssp
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/items"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+//
+// MSSP (Mud Server Status Protocol)
+// Lets MUD listing crawlers ask how big and busy the server is.
+// The world is only safe to count while the mud is locked, so Update()
+// takes a snapshot from the game loop and crawlers are answered from that.
+//
+
+type worldCounts struct {
+	Players int
+	Areas   int
+	Rooms   int
+	Mobiles int
+	Objects int
+}
+
+var (
+	lock      = sync.RWMutex{}
+	startTime = time.Now()
+	codebase  = `GoMud`
+	world     = worldCounts{}
+)
+
+// SetServer sets the CODEBASE reported, such as "GoMud 1.0.0", and when the server started
+func SetServer(name string, started time.Time) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	codebase = name
+	startTime = started
+}
+
+// Update counts the players and world. The mud must be locked while it runs.
+func Update() {
+
+	counts := worldCounts{
+		Players: len(users.GetOnlineUserIds()),
+		Areas:   len(rooms.GetAllZoneNames()),
+		Rooms:   len(rooms.GetAllRoomIds()),
+		Mobiles: len(mobs.GetAllMobInfo()),
+		Objects: len(items.GetAllItemSpecs()),
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	world = counts
+}
+
+// Status returns the variables to report, in the order they should be sent.
+// Anything set in the Server.MSSP config replaces the value worked out here, empty values are left out.
+func Status() []term.MsspVar {
+
+	lock.RLock()
+	counts := world
+	started := startTime
+	cb := codebase
+	lock.RUnlock()
+
+	c := configs.GetConfig()
+
+	boolVal := func(b bool) string {
+		if b {
+			return `1`
+		}
+		return `0`
+	}
+
+	ports := []string{}
+	for _, p := range c.Network.TelnetPort {
+		if _, err := strconv.Atoi(p); err == nil {
+			ports = append(ports, p)
+		}
+	}
+
+	tlsPorts := []string{}
+	for _, p := range c.Network.TelnetTLSPort {
+		if _, err := strconv.Atoi(p); err == nil {
+			tlsPorts = append(tlsPorts, p)
+		}
+	}
+
+	connected, disconnected := connections.Stats()
+
+	vars := []term.MsspVar{
+		{Name: `NAME`, Values: []string{string(c.Server.MudName)}},
+		{Name: `PLAYERS`, Values: []string{strconv.Itoa(counts.Players)}},
+		{Name: `UPTIME`, Values: []string{strconv.FormatInt(started.Unix(), 10)}},
+		{Name: `CODEBASE`, Values: []string{cb}},
+		{Name: `PORT`, Values: ports},
+		{Name: `CONNECTIONS`, Values: []string{strconv.FormatUint(connected-disconnected, 10)}},
+		{Name: `AREAS`, Values: []string{strconv.Itoa(counts.Areas)}},
+		{Name: `ROOMS`, Values: []string{strconv.Itoa(counts.Rooms)}},
+		{Name: `MOBILES`, Values: []string{strconv.Itoa(counts.Mobiles)}},
+		{Name: `OBJECTS`, Values: []string{strconv.Itoa(counts.Objects)}},
+		{Name: `ANSI`, Values: []string{`1`}},
+		{Name: `UTF-8`, Values: []string{`1`}},
+		{Name: `XTERM 256 COLORS`, Values: []string{`1`}},
+		{Name: `MSP`, Values: []string{`1`}},
+		{Name: `MCCP`, Values: []string{boolVal(bool(c.Network.MCCP2))}},
+	}
+
+	if len(tlsPorts) > 0 {
+		vars = append(vars, term.MsspVar{Name: `SSL`, Values: tlsPorts})
+	}
+
+	if len(c.Server.MSSP) == 0 {
+		return vars
+	}
+
+	extraNames := []string{}
+	for name := range c.Server.MSSP {
+		extraNames = append(extraNames, name)
+	}
+	slices.Sort(extraNames)
+
+	for _, name := range extraNames {
+
+		value := string(c.Server.MSSP[name])
+		if value == `` {
+			continue
+		}
+
+		idx := slices.IndexFunc(vars, func(v term.MsspVar) bool { return v.Name == name })
+		if idx == -1 {
+			vars = append(vars, term.MsspVar{Name: name, Values: []string{value}})
+			continue
+		}
+
+		vars[idx].Values = []string{value}
+	}
+
+	return vars
+}
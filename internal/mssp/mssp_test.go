@@ -0,0 +1,107 @@
+package mssp
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	mudlog.SetupLogger(nil, `LOW`, ``, false)
+	os.Exit(m.Run())
+}
+
+func findVar(vars []term.MsspVar, name string) (term.MsspVar, bool) {
+	for _, v := range vars {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return term.MsspVar{}, false
+}
+
+func TestStatus(t *testing.T) {
+
+	require.NoError(t, configs.AddOverlayOverrides(map[string]any{
+		"Server.MudName":     "Test Mud",
+		"Network.TelnetPort": []string{"33333", "44444"},
+		"Network.MCCP2":      true,
+		"Server.MSSP": map[string]any{
+			"CONTACT": "admin@example.com",
+			"ROOMS":   "5000",
+			"WEBSITE": "",
+		},
+	}))
+	t.Cleanup(func() {
+		configs.AddOverlayOverrides(map[string]any{
+			"Server.MSSP": map[string]any{"CONTACT": "", "ROOMS": "", "WEBSITE": ""},
+		})
+	})
+
+	started := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	SetServer(`GoMud 1.0.0`, started)
+
+	lock.Lock()
+	world = worldCounts{Players: 3, Areas: 2, Rooms: 100, Mobiles: 10, Objects: 20}
+	lock.Unlock()
+
+	vars := Status()
+
+	// The standard variables come first, in a fixed order
+	assert.Equal(t, `NAME`, vars[0].Name)
+	assert.Equal(t, []string{`Test Mud`}, vars[0].Values)
+
+	tests := map[string][]string{
+		`PLAYERS`:  {`3`},
+		`UPTIME`:   {`1748779200`},
+		`CODEBASE`: {`GoMud 1.0.0`},
+		`PORT`:     {`33333`, `44444`},
+		`AREAS`:    {`2`},
+		`ROOMS`:    {`5000`}, // Replaced by config
+		`MOBILES`:  {`10`},
+		`OBJECTS`:  {`20`},
+		`MCCP`:     {`1`},
+		`CONTACT`:  {`admin@example.com`},
+	}
+
+	for name, expected := range tests {
+		v, ok := findVar(vars, name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, expected, v.Values, name)
+		}
+	}
+
+	_, ok := findVar(vars, `WEBSITE`)
+	assert.False(t, ok, `empty values are left out`)
+}
+
+func TestEncoding(t *testing.T) {
+
+	vars := []term.MsspVar{
+		{Name: `NAME`, Values: []string{`Test Mud`}},
+		{Name: `PORT`, Values: []string{`33333`, `44444`}},
+		{Name: `CONTACT`, Values: []string{"bad\xff\x01\x02name"}},
+	}
+
+	payload := term.MsspPayload(vars)
+	assert.Equal(t,
+		"\x01NAME\x02Test Mud\x01PORT\x0233333\x0244444\x01CONTACT\x02badname",
+		string(payload),
+	)
+
+	sb := term.MsspStatus.BytesWithPayload(payload)
+	assert.Equal(t, []byte{term.TELNET_IAC, term.TELNET_SB, term.MSSP}, sb[:3])
+	assert.Equal(t, []byte{term.TELNET_IAC, term.TELNET_SE}, sb[len(sb)-2:])
+
+	vars[2].Values = []string{"line\r\nbreak"}
+	assert.Equal(t,
+		"\r\nMSSP-REPLY-START\r\nNAME\tTest Mud\r\nPORT\t33333\t44444\r\nCONTACT\tline  break\r\nMSSP-REPLY-END\r\n",
+		string(term.MsspPlainText(vars)),
+	)
+}
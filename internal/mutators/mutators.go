@@ -345,6 +345,13 @@ func (m *MutatorSpec) Id() string {
 
 func (m *MutatorSpec) Validate() error {
 
+	// A modifier that changes nothing is the same as no modifier
+	for _, mod := range []**TextModifier{&m.NameModifier, &m.DescriptionModifier, &m.AlertModifier} {
+		if *mod != nil && (*mod).Text == `` && (*mod).ColorPattern == `` {
+			*mod = nil
+		}
+	}
+
 	if m.NameModifier != nil && !m.NameModifier.Behavior.IsValid() {
 		m.NameModifier.Behavior = TextDefault
 	}
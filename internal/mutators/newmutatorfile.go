@@ -0,0 +1,66 @@
+package mutators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/fileloader"
+)
+
+// CreateNewMutatorFile saves a new mutator spec. Mutators are keyed by name, so the id must be new.
+func CreateNewMutatorFile(newMutatorInfo MutatorSpec) (string, error) {
+
+	newMutatorInfo.MutatorId = strings.TrimSpace(newMutatorInfo.MutatorId)
+	if newMutatorInfo.MutatorId == `` {
+		return ``, errors.New(`mutator id cannot be empty`)
+	}
+
+	if GetMutatorSpec(newMutatorInfo.MutatorId) != nil {
+		return ``, fmt.Errorf(`mutator %s already exists`, newMutatorInfo.MutatorId)
+	}
+
+	if err := newMutatorInfo.Validate(); err != nil {
+		return ``, err
+	}
+
+	if err := saveMutatorFile(&newMutatorInfo); err != nil {
+		return ``, err
+	}
+
+	allMutators[newMutatorInfo.Id()] = &newMutatorInfo
+
+	return newMutatorInfo.Id(), nil
+}
+
+// SaveMutatorFile overwrites an existing mutator spec.
+// Rooms pick up the change the next time they check their mutators.
+func SaveMutatorFile(mutatorInfo MutatorSpec) error {
+
+	if GetMutatorSpec(mutatorInfo.MutatorId) == nil {
+		return fmt.Errorf(`mutator %s does not exist`, mutatorInfo.MutatorId)
+	}
+
+	if err := mutatorInfo.Validate(); err != nil {
+		return err
+	}
+
+	if err := saveMutatorFile(&mutatorInfo); err != nil {
+		return err
+	}
+
+	allMutators[mutatorInfo.Id()] = &mutatorInfo
+
+	return nil
+}
+
+func saveMutatorFile(mutatorInfo *MutatorSpec) error {
+
+	saveModes := []fileloader.SaveOption{}
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	return fileloader.SaveFlatFile[*MutatorSpec](configs.GetFilePathsConfig().DataFiles.String()+`/mutators`, mutatorInfo, saveModes...)
+}
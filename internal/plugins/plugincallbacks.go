@@ -16,6 +16,9 @@ type PluginCallbacks struct {
 	onLoad       func()
 	onSave       func()
 	onNetConnect func(NetConnection)
+
+	onCopyoverSave    func(uint64) []byte
+	onCopyoverRestore func(uint64, []byte)
 }
 
 func newPluginCallbacks() PluginCallbacks {
@@ -51,3 +54,13 @@ func (c *PluginCallbacks) SetOnSave(f func()) {
 func (c *PluginCallbacks) SetOnNetConnect(f func(NetConnection)) {
 	c.onNetConnect = f
 }
+
+// SetOnCopyoverSave is called for each connection carried over by a copyover, and returns whatever the plugin needs to remember about it
+func (c *PluginCallbacks) SetOnCopyoverSave(f func(connectionId uint64) []byte) {
+	c.onCopyoverSave = f
+}
+
+// SetOnCopyoverRestore is given back what SetOnCopyoverSave returned, once the connection is reattached after the copyover
+func (c *PluginCallbacks) SetOnCopyoverRestore(f func(connectionId uint64, data []byte)) {
+	c.onCopyoverRestore = f
+}
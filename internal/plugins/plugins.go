@@ -2,11 +2,11 @@ package plugins
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
 	"maps"
 	"net/http"
-	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -16,6 +16,7 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/mobcommands"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/scripting"
+	"github.com/GoMudEngine/GoMud/internal/storage"
 	"github.com/GoMudEngine/GoMud/internal/usercommands"
 	"github.com/GoMudEngine/GoMud/internal/util"
 	"gopkg.in/yaml.v2"
@@ -32,7 +33,6 @@ var (
 	registrationOpen = true
 	registry         = pluginRegistry{}
 	txtCleanRegex    = regexp.MustCompile(`[^a-zA-Z0-9\._]+`)
-	writeFolderPath  = os.TempDir()
 )
 
 const (
@@ -331,24 +331,8 @@ func (p *Plugin) AttachFileSystem(f embed.FS) error {
 
 func (p *Plugin) WriteBytes(identifier string, bytes []byte) error {
 
-	// Fix up identifier
-	fileName := strings.ToLower(txtCleanRegex.ReplaceAllString(identifier, "-")) + `.plugin.dat`
-
-	// Fix up folderpath
-	folderPath := util.FilePath(writeFolderPath, `/`, strings.ToLower(txtCleanRegex.ReplaceAllString(fmt.Sprintf(`%s-v%s`, p.name, p.version), "-")))
-
-	// Create full path
-	fullPath := util.FilePath(folderPath, `/`, fileName)
-
-	if _, err := os.Stat(fullPath); err != nil {
-		if err = os.MkdirAll(folderPath, 0777); err != nil {
-			mudlog.Error(`plugin.WriteBytes`, `name`, p.name, `path`, folderPath, `error`, err)
-			return err
-		}
-	}
-
-	if err := os.WriteFile(fullPath, bytes, 0777); err != nil {
-		mudlog.Error(`plugin.WriteBytes`, `name`, p.name, `path`, fullPath, `error`, err)
+	if err := storage.Put(p.storageCollection(), storage.Record{Key: storageKey(identifier), Data: bytes}); err != nil {
+		mudlog.Error(`plugin.WriteBytes`, `name`, p.name, `identifier`, identifier, `error`, err)
 		return err
 	}
 
@@ -357,23 +341,23 @@ func (p *Plugin) WriteBytes(identifier string, bytes []byte) error {
 
 func (p *Plugin) ReadBytes(identifier string) ([]byte, error) {
 
-	// Fix up identifier
-	fileName := strings.ToLower(txtCleanRegex.ReplaceAllString(identifier, "-")) + `.plugin.dat`
-
-	// Fix up folderpath
-	folderPath := util.FilePath(writeFolderPath, `/`, strings.ToLower(txtCleanRegex.ReplaceAllString(fmt.Sprintf(`%s-v%s`, p.name, p.version), "-")))
-
-	// Create full path
-	fullPath := util.FilePath(folderPath, `/`, fileName)
-
-	bytes, err := os.ReadFile(fullPath)
-	if err != nil && !os.IsNotExist(err) {
-		mudlog.Warn(`plugin.ReadBytes`, `name`, p.name, `path`, fullPath, `error`, err)
+	bytes, err := storage.Get(p.storageCollection(), storageKey(identifier))
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		mudlog.Warn(`plugin.ReadBytes`, `name`, p.name, `identifier`, identifier, `error`, err)
 	}
 
 	return bytes, err
 }
 
+// Each version of a plugin keeps its data separately, such as "plugin-data/leaderboards-v1-0"
+func (p *Plugin) storageCollection() string {
+	return storage.PluginData + `/` + strings.ToLower(txtCleanRegex.ReplaceAllString(fmt.Sprintf(`%s-v%s`, p.name, p.version), "-"))
+}
+
+func storageKey(identifier string) string {
+	return strings.ToLower(txtCleanRegex.ReplaceAllString(identifier, "-"))
+}
+
 func (p *Plugin) WriteStruct(identifier string, in any) error {
 
 	b, err := yaml.Marshal(in)
@@ -403,8 +387,6 @@ func (p *Plugin) ReadIntoStruct(identifier string, out any) error {
 
 func Load(dataFilesPath string) {
 
-	writeFolderPath = util.FilePath(dataFilesPath, `/`, `plugin-data`)
-
 	registrationOpen = false
 
 	pluginCt := 0
@@ -509,6 +491,36 @@ func OnNetConnect(n NetConnection) {
 
 }
 
+// CopyoverSave collects what each plugin knows about a connection, keyed by plugin name
+func CopyoverSave(connectionId uint64) map[string][]byte {
+
+	saved := map[string][]byte{}
+
+	for _, p := range registry {
+		if p.Callbacks.onCopyoverSave != nil {
+			if data := p.Callbacks.onCopyoverSave(connectionId); data != nil {
+				saved[p.name] = data
+			}
+		}
+	}
+
+	return saved
+}
+
+// CopyoverRestore hands each plugin back what it saved about a connection before the copyover
+func CopyoverRestore(connectionId uint64, saved map[string][]byte) {
+
+	for _, p := range registry {
+		if p.Callbacks.onCopyoverRestore == nil {
+			continue
+		}
+		if data, ok := saved[p.name]; ok {
+			p.Callbacks.onCopyoverRestore(connectionId, data)
+		}
+	}
+
+}
+
 func ReadFile(dfPath string) ([]byte, error) {
 	return registry.ReadFile(dfPath)
 }
@@ -29,6 +29,10 @@ if cmdPrompt := prompt.Get(userId); cmdPrompt != nil {
 
 */
 
+const (
+	MaskReply = 1 << iota // The reply is a secret (a password), and shouldn't be kept anywhere
+)
+
 type Question struct {
 	Question        string   // What's the prompt?
 	Options         []string // What options (if any) are available? None = freeform
@@ -91,6 +95,13 @@ func (p *Prompt) Ask(question string, responseOptions []string, defaultOption ..
 	return q
 }
 
+// Same as Ask(), but for a reply that shouldn't be kept anywhere, such as a password.
+func (p *Prompt) AskSecret(question string) *Question {
+	q := p.Ask(question, []string{})
+	q.Flags |= MaskReply
+	return q
+}
+
 // Returns the next pending question.
 func (p *Prompt) GetNextQuestion() *Question {
 
@@ -115,6 +126,10 @@ func (p *Prompt) Recall(name string) (any, bool) {
 	return nil, false
 }
 
+func (q *Question) IsMasked() bool {
+	return q.Flags&MaskReply == MaskReply
+}
+
 func (q *Question) Reset() {
 	q.Done = false
 }
@@ -44,6 +44,28 @@ func TestAsk(t *testing.T) {
 	}
 }
 
+// TestAskSecret ensures that secret questions are masked, and asking again doesn't add another
+func TestAskSecret(t *testing.T) {
+	p := New("testCommand", "testRest")
+
+	if q := p.Ask("What's your name?", []string{}); q.IsMasked() {
+		t.Errorf("Expected an ordinary question not to be masked")
+	}
+
+	q := p.AskSecret("What's your password?")
+	if !q.IsMasked() {
+		t.Errorf("Expected a secret question to be masked")
+	}
+
+	if p.AskSecret("What's your password?") != q {
+		t.Errorf("Expected asking again to return the same question")
+	}
+
+	if len(p.Questions) != 2 {
+		t.Errorf("Expected Questions length to be 2, got %d", len(p.Questions))
+	}
+}
+
 // TestGetNextQuestion ensures that the next pending question is returned correctly
 func TestGetNextQuestion(t *testing.T) {
 	p := New("testCommand", "testRest")
@@ -0,0 +1,93 @@
+package quests
+
+import (
+	"fmt"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/fileloader"
+)
+
+func CreateNewQuestFile(newQuestInfo Quest) (int, error) {
+
+	newQuestInfo.QuestId = getNextQuestId()
+
+	if err := newQuestInfo.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := saveQuestFile(&newQuestInfo); err != nil {
+		return 0, err
+	}
+
+	// Save to in-memory cache
+	quests[newQuestInfo.Id()] = &newQuestInfo
+
+	return newQuestInfo.Id(), nil
+}
+
+// SaveQuestFile overwrites an existing quest, removing the old file if the name changed.
+func SaveQuestFile(questInfo Quest) error {
+
+	oldQuestInfo, ok := quests[questInfo.QuestId]
+	if !ok {
+		return fmt.Errorf(`quest %d does not exist`, questInfo.QuestId)
+	}
+
+	if err := questInfo.Validate(); err != nil {
+		return err
+	}
+
+	if err := saveQuestFile(&questInfo); err != nil {
+		return err
+	}
+
+	if oldQuestInfo.Filepath() != questInfo.Filepath() {
+		if err := fileloader.DeleteFlatFile[*Quest](configs.GetFilePathsConfig().DataFiles.String()+`/quests`, oldQuestInfo); err != nil {
+			return err
+		}
+	}
+
+	quests[questInfo.Id()] = &questInfo
+
+	return nil
+}
+
+// DeleteQuestFile removes a quest.
+// Players partway through it keep their progress tokens, which are ignored once the quest is gone.
+func DeleteQuestFile(questId int) error {
+
+	questInfo, ok := quests[questId]
+	if !ok {
+		return fmt.Errorf(`quest %d does not exist`, questId)
+	}
+
+	if err := fileloader.DeleteFlatFile[*Quest](configs.GetFilePathsConfig().DataFiles.String()+`/quests`, questInfo); err != nil {
+		return err
+	}
+
+	delete(quests, questId)
+
+	return nil
+}
+
+func saveQuestFile(questInfo *Quest) error {
+
+	saveModes := []fileloader.SaveOption{}
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	return fileloader.SaveFlatFile[*Quest](configs.GetFilePathsConfig().DataFiles.String()+`/quests`, questInfo, saveModes...)
+}
+
+func getNextQuestId() int {
+
+	lowestFreeId := 1
+	for _, q := range quests {
+		if q.QuestId >= lowestFreeId {
+			lowestFreeId = q.QuestId + 1
+		}
+	}
+
+	return lowestFreeId
+}
@@ -0,0 +1,93 @@
+package races
+
+import (
+	"fmt"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/fileloader"
+)
+
+func CreateNewRaceFile(newRaceInfo Race) (int, error) {
+
+	newRaceInfo.RaceId = getNextRaceId()
+
+	if err := newRaceInfo.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := saveRaceFile(&newRaceInfo); err != nil {
+		return 0, err
+	}
+
+	// Save to in-memory cache
+	races[newRaceInfo.Id()] = &newRaceInfo
+
+	return newRaceInfo.Id(), nil
+}
+
+// SaveRaceFile overwrites an existing race, removing the old file if the name changed.
+func SaveRaceFile(raceInfo Race) error {
+
+	oldRaceInfo := GetRace(raceInfo.RaceId)
+	if oldRaceInfo == nil {
+		return fmt.Errorf(`race %d does not exist`, raceInfo.RaceId)
+	}
+
+	if err := raceInfo.Validate(); err != nil {
+		return err
+	}
+
+	if err := saveRaceFile(&raceInfo); err != nil {
+		return err
+	}
+
+	if oldRaceInfo.Filepath() != raceInfo.Filepath() {
+		if err := fileloader.DeleteFlatFile[*Race](configs.GetFilePathsConfig().DataFiles.String()+`/races`, oldRaceInfo); err != nil {
+			return err
+		}
+	}
+
+	races[raceInfo.Id()] = &raceInfo
+
+	return nil
+}
+
+// DeleteRaceFile removes a race.
+// Characters and mobs of this race keep their RaceId, so only remove races nothing uses.
+func DeleteRaceFile(raceId int) error {
+
+	raceInfo := GetRace(raceId)
+	if raceInfo == nil {
+		return fmt.Errorf(`race %d does not exist`, raceId)
+	}
+
+	if err := fileloader.DeleteFlatFile[*Race](configs.GetFilePathsConfig().DataFiles.String()+`/races`, raceInfo); err != nil {
+		return err
+	}
+
+	delete(races, raceId)
+
+	return nil
+}
+
+func saveRaceFile(raceInfo *Race) error {
+
+	saveModes := []fileloader.SaveOption{}
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	return fileloader.SaveFlatFile[*Race](configs.GetFilePathsConfig().DataFiles.String()+`/races`, raceInfo, saveModes...)
+}
+
+func getNextRaceId() int {
+
+	lowestFreeId := 1
+	for _, r := range races {
+		if r.RaceId >= lowestFreeId {
+			lowestFreeId = r.RaceId + 1
+		}
+	}
+
+	return lowestFreeId
+}
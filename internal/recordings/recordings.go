@@ -0,0 +1,511 @@
+package recordings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+//
+// Session recordings, for when a player reports a bug or harassment.
+// Nothing is recorded unless an admin starts it. Everything the connection
+// sends and receives is written in asciicast v2 format
+// (https://docs.asciinema.org/manual/asciicast/v2/), which the web admin plays back.
+//
+
+const (
+	FileExtension = `.cast`
+)
+
+var (
+	ErrAlreadyRecording = errors.New("already being recorded")
+	ErrInvalidName      = errors.New("invalid recording name")
+
+	lock   = sync.Mutex{}
+	active = map[connections.ConnectionId]*Recording{}
+
+	// Files currently being written, which are never deleted to make room
+	filesLock = sync.Mutex{}
+	openFiles = map[string]struct{}{}
+
+	unsafeNameChars = regexp.MustCompile(`[^a-z0-9_]`)
+)
+
+// The first line of every asciicast file
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recording writes one connection's session to disk.
+// Once a file reaches the size limit the recording continues in a new file.
+type Recording struct {
+	Username     string
+	ConnectionId connections.ConnectionId
+	Started      time.Time
+
+	lock      sync.Mutex
+	conn      *connections.ConnectionDetails
+	baseName  string
+	part      int
+	fileName  string
+	file      *os.File
+	fileStart time.Time
+	size      int64
+	maxBytes  int64
+	width     int
+	height    int
+	terminal  string
+	closed    bool
+}
+
+// FileInfo describes a recording file on disk
+type FileInfo struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+	Active   bool // Still being written to
+}
+
+// Folder is where recordings are saved
+func Folder() string {
+	return util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `recordings`)
+}
+
+// Start begins recording a connection
+func Start(cd *connections.ConnectionDetails, username string) (*Recording, error) {
+
+	if cd == nil {
+		return nil, errors.New("connection not found")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if r, ok := active[cd.ConnectionId()]; ok && !r.isClosed() {
+		return nil, ErrAlreadyRecording
+	}
+
+	c := configs.GetSecurityConfig()
+	cs := connections.GetClientSettings(cd.ConnectionId())
+
+	terminal := strings.ToLower(cs.Terminal.TerminalType)
+	if terminal == `` {
+		terminal = `xterm-256color`
+	}
+
+	now := util.Now()
+
+	safeName := unsafeNameChars.ReplaceAllString(strings.ToLower(username), ``)
+	if safeName == `` {
+		safeName = `unknown`
+	}
+
+	r := &Recording{
+		Username:     username,
+		ConnectionId: cd.ConnectionId(),
+		Started:      now,
+		conn:         cd,
+		baseName:     fmt.Sprintf(`%s-%s-%d`, safeName, now.Format(`20060102-150405`), cd.ConnectionId()),
+		maxBytes:     int64(c.RecordingMaxKB) * 1024,
+		width:        cs.Display.GetScreenWidth(),
+		height:       cs.Display.GetScreenHeight(),
+		terminal:     terminal,
+	}
+
+	if err := os.MkdirAll(Folder(), 0755); err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	err := r.openFile()
+	r.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	active[cd.ConnectionId()] = r
+	cd.StartRecording(r)
+
+	pruneFiles()
+
+	mudlog.Info("Recording", "action", "started", "username", username, "connectionId", cd.ConnectionId(), "file", r.FileName())
+
+	return r, nil
+}
+
+// Stop ends the recording of a connection, returning false if it wasn't being recorded
+func Stop(connectionId connections.ConnectionId) bool {
+
+	lock.Lock()
+	r, ok := active[connectionId]
+	delete(active, connectionId)
+	lock.Unlock()
+
+	if !ok || r.isClosed() {
+		return false
+	}
+
+	r.conn.StopRecording()
+	r.Close()
+
+	return true
+}
+
+// Active returns the recordings still running
+func Active() []*Recording {
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	running := []*Recording{}
+	for id, r := range active {
+		if r.isClosed() {
+			delete(active, id)
+			continue
+		}
+		running = append(running, r)
+	}
+
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].Started.Before(running[j].Started)
+	})
+
+	return running
+}
+
+// List returns every recording file on disk, newest first
+func List() []FileInfo {
+
+	entries, err := os.ReadDir(Folder())
+	if err != nil {
+		return []FileInfo{}
+	}
+
+	filesLock.Lock()
+	defer filesLock.Unlock()
+
+	files := []FileInfo{}
+	for _, entry := range entries {
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), FileExtension) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		_, isOpen := openFiles[entry.Name()]
+
+		files = append(files, FileInfo{
+			Name:     entry.Name(),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			Active:   isOpen,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].Modified.Equal(files[j].Modified) {
+			return files[i].Modified.After(files[j].Modified)
+		}
+		// Parts of the same recording can be written within the same instant
+		baseI, partI := splitPart(files[i].Name)
+		baseJ, partJ := splitPart(files[j].Name)
+		if baseI == baseJ {
+			return partI > partJ
+		}
+		return files[i].Name > files[j].Name
+	})
+
+	return files
+}
+
+// splitPart turns "name-part3.cast" into "name", 3
+func splitPart(fileName string) (string, int) {
+
+	base := strings.TrimSuffix(fileName, FileExtension)
+
+	idx := strings.LastIndex(base, `-part`)
+	if idx == -1 {
+		return base, 1
+	}
+
+	part, err := strconv.Atoi(base[idx+len(`-part`):])
+	if err != nil {
+		return base, 1
+	}
+
+	return base[:idx], part
+}
+
+// FilePath returns the full path of a recording file, making sure the name can't point anywhere else
+func FilePath(name string) (string, error) {
+
+	if name == `` || name != filepath.Base(name) || strings.HasPrefix(name, `.`) || !strings.HasSuffix(name, FileExtension) {
+		return ``, ErrInvalidName
+	}
+
+	path := util.FilePath(Folder(), `/`, name)
+	if _, err := os.Stat(path); err != nil {
+		return ``, err
+	}
+
+	return path, nil
+}
+
+// pruneFiles deletes the oldest recordings once there are too many
+func pruneFiles() {
+
+	maxFiles := int(configs.GetSecurityConfig().RecordingMaxFiles)
+
+	files := List()
+	if len(files) <= maxFiles {
+		return
+	}
+
+	// Oldest first
+	slices.Reverse(files)
+
+	for _, f := range files[:len(files)-maxFiles] {
+		if f.Active {
+			continue
+		}
+		if err := os.Remove(util.FilePath(Folder(), `/`, f.Name)); err != nil {
+			mudlog.Error("Recording", "error", "Could not remove old recording: "+err.Error(), "file", f.Name)
+		}
+	}
+}
+
+// FileName is the file currently being written
+func (r *Recording) FileName() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.fileName
+}
+
+func (r *Recording) isClosed() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.closed
+}
+
+// openFile starts a new file and writes the header, and expects r.lock to already be held
+func (r *Recording) openFile() error {
+
+	r.part++
+
+	r.fileName = r.baseName + FileExtension
+	if r.part > 1 {
+		r.fileName = fmt.Sprintf(`%s-part%d%s`, r.baseName, r.part, FileExtension)
+	}
+
+	f, err := os.OpenFile(util.FilePath(Folder(), `/`, r.fileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	r.file = f
+	r.fileStart = util.Now()
+	r.size = 0
+
+	filesLock.Lock()
+	openFiles[r.fileName] = struct{}{}
+	filesLock.Unlock()
+
+	title := fmt.Sprintf(`%s (connection %d)`, r.Username, r.ConnectionId)
+	if r.part > 1 {
+		title += fmt.Sprintf(` part %d`, r.part)
+	}
+
+	h, _ := json.Marshal(header{
+		Version:   2,
+		Width:     r.width,
+		Height:    r.height,
+		Timestamp: r.fileStart.Unix(),
+		Title:     title,
+		Env:       map[string]string{`TERM`: r.terminal},
+	})
+
+	n, err := r.file.Write(append(h, '\n'))
+	r.size += int64(n)
+
+	return err
+}
+
+// closeFile expects r.lock to already be held
+func (r *Recording) closeFile() {
+
+	if r.file == nil {
+		return
+	}
+
+	r.file.Close()
+	r.file = nil
+
+	filesLock.Lock()
+	delete(openFiles, r.fileName)
+	filesLock.Unlock()
+}
+
+func (r *Recording) RecordOutput(p []byte) {
+	r.record(`o`, p, false)
+}
+
+func (r *Recording) RecordInput(p []byte, masked bool) {
+	r.record(`i`, p, masked)
+}
+
+func (r *Recording) record(eventType string, p []byte, masked bool) {
+
+	// Web client control messages such as !!SOUND(...) aren't terminal output
+	if bytes.HasPrefix(p, []byte("!!")) {
+		return
+	}
+
+	p = StripTelnet(p)
+	if len(p) == 0 {
+		return
+	}
+
+	if masked {
+		p = MaskInput(p)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed || r.file == nil {
+		return
+	}
+
+	elapsed := float64(util.Now().Sub(r.fileStart).Microseconds()) / 1000000
+
+	line, err := json.Marshal([]any{elapsed, eventType, string(p)})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	if r.size+int64(len(line)) > r.maxBytes {
+
+		r.closeFile()
+
+		if err := r.openFile(); err != nil {
+			mudlog.Error("Recording", "error", "Could not start next recording file: "+err.Error(), "username", r.Username)
+			r.closed = true
+			return
+		}
+
+		pruneFiles()
+
+		line, _ = json.Marshal([]any{0.0, eventType, string(p)})
+		line = append(line, '\n')
+	}
+
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+
+	if err != nil {
+		mudlog.Error("Recording", "error", "Could not write recording: "+err.Error(), "username", r.Username)
+		r.closeFile()
+		r.closed = true
+	}
+}
+
+func (r *Recording) Close() error {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+	r.closeFile()
+
+	mudlog.Info("Recording", "action", "stopped", "username", r.Username, "connectionId", r.ConnectionId, "duration", util.Now().Sub(r.Started).Round(time.Second))
+
+	return nil
+}
+
+// MaskInput replaces every character typed with a *, leaving only line endings and other
+// control characters, so the playback shows the reply was typed without showing what it was
+func MaskInput(p []byte) []byte {
+
+	out := make([]byte, 0, len(p))
+
+	for _, r := range string(p) {
+		if r < 32 || r == 127 {
+			out = append(out, byte(r))
+			continue
+		}
+		out = append(out, '*')
+	}
+
+	return out
+}
+
+// StripTelnet removes telnet commands (IAC ...) so only what would be shown on a terminal is left
+func StripTelnet(p []byte) []byte {
+
+	if !bytes.Contains(p, []byte{term.TELNET_IAC}) {
+		return p
+	}
+
+	out := make([]byte, 0, len(p))
+
+	for i := 0; i < len(p); i++ {
+
+		if p[i] != term.TELNET_IAC {
+			out = append(out, p[i])
+			continue
+		}
+
+		if i+1 >= len(p) {
+			break
+		}
+
+		switch p[i+1] {
+
+		case term.TELNET_SB:
+			// Skip to the IAC SE
+			end := bytes.Index(p[i+2:], []byte{term.TELNET_IAC, term.TELNET_SE})
+			if end == -1 {
+				return out
+			}
+			i += 2 + end + 1
+
+		case term.TELNET_WILL, term.TELNET_WONT, term.TELNET_DO, term.TELNET_DONT:
+			i += 2
+
+		default:
+			i++
+		}
+	}
+
+	return out
+}
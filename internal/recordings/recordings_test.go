@@ -0,0 +1,229 @@
+package recordings
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+// useRecordingDir points the datafiles somewhere temporary and freezes the clock
+func useRecordingDir(t *testing.T, maxKB int, maxFiles int) (string, *util.SimClock) {
+	t.Helper()
+
+	dir := testsupport.UseDataFiles(t, map[string]any{
+		"Security.RecordingMaxKB":    maxKB,
+		"Security.RecordingMaxFiles": maxFiles,
+	})
+
+	clock := testsupport.FreezeClock(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	return filepath.Join(dir, `recordings`), clock
+}
+
+// newConnection returns a connection, and the client end of it
+func newConnection(t *testing.T, id connections.ConnectionId) (*connections.ConnectionDetails, net.Conn) {
+	t.Helper()
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+
+	return connections.NewConnectionDetails(id, server, nil, nil), client
+}
+
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	lines := [][]byte{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, append([]byte{}, s.Bytes()...))
+	}
+	return lines
+}
+
+func TestRecording(t *testing.T) {
+
+	dir, clock := useRecordingDir(t, 64, 10)
+
+	cd, _ := newConnection(t, 7)
+
+	r, err := Start(cd, `Bob`)
+	require.NoError(t, err)
+	assert.True(t, cd.IsRecording())
+	assert.Equal(t, `bob-20250601-120000-7.cast`, r.FileName())
+
+	_, err = Start(cd, `Bob`)
+	assert.ErrorIs(t, err, ErrAlreadyRecording)
+
+	clock.Advance(1500 * time.Millisecond)
+	r.RecordInput([]byte("look\r\n"), false)
+	r.RecordOutput(append([]byte("You see a rat."), term.TELNET_IAC, term.TELNET_GA))
+	r.RecordOutput([]byte("!!SOUND(Off)"))
+
+	assert.Len(t, Active(), 1)
+	assert.True(t, Stop(7))
+	assert.False(t, cd.IsRecording())
+	assert.Empty(t, Active())
+
+	lines := readLines(t, filepath.Join(dir, `bob-20250601-120000-7.cast`))
+	require.Len(t, lines, 3)
+
+	h := header{}
+	require.NoError(t, json.Unmarshal(lines[0], &h))
+	assert.Equal(t, 2, h.Version)
+	assert.Equal(t, connections.DefaultScreenWidth, h.Width)
+	assert.Equal(t, connections.DefaultScreenHeight, h.Height)
+
+	assert.JSONEq(t, `[1.5, "i", "look\r\n"]`, string(lines[1]))
+	assert.JSONEq(t, `[1.5, "o", "You see a rat."]`, string(lines[2]))
+
+	files := List()
+	require.Len(t, files, 1)
+	assert.False(t, files[0].Active)
+}
+
+func TestRecordingRotation(t *testing.T) {
+
+	dir, _ := useRecordingDir(t, 1, 2)
+
+	cd, _ := newConnection(t, 8)
+
+	r, err := Start(cd, `Alice`)
+	require.NoError(t, err)
+
+	// 1KB per file, so this spills over into several files
+	for i := 0; i < 60; i++ {
+		r.RecordOutput([]byte("The quick brown fox jumps over the lazy dog.\r\n"))
+	}
+
+	Stop(8)
+	pruneFiles()
+
+	files := List()
+	require.Len(t, files, 2)
+
+	for _, f := range files {
+		assert.LessOrEqual(t, f.Size, int64(1024))
+		lines := readLines(t, filepath.Join(dir, f.Name))
+		require.NotEmpty(t, lines)
+		assert.Contains(t, string(lines[0]), `"version":2`)
+	}
+
+	// The first file was the oldest, so it was removed
+	_, err = os.Stat(filepath.Join(dir, `alice-20250601-120000-8.cast`))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecordingMasksPasswords(t *testing.T) {
+
+	dir, _ := useRecordingDir(t, 64, 10)
+
+	cd, client := newConnection(t, 9)
+
+	_, err := Start(cd, `Carol`)
+	require.NoError(t, err)
+
+	// Reads what the client sends, the way the connection's input loop does
+	send := func(input string) {
+		go client.Write([]byte(input))
+		buf := make([]byte, 64)
+		n, err := cd.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, input, string(buf[:n]))
+	}
+
+	send("password\r\n")
+
+	cd.SetInputMasked(true)
+	send("hunter2\r\n")
+	send("s3cr")
+	send("et!\r\n")
+
+	cd.SetInputMasked(false)
+	send("look\r\n")
+
+	Stop(9)
+
+	contents, err := os.ReadFile(filepath.Join(dir, `carol-20250601-120000-9.cast`))
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(contents), `hunter2`)
+	assert.NotContains(t, string(contents), `s3cr`)
+	assert.NotContains(t, string(contents), `et!`)
+
+	lines := readLines(t, filepath.Join(dir, `carol-20250601-120000-9.cast`))
+	require.Len(t, lines, 6)
+	assert.JSONEq(t, `[0, "i", "password\r\n"]`, string(lines[1]))
+	assert.JSONEq(t, `[0, "i", "*******\r\n"]`, string(lines[2]))
+	assert.JSONEq(t, `[0, "i", "****"]`, string(lines[3]))
+	assert.JSONEq(t, `[0, "i", "***\r\n"]`, string(lines[4]))
+	assert.JSONEq(t, `[0, "i", "look\r\n"]`, string(lines[5]))
+}
+
+func TestMaskInput(t *testing.T) {
+	assert.Equal(t, []byte("****\r\n"), MaskInput([]byte("pass\r\n")))
+	assert.Equal(t, []byte("***\b"), MaskInput([]byte("péé\b")))
+	assert.Empty(t, MaskInput([]byte{}))
+}
+
+func TestFilePath(t *testing.T) {
+
+	dir, _ := useRecordingDir(t, 64, 10)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, `bob.cast`), []byte("{}\n"), 0644))
+
+	path, err := FilePath(`bob.cast`)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, `bob.cast`), path)
+
+	for _, name := range []string{``, `../config.yaml`, `../bob.cast`, `.cast`, `bob.txt`} {
+		_, err := FilePath(name)
+		assert.ErrorIs(t, err, ErrInvalidName, name)
+	}
+
+	_, err = FilePath(`missing.cast`)
+	assert.Error(t, err)
+}
+
+func TestStripTelnet(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected []byte
+	}{
+		{"Plain text", []byte("hello"), []byte("hello")},
+		{"Negotiation", []byte{'a', term.TELNET_IAC, term.TELNET_WILL, 201, 'b'}, []byte("ab")},
+		{"Subnegotiation", append(append([]byte("a"), term.MsspStatus.BytesWithPayload([]byte("\x01NAME\x02GoMud"))...), 'b'), []byte("ab")},
+		{"Go ahead", []byte{'>', ' ', term.TELNET_IAC, term.TELNET_GA}, []byte("> ")},
+		{"Unfinished subnegotiation", []byte{'a', term.TELNET_IAC, term.TELNET_SB, 201, 'x'}, []byte("a")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, StripTelnet(tt.input))
+		})
+	}
+}
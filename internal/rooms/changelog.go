@@ -0,0 +1,213 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+//
+// Room files put back by undo or redo of builder changes (see internal/changelog)
+// are loaded into the running game here. Paths are relative to DataFiles, such as "rooms/frostfang/1.yaml"
+//
+
+// changedRooms returns the rooms changed, and the path of each room's file after the change ("" if it was removed).
+// A room moved to another zone has its old file removed and a new one created, so it keeps the new path.
+func changedRooms(paths map[string]bool) map[int]string {
+
+	changed := map[int]string{}
+
+	for relPath, exists := range paths {
+
+		if !strings.HasSuffix(relPath, `.yaml`) {
+			continue // Scripts are read when they are next run
+		}
+
+		roomId, err := strconv.Atoi(strings.TrimSuffix(path.Base(relPath), `.yaml`))
+		if err != nil {
+			continue
+		}
+
+		if exists {
+			changed[roomId] = relPath
+		} else if _, ok := changed[roomId]; !ok {
+			changed[roomId] = ``
+		}
+	}
+
+	return changed
+}
+
+// zoneOfRoom finds the zone a room was last loaded into, even if the room has since been unloaded
+func zoneOfRoom(roomId int) (string, bool) {
+	for zoneName, zoneInfo := range roomManager.zones {
+		if _, ok := zoneInfo.RoomIds[roomId]; ok {
+			return zoneName, true
+		}
+	}
+	return ``, false
+}
+
+// CheckChangedFiles refuses to remove rooms with players in them,
+// or the root room of a zone that will still have other rooms.
+func CheckChangedFiles(paths map[string]bool) error {
+
+	changed := changedRooms(paths)
+
+	for roomId, relPath := range changed {
+
+		if relPath != `` {
+			continue
+		}
+
+		if room, ok := roomManager.rooms[roomId]; ok && len(room.players) > 0 {
+			return fmt.Errorf(`room %d has players in it`, roomId)
+		}
+
+		zoneName, ok := zoneOfRoom(roomId)
+		if !ok || roomManager.zones[zoneName].RootRoomId != roomId {
+			continue
+		}
+
+		for otherRoomId := range roomManager.zones[zoneName].RoomIds {
+			if newPath, ok := changed[otherRoomId]; otherRoomId != roomId && (!ok || newPath != ``) {
+				return fmt.Errorf(`room %d is the root of zone %s, which still has other rooms`, roomId, zoneName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReloadChangedFiles loads the new version of each room changed, and unloads any that were removed.
+func ReloadChangedFiles(paths map[string]bool) error {
+
+	changed := changedRooms(paths)
+	var errs []error
+
+	for _, roomId := range slices.Sorted(maps.Keys(changed)) {
+
+		relPath := changed[roomId]
+		if relPath == `` {
+			continue
+		}
+
+		roomTpl, err := loadRoomFromFile(util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, relPath))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if oldFilepath, ok := roomManager.roomIdToFileCache[roomId]; ok && oldFilepath != roomTpl.Filepath() {
+			moveLoadedRoom(roomId, oldFilepath, roomTpl.Filepath())
+		} else {
+			// Make sure whatever is in it now (items, mobs etc.) is carried over
+			LoadRoom(roomId)
+		}
+
+		updateLoadedRoom(*roomTpl)
+	}
+
+	// Zone roots last, since they can only be removed along with the rest of their zone
+	removed := []int{}
+	for roomId, relPath := range changed {
+		if relPath == `` {
+			removed = append(removed, roomId)
+		}
+	}
+	slices.SortFunc(removed, func(a, b int) int {
+		aZone, _ := zoneOfRoom(a)
+		bZone, _ := zoneOfRoom(b)
+		aRoot, bRoot := roomManager.zones[aZone].RootRoomId == a, roomManager.zones[bZone].RootRoomId == b
+		if aRoot != bRoot {
+			if aRoot {
+				return 1
+			}
+			return -1
+		}
+		return a - b
+	})
+
+	for _, roomId := range removed {
+		if err := unloadRemovedRoom(roomId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// moveLoadedRoom takes a room out of the zone it was in, and moves its instance data to go with its new file
+func moveLoadedRoom(roomId int, oldFilepath string, newFilepath string) {
+
+	if zoneName, ok := zoneOfRoom(roomId); ok {
+		delete(roomManager.zones[zoneName].RoomIds, roomId)
+	}
+	delete(roomManager.roomIdToFileCache, roomId)
+
+	oldInstanceKey, newInstanceKey := instanceKey(oldFilepath), instanceKey(newFilepath)
+
+	storage.Update(func(tx storage.Tx) error {
+		data, err := tx.Get(storage.RoomInstances, oldInstanceKey)
+		if err != nil {
+			return err
+		}
+		if err := tx.Put(storage.RoomInstances, storage.Record{Key: newInstanceKey, Data: data}); err != nil {
+			return err
+		}
+		return tx.Delete(storage.RoomInstances, oldInstanceKey)
+	})
+}
+
+// unloadRemovedRoom forgets a room whose file is gone. A zone root takes the (now empty) zone with it.
+func unloadRemovedRoom(roomId int) error {
+
+	if room, ok := roomManager.rooms[roomId]; ok {
+		for _, mobInstanceId := range room.mobs {
+			mobs.DestroyInstance(mobInstanceId)
+		}
+	}
+
+	if relFilepath, ok := roomManager.roomIdToFileCache[roomId]; ok {
+		key := instanceKey(relFilepath)
+		if _, err := storage.Get(storage.RoomInstances, key); err == nil {
+			if err := storage.Delete(storage.RoomInstances, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if zoneName, ok := zoneOfRoom(roomId); ok {
+
+		zoneInfo := roomManager.zones[zoneName]
+
+		if zoneInfo.RootRoomId == roomId {
+			delete(roomManager.zones, zoneName)
+
+			// Only removed if nothing else is in them
+			os.Remove(util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/rooms/`, ZoneToFolder(zoneName)))
+			os.Remove(util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/rooms.instances/`, ZoneToFolder(zoneName)))
+		} else {
+			delete(zoneInfo.RoomIds, roomId)
+			events.AddToQueue(events.RebuildMap{MapRootRoomId: zoneInfo.RootRoomId})
+		}
+	}
+
+	delete(roomManager.rooms, roomId)
+	delete(roomManager.roomsWithUsers, roomId)
+	delete(roomManager.roomsWithMobs, roomId)
+	delete(roomManager.roomIdToFileCache, roomId)
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package rooms
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+)
+
+func init() {
+	metrics.NewGaugeFunc(`gomud_rooms_loaded`, `Rooms loaded in memory.`, func() float64 {
+		return float64(len(roomManager.rooms))
+	})
+	metrics.NewGaugeFunc(`gomud_rooms_with_players`, `Rooms that have players in them.`, func() float64 {
+		return float64(len(roomManager.roomsWithUsers))
+	})
+}
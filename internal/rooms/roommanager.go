@@ -3,19 +3,23 @@ package rooms
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/exit"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/storage"
 	"github.com/GoMudEngine/GoMud/internal/users"
 	"github.com/GoMudEngine/GoMud/internal/util"
 )
@@ -170,7 +174,10 @@ func RoomMaintenance() []int {
 		allowedUnloadCt = 0
 	}
 
-	for _, room := range roomManager.rooms {
+	// Sorted, so that when only some rooms can be unloaded, it's the same ones every run
+	for _, roomId := range slices.Sorted(maps.Keys(roomManager.rooms)) {
+
+		room := roomManager.rooms[roomId]
 
 		room.PruneVisitors()
 
@@ -366,7 +373,10 @@ func GetRoomWithMostItems(skipRecentlyVisited bool, minimumItemCt int, minimumGo
 	topItemRoomId, topItemCt := 0, 0
 	topGoldRoomId, topGoldCt := 0, 0
 
-	for cRoomId, cRoom := range roomManager.rooms {
+	// Lowest room id wins a tie
+	for _, cRoomId := range slices.Sorted(maps.Keys(roomManager.rooms)) {
+		cRoom := roomManager.rooms[cRoomId]
+
 		// Don't include goblin trash zone items
 		if cRoom.Zone == goblinZone {
 			continue
@@ -430,6 +440,9 @@ func GetRoomsWithPlayers() []int {
 
 	}
 
+	// Always the same order, so that a seeded run plays out the same way
+	slices.Sort(roomsWithPlayers)
+
 	return roomsWithPlayers
 }
 
@@ -442,6 +455,8 @@ func GetRoomsWithMobs() []int {
 		i++
 	}
 
+	slices.Sort(roomsWithMobs)
+
 	return roomsWithMobs
 }
 
@@ -488,6 +503,8 @@ func addRoomToMemory(room *Room, forceOverWrite ...bool) error {
 
 	if len(forceOverWrite) > 0 && forceOverWrite[0] {
 		ClearRoomCache(room.RoomId)
+		// Zone roots aren't cleared from the cache, but still need replacing
+		delete(roomManager.rooms, room.RoomId)
 	}
 
 	if _, ok := roomManager.rooms[room.RoomId]; ok {
@@ -552,6 +569,32 @@ func GetZoneConfig(zone string) *ZoneConfig {
 	return nil
 }
 
+// UnloadZone saves and unloads every room of a zone, so the next time they are loaded it's from storage.
+// Nothing is unloaded if any of them have players in them.
+func UnloadZone(zone string) error {
+
+	zoneInfo, ok := roomManager.zones[zone]
+	if !ok {
+		return fmt.Errorf("zone %s does not exist.", zone)
+	}
+
+	loaded := []*Room{}
+	for _, roomId := range slices.Sorted(maps.Keys(zoneInfo.RoomIds)) {
+		if room, ok := roomManager.rooms[roomId]; ok {
+			if len(room.players) > 0 {
+				return fmt.Errorf("room %d has players in it", roomId)
+			}
+			loaded = append(loaded, room)
+		}
+	}
+
+	for _, room := range loaded {
+		removeRoomFromMemory(room)
+	}
+
+	return nil
+}
+
 func IsRoomLoaded(roomId int) bool {
 	_, ok := roomManager.rooms[roomId]
 	return ok
@@ -632,7 +675,7 @@ func MoveToZone(roomId int, newZoneName string) error {
 		return errors.New("old zone doesn't exist")
 	}
 	oldFilePath := fmt.Sprintf("%s/rooms/%s", configs.GetFilePathsConfig().DataFiles.String(), tplRoom.Filepath())
-	oldInstanceFilePath := fmt.Sprintf("%s/rooms.instances/%s", configs.GetFilePathsConfig().DataFiles.String(), tplRoom.Filepath())
+	oldInstanceKey := instanceKey(tplRoom.Filepath())
 
 	newZoneInfo, ok := roomManager.zones[newZoneName]
 	if !ok {
@@ -645,13 +688,24 @@ func MoveToZone(roomId int, newZoneName string) error {
 
 	tplRoom.Zone = newZoneName
 	newFilePath := fmt.Sprintf("%s/rooms/%s", configs.GetFilePathsConfig().DataFiles.String(), tplRoom.Filepath())
-	newInstanceFilePath := fmt.Sprintf("%s/rooms.instances/%s", configs.GetFilePathsConfig().DataFiles.String(), tplRoom.Filepath())
+	newInstanceKey := instanceKey(tplRoom.Filepath())
 
+	changelog.Touch(oldFilePath)
+	changelog.Touch(newFilePath)
 	if err := os.Rename(oldFilePath, newFilePath); err != nil {
 		return err
 	}
 
-	os.Rename(oldInstanceFilePath, newInstanceFilePath)
+	storage.Update(func(tx storage.Tx) error {
+		data, err := tx.Get(storage.RoomInstances, oldInstanceKey)
+		if err != nil {
+			return err
+		}
+		if err := tx.Put(storage.RoomInstances, storage.Record{Key: newInstanceKey, Data: data}); err != nil {
+			return err
+		}
+		return tx.Delete(storage.RoomInstances, oldInstanceKey)
+	})
 
 	delete(oldZoneInfo.RoomIds, roomId)
 	roomManager.zones[oldZoneName] = oldZoneInfo
@@ -799,6 +853,83 @@ func ConnectRoom(fromRoomId int, toRoomId int, exitName string, mapDirection ...
 	return nil
 }
 
+// CreateRoom saves a new room in an existing zone from template data, giving it the next free room id.
+func CreateRoom(roomTpl Room) (roomId int, err error) {
+
+	if _, ok := roomManager.zones[roomTpl.Zone]; !ok {
+		return 0, fmt.Errorf("zone %s does not exist.", roomTpl.Zone)
+	}
+
+	newRoom := NewRoom(roomTpl.Zone)
+
+	roomTpl.RoomId = newRoom.RoomId
+	if roomTpl.Exits == nil {
+		roomTpl.Exits = newRoom.Exits
+	}
+
+	if err := roomTpl.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := SaveRoomTemplate(roomTpl); err != nil {
+		return 0, err
+	}
+
+	return roomTpl.RoomId, nil
+}
+
+// DeleteRoom removes a room template along with any instance data.
+// Zone roots, rooms with players in them, and rooms that other rooms still have exits to are not deleted.
+func DeleteRoom(roomId int) error {
+
+	room := LoadRoom(roomId)
+	if room == nil {
+		return fmt.Errorf(`room %d not found`, roomId)
+	}
+
+	if rootRoomId, _ := GetZoneRoot(room.Zone); rootRoomId == roomId {
+		return fmt.Errorf(`room %d is the zone root`, roomId)
+	}
+
+	if len(room.players) > 0 {
+		return fmt.Errorf(`room %d has players in it`, roomId)
+	}
+
+	for _, otherRoomId := range GetAllRoomIds() {
+		if otherRoomId == roomId {
+			continue
+		}
+		otherRoom := LoadRoomTemplate(otherRoomId)
+		if otherRoom == nil {
+			continue
+		}
+		for exitName, exitInfo := range otherRoom.Exits {
+			if exitInfo.RoomId == roomId {
+				return fmt.Errorf(`room %d has a %s exit leading to room %d`, otherRoomId, exitName, roomId)
+			}
+		}
+	}
+
+	for _, mobInstanceId := range room.mobs {
+		mobs.DestroyInstance(mobInstanceId)
+	}
+
+	roomFilePath := util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/rooms/`, room.Filepath())
+	changelog.Touch(roomFilePath)
+	if err := os.Remove(roomFilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	key := instanceKey(room.Filepath())
+	if _, err := storage.Get(storage.RoomInstances, key); err == nil {
+		if err := storage.Delete(storage.RoomInstances, key); err != nil {
+			return err
+		}
+	}
+
+	return ClearRoomCache(roomId)
+}
+
 func GetRoomCount(zoneName string) int {
 
 	zoneInfo, ok := roomManager.zones[zoneName]
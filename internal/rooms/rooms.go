@@ -3,7 +3,9 @@ package rooms
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -257,11 +259,17 @@ func (r *Room) SendText(txt string, excludeUserIds ...int) {
 func (r *Room) PlaySound(soundId string, category string, excludeUserIds ...int) {
 
 	volume := 100
+	tag := category
+	priority, loops := 0, 0
 	if soundConfig := audio.GetFile(soundId); soundConfig.FilePath != `` {
 		soundId = soundConfig.FilePath
 		if soundConfig.Volume > 0 && soundConfig.Volume <= 100 {
 			volume = soundConfig.Volume
 		}
+		if soundConfig.Tag != `` {
+			tag = soundConfig.Tag
+		}
+		priority, loops = soundConfig.Priority, soundConfig.Loops
 	}
 
 	for _, userId := range r.players {
@@ -288,6 +296,9 @@ func (r *Room) PlaySound(soundId string, category string, excludeUserIds ...int)
 			SoundFile: soundId,
 			Volume:    volume,
 			Category:  category,
+			Tag:       tag,
+			Priority:  priority,
+			Loops:     loops,
 		})
 	}
 
@@ -737,6 +748,9 @@ func (r *Room) Prepare(checkAdjacentRooms bool) {
 		prepRoomIds = append(prepRoomIds, exit.RoomId)
 	}
 
+	// Prepare them in the same order every time, so spawns always get the same instance ids
+	slices.Sort(prepRoomIds)
+
 	for _, exitRoomId := range prepRoomIds {
 
 		if exitRoom := LoadRoom(exitRoomId); exitRoom != nil {
@@ -905,16 +919,15 @@ func (r *Room) GetRandomExit() (exitName string, roomId int) {
 		}
 	}
 
-	roomSelection := util.Rand(len(allExits))
-
-	for exitName, roomId := range allExits {
-		if roomSelection == 0 {
-			return exitName, roomId
-		}
-		roomSelection--
+	if len(allExits) == 0 {
+		return ``, 0
 	}
 
-	return ``, 0
+	// Sorted so that the same roll picks the same exit every run
+	exitNames := slices.Sorted(maps.Keys(allExits))
+	exitName = exitNames[util.Rand(len(exitNames))]
+
+	return exitName, allExits[exitName]
 }
 
 func (r *Room) RemoveItem(i items.Item, stash bool) {
@@ -1378,7 +1391,7 @@ func (r *Room) AddSign(displayText string, visibleUserId int, daysBeforeDecay in
 	s := Sign{
 		VisibleUserId: visibleUserId,
 		DisplayText:   displayText,
-		Expires:       time.Now().Add(time.Hour * 24 * time.Duration(daysBeforeDecay)),
+		Expires:       util.Now().Add(time.Hour * 24 * time.Duration(daysBeforeDecay)),
 	}
 
 	// If it's a public sign and one exists, replace it.
@@ -1811,7 +1824,7 @@ func (r *Room) PruneSigns() []Sign {
 
 	for i := signCt - 1; i >= 0; i-- {
 		s := r.Signs[i]
-		if s.Expires.Before(time.Now()) {
+		if s.Expires.Before(util.Now()) {
 			r.Signs = append(r.Signs[:i], r.Signs[i+1:]...)
 			prunedSigned = append(prunedSigned, s)
 		}
@@ -2186,6 +2199,13 @@ func (r *Room) Validate() error {
 		}
 	}
 
+	// A range that tops out at 0 doesn't train anything
+	for skillName, trainingRange := range r.SkillTraining {
+		if trainingRange.Max < 1 {
+			delete(r.SkillTraining, skillName)
+		}
+	}
+
 	// Validate the biome.
 	if r.Biome != `` {
 		if _, found := GetBiome(r.Biome); !found {
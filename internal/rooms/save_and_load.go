@@ -4,15 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/fileloader"
 	"github.com/GoMudEngine/GoMud/internal/items"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/storage"
 	"github.com/GoMudEngine/GoMud/internal/util"
 	"gopkg.in/yaml.v2"
 )
@@ -111,9 +114,7 @@ func LoadRoomInstance(roomId int) *Room {
 	}
 
 	// Look for specially saved instance data
-	filepath := util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/rooms.instances/`, filename)
-
-	if bytes, err := os.ReadFile(filepath); err == nil {
+	if bytes, err := storage.Get(storage.RoomInstances, instanceKey(filename)); err == nil {
 		// Unmarshal onto the default template data, overwriting any set fields in the instance save file
 		yaml.Unmarshal(bytes, room)
 	}
@@ -167,64 +168,74 @@ func SaveRoomTemplate(roomTpl Room) error {
 
 	// First write the empty version to its template file
 	roomFilePath := util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/rooms/`, fmt.Sprintf("%s%d.yaml", zoneFolder, roomTpl.RoomId))
+	changelog.Touch(roomFilePath)
 	if err = os.WriteFile(roomFilePath, data, 0777); err != nil {
 		return err
 	}
 
-	// Get zone root
-	cfg := GetZoneConfig(roomTpl.Zone)
+	updateLoadedRoom(roomTpl)
+
+	return nil
+}
+
+// updateLoadedRoom replaces a room in memory with a new version of its template,
+// keeping anything that was in the old one (items, gold, mobs, players etc.)
+func updateLoadedRoom(roomTpl Room) {
 
 	// Queue rebuild the zone map
-	events.AddToQueue(events.RebuildMap{MapRootRoomId: cfg.RoomId})
+	if cfg := GetZoneConfig(roomTpl.Zone); cfg != nil {
+		events.AddToQueue(events.RebuildMap{MapRootRoomId: cfg.RoomId})
+	}
 	// Also queue rebuild the map based on the room, if it's not already present in a map (AKA, SkipIfExists)
 	events.AddToQueue(events.RebuildMap{MapRootRoomId: roomTpl.RoomId, SkipIfExists: true})
 
 	//
 	// Now we can make changes to the roomTpl as though it is the room (copy over stuff etc)
+	// A brand new room has nothing to carry over.
 	//
-	roomBeingReplaced := roomManager.rooms[roomTpl.RoomId]
+	if roomBeingReplaced := roomManager.rooms[roomTpl.RoomId]; roomBeingReplaced != nil {
+		// Copy container contents (if new vs. old room container names match)
+		for containerName, container := range roomBeingReplaced.Containers {
 
-	// Copy container contents (if new vs. old room container names match)
-	for containerName, container := range roomBeingReplaced.Containers {
+			if newContainer, ok := roomTpl.Containers[containerName]; ok {
 
-		if newContainer, ok := roomTpl.Containers[containerName]; ok {
+				if newContainer.Gold == 0 {
+					newContainer.Gold = container.Gold
+				}
 
-			if newContainer.Gold == 0 {
-				newContainer.Gold = container.Gold
-			}
+				if len(newContainer.Items) == 0 && len(container.Items) > 0 {
+					newContainer.Items = make([]items.Item, len(container.Items))
+					copy(newContainer.Items, container.Items)
+				}
 
-			if len(newContainer.Items) == 0 && len(container.Items) > 0 {
-				newContainer.Items = make([]items.Item, len(container.Items))
-				copy(newContainer.Items, container.Items)
+				roomTpl.Containers[containerName] = newContainer
 			}
-
-			roomTpl.Containers[containerName] = newContainer
 		}
-	}
 
-	// Copy items and stashed items
-	for _, itm := range roomBeingReplaced.GetAllFloorItems(true) {
-		if itm.StashedBy > 0 {
-			roomTpl.AddItem(itm, true)
-		} else {
-			roomTpl.AddItem(itm, false)
+		// Copy items and stashed items
+		for _, itm := range roomBeingReplaced.GetAllFloorItems(true) {
+			if itm.StashedBy > 0 {
+				roomTpl.AddItem(itm, true)
+			} else {
+				roomTpl.AddItem(itm, false)
+			}
 		}
-	}
 
-	// Copy gold on floor
-	roomTpl.Gold = roomBeingReplaced.Gold
+		// Copy gold on floor
+		roomTpl.Gold = roomBeingReplaced.Gold
 
-	// Copy signs
-	roomTpl.Signs = make([]Sign, len(roomBeingReplaced.Signs))
-	copy(roomTpl.Signs, roomBeingReplaced.Signs)
+		// Copy signs
+		roomTpl.Signs = make([]Sign, len(roomBeingReplaced.Signs))
+		copy(roomTpl.Signs, roomBeingReplaced.Signs)
 
-	// Copy mobs in room
-	roomTpl.mobs = make([]int, len(roomBeingReplaced.mobs))
-	copy(roomTpl.mobs, roomBeingReplaced.mobs)
+		// Copy mobs in room
+		roomTpl.mobs = make([]int, len(roomBeingReplaced.mobs))
+		copy(roomTpl.mobs, roomBeingReplaced.mobs)
 
-	// Copy players in room
-	roomTpl.players = make([]int, len(roomBeingReplaced.players))
-	copy(roomTpl.players, roomBeingReplaced.players)
+		// Copy players in room
+		roomTpl.players = make([]int, len(roomBeingReplaced.players))
+		copy(roomTpl.players, roomBeingReplaced.players)
+	}
 
 	// Add to memory with the force flag true
 	// This will clear out the old data and force write the new data.
@@ -232,8 +243,6 @@ func SaveRoomTemplate(roomTpl Room) error {
 
 	// Save whatever is in this room as the instance data
 	SaveRoomInstance(roomTpl)
-
-	return nil
 }
 
 type SaveEqualityChecker interface {
@@ -296,12 +305,12 @@ func SaveRoomInstance(r Room) error {
 
 	}
 
-	zone := ZoneToFolder(r.Zone)
-	folderPath := util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/rooms.instances/`, zone)
-	instanceFilePath := fmt.Sprintf("%s%d.yaml", folderPath, r.RoomId)
+	key := instanceKey(r.Filepath())
 
 	if len(instanceSaveData) == 0 {
-		os.Remove(instanceFilePath)
+		if _, err := storage.Get(storage.RoomInstances, key); err == nil {
+			return storage.Delete(storage.RoomInstances, key)
+		}
 		return nil
 	}
 
@@ -310,11 +319,12 @@ func SaveRoomInstance(r Room) error {
 		return err
 	}
 
-	if err = os.WriteFile(instanceFilePath, data, 0777); err != nil {
-		return err
-	}
+	return storage.Put(storage.RoomInstances, storage.Record{Key: key, Data: data})
+}
 
-	return nil
+// The key instance data is saved under, from the template's path, such as "frostfang/1"
+func instanceKey(templateFilepath string) string {
+	return strings.TrimSuffix(filepath.ToSlash(templateFilepath), `.yaml`)
 }
 
 func loadRoomFromFile(roomFilePath string) (*Room, error) {
@@ -68,7 +68,7 @@ func TryBuffScriptEvent(eventName string, userId int, mobInstanceId int, buffId
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`buff`, finalErr)
 				return false, finalErr
 			}
 
@@ -121,7 +121,7 @@ func TryBuffCommand(cmd string, rest string, userId int, mobInstanceId int, buff
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`buff`, finalErr)
 				return false, finalErr
 			}
 
@@ -159,7 +159,7 @@ func TryBuffCommand(cmd string, rest string, userId int, mobInstanceId int, buff
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`buff`, finalErr)
 				return false, finalErr
 			}
 
@@ -219,7 +219,7 @@ func getBuffVM(buffId int) (*VMWrapper, error) {
 			mudlog.Error("JSVM", "exception", finalErr)
 			return nil, finalErr
 		} else if errors.Is(finalErr, errTimeout) {
-			mudlog.Error("JSVM", "interrupted", finalErr)
+			scriptTimedOut(`buff`, finalErr)
 			return nil, finalErr
 		}
 
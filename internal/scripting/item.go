@@ -63,7 +63,7 @@ func TryItemScriptEvent(eventName string, item items.Item, userId int) (bool, er
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`item`, finalErr)
 				return false, finalErr
 			}
 
@@ -124,7 +124,7 @@ func TryItemCommand(cmd string, item items.Item, userId int) (bool, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`item`, finalErr)
 				return false, finalErr
 			}
 
@@ -165,7 +165,7 @@ func TryItemCommand(cmd string, item items.Item, userId int) (bool, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`item`, finalErr)
 				return false, finalErr
 			}
 
@@ -226,7 +226,7 @@ func getItemVM(sItem *ScriptItem) (*VMWrapper, error) {
 			mudlog.Error("JSVM", "exception", finalErr)
 			return nil, finalErr
 		} else if errors.Is(finalErr, errTimeout) {
-			mudlog.Error("JSVM", "interrupted", finalErr)
+			scriptTimedOut(`item`, finalErr)
 			return nil, finalErr
 		}
 
@@ -0,0 +1,44 @@
+package scripting
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+var (
+	timeoutsMetric = metrics.NewCounter(
+		`gomud_script_timeouts_total`,
+		`Scripts that were interrupted for running too long.`,
+		`kind`,
+	)
+)
+
+func init() {
+	// The caches are only touched with the mud lock held, as is anything that writes the metrics out
+	metrics.NewGaugeVecFunc(`gomud_script_vms`, `Script VMs loaded in memory.`, `kind`, func() map[string]float64 {
+		return map[string]float64{
+			`room`:  float64(countVMs(roomVMCache)),
+			`mob`:   float64(countVMs(mobVMCache)),
+			`item`:  float64(countVMs(itemVMCache)),
+			`buff`:  float64(countVMs(buffVMCache)),
+			`spell`: float64(countVMs(spellVMCache)),
+		}
+	})
+}
+
+// countVMs skips the nil entries left for things that have no script
+func countVMs[K comparable](cache map[K]*VMWrapper) int {
+	ct := 0
+	for _, vmw := range cache {
+		if vmw != nil {
+			ct++
+		}
+	}
+	return ct
+}
+
+// scriptTimedOut logs and counts a script that was interrupted by errTimeout
+func scriptTimedOut(kind string, err error) {
+	timeoutsMetric.Inc(kind)
+	mudlog.Error("JSVM", "interrupted", err)
+}
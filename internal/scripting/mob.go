@@ -72,7 +72,7 @@ func TryMobScriptEvent(eventName string, mobInstanceId int, sourceId int, source
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`mob`, finalErr)
 				return false, finalErr
 			}
 
@@ -136,7 +136,7 @@ func TryMobCommand(cmd string, rest string, mobInstanceId int, sourceId int, sou
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`mob`, finalErr)
 				return false, finalErr
 			}
 
@@ -179,7 +179,7 @@ func TryMobCommand(cmd string, rest string, mobInstanceId int, sourceId int, sou
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`mob`, finalErr)
 				return false, finalErr
 			}
 
@@ -236,7 +236,7 @@ func getMobVM(mobActor *ScriptActor) (*VMWrapper, error) {
 			mudlog.Error("JSVM", "exception", finalErr)
 			return nil, finalErr
 		} else if errors.Is(finalErr, errTimeout) {
-			mudlog.Error("JSVM", "interrupted", finalErr)
+			scriptTimedOut(`mob`, finalErr)
 			return nil, finalErr
 		}
 
@@ -262,7 +262,7 @@ func getMobVM(mobActor *ScriptActor) (*VMWrapper, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return nil, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`mob`, finalErr)
 				return nil, finalErr
 			}
 
@@ -91,7 +91,7 @@ func TryRoomScriptEvent(eventName string, userId int, roomId int) (bool, error)
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`room`, finalErr)
 				return false, finalErr
 			}
 
@@ -150,7 +150,7 @@ func TryRoomIdleEvent(roomId int) (bool, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`room`, finalErr)
 				return false, finalErr
 			}
 
@@ -236,7 +236,7 @@ func TryRoomCommand(cmd string, rest string, userId int) (bool, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`room`, finalErr)
 				return false, finalErr
 			}
 
@@ -281,7 +281,7 @@ func TryRoomCommand(cmd string, rest string, userId int) (bool, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`room`, finalErr)
 				return false, finalErr
 			}
 
@@ -341,7 +341,7 @@ func getRoomVM(roomId int) (*VMWrapper, error) {
 			mudlog.Error("JSVM", "exception", finalErr)
 			return nil, finalErr
 		} else if errors.Is(finalErr, errTimeout) {
-			mudlog.Error("JSVM", "interrupted", finalErr)
+			scriptTimedOut(`room`, finalErr)
 			return nil, finalErr
 		}
 
@@ -369,7 +369,7 @@ func getRoomVM(roomId int) (*VMWrapper, error) {
 				mudlog.Error("JSVM", "exception", finalErr)
 				return nil, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`room`, finalErr)
 				return nil, finalErr
 			}
 
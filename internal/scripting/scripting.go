@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/colorpatterns"
+	"github.com/GoMudEngine/GoMud/internal/util"
 	"github.com/dop251/goja"
 )
 
@@ -94,6 +95,10 @@ func Setup(scriptLoadTimeoutMs int, scriptRoomTimeoutMs int) {
 }
 
 func setAllScriptingFunctions(vm *goja.Runtime) {
+	// Math.random() and Date follow the game, so that seeded runs are repeatable
+	vm.SetRandSource(util.RandFloat)
+	vm.SetTimeSource(util.Now)
+
 	setMessagingFunctions(vm)
 	setRoomFunctions(vm)
 	setActorFunctions(vm)
@@ -137,7 +137,7 @@ func TrySpellScriptEvent(eventName string, sourceUserId int, sourceMobInstanceId
 				mudlog.Error("JSVM", "exception", finalErr)
 				return false, finalErr
 			} else if errors.Is(finalErr, errTimeout) {
-				mudlog.Error("JSVM", "interrupted", finalErr)
+				scriptTimedOut(`spell`, finalErr)
 				return false, finalErr
 			}
 
@@ -199,7 +199,7 @@ func getSpellVM(scriptId string) (*VMWrapper, error) {
 			mudlog.Error("JSVM", "exception", finalErr)
 			return nil, finalErr
 		} else if errors.Is(finalErr, errTimeout) {
-			mudlog.Error("JSVM", "interrupted", finalErr)
+			scriptTimedOut(`spell`, finalErr)
 			return nil, finalErr
 		}
 
@@ -2,9 +2,11 @@ package spells
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/fileloader"
 
@@ -42,3 +44,50 @@ func CreateNewSpellFile(newSpellInfo SpellData) (string, error) {
 
 	return newSpellInfo.SpellId, nil
 }
+
+// SaveSpellFile overwrites an existing spell.
+func SaveSpellFile(spellInfo SpellData) error {
+
+	if sp := GetSpell(spellInfo.SpellId); sp == nil {
+		return fmt.Errorf(`spell %s does not exist`, spellInfo.SpellId)
+	}
+
+	if err := spellInfo.Validate(); err != nil {
+		return err
+	}
+
+	saveModes := []fileloader.SaveOption{}
+
+	if configs.GetFilePathsConfig().CarefulSaveFiles {
+		saveModes = append(saveModes, fileloader.SaveCareful)
+	}
+
+	if err := fileloader.SaveFlatFile[*SpellData](string(configs.GetFilePathsConfig().DataFiles)+`/spells`, &spellInfo, saveModes...); err != nil {
+		return err
+	}
+
+	// Save to in-memory cache
+	allSpells[spellInfo.Id()] = &spellInfo
+
+	return nil
+}
+
+// DeleteSpellFile removes a spell and its script.
+func DeleteSpellFile(spellId string) error {
+
+	spellInfo := GetSpell(spellId)
+	if spellInfo == nil {
+		return fmt.Errorf(`spell %s does not exist`, spellId)
+	}
+
+	if err := fileloader.DeleteFlatFile[*SpellData](string(configs.GetFilePathsConfig().DataFiles)+`/spells`, spellInfo); err != nil {
+		return err
+	}
+
+	changelog.Touch(spellInfo.GetScriptPath())
+	os.Remove(spellInfo.GetScriptPath())
+
+	delete(allSpells, spellId)
+
+	return nil
+}
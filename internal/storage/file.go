@@ -0,0 +1,395 @@
+package storage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//
+// FileStore keeps each record in its own file, using the same layout the game always has,
+// so existing worlds keep working without any conversion.
+//
+// Every file is written to {name}.new first, then renamed over the old one, so a crash can't leave
+// a half written record. When an Update changes more than one record, the renames are listed in a
+// journal before any are made. If the server stops part way through, the rest are finished the
+// next time the store is opened.
+//
+
+const (
+	journalFilename = `storage.journal`
+	newFileSuffix   = `.new`
+)
+
+// Where the records of a collection live, relative to the DataFiles folder
+type fileLayout struct {
+	folder  string
+	ext     string
+	skipExt string // Another collection's files kept in the same folder
+}
+
+func layoutFor(collection string) fileLayout {
+	switch {
+	case collection == Users:
+		return fileLayout{folder: `users`, ext: `.yaml`, skipExt: `.alts.yaml`}
+	case collection == Alts:
+		return fileLayout{folder: `users`, ext: `.alts.yaml`}
+	case collection == RoomInstances:
+		return fileLayout{folder: `rooms.instances`, ext: `.yaml`}
+	case strings.HasPrefix(collection, PluginData+`/`):
+		return fileLayout{folder: collection, ext: `.plugin.dat`}
+	}
+	return fileLayout{folder: collection, ext: `.yaml`}
+}
+
+type FileStore struct {
+	root string
+	lock sync.Mutex // One Update at a time
+}
+
+func NewFileStore(dataFilesPath string) (*FileStore, error) {
+
+	s := &FileStore{root: filepath.FromSlash(dataFilesPath)}
+
+	if err := s.finishJournal(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) Backend() string {
+	return BackendYAML
+}
+
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// path is where a record is kept. Keys may be split into folders with /, such as "frostfang/1",
+// but nothing that would reach outside the collection's folder is allowed.
+func (s *FileStore) path(collection string, key string) (string, error) {
+
+	if strings.ContainsRune(key, '\\') || filepath.IsAbs(key) {
+		return ``, fmt.Errorf(`%w: %q`, ErrInvalidKey, key)
+	}
+
+	for _, part := range strings.Split(key, `/`) {
+		if part == `` || part == `.` || part == `..` {
+			return ``, fmt.Errorf(`%w: %q`, ErrInvalidKey, key)
+		}
+	}
+
+	layout := layoutFor(collection)
+	return filepath.Join(s.root, filepath.FromSlash(layout.folder), filepath.FromSlash(key)+layout.ext), nil
+}
+
+func (s *FileStore) Get(collection string, key string) ([]byte, error) {
+
+	path, err := s.path(collection, key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return data, err
+}
+
+func (s *FileStore) Keys(collection string) ([]string, error) {
+
+	layout := layoutFor(collection)
+	folder := filepath.Join(s.root, filepath.FromSlash(layout.folder))
+
+	keys := []string{}
+
+	err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		name := d.Name()
+		if !strings.HasSuffix(name, layout.ext) {
+			return nil
+		}
+		if layout.skipExt != `` && strings.HasSuffix(name, layout.skipExt) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, strings.TrimSuffix(filepath.ToSlash(rel), layout.ext))
+
+		return nil
+	})
+
+	slices.Sort(keys)
+
+	return keys, err
+}
+
+func (s *FileStore) Collections() ([]string, error) {
+
+	collections := []string{}
+
+	for _, c := range namedCollections {
+		if keys, err := s.Keys(c); err != nil {
+			return nil, err
+		} else if len(keys) > 0 {
+			collections = append(collections, c)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.root, PluginData))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			collections = append(collections, PluginData+`/`+entry.Name())
+		}
+	}
+
+	slices.Sort(collections)
+
+	return collections, nil
+}
+
+func (s *FileStore) Update(fn func(tx Tx) error) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tx := &fileTx{
+		store:   s,
+		changes: map[string]*[]byte{},
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.commit()
+}
+
+// fileTx holds changes in memory until the Update is done
+type fileTx struct {
+	store   *FileStore
+	order   []string           // Paths in the order they were first changed
+	changes map[string]*[]byte // nil means delete
+}
+
+func (tx *fileTx) Get(collection string, key string) ([]byte, error) {
+
+	path, err := tx.store.path(collection, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, ok := tx.changes[path]; ok {
+		if data == nil {
+			return nil, ErrNotFound
+		}
+		return *data, nil
+	}
+
+	return tx.store.Get(collection, key)
+}
+
+func (tx *fileTx) Put(collection string, record Record) error {
+
+	if record.Key == `` {
+		return errors.New(`record has no key`)
+	}
+
+	path, err := tx.store.path(collection, record.Key)
+	if err != nil {
+		return err
+	}
+
+	tx.change(path, &record.Data)
+
+	return nil
+}
+
+func (tx *fileTx) Delete(collection string, key string) error {
+
+	path, err := tx.store.path(collection, key)
+	if err != nil {
+		return err
+	}
+
+	tx.change(path, nil)
+
+	return nil
+}
+
+func (tx *fileTx) change(path string, data *[]byte) {
+	if _, ok := tx.changes[path]; !ok {
+		tx.order = append(tx.order, path)
+	}
+	tx.changes[path] = data
+}
+
+func (tx *fileTx) commit() error {
+
+	if len(tx.order) == 0 {
+		return nil
+	}
+
+	// Write everything to .new files. Nothing has changed if this fails part way.
+	written := []string{}
+	for _, path := range tx.order {
+
+		data := tx.changes[path]
+		if data == nil {
+			continue
+		}
+
+		if err := writeFileSynced(path+newFileSuffix, *data); err != nil {
+			for _, p := range written {
+				os.Remove(p + newFileSuffix)
+			}
+			return err
+		}
+		written = append(written, path)
+	}
+
+	journal := filepath.Join(tx.store.root, journalFilename)
+
+	if len(tx.order) > 1 {
+
+		lines := strings.Builder{}
+		for _, path := range tx.order {
+			rel, err := filepath.Rel(tx.store.root, path)
+			if err != nil {
+				return err
+			}
+			if tx.changes[path] == nil {
+				lines.WriteString("delete\t" + filepath.ToSlash(rel) + "\n")
+			} else {
+				lines.WriteString("write\t" + filepath.ToSlash(rel) + "\n")
+			}
+		}
+
+		if err := writeFileSynced(journal, []byte(lines.String())); err != nil {
+			for _, p := range written {
+				os.Remove(p + newFileSuffix)
+			}
+			return err
+		}
+	}
+
+	// From here the changes are committed, even if the rest doesn't happen until the store is next opened
+	for _, path := range tx.order {
+		if err := applyChange(path, tx.changes[path] == nil); err != nil {
+			return err
+		}
+	}
+
+	if len(tx.order) > 1 {
+		return os.Remove(journal)
+	}
+
+	return nil
+}
+
+// finishJournal completes an Update that was interrupted after it was committed
+func (s *FileStore) finishJournal() error {
+
+	journal := filepath.Join(s.root, journalFilename)
+
+	f, err := os.Open(journal)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+
+		action, rel, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(s.root, filepath.FromSlash(rel))
+
+		if action == `write` {
+			// Already renamed if the .new file is gone
+			if _, err := os.Stat(path + newFileSuffix); err != nil {
+				continue
+			}
+		}
+
+		if err := applyChange(path, action == `delete`); err != nil {
+			f.Close()
+			return fmt.Errorf(`finishing %s: %w`, journalFilename, err)
+		}
+	}
+	f.Close()
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return os.Remove(journal)
+}
+
+func applyChange(path string, isDelete bool) error {
+
+	if isDelete {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	return os.Rename(path+newFileSuffix, path)
+}
+
+func writeFileSynced(path string, data []byte) error {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0777)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
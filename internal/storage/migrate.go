@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Copy puts every record from one store into another, a collection at a time.
+// Records already in the destination are overwritten, and anything only in the destination is left alone.
+func Copy(from Store, to Store) (int, error) {
+
+	collections, err := from.Collections()
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+
+	for _, collection := range collections {
+
+		keys, err := from.Keys(collection)
+		if err != nil {
+			return copied, err
+		}
+
+		err = to.Update(func(tx Tx) error {
+			for _, key := range keys {
+
+				data, err := from.Get(collection, key)
+				if err != nil {
+					return fmt.Errorf(`%s/%s: %w`, collection, key, err)
+				}
+
+				if err := tx.Put(collection, Record{Key: key, Data: data}); err != nil {
+					return fmt.Errorf(`%s/%s: %w`, collection, key, err)
+				}
+			}
+			return nil
+		})
+
+		if err != nil {
+			return copied, err
+		}
+
+		copied += len(keys)
+	}
+
+	return copied, nil
+}
+
+// Migrate copies everything saved with one backend into another, in the same DataFiles folder
+func Migrate(fromBackend string, toBackend string, dataFilesPath string) (int, error) {
+
+	if strings.EqualFold(fromBackend, toBackend) {
+		return 0, fmt.Errorf(`already using %s storage`, toBackend)
+	}
+
+	from, err := New(fromBackend, dataFilesPath)
+	if err != nil {
+		return 0, err
+	}
+	defer from.Close()
+
+	to, err := New(toBackend, dataFilesPath)
+	if err != nil {
+		return 0, err
+	}
+	defer to.Close()
+
+	return Copy(from, to)
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+//
+// SQLiteStore keeps every collection in one table of a single database file.
+// An Update is a database transaction.
+//
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS records (
+	collection TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	name       TEXT NOT NULL DEFAULT '',
+	data       BLOB NOT NULL,
+	PRIMARY KEY (collection, key)
+);
+CREATE INDEX IF NOT EXISTS records_by_name ON records (collection, name);
+`
+
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(dbFilePath string) (*SQLiteStore, error) {
+
+	db, err := sql.Open(`sqlite`, dbFilePath+`?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Writes are serialized by sqlite anyway, and a single connection keeps transactions simple
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Backend() string {
+	return BackendSQLite
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Get(collection string, key string) ([]byte, error) {
+	return sqliteGet(s.db, collection, key)
+}
+
+func (s *SQLiteStore) Keys(collection string) ([]string, error) {
+	return sqliteStrings(s.db, `SELECT key FROM records WHERE collection = ? ORDER BY key`, collection)
+}
+
+func (s *SQLiteStore) Collections() ([]string, error) {
+	return sqliteStrings(s.db, `SELECT DISTINCT collection FROM records ORDER BY collection`)
+}
+
+func (s *SQLiteStore) FindByName(collection string, name string) (string, error) {
+
+	var key string
+	err := s.db.QueryRow(`SELECT key FROM records WHERE collection = ? AND name = ? LIMIT 1`, collection, strings.ToLower(name)).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ``, ErrNotFound
+	}
+
+	return key, err
+}
+
+func (s *SQLiteStore) Update(fn func(tx Tx) error) error {
+
+	sqlTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&sqliteTx{tx: sqlTx}); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return sqlTx.Commit()
+}
+
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Get(collection string, key string) ([]byte, error) {
+	return sqliteGet(t.tx, collection, key)
+}
+
+func (t *sqliteTx) Put(collection string, record Record) error {
+
+	if record.Key == `` {
+		return errors.New(`record has no key`)
+	}
+
+	data := record.Data
+	if data == nil {
+		data = []byte{}
+	}
+
+	_, err := t.tx.Exec(
+		`INSERT INTO records (collection, key, name, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (collection, key) DO UPDATE SET name = excluded.name, data = excluded.data`,
+		collection, record.Key, nameOf(collection, record), data,
+	)
+
+	return err
+}
+
+func (t *sqliteTx) Delete(collection string, key string) error {
+	_, err := t.tx.Exec(`DELETE FROM records WHERE collection = ? AND key = ?`, collection, key)
+	return err
+}
+
+// Satisfied by both *sql.DB and *sql.Tx
+type sqliteQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func sqliteGet(q sqliteQuerier, collection string, key string) ([]byte, error) {
+
+	var data []byte
+	err := q.QueryRow(`SELECT data FROM records WHERE collection = ? AND key = ?`, collection, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return data, err
+}
+
+func sqliteStrings(q sqliteQuerier, query string, args ...any) ([]string, error) {
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, rows.Err()
+}
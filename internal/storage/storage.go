@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+)
+
+//
+// Everything the game saves about players and the state of the world goes through a Store,
+// rather than each package writing its own files. A Store is a set of collections, each a set of
+// records looked up by key. Any number of records can be saved together with Update(),
+// and either all of them are saved or none are.
+//
+// Two backends are available, chosen with FilePaths.Storage:
+//   yaml   - A file per record under the DataFiles folder, laid out as it always has been.
+//   sqlite - A single database file in the DataFiles folder.
+//
+// -migrate-storage copies everything from one to the other.
+//
+
+const (
+	BackendYAML   = `yaml`
+	BackendSQLite = `sqlite`
+
+	// The database file for the sqlite backend, inside the DataFiles folder
+	SQLiteFilename = `gomud.db`
+)
+
+// Collections
+const (
+	Users         = `users`           // By UserId, named by username
+	Alts          = `alts`            // By UserId
+	RoomInstances = `rooms.instances` // By zone folder and RoomId, such as "frostfang/1"
+	PluginData    = `plugin-data`     // Each plugin gets its own collection: PluginData + "/" + plugin folder
+	APITokens     = `api-tokens`      // By token id
+	Changelog     = `changelog`       // By entry id, zero padded so they sort in order
+)
+
+// Every collection above with a fixed name. The file store can't tell them apart from everything
+// else in the DataFiles folder, so it only looks for these. A new collection must be added here too.
+var namedCollections = []string{Users, Alts, RoomInstances, APITokens, Changelog}
+
+var (
+	// Wraps fs.ErrNotExist, so errors.Is(err, fs.ErrNotExist) still works for callers that used to read files
+	ErrNotFound       = fmt.Errorf(`record not found: %w`, fs.ErrNotExist)
+	ErrUnknownBackend = errors.New(`unknown storage backend`)
+	ErrInvalidKey     = errors.New(`invalid record key`)
+
+	activeLock  = sync.RWMutex{}
+	activeStore Store
+
+	nameFuncs = map[string]func(data []byte) string{}
+)
+
+// Record is one thing saved in a collection
+type Record struct {
+	Key  string
+	Name string // Optional. Stores that implement Finder can look records up by it (case insensitive).
+	Data []byte
+}
+
+type Store interface {
+	Backend() string
+	Get(collection string, key string) ([]byte, error) // ErrNotFound if there's no such record
+	Keys(collection string) ([]string, error)          // Sorted
+	Collections() ([]string, error)                    // Every collection with at least one record, sorted
+	Update(fn func(tx Tx) error) error                 // Saves everything fn puts or deletes, or nothing if fn returns an error
+	Close() error
+}
+
+// Tx is a set of changes that will be saved together
+type Tx interface {
+	Get(collection string, key string) ([]byte, error) // Sees changes already made in this Tx
+	Put(collection string, record Record) error
+	Delete(collection string, key string) error
+}
+
+// Finder is implemented by stores that can look records up by name without reading them all.
+// Callers keep their own index for stores that can't.
+type Finder interface {
+	FindByName(collection string, name string) (key string, err error)
+}
+
+// RegisterName tells the store how to get the name of a record in a collection from its data,
+// for when records are copied from a store that doesn't keep names.
+func RegisterName(collection string, nameFunc func(data []byte) string) {
+	nameFuncs[collection] = nameFunc
+}
+
+func nameOf(collection string, record Record) string {
+	if record.Name != `` {
+		return strings.ToLower(record.Name)
+	}
+	if nameFunc, ok := nameFuncs[collection]; ok {
+		return strings.ToLower(nameFunc(record.Data))
+	}
+	return ``
+}
+
+// New opens a store without making it the active one
+func New(backend string, dataFilesPath string) (Store, error) {
+	switch strings.ToLower(backend) {
+	case BackendYAML, ``:
+		return NewFileStore(dataFilesPath)
+	case BackendSQLite:
+		return NewSQLiteStore(dataFilesPath + `/` + SQLiteFilename)
+	}
+	return nil, fmt.Errorf(`%w: %q`, ErrUnknownBackend, backend)
+}
+
+// Open makes a store the one everything is saved to, closing whatever was open before
+func Open(backend string, dataFilesPath string) error {
+
+	s, err := New(backend, dataFilesPath)
+	if err != nil {
+		return err
+	}
+
+	activeLock.Lock()
+	defer activeLock.Unlock()
+
+	if activeStore != nil {
+		activeStore.Close()
+	}
+	activeStore = s
+
+	return nil
+}
+
+// Close closes the active store. The next use opens one again from the config.
+func Close() error {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+
+	if activeStore == nil {
+		return nil
+	}
+
+	err := activeStore.Close()
+	activeStore = nil
+
+	return err
+}
+
+// Active is the store everything is saved to. If Open() hasn't been called, it is opened from the config.
+func Active() Store {
+
+	activeLock.RLock()
+	s := activeStore
+	activeLock.RUnlock()
+
+	if s != nil {
+		return s
+	}
+
+	activeLock.Lock()
+	defer activeLock.Unlock()
+
+	if activeStore == nil {
+		c := configs.GetFilePathsConfig()
+		s, err := New(c.Storage.String(), c.DataFiles.String())
+		if err != nil {
+			// Fall back to files rather than losing saves
+			s, _ = NewFileStore(c.DataFiles.String())
+		}
+		activeStore = s
+	}
+
+	return activeStore
+}
+
+func Get(collection string, key string) ([]byte, error) {
+	return Active().Get(collection, key)
+}
+
+func Keys(collection string) ([]string, error) {
+	return Active().Keys(collection)
+}
+
+// Put saves records to a collection, all or nothing
+func Put(collection string, records ...Record) error {
+	return Active().Update(func(tx Tx) error {
+		for _, r := range records {
+			if err := tx.Put(collection, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func Delete(collection string, keys ...string) error {
+	return Active().Update(func(tx Tx) error {
+		for _, k := range keys {
+			if err := tx.Delete(collection, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func Update(fn func(tx Tx) error) error {
+	return Active().Update(fn)
+}
+
+// FindByName looks up the key of a record by name, if the active store can.
+// supported is false if the store can't, and the caller should use its own index.
+func FindByName(collection string, name string) (key string, found bool, supported bool) {
+
+	finder, ok := Active().(Finder)
+	if !ok {
+		return ``, false, false
+	}
+
+	key, err := finder.FindByName(collection, name)
+
+	return key, err == nil, true
+}
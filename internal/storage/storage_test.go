@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	stores := map[string]Store{}
+	for _, backend := range []string{BackendYAML, BackendSQLite} {
+		s, err := New(backend, t.TempDir())
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		stores[backend] = s
+	}
+	return stores
+}
+
+func TestStore_PutGetDelete(t *testing.T) {
+	for backend, s := range testStores(t) {
+		t.Run(backend, func(t *testing.T) {
+
+			_, err := s.Get(Users, `1`)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+
+			err = s.Update(func(tx Tx) error {
+				if err := tx.Put(Users, Record{Key: `2`, Data: []byte(`username: bob`)}); err != nil {
+					return err
+				}
+				if err := tx.Put(Users, Record{Key: `1`, Data: []byte(`username: alice`)}); err != nil {
+					return err
+				}
+				if err := tx.Put(Alts, Record{Key: `1`, Data: []byte(`[]`)}); err != nil {
+					return err
+				}
+				return tx.Put(RoomInstances, Record{Key: `frostfang/1`, Data: []byte(`title: hi`)})
+			})
+			require.NoError(t, err)
+
+			data, err := s.Get(Users, `1`)
+			require.NoError(t, err)
+			assert.Equal(t, `username: alice`, string(data))
+
+			// Alts share a folder with users in the file store, but not a collection
+			keys, err := s.Keys(Users)
+			require.NoError(t, err)
+			assert.Equal(t, []string{`1`, `2`}, keys)
+
+			keys, err = s.Keys(RoomInstances)
+			require.NoError(t, err)
+			assert.Equal(t, []string{`frostfang/1`}, keys)
+
+			collections, err := s.Collections()
+			require.NoError(t, err)
+			assert.Equal(t, []string{Alts, RoomInstances, Users}, collections)
+
+			require.NoError(t, s.Update(func(tx Tx) error { return tx.Delete(Users, `2`) }))
+
+			_, err = s.Get(Users, `2`)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestStore_UpdateIsAllOrNothing(t *testing.T) {
+	for backend, s := range testStores(t) {
+		t.Run(backend, func(t *testing.T) {
+
+			require.NoError(t, s.Update(func(tx Tx) error {
+				return tx.Put(Users, Record{Key: `1`, Data: []byte(`gold: 100`)})
+			}))
+
+			failed := errors.New(`buyer can't afford it`)
+
+			err := s.Update(func(tx Tx) error {
+				if err := tx.Put(Users, Record{Key: `1`, Data: []byte(`gold: 0`)}); err != nil {
+					return err
+				}
+				// Changes are visible inside the Update
+				data, err := tx.Get(Users, `1`)
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, `gold: 0`, string(data))
+
+				if err := tx.Put(Users, Record{Key: `2`, Data: []byte(`gold: 100`)}); err != nil {
+					return err
+				}
+				return failed
+			})
+			assert.ErrorIs(t, err, failed)
+
+			data, err := s.Get(Users, `1`)
+			require.NoError(t, err)
+			assert.Equal(t, `gold: 100`, string(data))
+
+			_, err = s.Get(Users, `2`)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestSQLiteStore_FindByName(t *testing.T) {
+
+	RegisterName(`test-named`, func(data []byte) string { return string(data) })
+
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), SQLiteFilename))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Update(func(tx Tx) error {
+		if err := tx.Put(Users, Record{Key: `7`, Name: `Alice`, Data: []byte(`x`)}); err != nil {
+			return err
+		}
+		return tx.Put(`test-named`, Record{Key: `3`, Data: []byte(`Bob`)})
+	}))
+
+	key, err := s.FindByName(Users, `ALICE`)
+	require.NoError(t, err)
+	assert.Equal(t, `7`, key)
+
+	key, err = s.FindByName(`test-named`, `bob`)
+	require.NoError(t, err)
+	assert.Equal(t, `3`, key)
+
+	_, err = s.FindByName(Users, `nobody`)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStore_FinishesJournal(t *testing.T) {
+
+	root := t.TempDir()
+
+	// An Update that was committed, but stopped after renaming the first file
+	require.NoError(t, os.MkdirAll(filepath.Join(root, `users`), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, `users`, `1.yaml`), []byte(`new 1`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, `users`, `2.yaml`), []byte(`old 2`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, `users`, `2.yaml.new`), []byte(`new 2`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, `users`, `3.yaml`), []byte(`old 3`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, journalFilename), []byte("write\tusers/1.yaml\nwrite\tusers/2.yaml\ndelete\tusers/3.yaml\n"), 0644))
+
+	s, err := NewFileStore(root)
+	require.NoError(t, err)
+
+	data, err := s.Get(Users, `1`)
+	require.NoError(t, err)
+	assert.Equal(t, `new 1`, string(data))
+
+	data, err = s.Get(Users, `2`)
+	require.NoError(t, err)
+	assert.Equal(t, `new 2`, string(data))
+
+	_, err = s.Get(Users, `3`)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = os.Stat(filepath.Join(root, journalFilename))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFileStore_RejectsUnsafeKeys(t *testing.T) {
+
+	root := filepath.Join(t.TempDir(), `data`)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, `users`), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, `users`, `1.yaml`), []byte(`username: alice`), 0644))
+
+	s, err := NewFileStore(root)
+	require.NoError(t, err)
+
+	for _, key := range []string{``, `..`, `../users/1`, `a/../../users/1`, `./1`, `/etc/passwd`, `a//b`, `a/`, `..\users\1`} {
+
+		_, err := s.Get(APITokens, key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+
+		err = s.Update(func(tx Tx) error { return tx.Put(APITokens, Record{Key: key, Data: []byte(`x`)}) })
+		if key == `` {
+			assert.Error(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidKey, key)
+		}
+
+		err = s.Update(func(tx Tx) error { return tx.Delete(APITokens, key) })
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+
+	// Nothing outside the collection was touched
+	data, err := s.Get(Users, `1`)
+	require.NoError(t, err)
+	assert.Equal(t, `username: alice`, string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(root))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestMigrate(t *testing.T) {
+
+	root := t.TempDir()
+
+	from, err := NewFileStore(root)
+	require.NoError(t, err)
+
+	require.NoError(t, from.Update(func(tx Tx) error {
+		if err := tx.Put(Users, Record{Key: `1`, Data: []byte(`username: alice`)}); err != nil {
+			return err
+		}
+		if err := tx.Put(RoomInstances, Record{Key: `frostfang/1`, Data: []byte(`title: hi`)}); err != nil {
+			return err
+		}
+		return tx.Put(PluginData+`/leaderboards-v1.0`, Record{Key: `latest`, Data: []byte(`top: []`)})
+	}))
+
+	_, err = Migrate(BackendYAML, BackendYAML, root)
+	assert.Error(t, err)
+
+	count, err := Migrate(BackendYAML, BackendSQLite, root)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	to, err := NewSQLiteStore(filepath.Join(root, SQLiteFilename))
+	require.NoError(t, err)
+	defer to.Close()
+
+	data, err := to.Get(PluginData+`/leaderboards-v1.0`, `latest`)
+	require.NoError(t, err)
+	assert.Equal(t, `top: []`, string(data))
+
+	data, err = to.Get(RoomInstances, `frostfang/1`)
+	require.NoError(t, err)
+	assert.Equal(t, `title: hi`, string(data))
+}
+
+func TestMigrate_RoundTrip(t *testing.T) {
+
+	records := map[string]Record{
+		Users:                             {Key: `1`, Data: []byte(`username: alice`)},
+		Alts:                              {Key: `1`, Data: []byte(`[]`)},
+		RoomInstances:                     {Key: `frostfang/1`, Data: []byte(`title: hi`)},
+		APITokens:                         {Key: `0123456789ab`, Data: []byte(`tokenid: 0123456789ab`)},
+		Changelog:                         {Key: `00000001`, Data: []byte(`entryid: 1`)},
+		PluginData + `/leaderboards-v1.0`: {Key: `latest`, Data: []byte(`top: []`)},
+	}
+
+	root := t.TempDir()
+
+	from, err := NewFileStore(root)
+	require.NoError(t, err)
+
+	require.NoError(t, from.Update(func(tx Tx) error {
+		for collection, record := range records {
+			if err := tx.Put(collection, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	count, err := Migrate(BackendYAML, BackendSQLite, root)
+	require.NoError(t, err)
+	assert.Equal(t, len(records), count)
+
+	// And back again, into an empty folder
+	db, err := NewSQLiteStore(filepath.Join(root, SQLiteFilename))
+	require.NoError(t, err)
+	defer db.Close()
+
+	back, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	count, err = Copy(db, back)
+	require.NoError(t, err)
+	assert.Equal(t, len(records), count)
+
+	for _, s := range []Store{db, back} {
+
+		collections, err := s.Collections()
+		require.NoError(t, err)
+		assert.Len(t, collections, len(records), s.Backend())
+
+		for collection, record := range records {
+			data, err := s.Get(collection, record.Key)
+			require.NoError(t, err, s.Backend()+` `+collection)
+			assert.Equal(t, string(record.Data), string(data), s.Backend()+` `+collection)
+		}
+	}
+}
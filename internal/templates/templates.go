@@ -13,8 +13,10 @@ import (
 
 	"github.com/GoMudEngine/GoMud/internal/colorpatterns"
 	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/fileloader"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/term"
 	"github.com/GoMudEngine/GoMud/internal/users"
 	"github.com/GoMudEngine/ansitags"
 	"github.com/mattn/go-runewidth"
@@ -396,7 +398,9 @@ func GetTable(title string, headers []string, rows [][]string, formatting ...[]s
 	return table
 }
 
-func AnsiParse(input string) string {
+// AnsiParse parses the ansi tags in input. Given the connection it's being sent to,
+// colors are downgraded to as many as that client says it can show.
+func AnsiParse(input string, connectionId ...connections.ConnectionId) string {
 	ansiLock.RLock()
 	defer ansiLock.RUnlock()
 
@@ -404,19 +408,16 @@ func AnsiParse(input string) string {
 		return input
 	}
 
-	if forceAnsiFlags == AnsiTagsParse {
-		return ansitags.Parse(input)
-	}
-
 	if forceAnsiFlags == AnsiTagsStrip {
 		return ansitags.Parse(input, ansitags.StripTags)
 	}
 
-	if forceAnsiFlags == AnsiTagsMono {
-		return ansitags.Parse(input, ansitags.Monochrome)
+	colorDepth := term.ColorDepthUnknown
+	if len(connectionId) > 0 {
+		colorDepth = connections.GetClientSettings(connectionId[0]).Terminal.ColorDepth
 	}
 
-	return ansitags.Parse(input)
+	return string(term.DowngradeColors([]byte(ansitags.Parse(input)), colorDepth))
 }
 
 // Loads the ansi aliases from the config file
@@ -0,0 +1,46 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/GoMudEngine/ansitags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnsiParse_ColorDepth(t *testing.T) {
+
+	// As LoadAliases() sets it for the server
+	ansitags.SetColorMode(ansitags.Color256)
+
+	connDetails := connections.Add(&connections.VirtualConn{}, nil)
+	t.Cleanup(func() { connections.Remove(connDetails.ConnectionId()) })
+
+	input := `<ansi fg="196">red</ansi>`
+
+	tests := []struct {
+		name       string
+		colorDepth term.ColorDepth
+		want       string
+	}{
+		{"Unknown is sent as rendered", term.ColorDepthUnknown, "\033[38;5;196m\033[49mred\033[0m"},
+		{"256 colors", term.ColorDepth256, "\033[38;5;196m\033[49mred\033[0m"},
+		{"16 colors", term.ColorDepth16, "\033[91m\033[49mred\033[0m"},
+		{"Monochrome", term.ColorDepthMono, "\033[49mred\033[0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			cs := connections.GetClientSettings(connDetails.ConnectionId())
+			cs.Terminal.ColorDepth = tt.colorDepth
+			connections.OverwriteClientSettings(connDetails.ConnectionId(), cs)
+
+			assert.Equal(t, tt.want, AnsiParse(input, connDetails.ConnectionId()))
+		})
+	}
+
+	// Without a connection there's nothing to downgrade for
+	assert.Equal(t, "\033[38;5;196m\033[49mred\033[0m", AnsiParse(input))
+}
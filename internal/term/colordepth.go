@@ -0,0 +1,266 @@
+package term
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is how many colors a client can show
+type ColorDepth uint8
+
+const (
+	ColorDepthUnknown ColorDepth = iota // Not negotiated, text is sent as rendered (256 colors)
+	ColorDepthMono                      // No colors, bold/underline etc. only
+	ColorDepth16                        // The 8 standard colors and their bright versions
+	ColorDepth256                       // xterm 256 color palette
+	ColorDepthTrue                      // 24 bit color
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepthMono:
+		return `mono`
+	case ColorDepth16:
+		return `16`
+	case ColorDepth256:
+		return `256`
+	case ColorDepthTrue:
+		return `truecolor`
+	}
+	return `unknown`
+}
+
+var (
+	// The xterm values of the first 16 colors of the 256 color palette
+	palette16 = [16][3]int{
+		{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+		{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+	}
+	// The levels each channel can have in the 6x6x6 color cube (16-231)
+	cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+)
+
+// DowngradeColors rewrites the color codes in ANSI escape sequences so a client that can only show depth colors
+// gets the closest match, rather than garbage or nothing at all.
+// Anything that isn't an SGR (ESC [ ... m) sequence is left alone.
+func DowngradeColors(b []byte, depth ColorDepth) []byte {
+
+	if depth == ColorDepthUnknown || depth == ColorDepthTrue {
+		return b
+	}
+
+	if !bytes.Contains(b, []byte{ANSI_ESC, '['}) {
+		return b
+	}
+
+	out := make([]byte, 0, len(b))
+
+	for len(b) > 0 {
+
+		start := bytes.Index(b, []byte{ANSI_ESC, '['})
+		if start == -1 {
+			out = append(out, b...)
+			break
+		}
+
+		out = append(out, b[:start]...)
+		b = b[start:]
+
+		// Find the final byte of the sequence
+		end := 2
+		for end < len(b) && (b[end] < 0x40 || b[end] > 0x7E) {
+			end++
+		}
+
+		if end == len(b) {
+			// Incomplete sequence, nothing more to do
+			out = append(out, b...)
+			break
+		}
+
+		if b[end] != 'm' {
+			out = append(out, b[:end+1]...)
+			b = b[end+1:]
+			continue
+		}
+
+		params := string(b[2:end])
+		b = b[end+1:]
+
+		// An empty parameter list is a reset, which is always fine
+		if params == `` {
+			out = append(out, ANSI_ESC, '[', 'm')
+			continue
+		}
+
+		if newParams := downgradeSGR(strings.Split(params, `;`), depth); len(newParams) > 0 {
+			out = append(out, ANSI_ESC, '[')
+			out = append(out, strings.Join(newParams, `;`)...)
+			out = append(out, 'm')
+		}
+	}
+
+	return out
+}
+
+// downgradeSGR rewrites the parameters of a single SGR sequence.
+func downgradeSGR(params []string, depth ColorDepth) []string {
+
+	newParams := make([]string, 0, len(params))
+
+	for i := 0; i < len(params); i++ {
+
+		code, err := strconv.Atoi(params[i])
+		if err != nil {
+			newParams = append(newParams, params[i])
+			continue
+		}
+
+		switch {
+
+		case code == 38 || code == 48:
+
+			background := code == 48
+
+			// 38;5;n or 38;2;r;g;b
+			if i+1 >= len(params) {
+				continue
+			}
+
+			var r, g, bl, index int
+			isIndex := false
+
+			switch params[i+1] {
+			case `5`:
+				if i+2 >= len(params) {
+					i = len(params)
+					continue
+				}
+				index, _ = strconv.Atoi(params[i+2])
+				isIndex = true
+				i += 2
+			case `2`:
+				if i+4 >= len(params) {
+					i = len(params)
+					continue
+				}
+				r, _ = strconv.Atoi(params[i+2])
+				g, _ = strconv.Atoi(params[i+3])
+				bl, _ = strconv.Atoi(params[i+4])
+				i += 4
+			default:
+				i++
+				continue
+			}
+
+			if depth == ColorDepthMono {
+				continue
+			}
+
+			if depth == ColorDepth256 {
+				if !isIndex {
+					index = rgbTo256(r, g, bl)
+				}
+				newParams = append(newParams, strconv.Itoa(code), `5`, strconv.Itoa(index))
+				continue
+			}
+
+			// 16 colors
+			if isIndex {
+				r, g, bl = color256ToRGB(index)
+			}
+			newParams = append(newParams, strconv.Itoa(color16Code(rgbTo16(r, g, bl), background)))
+
+		case (code >= 30 && code <= 37) || (code >= 40 && code <= 47) || (code >= 90 && code <= 97) || (code >= 100 && code <= 107):
+
+			if depth == ColorDepthMono {
+				continue
+			}
+			newParams = append(newParams, params[i])
+
+		default:
+			newParams = append(newParams, params[i])
+		}
+	}
+
+	return newParams
+}
+
+// color16Code turns a 16 color index into its SGR code
+func color16Code(index int, background bool) int {
+	code := 30 + index
+	if index > 7 {
+		code = 90 + index - 8
+	}
+	if background {
+		code += 10
+	}
+	return code
+}
+
+func color256ToRGB(index int) (int, int, int) {
+
+	switch {
+	case index < 0 || index > 255:
+		return 0, 0, 0
+	case index < 16:
+		return palette16[index][0], palette16[index][1], palette16[index][2]
+	case index < 232:
+		index -= 16
+		return cubeLevels[index/36], cubeLevels[(index/6)%6], cubeLevels[index%6]
+	}
+
+	gray := 8 + (index-232)*10
+	return gray, gray, gray
+}
+
+func rgbTo256(r, g, b int) int {
+
+	cubeIndex := func(v int) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(v-level) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	cube := 16 + 36*cubeIndex(r) + 6*cubeIndex(g) + cubeIndex(b)
+
+	// The grayscale ramp is often closer than the cube for grays
+	avg := (r + g + b) / 3
+	grayIndex := 232 + min(max((avg-3)/10, 0), 23)
+
+	cr, cg, cb := color256ToRGB(cube)
+	gr, gg, gb := color256ToRGB(grayIndex)
+	if colorDistance(r, g, b, gr, gg, gb) < colorDistance(r, g, b, cr, cg, cb) {
+		return grayIndex
+	}
+
+	return cube
+}
+
+func rgbTo16(r, g, b int) int {
+	best := 0
+	bestDistance := -1
+	for i, c := range palette16 {
+		if d := colorDistance(r, g, b, c[0], c[1], c[2]); bestDistance == -1 || d < bestDistance {
+			best = i
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package term
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDowngradeColors(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		depth    ColorDepth
+		expected string
+	}{
+		{"Unknown is untouched", "\033[38;5;196mred\033[39m", ColorDepthUnknown, "\033[38;5;196mred\033[39m"},
+		{"Truecolor is untouched", "\033[38;2;1;2;3mx", ColorDepthTrue, "\033[38;2;1;2;3mx"},
+		{"256 keeps 256", "\033[38;5;196mred\033[48;5;21m", ColorDepth256, "\033[38;5;196mred\033[48;5;21m"},
+		{"Truecolor to 256", "\033[38;2;255;0;0mred", ColorDepth256, "\033[38;5;196mred"},
+		{"Truecolor gray to 256", "\033[48;2;128;128;128m", ColorDepth256, "\033[48;5;244m"},
+		{"256 to 16", "\033[38;5;196mred\033[48;5;4mblue", ColorDepth16, "\033[91mred\033[44mblue"},
+		{"Truecolor to 16", "\033[1;38;2;0;200;0mgreen", ColorDepth16, "\033[1;32mgreen"},
+		{"Mono drops colors", "\033[1;38;5;196mred\033[0m \033[31mx\033[39;49m", ColorDepthMono, "\033[1mred\033[0m x\033[39;49m"},
+		{"Mono drops color-only sequences", "\033[38;5;196mred", ColorDepthMono, "red"},
+		{"Other sequences untouched", "\033[2J\033[1;1H\033[m", ColorDepthMono, "\033[2J\033[1;1H\033[m"},
+		{"Incomplete sequence", "abc\033[38;5", ColorDepth16, "abc\033[38;5"},
+		{"No sequences", "plain text", ColorDepthMono, "plain text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(DowngradeColors([]byte(tt.input), tt.depth)))
+		})
+	}
+}
+
+func TestParseMTTS(t *testing.T) {
+
+	m, ok := ParseMTTS(`MTTS 2825`)
+	assert.True(t, ok)
+	assert.True(t, m.Has(MttsAnsi))
+	assert.False(t, m.Has(MttsUTF8))
+	assert.True(t, m.Has(Mtts256Colors))
+	assert.True(t, m.Has(MttsTrueColor))
+	assert.Equal(t, ColorDepthTrue, m.ColorDepth())
+
+	_, ok = ParseMTTS(`XTERM`)
+	assert.False(t, ok)
+
+	_, ok = ParseMTTS(`MTTS abc`)
+	assert.False(t, ok)
+}
@@ -0,0 +1,46 @@
+package term
+
+import "bytes"
+
+const (
+	MCCP2 IACByte = 86 // Server to client compression https://tintin.mudhalla.net/protocols/mccp/
+	MCCP3 IACByte = 87 // Client to server compression
+)
+
+/*
+Handshake (MCCP2)
+The server sends IAC WILL MCCP2.
+The client responds with IAC DO MCCP2 or IAC DONT MCCP2.
+Once the server receives IAC DO MCCP2 it sends IAC SB MCCP2 IAC SE, and everything it sends after that is a zlib stream.
+
+Handshake (MCCP3)
+The server sends IAC WILL MCCP3.
+The client responds with IAC DO MCCP3 or IAC DONT MCCP3.
+Once agreed, the client sends IAC SB MCCP3 IAC SE, and everything it sends after that is a zlib stream.
+*/
+
+var (
+	Mccp2Enable = TerminalCommand{[]byte{TELNET_IAC, TELNET_WILL, MCCP2}, []byte{}} // Indicates the server wants to compress what it sends
+	Mccp2Accept = TerminalCommand{[]byte{TELNET_IAC, TELNET_DO, MCCP2}, []byte{}}   // Indicates the client accepts MCCP2
+	Mccp2Refuse = TerminalCommand{[]byte{TELNET_IAC, TELNET_DONT, MCCP2}, []byte{}} // Indicates the client refuses (or wants to stop) MCCP2
+	Mccp2Start  = TerminalCommand{[]byte{TELNET_IAC, TELNET_SB, MCCP2, TELNET_IAC, TELNET_SE}, []byte{}}
+
+	Mccp3Enable = TerminalCommand{[]byte{TELNET_IAC, TELNET_WILL, MCCP3}, []byte{}} // Indicates the server will accept compressed input
+	Mccp3Accept = TerminalCommand{[]byte{TELNET_IAC, TELNET_DO, MCCP3}, []byte{}}   // Indicates the client will compress what it sends
+	Mccp3Refuse = TerminalCommand{[]byte{TELNET_IAC, TELNET_DONT, MCCP3}, []byte{}} // Indicates the client refuses MCCP3
+	Mccp3Start  = TerminalCommand{[]byte{TELNET_IAC, TELNET_SB, MCCP3, TELNET_IAC, TELNET_SE}, []byte{}}
+)
+
+func IsMCCPCommand(b []byte) bool {
+	return len(b) > 2 && b[0] == TELNET_IAC && (b[2] == MCCP2 || b[2] == MCCP3)
+}
+
+// SplitMccp3Start looks for the client starting MCCP3 in raw input.
+// Everything after the start sequence is compressed.
+func SplitMccp3Start(b []byte) (before []byte, compressed []byte, found bool) {
+	idx := bytes.Index(b, Mccp3Start.Chars)
+	if idx == -1 {
+		return b, nil, false
+	}
+	return b[:idx], b[idx+len(Mccp3Start.Chars):], true
+}
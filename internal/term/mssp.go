@@ -0,0 +1,93 @@
+package term
+
+import "bytes"
+
+const (
+	MSSP IACByte = 70 // Mud Server Status Protocol https://tintin.mudhalla.net/protocols/mssp/
+
+	MSSP_VAR IACByte = 1
+	MSSP_VAL IACByte = 2
+
+	// Crawlers that don't speak telnet send this as a line of text instead
+	MsspRequest = `MSSP-REQUEST`
+)
+
+/*
+Handshake
+The server sends IAC WILL MSSP.
+The client responds with IAC DO MSSP or IAC DONT MSSP.
+Once the server receives IAC DO MSSP it sends IAC SB MSSP MSSP_VAR "NAME" MSSP_VAL "value" ... IAC SE
+A variable may have more than one MSSP_VAL.
+*/
+
+var (
+	MsspEnable = TerminalCommand{[]byte{TELNET_IAC, TELNET_WILL, MSSP}, []byte{}} // Indicates the server can report its status
+	MsspAccept = TerminalCommand{[]byte{TELNET_IAC, TELNET_DO, MSSP}, []byte{}}   // Indicates the client wants the status
+	MsspRefuse = TerminalCommand{[]byte{TELNET_IAC, TELNET_DONT, MSSP}, []byte{}} // Indicates the client doesn't want the status
+	MsspStatus = TerminalCommand{[]byte{TELNET_IAC, TELNET_SB, MSSP}, []byte{TELNET_IAC, TELNET_SE}}
+)
+
+// MsspVar is a single MSSP variable, in the order it should be sent
+type MsspVar struct {
+	Name   string
+	Values []string
+}
+
+func IsMSSPCommand(b []byte) bool {
+	return len(b) > 2 && b[0] == TELNET_IAC && b[2] == MSSP
+}
+
+// MsspPayload encodes variables for the MsspStatus subnegotiation.
+// Bytes that would break the subnegotiation are dropped from names and values.
+func MsspPayload(vars []MsspVar) []byte {
+
+	clean := func(s string) []byte {
+		out := make([]byte, 0, len(s))
+		for _, b := range []byte(s) {
+			if b == MSSP_VAR || b == MSSP_VAL || b == TELNET_IAC || b == 0 {
+				continue
+			}
+			out = append(out, b)
+		}
+		return out
+	}
+
+	payload := []byte{}
+	for _, v := range vars {
+		payload = append(payload, MSSP_VAR)
+		payload = append(payload, clean(v.Name)...)
+		for _, val := range v.Values {
+			payload = append(payload, MSSP_VAL)
+			payload = append(payload, clean(val)...)
+		}
+	}
+
+	return payload
+}
+
+// MsspPlainText encodes variables as the reply to a plain text MSSP-REQUEST.
+// Each line is the name followed by its values, separated by tabs.
+func MsspPlainText(vars []MsspVar) []byte {
+
+	clean := func(s string) []byte {
+		return bytes.Map(func(r rune) rune {
+			if r == '\t' || r == '\r' || r == '\n' {
+				return ' '
+			}
+			return r
+		}, []byte(s))
+	}
+
+	out := []byte("\r\nMSSP-REPLY-START\r\n")
+	for _, v := range vars {
+		out = append(out, clean(v.Name)...)
+		for _, val := range v.Values {
+			out = append(out, '\t')
+			out = append(out, clean(val)...)
+		}
+		out = append(out, '\r', '\n')
+	}
+	out = append(out, []byte("MSSP-REPLY-END\r\n")...)
+
+	return out
+}
@@ -0,0 +1,109 @@
+package term
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	TTYPE_IS   IACByte = 0
+	TTYPE_SEND IACByte = 1
+)
+
+/*
+Handshake (TTYPE with MTTS) https://tintin.mudhalla.net/protocols/mtts/
+The server sends IAC DO TTYPE.
+The client responds with IAC WILL TTYPE or IAC WONT TTYPE.
+The server then sends IAC SB TTYPE SEND IAC SE, and the client responds with IAC SB TTYPE IS "value" IAC SE.
+Each time the server asks again the client moves on to its next value:
+ 1. The client name, such as MUDLET
+ 2. The terminal type, such as XTERM-256COLOR
+ 3. "MTTS <bitflags>", describing what the client supports
+The client repeats its last value once it runs out, which is when the server stops asking.
+*/
+
+var (
+	TtypeRequest = TerminalCommand{[]byte{TELNET_IAC, TELNET_DO, TELNET_OPT_TERM_TYPE}, []byte{}}   // Asks the client to report its terminal type
+	TtypeAccept  = TerminalCommand{[]byte{TELNET_IAC, TELNET_WILL, TELNET_OPT_TERM_TYPE}, []byte{}} // Indicates the client will report its terminal type
+	TtypeRefuse  = TerminalCommand{[]byte{TELNET_IAC, TELNET_WONT, TELNET_OPT_TERM_TYPE}, []byte{}} // Indicates the client won't report its terminal type
+	TtypeSend    = TerminalCommand{[]byte{TELNET_IAC, TELNET_SB, TELNET_OPT_TERM_TYPE, TTYPE_SEND, TELNET_IAC, TELNET_SE}, []byte{}}
+	TtypeIs      = TerminalCommand{[]byte{TELNET_IAC, TELNET_SB, TELNET_OPT_TERM_TYPE, TTYPE_IS}, []byte{TELNET_IAC, TELNET_SE}}
+)
+
+func IsTTYPECommand(b []byte) bool {
+	return len(b) > 2 && b[0] == TELNET_IAC && b[2] == TELNET_OPT_TERM_TYPE
+}
+
+// MTTS bitflags, reported by the client as "MTTS <sum of flags>"
+type MTTS uint16
+
+const (
+	MttsAnsi          MTTS = 1
+	MttsVT100         MTTS = 2
+	MttsUTF8          MTTS = 4
+	Mtts256Colors     MTTS = 8
+	MttsMouseTracking MTTS = 16
+	MttsOSCPalette    MTTS = 32
+	MttsScreenReader  MTTS = 64
+	MttsProxy         MTTS = 128
+	MttsTrueColor     MTTS = 256
+	MttsMNES          MTTS = 512
+	MttsMSLP          MTTS = 1024
+	MttsSSL           MTTS = 2048
+)
+
+// ParseMTTS reads a TTYPE value such as "MTTS 2825", returning false if it isn't an MTTS value
+func ParseMTTS(value string) (MTTS, bool) {
+
+	flagStr, found := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(value)), `MTTS `)
+	if !found {
+		return 0, false
+	}
+
+	flags, err := strconv.ParseUint(strings.TrimSpace(flagStr), 10, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return MTTS(flags), true
+}
+
+func (m MTTS) Has(flag MTTS) bool {
+	return m&flag == flag
+}
+
+// ColorDepth returns the most colors the flags say the client can show
+func (m MTTS) ColorDepth() ColorDepth {
+	if m.Has(MttsTrueColor) {
+		return ColorDepthTrue
+	}
+	if m.Has(Mtts256Colors) {
+		return ColorDepth256
+	}
+	if m.Has(MttsAnsi) {
+		return ColorDepth16
+	}
+	return ColorDepthMono
+}
+
+// TerminalColorDepth guesses how many colors a terminal type (such as XTERM-256COLOR) can show,
+// for clients that don't report MTTS flags.
+// Returns ColorDepthUnknown if it can't tell.
+func TerminalColorDepth(terminalType string) ColorDepth {
+
+	terminalType = strings.ToUpper(strings.TrimSpace(terminalType))
+
+	switch {
+	case strings.Contains(terminalType, `TRUECOLOR`), strings.HasSuffix(terminalType, `-DIRECT`):
+		return ColorDepthTrue
+	case strings.Contains(terminalType, `256COLOR`):
+		return ColorDepth256
+	case terminalType == `DUMB`:
+		return ColorDepthMono
+	case strings.HasPrefix(terminalType, `ANSI`), strings.HasPrefix(terminalType, `VT100`), strings.HasPrefix(terminalType, `XTERM`),
+		strings.HasPrefix(terminalType, `SCREEN`), strings.HasPrefix(terminalType, `LINUX`):
+		return ColorDepth16
+	}
+
+	return ColorDepthUnknown
+}
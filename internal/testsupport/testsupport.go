@@ -0,0 +1,60 @@
+// Package testsupport is the setup shared by tests in many packages.
+// It is only meant to be imported from _test.go files.
+package testsupport
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+// Main sets up logging and runs a package's tests. Call it from TestMain.
+func Main(m *testing.M) {
+	mudlog.SetupLogger(nil, `LOW`, ``, false)
+	os.Exit(m.Run())
+}
+
+// UseConfig applies config overrides until the test finishes
+func UseConfig(t testing.TB, overrides map[string]any) {
+	t.Helper()
+
+	snapshot := configs.TakeSnapshot()
+	t.Cleanup(snapshot.Restore)
+
+	if err := configs.AddOverlayOverrides(overrides); err != nil {
+		t.Fatalf("config overrides: %v", err)
+	}
+}
+
+// UseDataFiles points FilePaths.DataFiles at an empty temporary folder, along with any other
+// overrides given, until the test finishes. It returns the folder.
+func UseDataFiles(t testing.TB, overrides map[string]any) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	withDataFiles := map[string]any{`FilePaths.DataFiles`: dir}
+	for k, v := range overrides {
+		withDataFiles[k] = v
+	}
+
+	UseConfig(t, withDataFiles)
+
+	return dir
+}
+
+// FreezeClock stops the game clock at a given time until the test finishes.
+// Move it along with Advance().
+func FreezeClock(t testing.TB, at time.Time) *util.SimClock {
+	t.Helper()
+
+	clock := util.NewSimClock(at)
+	util.SetClock(clock.Now)
+	t.Cleanup(func() { util.SetClock(nil) })
+
+	return clock
+}
@@ -0,0 +1,119 @@
+package usercommands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+/*
+* Role Permissions:
+* apitoken 				(All)
+ */
+func APIToken(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 || strings.EqualFold(args[0], `help`) {
+		infoOutput, _ := templates.Process("admincommands/help/command.apitoken", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	// Only admins can see or manage tokens that belong to someone else,
+	// otherwise a role with this command could borrow an admin's access.
+	isAdmin := user.Role == users.RoleAdmin
+
+	switch strings.ToLower(args[0]) {
+
+	case `list`:
+
+		tokens, err := users.GetAPITokens()
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Could not list api tokens: %s`, err.Error()))
+			return true, nil
+		}
+
+		headers := []string{`Id`, `Name`, `User`, `Created`}
+		rows := [][]string{}
+
+		for _, t := range tokens {
+			if !isAdmin && t.UserId != user.UserId {
+				continue
+			}
+			rows = append(rows, []string{t.TokenId, t.Name, fmt.Sprintf(`%s (%d)`, t.Username, t.UserId), t.Created.Format(`2006-01-02 15:04`)})
+		}
+
+		tableData := templates.GetTable(`API Tokens`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		return true, nil
+
+	case `create`:
+
+		if len(args) < 2 {
+			user.SendText(`Create a token for who? <ansi fg="command">apitoken create [username] [name]</ansi>`)
+			return true, nil
+		}
+
+		tokenUser := user
+		if !strings.EqualFold(args[1], user.Username) {
+
+			if !isAdmin {
+				user.SendText(`You can only create tokens for yourself.`)
+				return true, nil
+			}
+
+			var err error
+			if tokenUser, err = users.LoadUser(args[1], true); err != nil {
+				user.SendText(fmt.Sprintf(`Could not find <ansi fg="username">%s</ansi>.`, args[1]))
+				return true, nil
+			}
+		}
+
+		tokenStr, token, err := users.CreateAPIToken(tokenUser, strings.Join(args[2:], ` `))
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Could not create an api token: %s`, err.Error()))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Created api token <ansi fg="yellow">%s</ansi> for <ansi fg="username">%s</ansi> (role: %s).`, token.TokenId, tokenUser.Username, tokenUser.Role))
+		user.SendText(`Copy it now, it won't be shown again:`)
+		user.SendText(fmt.Sprintf(`    <ansi fg="yellow-bold">%s</ansi>`, tokenStr))
+
+		return true, nil
+
+	case `revoke`:
+
+		if len(args) < 2 {
+			user.SendText(`Revoke which token? <ansi fg="command">apitoken revoke [id]</ansi>`)
+			return true, nil
+		}
+
+		token, err := users.GetAPIToken(args[1])
+		if err != nil || (!isAdmin && token.UserId != user.UserId) {
+			user.SendText(fmt.Sprintf(`No api token with id <ansi fg="yellow">%s</ansi>.`, args[1]))
+			return true, nil
+		}
+
+		if err := users.RevokeAPIToken(token.TokenId); err != nil {
+			user.SendText(fmt.Sprintf(`Could not revoke <ansi fg="yellow">%s</ansi>: %s`, token.TokenId, err.Error()))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Revoked api token <ansi fg="yellow">%s</ansi>.`, token.TokenId))
+
+		return true, nil
+	}
+
+	infoOutput, _ := templates.Process("admincommands/help/command.apitoken", nil, user.UserId)
+	user.SendText(infoOutput)
+
+	return true, nil
+}
@@ -0,0 +1,137 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/backups"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+/*
+* Role Permissions:
+* backup 				(All)
+ */
+func Backup(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 || strings.EqualFold(args[0], `help`) {
+		infoOutput, _ := templates.Process("admincommands/help/command.backup", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	switch strings.ToLower(args[0]) {
+
+	case `list`:
+
+		snapshots, err := backups.List()
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Could not list snapshots: %s`, err.Error()))
+			return true, nil
+		}
+
+		headers := []string{`Snapshot`, `Size`, `Taken`}
+		rows := [][]string{}
+
+		for _, s := range snapshots {
+			rows = append(rows, []string{s.Name, fmt.Sprintf(`%.1fKB`, float64(s.Size)/1024), s.Created.Format(`2006-01-02 15:04`)})
+		}
+
+		tableData := templates.GetTable(`Backup Snapshots`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		return true, nil
+
+	case `now`:
+
+		user.SendText(`Saving everything and taking a snapshot...`)
+		events.AddToQueue(events.Backup{UserId: user.UserId})
+
+		return true, nil
+
+	case `restore`:
+
+		if len(args) < 3 {
+			user.SendText(`Restore what? <ansi fg="command">backup restore [snapshot] user [name]</ansi> or <ansi fg="command">backup restore [snapshot] zone [zone]</ansi>`)
+			return true, nil
+		}
+
+		snapshotName := args[1]
+		what := strings.ToLower(args[2])
+		target := strings.Join(args[3:], ` `)
+
+		if what == `all` {
+			user.SendText(`Everything can only be restored while the server is stopped, with <ansi fg="command">-restore=` + snapshotName + `</ansi>`)
+			return true, nil
+		}
+
+		if target == `` {
+			user.SendText(fmt.Sprintf(`Which %s?`, what))
+			return true, nil
+		}
+
+		switch what {
+
+		case `user`:
+
+			userId, _ := strconv.Atoi(target)
+			if userId == 0 {
+				userId = users.FindUserId(target)
+			}
+
+			if userId > 0 && users.GetByUserId(userId) != nil {
+				user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> is online. They must log out first.`, target))
+				return true, nil
+			}
+
+			snap, userId, err := backups.RestoreUser(snapshotName, target)
+			if err != nil {
+				user.SendText(fmt.Sprintf(`Could not restore <ansi fg="username">%s</ansi>: %s`, target, err.Error()))
+				return true, nil
+			}
+
+			// In case they were renamed since
+			users.RebuildIndex()
+
+			user.SendText(fmt.Sprintf(`Restored <ansi fg="username">%s</ansi> (UserId %d) from <ansi fg="yellow">%s</ansi>.`, target, userId, snap.Name))
+
+			return true, nil
+
+		case `zone`:
+
+			zoneName := rooms.FindZoneName(target)
+			if zoneName == `` {
+				user.SendText(fmt.Sprintf(`No zone matches <ansi fg="zone">%s</ansi>.`, target))
+				return true, nil
+			}
+
+			if err := rooms.UnloadZone(zoneName); err != nil {
+				user.SendText(fmt.Sprintf(`Could not restore <ansi fg="zone">%s</ansi>: %s`, zoneName, err.Error()))
+				return true, nil
+			}
+
+			snap, restoredCt, err := backups.RestoreZone(snapshotName, rooms.ZoneToFolder(zoneName))
+			if err != nil {
+				user.SendText(fmt.Sprintf(`Could not restore <ansi fg="zone">%s</ansi>: %s`, zoneName, err.Error()))
+				return true, nil
+			}
+
+			user.SendText(fmt.Sprintf(`Restored <ansi fg="zone">%s</ansi> from <ansi fg="yellow">%s</ansi> (%d rooms with saved changes).`, zoneName, snap.Name, restoredCt))
+
+			return true, nil
+		}
+	}
+
+	infoOutput, _ := templates.Process("admincommands/help/command.backup", nil, user.UserId)
+	user.SendText(infoOutput)
+
+	return true, nil
+}
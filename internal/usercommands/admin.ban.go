@@ -0,0 +1,162 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/bans"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+/*
+* Role Permissions:
+* ban 				(All)
+ */
+func Ban(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 || strings.EqualFold(args[0], `help`) {
+		infoOutput, _ := templates.Process("admincommands/help/command.ban", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	switch strings.ToLower(args[0]) {
+
+	case `list`:
+
+		headers := []string{`Kind`, `Banned`, `Reason`, `By`, `Expires`}
+		rows := [][]string{}
+
+		for _, b := range bans.List() {
+			expires := `never`
+			if !b.Expires.IsZero() {
+				expires = b.Expires.Format(`2006-01-02 15:04`)
+			}
+			rows = append(rows, []string{b.Kind, b.Value, b.Reason, b.By, expires})
+		}
+
+		tableData := templates.GetTable(`Bans`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		return true, nil
+
+	case `lockouts`:
+
+		headers := []string{`Kind`, `Locked Out`, `Failures`, `Until`}
+		rows := [][]string{}
+
+		for _, l := range bans.Lockouts() {
+			rows = append(rows, []string{l.Kind, l.Value, strconv.Itoa(l.Failures), l.Until.Format(`15:04:05`)})
+		}
+
+		tableData := templates.GetTable(`Login Lockouts`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		return true, nil
+
+	case `unlock`:
+
+		if len(args) < 2 {
+			user.SendText(`Unlock who? <ansi fg="command">ban unlock [username|ip]</ansi>`)
+			return true, nil
+		}
+
+		if !bans.Unlock(args[1]) {
+			user.SendText(fmt.Sprintf(`<ansi fg="red">%s</ansi> isn't locked out.`, args[1]))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`<ansi fg="red">%s</ansi> is no longer locked out.`, args[1]))
+		return true, nil
+	}
+
+	// ban [username|ip|cidr] [duration] [reason]
+	kind, value, err := bans.Kind(args[0])
+	if err != nil {
+		user.SendText(err.Error())
+		return true, nil
+	}
+
+	if kind == bans.KindUsername && !users.Exists(value) {
+		user.SendText(fmt.Sprintf(`There is no user named <ansi fg="username">%s</ansi>.`, value))
+		return true, nil
+	}
+
+	var duration time.Duration
+	reasonStart := 1
+
+	if len(args) > 1 {
+		if strings.EqualFold(args[1], `forever`) {
+			reasonStart = 2
+		} else if d, err := bans.ParseDuration(args[1]); err == nil {
+			duration = d
+			reasonStart = 2
+		}
+	}
+
+	ban, err := bans.Add(value, duration, strings.Join(args[reasonStart:], ` `), user.Username)
+	if err != nil {
+		user.SendText(err.Error())
+		return true, nil
+	}
+
+	expires := `It never expires.`
+	if duration > 0 {
+		expires = fmt.Sprintf(`It expires in %s.`, duration)
+	}
+	user.SendText(fmt.Sprintf(`<ansi fg="red">%s</ansi> (%s) has been <ansi fg="alert-5">BANNED</ansi>. %s`, ban.Value, ban.Kind, expires))
+
+	// Anyone already connected that the ban applies to is disconnected
+	banErr := bans.BanError{Ban: ban}
+	for _, connId := range connections.GetAllConnectionIds() {
+
+		cd := connections.Get(connId)
+		if cd == nil || connId == user.ConnectionId() {
+			continue
+		}
+
+		username := ``
+		if u := users.GetByConnectionId(connId); u != nil {
+			username = u.Username
+		}
+
+		if !ban.Matches(username, bans.AddressIP(cd.RemoteAddr())) {
+			continue
+		}
+
+		connections.SendTo([]byte(banErr.Error()+"\r\n"), connId)
+		connections.Kick(connId, `banned`)
+
+		user.SendText(fmt.Sprintf(`Disconnected connection #%d (%s).`, connId, username))
+	}
+
+	return true, nil
+}
+
+func Unban(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	if rest == `` {
+		infoOutput, _ := templates.Process("admincommands/help/command.ban", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	if !bans.Remove(rest, user.Username) {
+		user.SendText(fmt.Sprintf(`<ansi fg="red">%s</ansi> isn't banned.`, rest))
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`<ansi fg="red">%s</ansi> has been <ansi fg="alert-1">UNBANNED</ansi>.`, rest))
+	return true, nil
+}
@@ -0,0 +1,29 @@
+package usercommands
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/copyover"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+/*
+* Role Permissions:
+* copyover 				(All)
+ */
+func Copyover(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	if !copyover.Supported() {
+		user.SendText(`Copyover isn't supported on this platform.`)
+		return true, nil
+	}
+
+	mudlog.Warn("Copyover", "requestedBy", user.Username)
+
+	user.SendText(`Starting copyover...`)
+
+	copyover.Request(user.UserId)
+
+	return true, nil
+}
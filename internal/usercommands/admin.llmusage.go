@@ -0,0 +1,142 @@
+package usercommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/llm"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+/*
+* Role Permissions:
+* llmusage 				(All)
+* llmusage.reset		(Reset usage)
+ */
+func LLMUsage(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	args := util.SplitButRespectQuotes(strings.ToLower(rest))
+
+	if len(args) > 0 && args[0] == `help` {
+		infoOutput, _ := templates.Process("admincommands/help/command.llmusage", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	if len(args) > 0 && args[0] == `reset` {
+
+		if !user.HasRolePermission(`llmusage.reset`) {
+			user.SendText(`you do not have <ansi fg="command">llmusage.reset</ansi> permission`)
+			return true, nil
+		}
+
+		if len(args) < 2 {
+			user.SendText(`Reset whose usage? <ansi fg="command">llmusage reset [username|server]</ansi>`)
+			return true, nil
+		}
+
+		if args[1] == `server` {
+			llm.ResetServerQuotaUsage()
+			user.SendText(`Server LLM usage has been reset.`)
+			return true, nil
+		}
+
+		userId, name := llmUsageFindUser(args[1])
+		if userId == 0 {
+			user.SendText(`Could not find user.`)
+			return true, nil
+		}
+
+		llm.ResetUserQuotaUsage(userId)
+		user.SendText(fmt.Sprintf(`LLM usage for <ansi fg="username">%s</ansi> has been reset.`, name))
+		return true, nil
+	}
+
+	quotas := configs.GetIntegrationsConfig().LLM.Quotas
+
+	headers := []string{`Who`, `Role`, `Today Tokens`, `Today Requests`, `Month Tokens`, `Month Requests`}
+	rows := [][]string{}
+
+	if len(args) > 0 {
+
+		userId, name := llmUsageFindUser(args[0])
+		if userId == 0 {
+			user.SendText(`Could not find user.`)
+			return true, nil
+		}
+
+		role := llmUsageRole(userId)
+		rows = append(rows, llmUsageRow(name, role, llm.GetUserQuotaUsage(userId), llm.GetRoleQuota(role)))
+
+	} else {
+
+		rows = append(rows, llmUsageRow(`(server)`, ``, llm.GetServerQuotaUsage(), quotas.Server))
+
+		for _, userId := range llm.GetQuotaUserIds() {
+			name := `#` + strconv.Itoa(userId)
+			if u := users.GetByUserId(userId); u != nil {
+				name = u.Username
+			}
+			role := llmUsageRole(userId)
+			rows = append(rows, llmUsageRow(name, role, llm.GetUserQuotaUsage(userId), llm.GetRoleQuota(role)))
+		}
+	}
+
+	title := `LLM Usage`
+	if !bool(quotas.Enabled) {
+		title += ` (quotas not enforced)`
+	}
+
+	tableData := templates.GetTable(title, headers, rows)
+	tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+
+	user.SendText(tplTxt)
+
+	return true, nil
+}
+
+// llmUsageFindUser finds a user by username or character name, online or not
+func llmUsageFindUser(name string) (userId int, username string) {
+
+	if u := users.GetByCharacterName(name); u != nil {
+		return u.UserId, u.Username
+	}
+
+	if userId = users.FindUserId(name); userId > 0 {
+		return userId, name
+	}
+
+	return 0, ``
+}
+
+func llmUsageRole(userId int) string {
+	if u := users.GetByUserId(userId); u != nil {
+		return u.Role
+	}
+	return `(offline)`
+}
+
+func llmUsageRow(name string, role string, usage llm.QuotaUsage, limits configs.IntegrationsLLMQuota) []string {
+
+	format := func(used int, limit int) string {
+		if limit < 1 {
+			return strconv.Itoa(used)
+		}
+		return fmt.Sprintf(`%d/%d`, used, limit)
+	}
+
+	return []string{
+		name,
+		role,
+		format(usage.DailyTokens, int(limits.DailyTokens)),
+		format(usage.DailyRequests, int(limits.DailyRequests)),
+		format(usage.MonthlyTokens, int(limits.MonthlyTokens)),
+		format(usage.MonthlyRequests, int(limits.MonthlyRequests)),
+	}
+}
@@ -0,0 +1,103 @@
+package usercommands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/recordings"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+/*
+* Role Permissions:
+* record 				(All)
+ */
+func Record(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) == 0 || strings.EqualFold(args[0], `help`) {
+		infoOutput, _ := templates.Process("admincommands/help/command.record", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	switch strings.ToLower(args[0]) {
+
+	case `list`:
+
+		headers := []string{`Player`, `Connection`, `Started`, `File`}
+		rows := [][]string{}
+
+		for _, r := range recordings.Active() {
+			rows = append(rows, []string{r.Username, fmt.Sprintf(`%d`, r.ConnectionId), r.Started.Format(`2006-01-02 15:04`), r.FileName()})
+		}
+
+		tableData := templates.GetTable(`Recording Now`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		headers = []string{`File`, `Size`, `Modified`}
+		rows = [][]string{}
+
+		for _, f := range recordings.List() {
+			name := f.Name
+			if f.Active {
+				name += ` *`
+			}
+			rows = append(rows, []string{name, fmt.Sprintf(`%.1fKB`, float64(f.Size)/1024), f.Modified.Format(`2006-01-02 15:04`)})
+		}
+
+		tableData = templates.GetTable(`Saved Recordings`, headers, rows)
+		tplTxt, _ = templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		user.SendText(`Recordings can be played back in the web admin.`)
+
+		return true, nil
+
+	case `start`, `stop`:
+
+		if len(args) < 2 {
+			user.SendText(fmt.Sprintf(`Who? <ansi fg="command">record %s [player]</ansi>`, strings.ToLower(args[0])))
+			return true, nil
+		}
+
+		targetUser := users.GetByCharacterName(args[1])
+		if targetUser == nil {
+			user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> isn't online.`, args[1]))
+			return true, nil
+		}
+
+		if strings.EqualFold(args[0], `stop`) {
+
+			if !recordings.Stop(targetUser.ConnectionId()) {
+				user.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> isn't being recorded.`, targetUser.Character.Name))
+				return true, nil
+			}
+
+			user.SendText(fmt.Sprintf(`Stopped recording <ansi fg="username">%s</ansi>.`, targetUser.Character.Name))
+			return true, nil
+		}
+
+		r, err := recordings.Start(connections.Get(targetUser.ConnectionId()), targetUser.Username)
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Could not record <ansi fg="username">%s</ansi>: %s`, targetUser.Character.Name, err.Error()))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Recording <ansi fg="username">%s</ansi> to <ansi fg="yellow">%s</ansi>. It stops when they disconnect, or with <ansi fg="command">record stop %s</ansi>.`, targetUser.Character.Name, r.FileName(), targetUser.Character.Name))
+
+		return true, nil
+	}
+
+	infoOutput, _ := templates.Process("admincommands/help/command.record", nil, user.UserId)
+	user.SendText(infoOutput)
+
+	return true, nil
+}
@@ -0,0 +1,45 @@
+package usercommands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/changelog"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+/*
+* Role Permissions:
+* redo 				(All)
+ */
+func Redo(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	if strings.EqualFold(rest, `help`) {
+		infoOutput, _ := templates.Process("admincommands/help/command.redo", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	entry, err := changelog.Redo(user.UserId)
+	if err != nil {
+		if errors.Is(err, changelog.ErrNothingToRedo) {
+			user.SendText(`You have no undone changes to redo.`)
+			return true, nil
+		}
+		if entry.EntryId > 0 {
+			user.SendText(fmt.Sprintf(`Could not redo change <ansi fg="yellow">#%d</ansi> (<ansi fg="command">%s</ansi>): %s`, entry.EntryId, entry.Command, err.Error()))
+			return true, nil
+		}
+		user.SendText(fmt.Sprintf(`Could not redo: %s`, err.Error()))
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`Redid change <ansi fg="yellow">#%d</ansi> (<ansi fg="command">%s</ansi>):`, entry.EntryId, entry.Command))
+	sendChangedFiles(entry, user)
+
+	return true, nil
+}
@@ -8,10 +8,12 @@ import (
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/gametime"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
 	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/term"
 	"github.com/GoMudEngine/GoMud/internal/users"
 	"github.com/GoMudEngine/GoMud/internal/util"
 )
@@ -119,12 +121,22 @@ func Server(rest string, user *users.UserRecord, room *rooms.Room, flags events.
 		templates.SetAnsiFlag(templates.AnsiTagsStrip)
 	}
 
+	// Color is taken away per connection, the same way it is for clients that can't show it
 	if rest == "ansi-mono" {
-		templates.SetAnsiFlag(templates.AnsiTagsMono)
+		for _, connectionId := range connections.GetAllConnectionIds() {
+			cs := connections.GetClientSettings(connectionId)
+			cs.Terminal.ColorDepth = term.ColorDepthMono
+			connections.OverwriteClientSettings(connectionId, cs)
+		}
 	}
 
 	if rest == "ansi-normal" {
-		templates.SetAnsiFlag(templates.AnsiTagsDefault)
+		templates.SetAnsiFlag(templates.AnsiTagsParse)
+		for _, connectionId := range connections.GetAllConnectionIds() {
+			cs := connections.GetClientSettings(connectionId)
+			cs.Terminal.ColorDepth = cs.Terminal.NegotiatedColorDepth()
+			connections.OverwriteClientSettings(connectionId, cs)
+		}
 	}
 
 	if rest == "stats" || rest == "info" {
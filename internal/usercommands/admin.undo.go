@@ -0,0 +1,53 @@
+package usercommands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/changelog"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/templates"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+/*
+* Role Permissions:
+* undo 				(All)
+ */
+func Undo(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	if strings.EqualFold(rest, `help`) {
+		infoOutput, _ := templates.Process("admincommands/help/command.undo", nil, user.UserId)
+		user.SendText(infoOutput)
+		return true, nil
+	}
+
+	entry, err := changelog.Undo(user.UserId)
+	if err != nil {
+		if errors.Is(err, changelog.ErrNothingToUndo) {
+			user.SendText(`You have no changes to undo.`)
+			return true, nil
+		}
+		if entry.EntryId > 0 {
+			user.SendText(fmt.Sprintf(`Could not undo change <ansi fg="yellow">#%d</ansi> (<ansi fg="command">%s</ansi>): %s`, entry.EntryId, entry.Command, err.Error()))
+			return true, nil
+		}
+		user.SendText(fmt.Sprintf(`Could not undo: %s`, err.Error()))
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`Undid change <ansi fg="yellow">#%d</ansi> (<ansi fg="command">%s</ansi>):`, entry.EntryId, entry.Command))
+	sendChangedFiles(entry, user)
+
+	return true, nil
+}
+
+func sendChangedFiles(entry changelog.Entry, user *users.UserRecord) {
+	paths := []string{}
+	for _, c := range entry.Changes {
+		paths = append(paths, `  `+c.Path)
+	}
+	user.SendText(strings.Join(paths, "\n"))
+}
@@ -13,11 +13,14 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/templates"
 	"github.com/GoMudEngine/GoMud/internal/users"
 	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/GoMudEngine/GoMud/internal/zonearchive"
 )
 
 /*
 * Role Permissions:
 * zone 				(All)
+* zone.export		(Export a zone to an archive)
+* zone.import		(Import a zone from an archive)
  */
 func Zone(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
 
@@ -44,6 +47,22 @@ func Zone(rest string, user *users.UserRecord, room *rooms.Room, flags events.Ev
 		return zone_Edit(``, user, room, flags)
 	}
 
+	if zoneCmd == `export` {
+		if !user.HasRolePermission(`zone.export`) {
+			user.SendText(`you do not have <ansi fg="command">zone.export</ansi> permission`)
+			return true, nil
+		}
+		return zone_Export(strings.Join(args, ` `), user, room, flags)
+	}
+
+	if zoneCmd == `import` {
+		if !user.HasRolePermission(`zone.import`) {
+			user.SendText(`you do not have <ansi fg="command">zone.import</ansi> permission`)
+			return true, nil
+		}
+		return zone_Import(args, user, room, flags)
+	}
+
 	zoneConfig := rooms.GetZoneConfig(room.Zone)
 	if zoneConfig == nil {
 		user.SendText(fmt.Sprintf(`Couldn't find zone info for <ansi fg="red">%s</ansi>`, room.Zone))
@@ -301,3 +320,138 @@ func zone_Edit(rest string, user *users.UserRecord, room *rooms.Room, flags even
 
 	return true, nil
 }
+
+func zone_Export(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	zoneName := room.Zone
+	if rest != `` {
+		if zoneName = rooms.FindZoneName(rest); zoneName == `` {
+			user.SendText(fmt.Sprintf(`No zone matches <ansi fg="zone">%s</ansi>.`, rest))
+			return true, nil
+		}
+	}
+
+	a, m, missing, err := zonearchive.Export(zoneName)
+	if err != nil {
+		user.SendText(fmt.Sprintf(`Could not export <ansi fg="zone">%s</ansi>: %s`, zoneName, err.Error()))
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`Exported <ansi fg="zone">%s</ansi> to <ansi fg="yellow">%s</ansi>`, m.Zone, a.Name))
+	user.SendText(fmt.Sprintf(`  <ansi fg="yellow-bold">Rooms:</ansi> %d  <ansi fg="yellow-bold">Mobs:</ansi> %d  <ansi fg="yellow-bold">Items:</ansi> %d  <ansi fg="yellow-bold">Buffs:</ansi> %d  <ansi fg="yellow-bold">Quests:</ansi> %d  <ansi fg="yellow-bold">Mutators:</ansi> %d  <ansi fg="yellow-bold">Files:</ansi> %d`,
+		len(m.RoomIds), len(m.MobIds), len(m.ItemIds), len(m.BuffIds), len(m.QuestIds), len(m.MutatorIds), len(m.Files)))
+
+	if len(missing) > 0 {
+		user.SendText(``)
+		user.SendText(`<ansi fg="red">Left out, because they don't exist:</ansi>`)
+		for _, msg := range missing {
+			user.SendText(`  ` + msg)
+		}
+	}
+
+	return true, nil
+}
+
+func zone_Import(args []string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
+
+	if len(args) == 0 {
+
+		archives, err := zonearchive.List()
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Could not list archives: %s`, err.Error()))
+			return true, nil
+		}
+
+		headers := []string{`Archive`, `Size`, `Exported`}
+		rows := [][]string{}
+
+		for _, a := range archives {
+			rows = append(rows, []string{a.Name, fmt.Sprintf(`%.1fKB`, float64(a.Size)/1024), a.Modified.Format(`2006-01-02 15:04`)})
+		}
+
+		tableData := templates.GetTable(`Zone Archives`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+
+		user.SendText(`Use <ansi fg="command">zone import [archive]</ansi> to see what importing one would do.`)
+
+		return true, nil
+	}
+
+	archiveName := args[0]
+	args = args[1:]
+
+	opts := zonearchive.Options{NextRoomId: rooms.GetNextRoomId()}
+
+	for len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case `confirm`:
+			opts.Write = true
+			args = args[1:]
+		case `as`:
+			if len(args) < 2 {
+				user.SendText(`Import it as what? <ansi fg="command">zone import [archive] as "[zone name]"</ansi>`)
+				return true, nil
+			}
+			opts.Zone = args[1]
+			args = args[2:]
+		default:
+			user.SendText(fmt.Sprintf(`Unknown option <ansi fg="red">%s</ansi>`, args[0]))
+			return true, nil
+		}
+	}
+
+	report, err := zonearchive.Import(archiveName, opts)
+	if err != nil {
+		user.SendText(fmt.Sprintf(`Could not import <ansi fg="yellow">%s</ansi>: %s`, archiveName, err.Error()))
+		return true, nil
+	}
+
+	if len(report.Remapped) > 0 {
+		headers := []string{`Kind`, `Name`, `Archive Id`, `New Id`}
+		rows := [][]string{}
+		for _, r := range report.Remapped {
+			rows = append(rows, []string{r.Kind, r.Name, r.OldId, r.NewId})
+		}
+		tableData := templates.GetTable(`Given New Ids`, headers, rows)
+		tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId, user.UserId)
+		user.SendText(tplTxt)
+	}
+
+	if len(report.Reused) > 0 {
+		user.SendText(`<ansi fg="yellow-bold">Already here, and left as is:</ansi>`)
+		for _, msg := range report.Reused {
+			user.SendText(`  ` + msg)
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		user.SendText(`<ansi fg="yellow-bold">Warnings:</ansi>`)
+		for _, msg := range report.Warnings {
+			user.SendText(`  ` + msg)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		user.SendText(`<ansi fg="red">Errors:</ansi>`)
+		for _, msg := range report.Errors {
+			user.SendText(`  ` + msg)
+		}
+		user.SendText(fmt.Sprintf(`<ansi fg="red">%s can't be imported until these are fixed.</ansi>`, report.Archive))
+		return true, nil
+	}
+
+	if !report.Written {
+		user.SendText(fmt.Sprintf(`Importing <ansi fg="yellow">%s</ansi> as <ansi fg="zone">%s</ansi> would write %d files. Nothing has been written yet.`, report.Archive, report.Zone, len(report.Files)))
+		confirmCmd := `zone import ` + report.Archive
+		if opts.Zone != `` {
+			confirmCmd += ` as "` + opts.Zone + `"`
+		}
+		user.SendText(fmt.Sprintf(`To go ahead, type: <ansi fg="command">%s confirm</ansi>`, confirmCmd))
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`Imported <ansi fg="yellow">%s</ansi> as <ansi fg="zone">%s</ansi> (%d files written). Use <ansi fg="command">undo</ansi> to take it back out.`, report.Archive, report.Zone, len(report.Files)))
+
+	return true, nil
+}
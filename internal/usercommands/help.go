@@ -1,6 +1,7 @@
 package usercommands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
@@ -209,6 +210,18 @@ func Help(rest string, user *users.UserRecord, room *rooms.Room, flags events.Ev
 					mudlog.Info("help-llm", "query", rest, "found", "true")
 				} else {
 					mudlog.Warn("help-llm", "response", llmResponse, "query", rest, "error", llmErr.Error())
+
+					// Out of LLM budget, so see if any single word of the question has a help topic
+					if errors.Is(llmErr, llm.ErrQuotaExceeded) {
+						for _, word := range strings.Fields(strings.ToLower(rest)) {
+							if !templates.Exists("help/" + word) {
+								continue
+							}
+							if helpTxt, err = templates.Process("help/"+word, nil, user.UserId); err == nil {
+								break
+							}
+						}
+					}
 				}
 			} else {
 				// Log that LLM is disabled
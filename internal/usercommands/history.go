@@ -1,15 +1,38 @@
 package usercommands
 
 import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
 	"github.com/GoMudEngine/GoMud/internal/templates"
 	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
 )
 
+/*
+* Role Permissions:
+* history.room 			(Builder changes to a room)
+* history.export 		(Write builder changes to a patch file)
+ */
 func History(rest string, user *users.UserRecord, room *rooms.Room, flags events.EventFlag) (bool, error) {
 
+	args := util.SplitButRespectQuotes(rest)
+
+	if len(args) > 0 && args[0] == `room` && user.HasRolePermission(`history.room`) {
+		return historyRoom(args[1:], user, room)
+	}
+
+	if len(args) > 0 && args[0] == `export` && user.HasRolePermission(`history.export`) {
+		return historyExport(args[1:], user)
+	}
+
 	headers := []string{`Type` /*`Round`,*/, `Time`, `Log`}
 
 	rows := [][]string{}
@@ -44,3 +67,119 @@ func History(rest string, user *users.UserRecord, room *rooms.Room, flags events
 
 	return true, nil
 }
+
+// history room [roomId] [change]
+// Lists the builder changes to a room, or shows what one of them changed.
+func historyRoom(args []string, user *users.UserRecord, room *rooms.Room) (bool, error) {
+
+	roomId := room.RoomId
+	if len(args) > 0 {
+		roomId, _ = strconv.Atoi(args[0])
+	}
+
+	isRoomFile := func(c changelog.Change) bool {
+		return strings.HasPrefix(c.Path, `rooms/`) && path.Base(c.Path) == fmt.Sprintf(`%d.yaml`, roomId)
+	}
+
+	if len(args) > 1 {
+
+		entryId, _ := strconv.Atoi(strings.TrimPrefix(args[1], `#`))
+		entry, err := changelog.Get(entryId)
+		if err != nil {
+			user.SendText(fmt.Sprintf(`Change <ansi fg="yellow">#%s</ansi> not found.`, args[1]))
+			return true, nil
+		}
+
+		diffs := []string{}
+		for _, c := range entry.Changes {
+			if isRoomFile(c) {
+				diffs = append(diffs, colorizeDiff(c.Diff()))
+			}
+		}
+
+		if len(diffs) == 0 {
+			user.SendText(fmt.Sprintf(`Change <ansi fg="yellow">#%d</ansi> didn't change room <ansi fg="yellow">#%d</ansi>.`, entry.EntryId, roomId))
+			return true, nil
+		}
+
+		user.SendText(fmt.Sprintf(`Change <ansi fg="yellow">#%d</ansi> by <ansi fg="username">%s</ansi> (<ansi fg="command">%s</ansi>):`, entry.EntryId, entry.Username, entry.Command))
+		user.SendText(strings.Join(diffs, "\n"))
+
+		return true, nil
+	}
+
+	entries, err := changelog.Entries()
+	if err != nil {
+		user.SendText(fmt.Sprintf(`Could not read the change history: %s`, err.Error()))
+		return true, nil
+	}
+
+	headers := []string{`Change`, `Time`, `Who`, `Command`, `Undone`}
+	rows := [][]string{}
+
+	tFormat := string(configs.GetTextFormatsConfig().TimeShort)
+
+	for _, e := range entries {
+		for _, c := range e.Changes {
+			if !isRoomFile(c) {
+				continue
+			}
+			undone := ``
+			if e.Undone {
+				undone = `yes`
+			}
+			rows = append(rows, []string{fmt.Sprintf(`#%d`, e.EntryId), e.When.Format(tFormat), e.Username, e.Command, undone})
+			break
+		}
+	}
+
+	tableData := templates.GetTable(fmt.Sprintf(`Changes to Room #%d`, roomId), headers, rows)
+	tplTxt, _ := templates.Process("tables/generic", tableData, user.UserId)
+	user.SendText(tplTxt)
+	user.SendText(fmt.Sprintf(`Type <ansi fg="command">history room %d [change]</ansi> to see what a change did.`, roomId))
+
+	return true, nil
+}
+
+// history export [from] [to]
+func historyExport(args []string, user *users.UserRecord) (bool, error) {
+
+	fromId, toId := 1, math.MaxInt
+	if len(args) > 0 {
+		fromId, _ = strconv.Atoi(strings.TrimPrefix(args[0], `#`))
+	}
+	if len(args) > 1 {
+		toId, _ = strconv.Atoi(strings.TrimPrefix(args[1], `#`))
+	}
+
+	patchPath, count, err := changelog.Export(fromId, toId)
+	if err != nil {
+		user.SendText(fmt.Sprintf(`Could not export changes: %s`, err.Error()))
+		return true, nil
+	}
+
+	user.SendText(fmt.Sprintf(`Wrote %d changes to <ansi fg="yellow">%s</ansi>`, count, patchPath))
+	user.SendText(`Apply it from inside another DataFiles folder with <ansi fg="command">git apply [file]</ansi> or <ansi fg="command">patch -p1 < [file]</ansi>`)
+
+	return true, nil
+}
+
+func colorizeDiff(diff string) string {
+
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, `+++`), strings.HasPrefix(line, `---`), strings.HasPrefix(line, `diff `):
+			lines[i] = `<ansi fg="white-bold">` + line + `</ansi>`
+		case strings.HasPrefix(line, `@@`):
+			lines[i] = `<ansi fg="cyan">` + line + `</ansi>`
+		case strings.HasPrefix(line, `+`):
+			lines[i] = `<ansi fg="green">` + line + `</ansi>`
+		case strings.HasPrefix(line, `-`):
+			lines[i] = `<ansi fg="red">` + line + `</ansi>`
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
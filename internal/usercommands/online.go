@@ -46,6 +46,9 @@ func Online(rest string, user *users.UserRecord, room *rooms.Room, flags events.
 			if onlineInfo.IsAFK {
 				onlineTime += ` <ansi fg="8">(afk)</ansi>`
 			}
+			if onlineInfo.Secure {
+				onlineTime += ` <ansi fg="8">(tls)</ansi>`
+			}
 
 			permClass := `user`
 			if onlineInfo.Role != users.RoleUser {
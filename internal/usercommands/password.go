@@ -11,7 +11,7 @@ func Password(rest string, user *users.UserRecord, room *rooms.Room, flags event
 	// Get if already exists, otherwise create new
 	cmdPrompt, _ := user.StartPrompt(`password`, rest)
 
-	question := cmdPrompt.Ask(`What is your current password?`, []string{})
+	question := cmdPrompt.AskSecret(`What is your current password?`)
 	if !question.Done {
 		return true, nil
 	}
@@ -22,14 +22,14 @@ func Password(rest string, user *users.UserRecord, room *rooms.Room, flags event
 		return true, nil
 	}
 
-	question = cmdPrompt.Ask(`What new password would you like?`, []string{})
+	question = cmdPrompt.AskSecret(`What new password would you like?`)
 	if !question.Done {
 		return true, nil
 	}
 
 	newPW := question.Response
 
-	question = cmdPrompt.Ask(`Confirm the change by entered the new password one more time.`, []string{})
+	question = cmdPrompt.AskSecret(`Confirm the change by entered the new password one more time.`)
 	if !question.Done {
 		return true, nil
 	}
@@ -121,24 +121,12 @@ func Say(rest string, user *users.UserRecord, room *rooms.Room, flags events.Eve
 			}
 			user.SendText(fmt.Sprintf(`You say, "<ansi fg="saytext">%s</ansi>"`, rest))
 
-			// Process the NPC's response asynchronously
-			go func(convId int, userInput string, mobInst *mobs.Mob, userRef *users.UserRecord, roomRef *rooms.Room) {
-				// Player is participant in this conversation
-				response, err := conversations.ProcessPlayerInput(convId, userInput)
-				if err == nil && response != "" {
-					// Send the response to the player
-					if !userRef.Character.HasBuffFlag(buffs.Hidden) {
-						roomRef.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to <ansi fg="username">%s</ansi>, "<ansi fg="saytext">%s</ansi>"`, mobInst.Character.Name, userRef.Character.Name, response), userRef.UserId)
-					} else {
-						roomRef.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to someone, "<ansi fg="saytext">%s</ansi>"`, mobInst.Character.Name, response), userRef.UserId)
-					}
-					userRef.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to you, "<ansi fg="saytext">%s</ansi>"`, mobInst.Character.Name, response))
-
-					mudlog.Debug("ProcessPlayerInput", "response", fmt.Sprintf("NPC response: %s", response))
-				} else if err != nil {
-					mudlog.Error("ProcessPlayerInput", "error", fmt.Sprintf("Error processing player input: %v", err))
-				}
-			}(conversationId, rest, mob, user, room)
+			// The NPC's reply is generated in the background and delivered by the LLMResponse hook
+			if response, err := conversations.ProcessPlayerInput(conversationId, rest); err != nil {
+				mudlog.Error("ProcessPlayerInput", "error", fmt.Sprintf("Error processing player input: %v", err))
+			} else if response != "" {
+				conversations.Say(mob, user, room, response, false)
+			}
 
 			// Since we've processed the conversation, we can return
 			return true, nil
@@ -265,24 +253,12 @@ func handleNPCResponse(mob *mobs.Mob, user *users.UserRecord, room *rooms.Room,
 		mob.SetConversation(conversationId)
 		mudlog.Debug("NPC Response", "context", fmt.Sprintf("Context for %s: %v", mob.Character.Name, context))
 
-		// Process the NPC's response asynchronously
-		go func(convId int, userInput string, mobInst *mobs.Mob, userRef *users.UserRecord, roomRef *rooms.Room) {
-			// Get initial greeting or response from the conversation
-			response, err := conversations.ProcessPlayerInput(convId, userInput)
-			if err == nil && response != "" {
-				// Send the response to the player
-				if !userRef.Character.HasBuffFlag(buffs.Hidden) {
-					roomRef.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to <ansi fg="username">%s</ansi>, "<ansi fg="saytext">%s</ansi>"`, mobInst.Character.Name, userRef.Character.Name, response), userRef.UserId)
-				} else {
-					roomRef.SendTextCommunication(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to someone, "<ansi fg="saytext">%s</ansi>"`, mobInst.Character.Name, response), userRef.UserId)
-				}
-				userRef.SendText(fmt.Sprintf(`<ansi fg="username">%s</ansi> says to you, "<ansi fg="saytext">%s</ansi>"`, mobInst.Character.Name, response))
-
-				mudlog.Debug("ProcessPlayerInput", "response", fmt.Sprintf("NPC response: %s", response))
-			} else if err != nil {
-				mudlog.Error("ProcessPlayerInput", "error", fmt.Sprintf("Error getting response: %v", err))
-			}
-		}(conversationId, originalMessage, mob, user, room)
+		// The NPC's reply is generated in the background and delivered by the LLMResponse hook
+		if response, err := conversations.ProcessPlayerInput(conversationId, originalMessage); err != nil {
+			mudlog.Error("ProcessPlayerInput", "error", fmt.Sprintf("Error getting response: %v", err))
+		} else if response != "" {
+			conversations.Say(mob, user, room, response, false)
+		}
 
 		return true
 	}
@@ -1,6 +1,7 @@
 package usercommands
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -129,33 +130,23 @@ INTENT: OTHER_SHOUT | DECISION: IGNORE`
 					prompt := fmt.Sprintf(promptTemplate, rest)
 					systemMsg := "You are an AI assistant roleplaying as a gate guard. Follow the provided response format precisely."
 
-					// Create custom LLM config for the guard
+					// Send request to LLM service
 					intConfig := configs.GetIntegrationsConfig()
-					guardConfig := llm.LLMConfig{
-						Enabled:      llm.GetStatus(),
-						Provider:     "openai", // Use OpenAI for consistency
-						EndpointURL:  string(intConfig.LLM.BaseURL),
-						APIKey:       string(intConfig.LLMHelp.APIKey),
-						Model:        string(intConfig.LLM.Model),
-						Temperature:  0.7,
-						MaxTokens:    150,
-						SystemPrompt: systemMsg,
-					}
-
-					// Create LLM messages
-					messages := []llm.LLMMessage{
-						{
-							Role:    "system",
-							Content: systemMsg,
-						},
-						{
-							Role:    "user",
-							Content: prompt,
+					resp, err := llm.Complete(context.Background(), string(intConfig.LLM.ConversationProfile), llm.Request{
+						Messages: []llm.Message{
+							{
+								Role:    llm.RoleSystem,
+								Content: systemMsg,
+							},
+							{
+								Role:    llm.RoleUser,
+								Content: prompt,
+							},
 						},
-					}
-
-					// Send request to LLM service
-					llmResponse, err := llm.SendRequest(messages, guardConfig)
+						Temperature: 0.7,
+						MaxTokens:   150,
+					})
+					llmResponse := resp.Text
 					if err != nil {
 						mudlog.Error("Shout", "LLM call failed for guard response", "error", err, "mobId", mobId)
 
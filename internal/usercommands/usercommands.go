@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/keywords"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
@@ -46,17 +47,20 @@ var (
 		`aid`:         {Aid, false, false},
 		`ai`:          {AI, true, false}, // New command to toggle AI/LLM features
 		`alias`:       {Alias, true, false},
+		`apitoken`:    {APIToken, true, true}, // Admin only
 		`appraise`:    {Appraise, false, false},
 		`ask`:         {Ask, false, false},
 		`attack`:      {Attack, false, false},
 		`backstab`:    {Backstab, false, false},
 		`badcommands`: {BadCommands, true, true}, // Admin only
+		`backup`:      {Backup, true, true},      // Admin only
 		`biome`:       {Biome, true, false},
 		`broadcast`:   {Broadcast, true, false},
 		`bury`:        {Bury, false, false},
 		`character`:   {Character, true, false},
 		`tackle`:      {Tackle, false, false},
 		`bank`:        {Bank, false, false},
+		`ban`:         {Ban, true, true}, // Admin only
 		`break`:       {Break, false, false},
 		`build`:       {Build, false, true}, // Admin only
 		`buff`:        {Buff, false, true},  // Admin only
@@ -67,7 +71,8 @@ var (
 		`command`:     {Command, false, true}, // Admin only
 		`conditions`:  {Conditions, true, false},
 		`consider`:    {Consider, true, false},
-		`deafen`:      {Deafen, true, true}, // Admin only
+		`copyover`:    {Copyover, true, true}, // Admin only
+		`deafen`:      {Deafen, true, true},   // Admin only
 		`default`:     {Default, false, false},
 		`disarm`:      {Disarm, false, false},
 		`drop`:        {Drop, true, false},
@@ -93,7 +98,8 @@ var (
 		`item`:        {Item, true, true}, // Admin only
 		`jobs`:        {Jobs, true, false},
 		`list`:        {List, false, false},
-		`locate`:      {Locate, true, true}, // Admin only
+		`llmusage`:    {LLMUsage, true, true}, // Admin only
+		`locate`:      {Locate, true, true},   // Admin only
 		`lock`:        {Lock, false, false},
 		`look`:        {Look, true, false},
 		`map`:         {Map, false, false},
@@ -126,7 +132,9 @@ var (
 		`rank`:        {Rank, false, false},
 		`read`:        {Read, false, false},
 		`recover`:     {Recover, false, false},
+		`redo`:        {Redo, true, true},   // Admin only
 		`reload`:      {Reload, true, true}, // Admin only
+		`record`:      {Record, true, true}, // Admin only
 		`remove`:      {Remove, false, false},
 		`rename`:      {Rename, false, true},     // Admin only
 		`redescribe`:  {Redescribe, false, true}, // Admin only
@@ -164,6 +172,8 @@ var (
 		`unlock`:      {Unlock, false, false},
 		`undeafen`:    {UnDeafen, true, true}, // Admin only
 		`unmute`:      {UnMute, true, true},   // Admin only
+		`unban`:       {Unban, true, true},    // Admin only
+		`undo`:        {Undo, true, true},     // Admin only
 		`use`:         {Use, false, false},
 		`dual-wield`:  {DualWield, true, false},
 		`whisper`:     {Whisper, true, false},
@@ -380,6 +390,10 @@ func TryCommand(cmd string, rest string, userId int, flags events.EventFlag) (bo
 
 			if cmdInfo.AdminOnly {
 				mudlog.Info("Admin Command", "cmd", cmd, "rest", rest, "userId", user.UserId)
+
+				// Any world files changed by the command are recorded, so they can be undone
+				changelog.Begin(user.UserId, user.Username, strings.TrimSpace(cmd+` `+rest))
+				defer changelog.End()
 			}
 
 			// Run the command here
@@ -0,0 +1,194 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// API tokens let tools call the web API as a user, without that user's password.
+// A token looks like "<id>.<secret>". Only a hash of the secret is saved, so a token
+// is shown once when it is created and can't be recovered later, only revoked.
+// Whatever a token can do is decided by its user's role each time it is used.
+//
+
+const (
+	apiTokenIdBytes     = 6
+	apiTokenSecretBytes = 32
+)
+
+var (
+	ErrInvalidAPIToken  = errors.New("invalid api token")
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
+type APIToken struct {
+	TokenId    string    `yaml:"tokenid"`
+	Name       string    `yaml:"name,omitempty"` // What it's for, chosen by whoever created it
+	UserId     int       `yaml:"userid"`
+	Username   string    `yaml:"username"`   // At the time it was created, for display
+	SecretHash string    `yaml:"secrethash"` // sha256 hex of the secret
+	Created    time.Time `yaml:"created"`
+}
+
+// CreateAPIToken saves a new token for a user and returns the full token string.
+// This is the only time the secret is available.
+func CreateAPIToken(u *UserRecord, name string) (string, APIToken, error) {
+
+	tokenId, err := randomHex(apiTokenIdBytes)
+	if err != nil {
+		return ``, APIToken{}, err
+	}
+
+	secret, err := randomHex(apiTokenSecretBytes)
+	if err != nil {
+		return ``, APIToken{}, err
+	}
+
+	token := APIToken{
+		TokenId:    tokenId,
+		Name:       strings.TrimSpace(name),
+		UserId:     u.UserId,
+		Username:   u.Username,
+		SecretHash: hashAPISecret(secret),
+		Created:    time.Now(),
+	}
+
+	data, err := yaml.Marshal(token)
+	if err != nil {
+		return ``, APIToken{}, err
+	}
+
+	if err := storage.Put(storage.APITokens, storage.Record{Key: token.TokenId, Data: data}); err != nil {
+		return ``, APIToken{}, err
+	}
+
+	return token.TokenId + `.` + secret, token, nil
+}
+
+// AuthenticateAPIToken checks a full token string and returns the user it belongs to.
+func AuthenticateAPIToken(tokenStr string) (*UserRecord, error) {
+
+	tokenId, secret, ok := strings.Cut(strings.TrimSpace(tokenStr), `.`)
+	if !ok || tokenId == `` || secret == `` {
+		return nil, ErrInvalidAPIToken
+	}
+
+	token, err := GetAPIToken(tokenId)
+	if err != nil {
+		return nil, ErrInvalidAPIToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.SecretHash), []byte(hashAPISecret(secret))) != 1 {
+		return nil, ErrInvalidAPIToken
+	}
+
+	// Online users have the most up to date record
+	if u := GetByUserId(token.UserId); u != nil {
+		return u, nil
+	}
+
+	data, err := storage.Get(storage.Users, strconv.Itoa(token.UserId))
+	if err != nil {
+		return nil, ErrInvalidAPIToken
+	}
+
+	u := &UserRecord{}
+	if err := yaml.Unmarshal(data, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func GetAPIToken(tokenId string) (APIToken, error) {
+
+	// Ids come from whoever is calling, so make sure it's one we could have made before looking it up
+	if !isAPITokenId(tokenId) {
+		return APIToken{}, ErrAPITokenNotFound
+	}
+
+	data, err := storage.Get(storage.APITokens, tokenId)
+	if err != nil {
+		return APIToken{}, ErrAPITokenNotFound
+	}
+
+	token := APIToken{}
+	if err := yaml.Unmarshal(data, &token); err != nil {
+		return APIToken{}, err
+	}
+
+	return token, nil
+}
+
+// GetAPITokens returns every token, oldest first
+func GetAPITokens() ([]APIToken, error) {
+
+	keys, err := storage.Keys(storage.APITokens)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]APIToken, 0, len(keys))
+	for _, tokenId := range keys {
+		token, err := GetAPIToken(tokenId)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Created.Before(tokens[j].Created)
+	})
+
+	return tokens, nil
+}
+
+func RevokeAPIToken(tokenId string) error {
+
+	if _, err := GetAPIToken(tokenId); err != nil {
+		return err
+	}
+
+	return storage.Delete(storage.APITokens, tokenId)
+}
+
+// isAPITokenId checks a token id is lowercase hex of the length CreateAPIToken makes
+func isAPITokenId(tokenId string) bool {
+
+	if len(tokenId) != apiTokenIdBytes*2 {
+		return false
+	}
+
+	for _, c := range tokenId {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hashAPISecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(byteCt int) (string, error) {
+	b := make([]byte, byteCt)
+	if _, err := rand.Read(b); err != nil {
+		return ``, err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,81 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+
+	testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.Storage": storage.BackendYAML,
+	})
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	builder := &UserRecord{UserId: 7, Username: `builder`, Role: `builder`}
+	require.NoError(t, storage.Put(storage.Users, storage.Record{Key: `7`, Data: []byte("userid: 7\nusername: builder\nrole: builder\n")}))
+
+	tokenStr, token, err := CreateAPIToken(builder, ` zone tools `)
+	require.NoError(t, err)
+	assert.Equal(t, `zone tools`, token.Name)
+	assert.Equal(t, `builder`, token.Username)
+	assert.True(t, len(tokenStr) > len(token.TokenId)+1)
+	assert.NotContains(t, token.SecretHash, tokenStr[len(token.TokenId)+1:], `the secret itself is never saved`)
+
+	u, err := AuthenticateAPIToken(tokenStr)
+	require.NoError(t, err)
+	assert.Equal(t, 7, u.UserId)
+	assert.Equal(t, `builder`, u.Role)
+
+	for _, bad := range []string{``, `nodot`, token.TokenId + `.wrong`, `unknown.` + tokenStr[len(token.TokenId)+1:]} {
+		_, err = AuthenticateAPIToken(bad)
+		assert.ErrorIs(t, err, ErrInvalidAPIToken, bad)
+	}
+
+	_, second, err := CreateAPIToken(builder, `other`)
+	require.NoError(t, err)
+
+	tokens, err := GetAPITokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, token.TokenId, tokens[0].TokenId)
+	assert.Equal(t, second.TokenId, tokens[1].TokenId)
+
+	require.NoError(t, RevokeAPIToken(token.TokenId))
+	assert.ErrorIs(t, RevokeAPIToken(token.TokenId), ErrAPITokenNotFound)
+
+	_, err = AuthenticateAPIToken(tokenStr)
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+}
+
+func TestAPITokens_IdMustBeHex(t *testing.T) {
+
+	testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.Storage": storage.BackendYAML,
+	})
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	// A record outside the api-tokens collection that a crafted id could otherwise reach
+	userData := []byte("tokenid: ../users/7\nuserid: 1\nsecrethash: " + hashAPISecret(`secret`) + "\n")
+	require.NoError(t, storage.Put(storage.Users, storage.Record{Key: `7`, Data: userData}))
+
+	for _, bad := range []string{`../users/7`, `..%2fusers%2f7`, `ABCDEF123456`, `abcdef12345`, `abcdef1234567`, `abcdef12345g`} {
+
+		_, err := GetAPIToken(bad)
+		assert.ErrorIs(t, err, ErrAPITokenNotFound, bad)
+
+		_, err = AuthenticateAPIToken(bad + `.secret`)
+		assert.ErrorIs(t, err, ErrInvalidAPIToken, bad)
+
+		assert.ErrorIs(t, RevokeAPIToken(bad), ErrAPITokenNotFound, bad)
+	}
+
+	_, err := storage.Get(storage.Users, `7`)
+	assert.NoError(t, err, `the user record is still there`)
+}
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/storage"
 	"github.com/GoMudEngine/GoMud/internal/util"
+	"gopkg.in/yaml.v2"
 )
 
 var (
@@ -431,3 +434,86 @@ func (idx *UserIndex) writeCompleteIndex(records []IndexUserRecord) error {
 	}
 	return nil
 }
+
+//
+// The index file is only needed when the store can't find users by name itself (files).
+// A store that can (sqlite) is asked directly instead.
+//
+
+func init() {
+	// So users copied over from files can still be found by name
+	storage.RegisterName(storage.Users, func(data []byte) string {
+		u := struct {
+			Username string `yaml:"username"`
+		}{}
+		yaml.Unmarshal(data, &u)
+		return u.Username
+	})
+}
+
+func lookupUserId(username string) (int, bool) {
+
+	if key, found, supported := storage.FindByName(storage.Users, username); supported {
+		if !found {
+			return 0, false
+		}
+		userId, err := strconv.Atoi(key)
+		return userId, err == nil
+	}
+
+	userId, found := NewUserIndex().FindByUsername(username)
+
+	return int(userId), found
+}
+
+func addToIndex(userId int, username string) {
+
+	if _, ok := storage.Active().(storage.Finder); ok {
+		return
+	}
+
+	NewUserIndex().AddUser(userId, username)
+}
+
+// highestIndexedUserId returns false if there's nowhere to look it up quickly
+func highestIndexedUserId() (int, bool) {
+
+	if _, ok := storage.Active().(storage.Finder); ok {
+
+		keys, err := storage.Keys(storage.Users)
+		if err != nil {
+			return 0, false
+		}
+
+		highest := 0
+		for _, key := range keys {
+			if userId, err := strconv.Atoi(key); err == nil && userId > highest {
+				highest = userId
+			}
+		}
+		return highest, true
+	}
+
+	idx := NewUserIndex()
+	if !idx.Exists() {
+		return 0, false
+	}
+
+	return idx.GetHighestUserId(), true
+}
+
+// RebuildIndex recreates the index file from the saved users, if the store needs one
+func RebuildIndex() {
+
+	if _, ok := storage.Active().(storage.Finder); ok {
+		return
+	}
+
+	idx := NewUserIndex()
+	if !idx.Exists() {
+		// Since it doesn't exist yet, that's a good indication we should do a quick format migration check
+		DoUserMigrations()
+	}
+	idx.Create()
+	idx.Rebuild()
+}
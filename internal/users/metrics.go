@@ -0,0 +1,11 @@
+package users
+
+import (
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+)
+
+func init() {
+	metrics.NewGaugeFunc(`gomud_users_online`, `Users logged in to the world.`, func() float64 {
+		return float64(len(userManager.Users))
+	})
+}
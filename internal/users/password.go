@@ -0,0 +1,182 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//
+// Passwords are stored as self describing strings, so the algorithm and cost can change over time:
+//   $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+//   $2a$12$<salt+hash> (bcrypt)
+// Anything else is a legacy format from before passwords were salted:
+//   unsalted sha256 hex, or plaintext (hand edited user files)
+//
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var (
+	ErrPasswordHash = errors.New("unrecognized password hash")
+)
+
+// HashPassword hashes a password with the configured algorithm and cost
+func HashPassword(pw string) (string, error) {
+
+	sec := configs.GetSecurityConfig()
+
+	if sec.PasswordHash == configs.PasswordHashBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(pw), int(sec.BcryptCost))
+		if err != nil {
+			return ``, err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return ``, err
+	}
+
+	params := argon2Params{
+		memory:  uint32(sec.Argon2MemoryKB),
+		time:    uint32(sec.Argon2Time),
+		threads: uint8(sec.Argon2Threads),
+	}
+
+	return params.encode(salt, params.key(pw, salt, argon2KeyLength)), nil
+}
+
+// verifyPassword reports whether input matches the stored password,
+// and whether the stored password should be rehashed with the current settings.
+func verifyPassword(stored string, input string) (match bool, rehash bool) {
+
+	sec := configs.GetSecurityConfig()
+
+	switch {
+
+	case strings.HasPrefix(stored, `$argon2id$`):
+
+		params, salt, hash, err := decodeArgon2(stored)
+		if err != nil {
+			return false, false
+		}
+
+		if subtle.ConstantTimeCompare(hash, params.key(input, salt, uint32(len(hash)))) != 1 {
+			return false, false
+		}
+
+		rehash = sec.PasswordHash != configs.PasswordHashArgon2id ||
+			params.memory != uint32(sec.Argon2MemoryKB) ||
+			params.time != uint32(sec.Argon2Time) ||
+			params.threads != uint8(sec.Argon2Threads)
+
+		return true, rehash
+
+	case strings.HasPrefix(stored, `$2`):
+
+		if bcrypt.CompareHashAndPassword([]byte(stored), []byte(input)) != nil {
+			return false, false
+		}
+
+		cost, err := bcrypt.Cost([]byte(stored))
+		rehash = err != nil ||
+			sec.PasswordHash != configs.PasswordHashBcrypt ||
+			cost != int(sec.BcryptCost)
+
+		return true, rehash
+
+	}
+
+	if stored == `` {
+		return false, false
+	}
+
+	// Legacy formats are always rehashed
+	if isLegacyHash(stored) {
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(util.Hash(input))) == 1, true
+	}
+
+	// Only a plaintext password is compared to the input as-is, otherwise knowing the hash would be enough
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(input)) == 1 {
+		return true, true
+	}
+
+	return false, false
+}
+
+// isLegacyHash reports whether a stored password is an unsalted sha256 hex digest
+func isLegacyHash(stored string) bool {
+
+	if len(stored) != 64 {
+		return false
+	}
+
+	for _, c := range stored {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+
+	return true
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+func (p argon2Params) key(pw string, salt []byte, keyLen uint32) []byte {
+	return argon2.IDKey([]byte(pw), salt, p.time, p.memory, p.threads, keyLen)
+}
+
+func (p argon2Params) encode(salt []byte, hash []byte) string {
+	return fmt.Sprintf(`$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s`,
+		argon2.Version,
+		p.memory,
+		p.time,
+		p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	)
+}
+
+func decodeArgon2(stored string) (params argon2Params, salt []byte, hash []byte, err error) {
+
+	// "", "argon2id", "v=19", "m=19456,t=2,p=1", salt, hash
+	parts := strings.Split(stored, `$`)
+	if len(parts) != 6 {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], `v=%d`, &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	if _, err = fmt.Sscanf(parts[3], `m=%d,t=%d,p=%d`, &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	if hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(hash) == 0 {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	return params, salt, hash, nil
+}
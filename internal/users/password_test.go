@@ -0,0 +1,171 @@
+package users
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+// usePasswordHash picks the hashing algorithm, keeping the cost low so tests are quick,
+// and points the datafiles somewhere temporary since rehashed users are saved.
+func usePasswordHash(t *testing.T, algorithm string, argon2Time int) string {
+	t.Helper()
+
+	dir := testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.CarefulSaveFiles": false,
+		"Security.PasswordHash":      algorithm,
+		"Security.BcryptCost":        bcrypt.MinCost,
+		"Security.Argon2Time":        argon2Time,
+		"Security.Argon2MemoryKB":    64,
+		"Security.Argon2Threads":     1,
+		"Validation.PasswordSizeMin": 4,
+		"Validation.PasswordSizeMax": 16,
+	})
+	require.NoError(t, os.Mkdir(filepath.Join(dir, `users`), 0755))
+
+	// Reopened from the new DataFiles on next use
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	return dir
+}
+
+// savedPassword reads the password back out of the user file
+func savedPassword(t *testing.T, dir string, u *UserRecord) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, `users`, `1.yaml`))
+	require.NoError(t, err)
+
+	saved := UserRecord{}
+	require.NoError(t, yaml.Unmarshal(data, &saved))
+
+	return saved.Password
+}
+
+func TestHashPassword(t *testing.T) {
+
+	usePasswordHash(t, configs.PasswordHashArgon2id, 1)
+
+	hash1, err := HashPassword(`hunter2`)
+	require.NoError(t, err)
+	hash2, err := HashPassword(`hunter2`)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hash1, `$argon2id$v=19$m=64,t=1,p=1$`))
+	assert.NotEqual(t, hash1, hash2, "passwords should be salted")
+
+	match, rehash := verifyPassword(hash1, `hunter2`)
+	assert.True(t, match)
+	assert.False(t, rehash)
+
+	match, _ = verifyPassword(hash1, `hunter3`)
+	assert.False(t, match)
+
+	usePasswordHash(t, configs.PasswordHashBcrypt, 1)
+
+	hash, err := HashPassword(`hunter2`)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, `$2a$04$`))
+
+	match, rehash = verifyPassword(hash, `hunter2`)
+	assert.True(t, match)
+	assert.False(t, rehash)
+}
+
+func TestLoginPasswordMatches_Migration(t *testing.T) {
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(`hunter2`), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	usePasswordHash(t, configs.PasswordHashArgon2id, 2)
+	oldArgon2, err := HashPassword(`hunter2`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		stored string
+	}{
+		{name: "plaintext", stored: `hunter2`},
+		{name: "unsalted sha256", stored: util.Hash(`hunter2`)},
+		{name: "bcrypt", stored: string(bcryptHash)},
+		{name: "argon2id with old settings", stored: oldArgon2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir := usePasswordHash(t, configs.PasswordHashArgon2id, 1)
+
+			u := &UserRecord{UserId: 1, Username: `bob`, Password: tt.stored}
+
+			// A wrong password leaves everything alone
+			assert.False(t, u.LoginPasswordMatches(`hunter3`))
+			assert.Equal(t, tt.stored, u.Password)
+			assert.NoFileExists(t, filepath.Join(dir, `users`, `1.yaml`))
+
+			// Checking without logging in (such as web admin auth) never rehashes
+			assert.True(t, u.PasswordMatches(`hunter2`))
+			assert.Equal(t, tt.stored, u.Password)
+			assert.NoFileExists(t, filepath.Join(dir, `users`, `1.yaml`))
+
+			assert.True(t, u.LoginPasswordMatches(`hunter2`))
+			assert.True(t, strings.HasPrefix(u.Password, `$argon2id$v=19$m=64,t=1,p=1$`), u.Password)
+			assert.Equal(t, u.Password, savedPassword(t, dir, u))
+
+			// Once upgraded it still matches, and isn't rehashed again
+			upgraded := u.Password
+			assert.True(t, u.LoginPasswordMatches(`hunter2`))
+			assert.Equal(t, upgraded, u.Password)
+		})
+	}
+}
+
+func TestPasswordMatches_Rejects(t *testing.T) {
+
+	usePasswordHash(t, configs.PasswordHashArgon2id, 1)
+
+	// The hash of a plaintext password used to be accepted in place of the password
+	u := &UserRecord{UserId: 1, Username: `bob`, Password: `hunter2`}
+	assert.False(t, u.PasswordMatches(util.Hash(`hunter2`)))
+
+	// Anyone who can read a user file must not be able to log in with the legacy hash in it
+	u.Password = util.Hash(`hunter2`)
+	assert.False(t, u.PasswordMatches(u.Password))
+	assert.False(t, u.PasswordMatches(strings.ToUpper(u.Password)))
+	assert.False(t, u.LoginPasswordMatches(u.Password))
+	assert.Equal(t, util.Hash(`hunter2`), u.Password)
+
+	// An empty or corrupt password never matches
+	u.Password = ``
+	assert.False(t, u.PasswordMatches(``))
+
+	u.Password = `$argon2id$v=19$m=64,t=1,p=1$bm9wZQ`
+	assert.False(t, u.PasswordMatches(`$argon2id$v=19$m=64,t=1,p=1$bm9wZQ`))
+}
+
+func TestSetPassword(t *testing.T) {
+
+	usePasswordHash(t, configs.PasswordHashArgon2id, 1)
+
+	u := &UserRecord{UserId: 1, Username: `bob`}
+	assert.Error(t, u.SetPassword(`abc`))
+	require.NoError(t, u.SetPassword(`hunter2`))
+
+	assert.NotEqual(t, `hunter2`, u.Password)
+	assert.True(t, u.PasswordMatches(`hunter2`))
+}
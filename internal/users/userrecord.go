@@ -88,22 +88,41 @@ func (u *UserRecord) ClientSettings() connections.ClientSettings {
 	return connections.GetClientSettings(u.connectionId)
 }
 
+// PasswordMatches checks input against the stored password. It never changes the record,
+// so it is safe to use on a copy loaded with LoadUser() while the player is online.
 func (u *UserRecord) PasswordMatches(input string) bool {
+	match, _ := verifyPassword(u.Password, input)
+	return match
+}
 
-	if input == u.Password {
-		return true
-	}
+// LoginPasswordMatches checks the password of a player logging in. Passwords stored in a legacy
+// format, or hashed with settings other than the current ones, are rehashed and the user saved when they match.
+// Only use it on the record about to be handed to LoginUser(), which carries the new hash over to a
+// reconnecting player's live record.
+func (u *UserRecord) LoginPasswordMatches(input string) bool {
 
-	if u.Password == util.Hash(input) {
-		return true
+	match, rehash := verifyPassword(u.Password, input)
+	if !match {
+		return false
 	}
 
-	// In case we reset the password to a plaintext string
-	if input == util.Hash(u.Password) {
-		return true
+	if rehash {
+
+		hash, err := HashPassword(input)
+		if err != nil {
+			mudlog.Error("LoginPasswordMatches", "username", u.Username, "error", err)
+			return true
+		}
+
+		u.Password = hash
+		if err := SaveUser(*u); err != nil {
+			mudlog.Error("LoginPasswordMatches", "username", u.Username, "error", err)
+		}
+
+		mudlog.Info("LoginPasswordMatches", "username", u.Username, "rehashed", true)
 	}
 
-	return false
+	return true
 }
 
 func (u *UserRecord) AddCommandAlias(input string, output string) (addedAlias string, deletedAlias string) {
@@ -262,14 +281,24 @@ func (u *UserRecord) DidTip(tipName string, completed ...bool) bool {
 	return u.TipsComplete[tipName]
 }
 
-func (u *UserRecord) PlayMusic(musicFileOrId string) {
+// PlayMusic starts music playing, replacing whatever music was playing before.
+// fadeMs optionally sets how long the old and new music fade, if the track doesn't set its own.
+func (u *UserRecord) PlayMusic(musicFileOrId string, fadeMs ...int) {
 
 	v := 100
+	fade := 0
+	if len(fadeMs) > 0 {
+		fade = fadeMs[0]
+	}
+
 	if soundConfig := audio.GetFile(musicFileOrId); soundConfig.FilePath != `` {
 		musicFileOrId = soundConfig.FilePath
 		if soundConfig.Volume > 0 && soundConfig.Volume <= 100 {
 			v = soundConfig.Volume
 		}
+		if soundConfig.FadeMs > 0 {
+			fade = soundConfig.FadeMs
+		}
 	}
 
 	events.AddToQueue(events.MSP{
@@ -277,6 +306,9 @@ func (u *UserRecord) PlayMusic(musicFileOrId string) {
 		SoundType: `MUSIC`,
 		SoundFile: musicFileOrId,
 		Volume:    v,
+		Tag:       audio.MusicTag,
+		Loops:     -1,
+		FadeMs:    fade,
 	})
 
 }
@@ -284,11 +316,17 @@ func (u *UserRecord) PlayMusic(musicFileOrId string) {
 func (u *UserRecord) PlaySound(soundId string, category string) {
 
 	v := 100
+	tag := category
+	priority, loops := 0, 0
 	if soundConfig := audio.GetFile(soundId); soundConfig.FilePath != `` {
 		soundId = soundConfig.FilePath
 		if soundConfig.Volume > 0 && soundConfig.Volume <= 100 {
 			v = soundConfig.Volume
 		}
+		if soundConfig.Tag != `` {
+			tag = soundConfig.Tag
+		}
+		priority, loops = soundConfig.Priority, soundConfig.Loops
 	}
 
 	events.AddToQueue(events.MSP{
@@ -297,6 +335,21 @@ func (u *UserRecord) PlaySound(soundId string, category string) {
 		SoundFile: soundId,
 		Volume:    v,
 		Category:  category,
+		Tag:       tag,
+		Priority:  priority,
+		Loops:     loops,
+	})
+
+}
+
+// StopSound stops any sounds or music playing with the tag, or everything if the tag is empty.
+// Clients that only support MSP can't stop by tag, so they stop all music or all sounds.
+func (u *UserRecord) StopSound(tag string) {
+
+	events.AddToQueue(events.MSP{
+		UserId: u.UserId,
+		Tag:    tag,
+		Stop:   true,
 	})
 
 }
@@ -580,7 +633,12 @@ func (u *UserRecord) SetPassword(pw string) error {
 		return fmt.Errorf("password must be between %d and %d characters long", validation.PasswordSizeMin, validation.PasswordSizeMax)
 	}
 
-	u.Password = util.Hash(pw)
+	hash, err := HashPassword(pw)
+	if err != nil {
+		return err
+	}
+
+	u.Password = hash
 	return nil
 }
 
@@ -640,6 +698,11 @@ func (u *UserRecord) GetOnlineInfo() OnlineInfo {
 		isAfk = true
 	}
 
+	isSecure := false
+	if cd := connections.Get(u.connectionId); cd != nil {
+		isSecure = cd.IsSecure()
+	}
+
 	return OnlineInfo{
 		u.Username,
 		u.Character.Name,
@@ -650,6 +713,7 @@ func (u *UserRecord) GetOnlineInfo() OnlineInfo {
 		timeStr,
 		isAfk,
 		u.Role,
+		isSecure,
 	}
 }
 
@@ -3,9 +3,8 @@ package users
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +14,7 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/storage"
 	"github.com/GoMudEngine/GoMud/internal/util"
 
 	//
@@ -105,6 +105,11 @@ func GetAllActiveUsers() []*UserRecord {
 		}
 	}
 
+	// Always the same order, so that a seeded run plays out the same way
+	slices.SortFunc(ret, func(a, b *UserRecord) int {
+		return a.UserId - b.UserId
+	})
+
 	return ret
 }
 
@@ -114,6 +119,7 @@ func GetOnlineUserIds() []int {
 	for _, user := range userManager.Users {
 		onlineList = append(onlineList, user.UserId)
 	}
+	slices.Sort(onlineList)
 	return onlineList
 }
 
@@ -172,6 +178,8 @@ func LoginUser(user *UserRecord, connectionId connections.ConnectionId) (*UserRe
 				mudlog.Info("LoginUser()", "Zombie", true)
 
 				if zombieUser, ok := userManager.Users[user.UserId]; ok {
+					// The password may have been rehashed when it was checked
+					zombieUser.Password = user.Password
 					user = zombieUser
 				}
 
@@ -305,13 +313,12 @@ func CreateUser(u *UserRecord) error {
 	u.UserId = GetUniqueUserId()
 	u.Role = RoleUser
 
-	idx := NewUserIndex()
-	idx.AddUser(u.UserId, u.Username)
-
 	if err := SaveUser(*u); err != nil {
 		return err
 	}
 
+	addToIndex(u.UserId, u.Username)
+
 	userManager.Users[u.UserId] = u
 	userManager.Usernames[u.Username] = u.UserId
 	userManager.Connections[u.connectionId] = u.UserId
@@ -322,16 +329,13 @@ func CreateUser(u *UserRecord) error {
 
 func LoadUser(username string, skipValidation ...bool) (*UserRecord, error) {
 
-	idx := NewUserIndex()
-	userId, found := idx.FindByUsername(username)
+	userId, found := lookupUserId(username)
 
 	if !found {
 		return nil, errors.New("user doesn't exist")
 	}
 
-	userFilePath := util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `users`, `/`, strconv.Itoa(int(userId))+`.yaml`)
-
-	userFileTxt, err := os.ReadFile(userFilePath)
+	userFileTxt, err := storage.Get(storage.Users, strconv.Itoa(userId))
 	if err != nil {
 		return nil, err
 	}
@@ -361,48 +365,35 @@ func LoadUser(username string, skipValidation ...bool) (*UserRecord, error) {
 // Stops searching if false is returned.
 func SearchOfflineUsers(searchFunc func(u *UserRecord) bool) {
 
-	basePath := util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `users`)
+	keys, err := storage.Keys(storage.Users)
+	if err != nil {
+		mudlog.Error("SearchOfflineUsers", "error", err.Error())
+		return
+	}
 
-	filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
+	for _, key := range keys {
 
+		bytes, err := storage.Get(storage.Users, key)
 		if err != nil {
-			return err
+			mudlog.Error("SearchOfflineUsers", "key", key, "error", err.Error())
+			return
 		}
 
-		if info.IsDir() {
-			return nil
+		var uRecord UserRecord
+		if err = yaml.Unmarshal(bytes, &uRecord); err != nil {
+			mudlog.Error("SearchOfflineUsers", "key", key, "error", err.Error())
+			return
 		}
 
-		if len(path) > 10 && path[len(path)-10:] == `.alts.yaml` {
-			return nil
+		// If this is an online user, skip it
+		if _, ok := userManager.Usernames[uRecord.Username]; ok {
+			continue
 		}
 
-		var uRecord UserRecord
-
-		fpathLower := path[len(path)-5:] // Only need to compare the last 5 characters
-		if fpathLower == `.yaml` {
-
-			bytes, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
-
-			err = yaml.Unmarshal(bytes, &uRecord)
-			if err != nil {
-				return err
-			}
-
-			// If this is an online user, skip it
-			if _, ok := userManager.Usernames[uRecord.Username]; ok {
-				return nil
-			}
-
-			if res := searchFunc(&uRecord); !res {
-				return errors.New(`done searching`)
-			}
+		if res := searchFunc(&uRecord); !res {
+			return
 		}
-		return nil
-	})
+	}
 
 }
 
@@ -480,52 +471,35 @@ func CharacterNameSearch(nameToFind string) (foundUserId int, foundUserName stri
 }
 
 func SaveUser(u UserRecord, isAutoSave ...bool) error {
+	return SaveUsers(u)
+}
 
-	fileWritten := false
-	tmpSaved := false
-	tmpCopied := false
-	completed := false
+// SaveUsers saves several users together, such as both sides of a trade.
+// Either they are all saved, or none are.
+func SaveUsers(userRecords ...UserRecord) error {
 
-	defer func() {
-		mudlog.Info("SaveUser()", "username", u.Username, "wrote-file", fileWritten, "tmp-file", tmpSaved, "tmp-copied", tmpCopied, "completed", completed)
-	}()
+	records := make([]storage.Record, 0, len(userRecords))
+	for _, u := range userRecords {
 
-	data, err := yaml.Marshal(&u)
-	if err != nil {
-		return err
-	}
-
-	carefulSave := configs.GetFilePathsConfig().CarefulSaveFiles
-
-	path := util.FilePath(string(configs.GetFilePathsConfig().DataFiles), `/`, `users`, `/`, strconv.Itoa(u.UserId)+`.yaml`)
+		data, err := yaml.Marshal(&u)
+		if err != nil {
+			return err
+		}
 
-	saveFilePath := path
-	if carefulSave { // careful save first saves a {filename}.new file
-		saveFilePath += `.new`
+		records = append(records, storage.Record{
+			Key:  strconv.Itoa(u.UserId),
+			Name: u.Username,
+			Data: data,
+		})
 	}
 
-	err = os.WriteFile(saveFilePath, data, 0777)
-	if err != nil {
-		return err
-	}
-	fileWritten = true
-	if carefulSave {
-		tmpSaved = true
-	}
+	err := storage.Put(storage.Users, records...)
 
-	if carefulSave {
-		//
-		// Once the file is written, rename it to remove the .new suffix and overwrite the old file
-		//
-		if err := os.Rename(saveFilePath, path); err != nil {
-			return err
-		}
-		tmpCopied = true
+	for _, u := range userRecords {
+		mudlog.Info("SaveUser()", "username", u.Username, "storage", storage.Active().Backend(), "completed", err == nil)
 	}
 
-	completed = true
-
-	return nil
+	return err
 }
 
 func GetUniqueUserId() int {
@@ -534,10 +508,9 @@ func GetUniqueUserId() int {
 
 	highestUserId := 0
 
-	idx := NewUserIndex()
-	if idx.Exists() {
+	if id, ok := highestIndexedUserId(); ok {
 
-		highestUserId = idx.GetHighestUserId()
+		highestUserId = id
 
 	} else {
 
@@ -574,14 +547,12 @@ func Exists(name string) bool {
 		}
 	}
 
-	idx := NewUserIndex()
-	_, found := idx.FindByUsername(name)
+	_, found := lookupUserId(name)
 
 	return found
 }
 
 func FindUserId(username string) int {
-	idx := NewUserIndex()
-	userid, _ := idx.FindByUsername(username)
-	return int(userid)
+	userid, _ := lookupUserId(username)
+	return userid
 }
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+//
+// The game asks the clock for the time rather than calling time.Now(),
+// so that a simulation can run the world faster than real time and still
+// see the same times on every run. Turns are still counted by the world, this
+// only covers timestamps the game logic records or compares.
+//
+
+var (
+	clockLock = sync.RWMutex{}
+	clockNow  = time.Now
+)
+
+// Now is the time according to the game, which is the real time unless SetClock was called
+func Now() time.Time {
+	clockLock.RLock()
+	defer clockLock.RUnlock()
+	return clockNow()
+}
+
+// SetClock replaces where the game gets the time from. nil goes back to the real time.
+func SetClock(now func() time.Time) {
+	clockLock.Lock()
+	defer clockLock.Unlock()
+
+	if now == nil {
+		now = time.Now
+	}
+	clockNow = now
+}
+
+// SimClock only moves when it is told to
+type SimClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+func (c *SimClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *SimClock) Advance(d time.Duration) time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
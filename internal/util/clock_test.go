@@ -0,0 +1,25 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimClock(t *testing.T) {
+	defer SetClock(nil)
+
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewSimClock(start)
+
+	SetClock(clock.Now)
+	assert.Equal(t, start, Now())
+	assert.Equal(t, start, Now(), "time shouldn't pass until the clock is advanced")
+
+	assert.Equal(t, start.Add(time.Second), clock.Advance(time.Second))
+	assert.Equal(t, start.Add(time.Second), Now())
+
+	SetClock(nil)
+	assert.WithinDuration(t, time.Now(), Now(), time.Minute)
+}
@@ -170,13 +170,44 @@ func (t *Accumulator) Record(nextValue float64) {
 	}
 }
 
+var (
+	randLock   = sync.Mutex{}
+	randSource *rand.Rand // nil unless SeedRand was called
+)
+
+// SeedRand makes everything that rolls through Rand repeatable from the seed.
+// Until it is called, Rand uses the global (randomly seeded) source.
+func SeedRand(seed int64) {
+	randLock.Lock()
+	defer randLock.Unlock()
+	randSource = rand.New(rand.NewSource(seed))
+}
+
 func Rand(maxInt int) int {
 	if maxInt < 1 {
 		return 0
 	}
+
+	randLock.Lock()
+	defer randLock.Unlock()
+
+	if randSource != nil {
+		return randSource.Intn(maxInt)
+	}
 	return rand.Intn(maxInt)
 }
 
+// RandFloat returns a number in [0.0,1.0) from the same source as Rand
+func RandFloat() float64 {
+	randLock.Lock()
+	defer randLock.Unlock()
+
+	if randSource != nil {
+		return randSource.Float64()
+	}
+	return rand.Float64()
+}
+
 func LogRoll(name string, rollResult int, targetNumber int) {
 	success := rollResult < targetNumber
 	mudlog.Debug(`Rand Result`, `Name`, name, `Result`, fmt.Sprintf(`%d < %d`, rollResult, targetNumber), `Success`, success)
@@ -206,6 +206,37 @@ func TestRand(t *testing.T) {
 	}
 }
 
+func TestSeedRand(t *testing.T) {
+	defer func() {
+		randLock.Lock()
+		randSource = nil
+		randLock.Unlock()
+	}()
+
+	rolls := func() []int {
+		r := make([]int, 20)
+		for i := range r {
+			r[i] = Rand(1000)
+		}
+		return r
+	}
+
+	SeedRand(42)
+	first := rolls()
+
+	SeedRand(42)
+	assert.Equal(t, first, rolls(), "the same seed should roll the same numbers")
+
+	SeedRand(42)
+	f := RandFloat()
+	SeedRand(42)
+	assert.Equal(t, f, RandFloat())
+	assert.True(t, f >= 0 && f < 1)
+
+	SeedRand(43)
+	assert.NotEqual(t, first, rolls())
+}
+
 func TestSplitString(t *testing.T) {
 	type args struct {
 		input string
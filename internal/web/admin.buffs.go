@@ -0,0 +1,74 @@
+package web
+
+import (
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+func buffsIndex(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("index.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String()+"/_header.html", configs.GetFilePathsConfig().AdminHtml.String()+"/buffs/index.html", configs.GetFilePathsConfig().AdminHtml.String()+"/_footer.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	buffSpecs := []buffs.BuffSpec{}
+	for _, buffId := range buffs.GetAllBuffIds() {
+		if b := buffs.GetBuffSpec(buffId); b != nil {
+			buffSpecs = append(buffSpecs, *b)
+		}
+	}
+
+	sort.SliceStable(buffSpecs, func(i, j int) bool {
+		return buffSpecs[i].BuffId < buffSpecs[j].BuffId
+	})
+
+	buffIndexData := struct {
+		Buffs []buffs.BuffSpec
+	}{
+		buffSpecs,
+	}
+
+	if err := tmpl.Execute(w, buffIndexData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+
+}
+
+func buffData(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("buff.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String() + "/buffs/buff.data.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	urlVals := r.URL.Query()
+
+	// Buff 0 is a real buff, so a new one is asked for with "new"
+	isNew := urlVals.Get(`buffid`) == `new`
+	buffIdInt, _ := strconv.Atoi(urlVals.Get(`buffid`))
+
+	buffSpec := &buffs.BuffSpec{}
+	if !isNew {
+		if b := buffs.GetBuffSpec(buffIdInt); b != nil {
+			buffSpec = b
+		}
+	}
+
+	tplData := map[string]any{}
+	tplData[`buffSpec`] = *buffSpec
+	tplData[`isNew`] = isNew
+	tplData[`script`] = html.EscapeString(buffSpec.GetScript())
+
+	if err := tmpl.Execute(w, tplData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+
+}
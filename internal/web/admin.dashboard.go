@@ -0,0 +1,115 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+const dashboardTableRows = 15
+
+// dashboardTiming is one row of a table of histograms, in milliseconds
+type dashboardTiming struct {
+	Name    string
+	Count   uint64
+	MeanMs  float64
+	P95Ms   float64
+	TotalMs float64
+}
+
+// dashboardData renders the live numbers on the admin index page, which reloads them every few seconds.
+func dashboardData(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("dashboard.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String() + "/dashboard.data.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	families := map[string]metrics.Family{}
+	for _, f := range metrics.Gather() {
+		families[f.Name] = f
+	}
+
+	tplData := map[string]any{}
+
+	tplData[`turnMs`] = configs.GetTimingConfig().TurnMs
+	tplData[`turnCount`] = dashboardValue(families[`gomud_turn_count`])
+	tplData[`roundCount`] = dashboardValue(families[`gomud_round_count`])
+	tplData[`usersOnline`] = dashboardValue(families[`gomud_users_online`])
+	tplData[`roomsLoaded`] = dashboardValue(families[`gomud_rooms_loaded`])
+	tplData[`roomsWithPlayers`] = dashboardValue(families[`gomud_rooms_with_players`])
+	tplData[`mobInstances`] = dashboardValue(families[`gomud_mob_instances`])
+	tplData[`eventQueueDepth`] = dashboardValue(families[`gomud_event_queue_depth`])
+	tplData[`llmPending`] = dashboardValue(families[`gomud_llm_pending_jobs`])
+	tplData[`goroutines`] = dashboardValue(families[`go_goroutines`])
+	tplData[`heapMB`] = dashboardValue(families[`go_memstats_heap_alloc_bytes`]) / 1024 / 1024
+
+	tplData[`connections`] = dashboardByLabel(families[`gomud_connections`], `protocol`)
+	tplData[`scriptVMs`] = dashboardByLabel(families[`gomud_script_vms`], `kind`)
+	tplData[`scriptTimeouts`] = dashboardByLabel(families[`gomud_script_timeouts_total`], `kind`)
+
+	tplData[`turnDrift`] = dashboardTimings(families[`gomud_turn_drift_seconds`])
+	tplData[`roundDrift`] = dashboardTimings(families[`gomud_round_drift_seconds`])
+	tplData[`events`] = dashboardTimings(families[`gomud_event_duration_seconds`], `event`)
+	tplData[`listeners`] = dashboardTimings(families[`gomud_listener_duration_seconds`], `listener`, `event`)
+	tplData[`llmRequests`] = dashboardTimings(families[`gomud_llm_request_duration_seconds`], `profile`, `provider`, `result`)
+
+	if err := tmpl.Execute(w, tplData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+}
+
+// dashboardValue adds up every sample of a counter or gauge
+func dashboardValue(f metrics.Family) float64 {
+	total := 0.0
+	for _, s := range f.Samples {
+		total += s.Value
+	}
+	return total
+}
+
+// dashboardByLabel returns the value for each value of a label
+func dashboardByLabel(f metrics.Family, labelName string) map[string]float64 {
+	ret := map[string]float64{}
+	for _, s := range f.Samples {
+		ret[s.Labels[labelName]] += s.Value
+	}
+	return ret
+}
+
+// dashboardTimings turns a histogram into table rows named after the labels given, with the most total time first.
+func dashboardTimings(f metrics.Family, labelNames ...string) []dashboardTiming {
+
+	rows := []dashboardTiming{}
+
+	for _, s := range f.Samples {
+
+		nameParts := []string{}
+		for _, labelName := range labelNames {
+			nameParts = append(nameParts, s.Labels[labelName])
+		}
+
+		rows = append(rows, dashboardTiming{
+			Name:    strings.Join(nameParts, ` / `),
+			Count:   s.Count,
+			MeanMs:  s.Mean() * 1000,
+			P95Ms:   s.Quantile(0.95) * 1000,
+			TotalMs: s.Sum * 1000,
+		})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].TotalMs > rows[j].TotalMs
+	})
+
+	if len(rows) > dashboardTableRows {
+		rows = rows[:dashboardTableRows]
+	}
+
+	return rows
+}
@@ -105,32 +105,40 @@ func mobData(w http.ResponseWriter, r *http.Request) {
 
 	tplData[`mobInfo`] = *mobInfo
 
-	shopData := map[string]characters.Shop{
+	// Each shop item keeps its place in the shop, so the editor saves them back in order
+	type mobShopEntry struct {
+		Index int
+		characters.ShopItem
+	}
+
+	shopData := map[string][]mobShopEntry{
 		`Items`:       {},
 		`Buffs`:       {},
 		`Mercenaries`: {},
 		`Pets`:        {},
 	}
 
-	for _, shopItm := range mobInfo.Character.Shop {
+	for i, shopItm := range mobInfo.Character.Shop {
+
+		entry := mobShopEntry{i, shopItm}
 
 		if shopItm.ItemId > 0 {
-			shopData[`Items`] = append(shopData[`Items`], shopItm)
+			shopData[`Items`] = append(shopData[`Items`], entry)
 			continue
 		}
 
 		if shopItm.BuffId > 0 {
-			shopData[`Buffs`] = append(shopData[`Buffs`], shopItm)
+			shopData[`Buffs`] = append(shopData[`Buffs`], entry)
 			continue
 		}
 
 		if shopItm.MobId > 0 {
-			shopData[`Mercenaries`] = append(shopData[`Mercenaries`], shopItm)
+			shopData[`Mercenaries`] = append(shopData[`Mercenaries`], entry)
 			continue
 		}
 
 		if shopItm.PetType != `` {
-			shopData[`Pets`] = append(shopData[`Pets`], shopItm)
+			shopData[`Pets`] = append(shopData[`Pets`], entry)
 			continue
 		}
 	}
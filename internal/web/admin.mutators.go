@@ -39,7 +39,7 @@ func mutatorsIndex(w http.ResponseWriter, r *http.Request) {
 
 func mutatorData(w http.ResponseWriter, r *http.Request) {
 
-	tmpl, err := template.New("mutator.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String() + "/mutators/mutator.data.html")
+	tmpl, err := template.New("mutator.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String()+"/mutators/mutator.data.html", configs.GetFilePathsConfig().AdminHtml.String()+"/_exit.html")
 	if err != nil {
 		mudlog.Error("HTML Template", "error", err)
 	}
@@ -77,6 +77,8 @@ func mutatorData(w http.ResponseWriter, r *http.Request) {
 	})
 	tplData[`colorPatterns`] = colorPatterns
 
+	tplData[`mapDirections`] = getMapDirections()
+
 	if err := tmpl.Execute(w, tplData); err != nil {
 		mudlog.Error("HTML Execute", "error", err)
 	}
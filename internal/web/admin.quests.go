@@ -0,0 +1,64 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/quests"
+)
+
+func questsIndex(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("index.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String()+"/_header.html", configs.GetFilePathsConfig().AdminHtml.String()+"/quests/index.html", configs.GetFilePathsConfig().AdminHtml.String()+"/_footer.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	allQuests := quests.GetAllQuests()
+
+	sort.SliceStable(allQuests, func(i, j int) bool {
+		return allQuests[i].QuestId < allQuests[j].QuestId
+	})
+
+	questIndexData := struct {
+		Quests []quests.Quest
+	}{
+		allQuests,
+	}
+
+	if err := tmpl.Execute(w, questIndexData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+
+}
+
+func questData(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("quest.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String() + "/quests/quest.data.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	urlVals := r.URL.Query()
+
+	questIdInt, _ := strconv.Atoi(urlVals.Get(`questid`))
+
+	questInfo := &quests.Quest{}
+	if questIdInt > 0 {
+		if q := quests.GetQuest(quests.PartsToToken(questIdInt, `all+`)); q != nil {
+			questInfo = q
+		}
+	}
+
+	tplData := map[string]any{}
+	tplData[`questInfo`] = *questInfo
+
+	if err := tmpl.Execute(w, tplData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"net/http"
+	"text/template"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/recordings"
+)
+
+// Recordings hold what players typed and saw, so only admins doing moderation should see them
+const recordingsPermission = `recordings.read`
+
+func registerRecordingRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/recordings/", RunWithMUDLocked(
+		doAdminAuth(recordingsPermission, recordingsIndex),
+	))
+	mux.HandleFunc("GET /admin/recordings/file/", RunWithMUDLocked(
+		doAdminAuth(recordingsPermission, recordingFile),
+	))
+}
+
+func recordingsIndex(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("index.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String()+"/_header.html", configs.GetFilePathsConfig().AdminHtml.String()+"/recordings/index.html", configs.GetFilePathsConfig().AdminHtml.String()+"/_footer.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	recordingIndexData := struct {
+		Recordings []recordings.FileInfo
+	}{
+		recordings.List(),
+	}
+
+	if err := tmpl.Execute(w, recordingIndexData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+
+}
+
+// recordingFile sends the raw asciicast file for the player to load
+func recordingFile(w http.ResponseWriter, r *http.Request) {
+
+	path, err := recordings.FilePath(r.URL.Query().Get(`name`))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	http.ServeFile(w, r, path)
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/characters"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRecordingRoutes_NeedPermission(t *testing.T) {
+
+	testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.Storage":          storage.BackendSQLite,
+		"Security.PasswordHash":      configs.PasswordHashBcrypt,
+		"Security.BcryptCost":        bcrypt.MinCost,
+		"Validation.PasswordSizeMin": 4,
+		"Validation.PasswordSizeMax": 16,
+		"Roles.builder":              []string{`build`, `api.rooms`},
+		"Roles.moderator":            []string{`recordings.read`},
+	})
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	for userId, role := range []string{users.RoleUser, `builder`, `moderator`, users.RoleAdmin} {
+		u := users.UserRecord{UserId: userId + 1, Username: role + `name`, Role: role, Character: characters.New()}
+		require.NoError(t, u.SetPassword(`password123`))
+		require.NoError(t, users.SaveUser(u))
+	}
+
+	mux := http.NewServeMux()
+	registerRecordingRoutes(mux)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		role   string
+		status int
+	}{
+		{users.RoleUser, http.StatusUnauthorized},
+		{`builder`, http.StatusForbidden},
+		{`moderator`, http.StatusNotFound}, // Let in, but there's no such recording
+		{users.RoleAdmin, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			req, err := http.NewRequest(`GET`, server.URL+`/admin/recordings/file/?name=missing.cast`, nil)
+			require.NoError(t, err)
+			req.SetBasicAuth(tt.role+`name`, `password123`)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
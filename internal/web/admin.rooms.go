@@ -141,7 +141,7 @@ func roomsIndex(w http.ResponseWriter, r *http.Request) {
 
 func roomData(w http.ResponseWriter, r *http.Request) {
 
-	tmpl, err := template.New("room.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String() + "/rooms/room.data.html")
+	tmpl, err := template.New("room.data.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String()+"/rooms/room.data.html", configs.GetFilePathsConfig().AdminHtml.String()+"/_exit.html")
 	if err != nil {
 		mudlog.Error("HTML Template", "error", err)
 	}
@@ -150,11 +150,19 @@ func roomData(w http.ResponseWriter, r *http.Request) {
 
 	roomIdInt, _ := strconv.Atoi(urlVals.Get(`roomid`))
 
-	roomInfo := rooms.LoadRoom(roomIdInt)
+	// The form edits the template, not whatever the instance currently looks like
+	roomInfo := rooms.LoadRoomTemplate(roomIdInt)
+	if roomInfo == nil {
+		roomInfo = &rooms.Room{}
+	}
 
 	tplData := map[string]any{}
 	tplData[`roomInfo`] = roomInfo
 
+	zoneNames := rooms.GetAllZoneNames()
+	sort.Strings(zoneNames)
+	tplData[`zoneNames`] = zoneNames
+
 	buffSpecs := []buffs.BuffSpec{}
 	for _, buffId := range buffs.GetAllBuffIds() {
 		if b := buffs.GetBuffSpec(buffId); b != nil {
@@ -186,15 +194,7 @@ func roomData(w http.ResponseWriter, r *http.Request) {
 
 	tplData[`allSlotTypes`] = characters.GetAllSlotTypes()
 
-	mapDirections := []string{}
-
-	for _, name := range mapper.GetDirectionDeltaNames() {
-		mapDirections = append(mapDirections, name)
-	}
-	sort.SliceStable(mapDirections, func(i, j int) bool {
-		return mapDirections[i] < mapDirections[j]
-	})
-	tplData[`mapDirections`] = mapDirections
+	tplData[`mapDirections`] = getMapDirections()
 
 	mutSpecs := mutators.GetAllMutatorSpecs()
 	sort.SliceStable(mutSpecs, func(i, j int) bool {
@@ -207,3 +207,17 @@ func roomData(w http.ResponseWriter, r *http.Request) {
 	}
 
 }
+
+func getMapDirections() []string {
+
+	mapDirections := []string{}
+
+	for _, name := range mapper.GetDirectionDeltaNames() {
+		mapDirections = append(mapDirections, name)
+	}
+	sort.SliceStable(mapDirections, func(i, j int) bool {
+		return mapDirections[i] < mapDirections[j]
+	})
+
+	return mapDirections
+}
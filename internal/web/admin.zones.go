@@ -0,0 +1,461 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"text/template"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mapper"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+)
+
+// zoneGraph is a zone laid out on a grid, as the mapper sees it.
+// Rooms the zone root can't reach are laid out from their own maps, and set off to the side
+// when nothing ties them to the rest of the zone. Each of these is an Island.
+type zoneGraph struct {
+	Zone       string
+	RootRoomId int
+	Rooms      []zoneGraphRoom
+}
+
+type zoneGraphRoom struct {
+	RoomId   int
+	Title    string
+	Symbol   string
+	ZoneRoot bool
+	Island   int
+	X        int
+	Y        int
+	Z        int
+	Exits    []zoneGraphExit
+}
+
+type zoneGraphExit struct {
+	Name         string
+	RoomId       int
+	MapDirection string
+	Secret       bool
+	Mapped       bool   // Whether the mapper uses this exit to place rooms
+	Zone         string // Set when the exit leads out of the zone
+}
+
+func (g zoneGraph) getRoom(roomId int) *zoneGraphRoom {
+	for i := range g.Rooms {
+		if g.Rooms[i].RoomId == roomId {
+			return &g.Rooms[i]
+		}
+	}
+	return nil
+}
+
+// exitIsMapped is true when the mapper will use the exit to position the room it leads to
+func exitIsMapped(exitName string, mapDirection string) bool {
+	return mapper.IsValidDirection(mapDirection) || mapper.IsValidDirection(exitName)
+}
+
+// exitStaysOnLevel is true for mapped exits that don't go up or down
+func exitStaysOnLevel(exitInfo zoneGraphExit) bool {
+	if !exitInfo.Mapped {
+		return false
+	}
+	direction := exitInfo.Name
+	if mapper.IsValidDirection(exitInfo.MapDirection) {
+		direction = exitInfo.MapDirection
+	}
+	_, _, z := mapper.GetDelta(direction)
+	return z == 0
+}
+
+func buildZoneGraph(zoneName string) (zoneGraph, error) {
+
+	rootRoomId, err := rooms.GetZoneRoot(zoneName)
+	if err != nil {
+		return zoneGraph{}, err
+	}
+
+	graph := zoneGraph{
+		Zone:       zoneName,
+		RootRoomId: rootRoomId,
+		Rooms:      []zoneGraphRoom{},
+	}
+
+	inZone := map[int]bool{}
+	zoneRoomIds := rooms.GetAllZoneRoomsIds(zoneName)
+	sort.Ints(zoneRoomIds)
+	for _, roomId := range zoneRoomIds {
+		inZone[roomId] = true
+	}
+
+	type gridPos struct{ x, y, z, island int }
+	placed := map[int]gridPos{}
+	islandCt := 0
+	maxX := 0
+
+	for i, startRoomId := range append([]int{rootRoomId}, zoneRoomIds...) {
+
+		if _, ok := placed[startRoomId]; ok {
+			continue
+		}
+
+		// The zone map is rebuilt so it shows saves the RebuildMap event hasn't got to yet
+		m := mapper.GetMapper(startRoomId, i == 0)
+		if m == nil {
+			continue
+		}
+
+		crawledRoomIds := m.CrawledRoomIds()
+		sort.Ints(crawledRoomIds)
+
+		// Line this map up with what's already placed if they share a room,
+		// otherwise set it off to the right of everything else.
+		offsetX, offsetY, offsetZ, island := 0, 0, 0, -1
+		for _, roomId := range crawledRoomIds {
+			if pos, ok := placed[roomId]; ok {
+				x, y, z, _ := m.GetCoordinates(roomId)
+				offsetX, offsetY, offsetZ, island = pos.x-x, pos.y-y, pos.z-z, pos.island
+				break
+			}
+		}
+
+		if island == -1 {
+			island = islandCt
+			islandCt++
+
+			if i > 0 {
+				minX, _, _, _ := m.GetCoordinates(startRoomId)
+				for _, roomId := range crawledRoomIds {
+					if x, _, _, err := m.GetCoordinates(roomId); err == nil && x < minX {
+						minX = x
+					}
+				}
+				offsetX = maxX + 3 - minX
+			}
+		}
+
+		for _, roomId := range crawledRoomIds {
+			if _, ok := placed[roomId]; ok || !inZone[roomId] {
+				continue
+			}
+
+			x, y, z, err := m.GetCoordinates(roomId)
+			if err != nil {
+				continue
+			}
+
+			pos := gridPos{x + offsetX, y + offsetY, z + offsetZ, island}
+			placed[roomId] = pos
+			if pos.x > maxX {
+				maxX = pos.x
+			}
+
+			room := rooms.LoadRoom(roomId)
+			if room == nil {
+				continue
+			}
+
+			graphRoom := zoneGraphRoom{
+				RoomId:   roomId,
+				Title:    room.Title,
+				Symbol:   room.MapSymbol,
+				ZoneRoot: roomId == rootRoomId,
+				Island:   pos.island,
+				X:        pos.x,
+				Y:        pos.y,
+				Z:        pos.z,
+				Exits:    []zoneGraphExit{},
+			}
+
+			if graphRoom.Symbol == `` {
+				if b := room.GetBiome(); b.Symbol() != 0 {
+					graphRoom.Symbol = string(b.Symbol())
+				}
+			}
+
+			for exitName, exitInfo := range room.Exits {
+				graphExit := zoneGraphExit{
+					Name:         exitName,
+					RoomId:       exitInfo.RoomId,
+					MapDirection: exitInfo.MapDirection,
+					Secret:       exitInfo.Secret,
+					Mapped:       exitIsMapped(exitName, exitInfo.MapDirection),
+				}
+				if !inZone[exitInfo.RoomId] {
+					if toRoom := rooms.LoadRoom(exitInfo.RoomId); toRoom != nil {
+						graphExit.Zone = toRoom.Zone
+					}
+				}
+				graphRoom.Exits = append(graphRoom.Exits, graphExit)
+			}
+
+			sort.Slice(graphRoom.Exits, func(i, j int) bool {
+				return graphRoom.Exits[i].Name < graphRoom.Exits[j].Name
+			})
+
+			graph.Rooms = append(graph.Rooms, graphRoom)
+		}
+	}
+
+	return graph, nil
+}
+
+// moveZoneGraphRoom points every mapped exit between the room and its neighbors on the same level
+// at the room's new spot, so only the room moves. Nothing is saved unless every exit can be pointed there.
+func moveZoneGraphRoom(graph zoneGraph, roomId int, x int, y int) error {
+
+	movingRoom := graph.getRoom(roomId)
+	if movingRoom == nil {
+		return fmt.Errorf(`room %d is not on the %s map`, roomId, graph.Zone)
+	}
+
+	for _, other := range graph.Rooms {
+		if other.RoomId != roomId && other.X == x && other.Y == y && other.Z == movingRoom.Z && other.Island == movingRoom.Island {
+			return fmt.Errorf(`room %d is already there`, other.RoomId)
+		}
+	}
+
+	// roomId => exitName => new map direction
+	changes := map[int]map[string]string{}
+	linked := false
+
+	addChange := func(from *zoneGraphRoom, exitInfo zoneGraphExit, fromX, fromY, toX, toY int) error {
+		linked = true
+		dirName, ok := mapper.GetDirectionName(toX-fromX, toY-fromY, 0)
+		if !ok {
+			return fmt.Errorf(`exit %s from room %d can't point there, rooms can only be 1 to 3 spaces apart in a straight or diagonal line`, exitInfo.Name, from.RoomId)
+		}
+		if dirName == exitInfo.Name {
+			dirName = ``
+		}
+		if dirName == exitInfo.MapDirection {
+			return nil
+		}
+		if changes[from.RoomId] == nil {
+			changes[from.RoomId] = map[string]string{}
+		}
+		changes[from.RoomId][exitInfo.Name] = dirName
+		return nil
+	}
+
+	for i := range graph.Rooms {
+		other := &graph.Rooms[i]
+		if other.RoomId == roomId || other.Z != movingRoom.Z || other.Island != movingRoom.Island {
+			continue
+		}
+
+		for _, exitInfo := range movingRoom.Exits {
+			if exitInfo.RoomId == other.RoomId && exitStaysOnLevel(exitInfo) {
+				if err := addChange(movingRoom, exitInfo, x, y, other.X, other.Y); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, exitInfo := range other.Exits {
+			if exitInfo.RoomId == roomId && exitStaysOnLevel(exitInfo) {
+				if err := addChange(other, exitInfo, other.X, other.Y, x, y); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !linked {
+		return errors.New(`no exits lead to or from that room on this level, so there is nothing to move it by`)
+	}
+
+	// Every room is changed and checked before any are saved, so a bad one leaves the map as it was
+	originals := []rooms.Room{}
+	updated := []rooms.Room{}
+
+	for _, changeRoomId := range slices.Sorted(maps.Keys(changes)) {
+
+		original := rooms.LoadRoomTemplate(changeRoomId)
+		roomTpl := rooms.LoadRoomTemplate(changeRoomId)
+		if original == nil || roomTpl == nil {
+			return fmt.Errorf(`room %d not found`, changeRoomId)
+		}
+
+		for exitName, mapDirection := range changes[changeRoomId] {
+			exitInfo, ok := roomTpl.Exits[exitName]
+			if !ok {
+				return fmt.Errorf(`room %d has no %s exit`, changeRoomId, exitName)
+			}
+			exitInfo.MapDirection = mapDirection
+			roomTpl.Exits[exitName] = exitInfo
+		}
+
+		if err := roomTpl.Validate(); err != nil {
+			return fmt.Errorf(`room %d: %w`, changeRoomId, err)
+		}
+
+		originals = append(originals, *original)
+		updated = append(updated, *roomTpl)
+	}
+
+	for i, roomTpl := range updated {
+
+		if err := rooms.SaveRoomTemplate(roomTpl); err != nil {
+
+			// Put back the rooms already saved
+			for j := i - 1; j >= 0; j-- {
+				if undoErr := rooms.SaveRoomTemplate(originals[j]); undoErr != nil {
+					mudlog.Error("moveZoneGraphRoom", "roomId", originals[j].RoomId, "error", "Could not put the room back: "+undoErr.Error())
+				}
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// connectZoneGraphRooms adds an exit (and optionally one back), pointed the way the rooms sit on the map
+func connectZoneGraphRooms(graph zoneGraph, fromRoomId int, toRoomId int, exitName string, returnExitName string) error {
+
+	if exitName == `` {
+		return errors.New(`exit name cannot be empty`)
+	}
+
+	// Don't quietly replace exits someone has already set up
+	for roomId, name := range map[int]string{fromRoomId: exitName, toRoomId: returnExitName} {
+		if room := rooms.LoadRoom(roomId); room != nil && name != `` {
+			if _, ok := room.Exits[name]; ok {
+				return fmt.Errorf(`room %d already has a %s exit`, roomId, name)
+			}
+		}
+	}
+
+	mapDirection, returnMapDirection := exitName, returnExitName
+
+	fromRoom, toRoom := graph.getRoom(fromRoomId), graph.getRoom(toRoomId)
+	if fromRoom != nil && toRoom != nil && fromRoom.Island == toRoom.Island {
+		if dirName, ok := mapper.GetDirectionName(toRoom.X-fromRoom.X, toRoom.Y-fromRoom.Y, toRoom.Z-fromRoom.Z); ok {
+			mapDirection = dirName
+		}
+		if dirName, ok := mapper.GetDirectionName(fromRoom.X-toRoom.X, fromRoom.Y-toRoom.Y, fromRoom.Z-toRoom.Z); ok {
+			returnMapDirection = dirName
+		}
+	}
+
+	if err := rooms.ConnectRoom(fromRoomId, toRoomId, exitName, mapDirection); err != nil {
+		return err
+	}
+
+	if returnExitName != `` {
+		if err := rooms.ConnectRoom(toRoomId, fromRoomId, returnExitName, returnMapDirection); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func zonesIndex(w http.ResponseWriter, r *http.Request) {
+
+	tmpl, err := template.New("index.html").Funcs(funcMap).ParseFiles(configs.GetFilePathsConfig().AdminHtml.String()+"/_header.html", configs.GetFilePathsConfig().AdminHtml.String()+"/zones/index.html", configs.GetFilePathsConfig().AdminHtml.String()+"/_footer.html")
+	if err != nil {
+		mudlog.Error("HTML Template", "error", err)
+	}
+
+	allZones := []ZoneDetails{}
+	for _, zoneName := range rooms.GetAllZoneNames() {
+		_, roomCt, _ := rooms.ZoneStats(zoneName)
+		allZones = append(allZones, ZoneDetails{
+			ZoneName:  zoneName,
+			RoomCount: roomCt,
+		})
+	}
+
+	sort.SliceStable(allZones, func(i, j int) bool {
+		return allZones[i].ZoneName < allZones[j].ZoneName
+	})
+
+	tplData := map[string]any{
+		`Zones`: allZones,
+		`Zone`:  r.URL.Query().Get(`zone`),
+	}
+
+	if err := tmpl.Execute(w, tplData); err != nil {
+		mudlog.Error("HTML Execute", "error", err)
+	}
+
+}
+
+func zoneGraphData(w http.ResponseWriter, r *http.Request) {
+	writeZoneGraph(w, r.URL.Query().Get(`zone`))
+}
+
+func writeZoneGraph(w http.ResponseWriter, zoneName string) {
+
+	graph, err := buildZoneGraph(zoneName)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, graph)
+}
+
+func zoneGraphMove(w http.ResponseWriter, r *http.Request) {
+
+	req := struct {
+		Zone   string
+		RoomId int
+		X      int
+		Y      int
+	}{}
+
+	if err := readAPIJSON(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	graph, err := buildZoneGraph(req.Zone)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := moveZoneGraphRoom(graph, req.RoomId, req.X, req.Y); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeZoneGraph(w, req.Zone)
+}
+
+func zoneGraphConnect(w http.ResponseWriter, r *http.Request) {
+
+	req := struct {
+		Zone           string
+		FromRoomId     int
+		ToRoomId       int
+		ExitName       string
+		ReturnExitName string
+	}{}
+
+	if err := readAPIJSON(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	graph, err := buildZoneGraph(req.Zone)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := connectZoneGraphRooms(graph, req.FromRoomId, req.ToRoomId, req.ExitName, req.ReturnExitName); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeZoneGraph(w, req.Zone)
+}
@@ -0,0 +1,681 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/items"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mutators"
+	"github.com/GoMudEngine/GoMud/internal/quests"
+	"github.com/GoMudEngine/GoMud/internal/races"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/spells"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+//
+// The JSON api at /api/v1/ lets tools read and edit world data.
+// Every resource gets the same routes:
+//   GET    /api/v1/{resource}       list
+//   GET    /api/v1/{resource}/{id}  get one
+//   POST   /api/v1/{resource}       create, the new id is assigned
+//   PUT    /api/v1/{resource}/{id}  update, fields left out of the body are unchanged
+//   PATCH  /api/v1/{resource}/{id}  update with a json merge patch (RFC 7396)
+//   DELETE /api/v1/{resource}/{id}  delete
+// Resources that can't be created, updated or deleted leave those routes out (405).
+//
+// Calls need an api token (see the apitoken admin command) whose user has the role permission
+// api.{resource}.read for GET requests, or api.{resource}.write for everything else.
+//
+// The admin pages use the same routes under /admin/api/, logged in with the admin password instead of a token.
+//
+
+const (
+	apiPrefix      = `/api/v1/`
+	editorPrefix   = `/admin/api/`
+	apiMaxBodySize = 1 << 20
+)
+
+var (
+	errAPINotFound = errors.New(`not found`)
+)
+
+// authWrapper checks who is calling before handing off to next
+type authWrapper func(permissionId string, next http.HandlerFunc) http.HandlerFunc
+
+// apiRoutes is anything that can add its routes to a mux
+type apiRoutes interface {
+	register(mux *http.ServeMux, prefix string, auth authWrapper)
+}
+
+// apiResource is one kind of world data served by the api.
+// K is the id type, T is what is sent and received as JSON.
+type apiResource[K comparable, T any] struct {
+	Name    string // In the url and permissions
+	ParseId func(id string) (K, error)
+	List    func(r *http.Request) any
+	Get     func(id K) (T, bool)
+	Create  func(data T) (K, error)  // Optional
+	Update  func(id K, data T) error // Optional. data is the existing value with the request's fields replaced.
+	Delete  func(id K) error         // Optional
+}
+
+func (res apiResource[K, T]) register(mux *http.ServeMux, prefix string, auth authWrapper) {
+
+	basePath := prefix + res.Name
+	readPermission := `api.` + res.Name + `.read`
+	writePermission := `api.` + res.Name + `.write`
+
+	mux.HandleFunc(`GET `+basePath, RunWithMUDLocked(
+		auth(readPermission, res.handleList),
+	))
+	mux.HandleFunc(`GET `+basePath+`/{id}`, RunWithMUDLocked(
+		auth(readPermission, res.handleGet),
+	))
+
+	if res.Create != nil {
+		mux.HandleFunc(`POST `+basePath, RunWithMUDLocked(
+			auth(writePermission, res.handleCreate),
+		))
+	}
+	if res.Update != nil {
+		mux.HandleFunc(`PUT `+basePath+`/{id}`, RunWithMUDLocked(
+			auth(writePermission, res.handleUpdate),
+		))
+		mux.HandleFunc(`PATCH `+basePath+`/{id}`, RunWithMUDLocked(
+			auth(writePermission, res.handlePatch),
+		))
+	}
+	if res.Delete != nil {
+		mux.HandleFunc(`DELETE `+basePath+`/{id}`, RunWithMUDLocked(
+			auth(writePermission, res.handleDelete),
+		))
+	}
+}
+
+func (res apiResource[K, T]) handleList(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, res.List(r))
+}
+
+func (res apiResource[K, T]) handleGet(w http.ResponseWriter, r *http.Request) {
+
+	id, err := res.ParseId(r.PathValue(`id`))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data, ok := res.Get(id)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, errAPINotFound)
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, data)
+}
+
+func (res apiResource[K, T]) handleCreate(w http.ResponseWriter, r *http.Request) {
+
+	var data T
+	if err := readAPIJSON(w, r, &data); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := res.Create(data)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	created, _ := res.Get(id)
+
+	w.Header().Set(`Location`, fmt.Sprintf(`%s%s/%v`, apiPrefix, res.Name, id))
+	writeAPIJSON(w, http.StatusCreated, created)
+}
+
+func (res apiResource[K, T]) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	// Fields in the body replace the existing ones whole, so maps and lists can shrink.
+	res.update(w, r, func(existing map[string]any, changes map[string]any) {
+		for field, value := range changes {
+			existing[field] = value
+		}
+	})
+}
+
+func (res apiResource[K, T]) handlePatch(w http.ResponseWriter, r *http.Request) {
+	// Objects in the body are merged into the existing ones, and null removes a key.
+	res.update(w, r, func(existing map[string]any, changes map[string]any) {
+		mergePatch(existing, changes)
+	})
+}
+
+// update applies the request body to the existing value with merge, and saves the result.
+// Everything is decoded fresh, so a bad request can't leave changes in cached maps and slices.
+func (res apiResource[K, T]) update(w http.ResponseWriter, r *http.Request, merge func(existing map[string]any, changes map[string]any)) {
+
+	id, err := res.ParseId(r.PathValue(`id`))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	existing, ok := res.Get(id)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, errAPINotFound)
+		return
+	}
+
+	changes := map[string]any{}
+	if err := readAPIJSON(w, r, &changes); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	merged := map[string]any{}
+	if b, err := json.Marshal(existing); err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.UseNumber()
+		decoder.Decode(&merged)
+	}
+	merge(merged, changes)
+
+	var data T
+	if err := decodeAPIJSON(merged, &data); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := res.Update(id, data); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	updated, _ := res.Get(id)
+
+	writeAPIJSON(w, http.StatusOK, updated)
+}
+
+func (res apiResource[K, T]) handleDelete(w http.ResponseWriter, r *http.Request) {
+
+	id, err := res.ParseId(r.PathValue(`id`))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := res.Get(id); !ok {
+		writeAPIError(w, http.StatusNotFound, errAPINotFound)
+		return
+	}
+
+	if err := res.Delete(id); err != nil {
+		writeAPIError(w, http.StatusConflict, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func readAPIJSON(w http.ResponseWriter, r *http.Request, v any) error {
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf(`invalid json: %w`, err)
+	}
+
+	return nil
+}
+
+func decodeAPIJSON(fields map[string]any, v any) error {
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf(`invalid json: %w`, err)
+	}
+
+	return nil
+}
+
+// mergePatch merges patch into target following RFC 7396:
+// objects are merged key by key, null removes a key, and anything else replaces what was there.
+func mergePatch(target map[string]any, patch map[string]any) {
+	for key, value := range patch {
+
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+
+		patchObj, ok := value.(map[string]any)
+		if !ok {
+			target[key] = value
+			continue
+		}
+
+		targetObj, ok := target[key].(map[string]any)
+		if !ok {
+			targetObj = map[string]any{}
+		}
+		mergePatch(targetObj, patchObj)
+		target[key] = targetObj
+	}
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set(`Content-Type`, `application/json`)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIJSON(w, status, map[string]string{`error`: err.Error()})
+}
+
+func parseIntId(id string) (int, error) {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf(`invalid id: %s`, id)
+	}
+	return i, nil
+}
+
+func parseStringId(id string) (string, error) {
+	if id == `` {
+		return ``, errors.New(`invalid id`)
+	}
+	return id, nil
+}
+
+// What the api shows for a zone. Zone settings live on the zone's root room.
+type apiZone struct {
+	Name       string
+	RootRoomId int
+	RoomCount  int
+	Config     rooms.ZoneConfig
+}
+
+// What the api shows for each room when listing, since loading every room in full would be slow
+type apiRoomSummary struct {
+	RoomId int
+	Zone   string
+	Title  string
+}
+
+// What the api shows for an online user
+type apiOnlineUser struct {
+	UserId int
+	users.OnlineInfo
+	RoomId int
+	Zone   string
+}
+
+func registerAPIRoutes(mux *http.ServeMux) {
+	for _, res := range worldResources() {
+		res.register(mux, apiPrefix, doTokenAuth)
+		res.register(mux, editorPrefix, doAdminAuth)
+	}
+}
+
+// worldResources is everything served by the api
+func worldResources() []apiRoutes {
+
+	resources := []apiRoutes{}
+
+	resources = append(resources, apiResource[int, items.ItemSpec]{
+		Name:    `items`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			itemSpecs := items.GetAllItemSpecs()
+			sort.Slice(itemSpecs, func(i, j int) bool {
+				return itemSpecs[i].ItemId < itemSpecs[j].ItemId
+			})
+			return itemSpecs
+		},
+		Get: func(id int) (items.ItemSpec, bool) {
+			if spec := items.GetItemSpec(id); spec != nil {
+				return *spec, true
+			}
+			return items.ItemSpec{}, false
+		},
+		Create: items.CreateNewItemFile,
+		Update: func(id int, data items.ItemSpec) error {
+			data.ItemId = id
+			return items.SaveItemFile(data)
+		},
+		Delete: items.DeleteItemFile,
+	})
+
+	resources = append(resources, apiResource[int, mobs.Mob]{
+		Name:    `mobs`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			mobSpecs := mobs.GetAllMobInfo()
+			sort.Slice(mobSpecs, func(i, j int) bool {
+				return mobSpecs[i].MobId < mobSpecs[j].MobId
+			})
+			return mobSpecs
+		},
+		Get: func(id int) (mobs.Mob, bool) {
+			if spec := mobs.GetMobSpec(mobs.MobId(id)); spec != nil {
+				return *spec, true
+			}
+			return mobs.Mob{}, false
+		},
+		Create: func(data mobs.Mob) (int, error) {
+			mobId, err := mobs.CreateNewMobFile(data, ``)
+			return int(mobId), err
+		},
+		Update: func(id int, data mobs.Mob) error {
+			data.MobId = mobs.MobId(id)
+			return mobs.SaveMobFile(data)
+		},
+		Delete: func(id int) error {
+			return mobs.DeleteMobFile(mobs.MobId(id))
+		},
+	})
+
+	resources = append(resources, apiResource[int, rooms.Room]{
+		Name:    `rooms`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+
+			zoneNames := rooms.GetAllZoneNames()
+			if zone := r.URL.Query().Get(`zone`); zone != `` {
+				zoneNames = []string{zone}
+			}
+
+			summaries := []apiRoomSummary{}
+			for _, zoneName := range zoneNames {
+				for _, roomId := range rooms.GetAllZoneRoomsIds(zoneName) {
+					if room := rooms.LoadRoomTemplate(roomId); room != nil {
+						summaries = append(summaries, apiRoomSummary{room.RoomId, room.Zone, room.Title})
+					}
+				}
+			}
+
+			sort.Slice(summaries, func(i, j int) bool {
+				return summaries[i].RoomId < summaries[j].RoomId
+			})
+			return summaries
+		},
+		Get: func(id int) (rooms.Room, bool) {
+			if room := rooms.LoadRoomTemplate(id); room != nil {
+				return *room, true
+			}
+			return rooms.Room{}, false
+		},
+		Create: rooms.CreateRoom,
+		Update: func(id int, data rooms.Room) error {
+
+			// The room needs to be loaded to carry its contents over to the new template
+			room := rooms.LoadRoom(id)
+			if room == nil {
+				return errAPINotFound
+			}
+
+			data.RoomId = id
+			if err := data.Validate(); err != nil {
+				return err
+			}
+
+			if data.Zone != room.Zone {
+				if err := rooms.MoveToZone(id, data.Zone); err != nil {
+					return err
+				}
+			}
+
+			return rooms.SaveRoomTemplate(data)
+		},
+		Delete: rooms.DeleteRoom,
+	})
+
+	getZone := func(name string) (apiZone, bool) {
+		rootRoomId, roomCt, err := rooms.ZoneStats(name)
+		if err != nil {
+			return apiZone{}, false
+		}
+		zone := apiZone{Name: name, RootRoomId: rootRoomId, RoomCount: roomCt}
+		if cfg := rooms.GetZoneConfig(name); cfg != nil {
+			zone.Config = *cfg
+		}
+		return zone, true
+	}
+
+	updateZone := func(name string, data apiZone) error {
+
+		rootRoomId, _, err := rooms.ZoneStats(name)
+		if err != nil {
+			return err
+		}
+
+		if rooms.LoadRoom(rootRoomId) == nil {
+			return fmt.Errorf(`zone %s has no root room`, name)
+		}
+
+		rootRoom := rooms.LoadRoomTemplate(rootRoomId)
+		if rootRoom == nil {
+			return fmt.Errorf(`zone %s has no root room`, name)
+		}
+
+		rootRoom.ZoneConfig = data.Config
+		rootRoom.ZoneConfig.RoomId = rootRoomId
+
+		if err := rootRoom.Validate(); err != nil {
+			return err
+		}
+
+		return rooms.SaveRoomTemplate(*rootRoom)
+	}
+
+	resources = append(resources, apiResource[string, apiZone]{
+		Name:    `zones`,
+		ParseId: parseStringId,
+		List: func(r *http.Request) any {
+			zones := []apiZone{}
+			for _, name := range rooms.GetAllZoneNames() {
+				if zone, ok := getZone(name); ok {
+					zones = append(zones, zone)
+				}
+			}
+			sort.Slice(zones, func(i, j int) bool {
+				return zones[i].Name < zones[j].Name
+			})
+			return zones
+		},
+		Get: getZone,
+		Create: func(data apiZone) (string, error) {
+
+			if err := rooms.ValidateZoneName(data.Name); err != nil {
+				return ``, err
+			}
+
+			name := strings.TrimSpace(data.Name)
+			if _, err := rooms.CreateZone(name); err != nil {
+				return ``, err
+			}
+
+			return name, updateZone(name, data)
+		},
+		Update: func(name string, data apiZone) error {
+			if data.Name != name {
+				return errors.New(`zones can't be renamed`)
+			}
+			return updateZone(name, data)
+		},
+	})
+
+	resources = append(resources, apiResource[string, mutators.MutatorSpec]{
+		Name:    `mutators`,
+		ParseId: parseStringId,
+		List: func(r *http.Request) any {
+			mutSpecs := mutators.GetAllMutatorSpecs()
+			sort.Slice(mutSpecs, func(i, j int) bool {
+				return mutSpecs[i].MutatorId < mutSpecs[j].MutatorId
+			})
+			return mutSpecs
+		},
+		Get: func(id string) (mutators.MutatorSpec, bool) {
+			if spec := mutators.GetMutatorSpec(id); spec != nil {
+				return *spec, true
+			}
+			return mutators.MutatorSpec{}, false
+		},
+		Create: mutators.CreateNewMutatorFile,
+		Update: func(id string, data mutators.MutatorSpec) error {
+			data.MutatorId = id
+			return mutators.SaveMutatorFile(data)
+		},
+	})
+
+	resources = append(resources, apiResource[int, quests.Quest]{
+		Name:    `quests`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			allQuests := quests.GetAllQuests()
+			sort.Slice(allQuests, func(i, j int) bool {
+				return allQuests[i].QuestId < allQuests[j].QuestId
+			})
+			return allQuests
+		},
+		Get: func(id int) (quests.Quest, bool) {
+			if q := quests.GetQuest(quests.PartsToToken(id, `all+`)); q != nil {
+				return *q, true
+			}
+			return quests.Quest{}, false
+		},
+		Create: quests.CreateNewQuestFile,
+		Update: func(id int, data quests.Quest) error {
+			data.QuestId = id
+			return quests.SaveQuestFile(data)
+		},
+		Delete: quests.DeleteQuestFile,
+	})
+
+	resources = append(resources, apiResource[int, buffs.BuffSpec]{
+		Name:    `buffs`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			buffIds := buffs.GetAllBuffIds()
+			sort.Ints(buffIds)
+
+			buffSpecs := []buffs.BuffSpec{}
+			for _, buffId := range buffIds {
+				buffSpecs = append(buffSpecs, *buffs.GetBuffSpec(buffId))
+			}
+			return buffSpecs
+		},
+		Get: func(id int) (buffs.BuffSpec, bool) {
+			if id >= 0 {
+				if spec := buffs.GetBuffSpec(id); spec != nil {
+					return *spec, true
+				}
+			}
+			return buffs.BuffSpec{}, false
+		},
+		Create: buffs.CreateNewBuffFile,
+		Update: func(id int, data buffs.BuffSpec) error {
+			data.BuffId = id
+			return buffs.SaveBuffFile(data)
+		},
+		Delete: buffs.DeleteBuffFile,
+	})
+
+	resources = append(resources, apiResource[string, spells.SpellData]{
+		Name:    `spells`,
+		ParseId: parseStringId,
+		List: func(r *http.Request) any {
+			allSpells := []spells.SpellData{}
+			for _, spell := range spells.GetAllSpells() {
+				allSpells = append(allSpells, *spell)
+			}
+			sort.Slice(allSpells, func(i, j int) bool {
+				return allSpells[i].SpellId < allSpells[j].SpellId
+			})
+			return allSpells
+		},
+		Get: func(id string) (spells.SpellData, bool) {
+			if spell := spells.GetSpell(id); spell != nil {
+				return *spell, true
+			}
+			return spells.SpellData{}, false
+		},
+		Create: spells.CreateNewSpellFile,
+		Update: func(id string, data spells.SpellData) error {
+			data.SpellId = id
+			return spells.SaveSpellFile(data)
+		},
+		Delete: spells.DeleteSpellFile,
+	})
+
+	resources = append(resources, apiResource[int, races.Race]{
+		Name:    `races`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			allRaces := races.GetRaces()
+			sort.Slice(allRaces, func(i, j int) bool {
+				return allRaces[i].RaceId < allRaces[j].RaceId
+			})
+			return allRaces
+		},
+		Get: func(id int) (races.Race, bool) {
+			if race := races.GetRace(id); race != nil {
+				return *race, true
+			}
+			return races.Race{}, false
+		},
+		Create: races.CreateNewRaceFile,
+		Update: func(id int, data races.Race) error {
+			data.RaceId = id
+			return races.SaveRaceFile(data)
+		},
+		Delete: races.DeleteRaceFile,
+	})
+
+	// Online users can only be looked at
+	getOnlineUser := func(userId int) (apiOnlineUser, bool) {
+		u := users.GetByUserId(userId)
+		if u == nil {
+			return apiOnlineUser{}, false
+		}
+		return apiOnlineUser{u.UserId, u.GetOnlineInfo(), u.Character.RoomId, u.Character.Zone}, true
+	}
+
+	resources = append(resources, apiResource[int, apiOnlineUser]{
+		Name:    `users`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			onlineUsers := []apiOnlineUser{}
+			for _, u := range users.GetAllActiveUsers() {
+				if info, ok := getOnlineUser(u.UserId); ok {
+					onlineUsers = append(onlineUsers, info)
+				}
+			}
+			sort.Slice(onlineUsers, func(i, j int) bool {
+				return onlineUsers[i].UserId < onlineUsers[j].UserId
+			})
+			return onlineUsers
+		},
+		Get: getOnlineUser,
+	})
+
+	return resources
+}
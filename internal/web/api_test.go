@@ -0,0 +1,280 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	mudlog.SetupLogger(nil, `LOW`, ``, false)
+	os.Exit(m.Run())
+}
+
+type testThing struct {
+	ThingId int
+	Name    string
+	Tags    []string
+}
+
+// testAPI serves a "things" resource backed by a map, and returns a token for a reader and a writer
+func testAPI(t *testing.T) (*httptest.Server, map[int]*testThing, string, string) {
+	t.Helper()
+
+	require.NoError(t, configs.AddOverlayOverrides(map[string]any{
+		"FilePaths.DataFiles": t.TempDir(),
+		"FilePaths.Storage":   storage.BackendYAML,
+		"Roles.apireader":     []string{`api.things.read`},
+		"Roles.apiwriter":     []string{`api.things`},
+	}))
+	storage.Close()
+	t.Cleanup(func() { storage.Close() })
+
+	tokens := []string{}
+	for userId, role := range []string{`apireader`, `apiwriter`} {
+		u := &users.UserRecord{UserId: userId + 1, Username: role, Role: role}
+		require.NoError(t, storage.Put(storage.Users, storage.Record{
+			Key:  fmt.Sprint(u.UserId),
+			Data: []byte(fmt.Sprintf("userid: %d\nusername: %s\nrole: %s\ncharacter:\n  name: %s\n", u.UserId, u.Username, u.Role, u.Username)),
+		}))
+		tokenStr, _, err := users.CreateAPIToken(u, `test`)
+		require.NoError(t, err)
+		tokens = append(tokens, tokenStr)
+	}
+
+	things := map[int]*testThing{
+		1: {ThingId: 1, Name: `rock`, Tags: []string{`heavy`, `grey`}},
+	}
+
+	mux := http.NewServeMux()
+	apiResource[int, testThing]{
+		Name:    `things`,
+		ParseId: parseIntId,
+		List: func(r *http.Request) any {
+			result := []testThing{}
+			for i := 1; i <= len(things); i++ {
+				result = append(result, *things[i])
+			}
+			return result
+		},
+		Get: func(id int) (testThing, bool) {
+			if thing, ok := things[id]; ok {
+				return *thing, true
+			}
+			return testThing{}, false
+		},
+		Create: func(data testThing) (int, error) {
+			if data.Name == `` {
+				return 0, errors.New(`name cannot be empty`)
+			}
+			data.ThingId = len(things) + 1
+			things[data.ThingId] = &data
+			return data.ThingId, nil
+		},
+		Update: func(id int, data testThing) error {
+			if data.Name == `` {
+				return errors.New(`name cannot be empty`)
+			}
+			data.ThingId = id
+			things[id] = &data
+			return nil
+		},
+	}.register(mux, apiPrefix, doTokenAuth)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, things, tokens[0], tokens[1]
+}
+
+func apiRequest(t *testing.T, server *httptest.Server, method string, path string, token string, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != `` {
+		req.Header.Set(`Authorization`, `Bearer `+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, strings.TrimSpace(string(b))
+}
+
+func TestAPI_Auth(t *testing.T) {
+
+	server, _, reader, writer := testAPI(t)
+
+	status, _ := apiRequest(t, server, `GET`, `/api/v1/things`, ``, ``)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = apiRequest(t, server, `GET`, `/api/v1/things`, reader+`x`, ``)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, body := apiRequest(t, server, `GET`, `/api/v1/things`, reader, ``)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `[{"ThingId":1,"Name":"rock","Tags":["heavy","grey"]}]`, body)
+
+	status, body = apiRequest(t, server, `POST`, `/api/v1/things`, reader, `{"Name":"pebble"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, `api.things.write`)
+
+	// "api.things" prefixes both
+	status, _ = apiRequest(t, server, `GET`, `/api/v1/things/1`, writer, ``)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = apiRequest(t, server, `POST`, `/api/v1/things`, writer, `{"Name":"pebble"}`)
+	assert.Equal(t, http.StatusCreated, status)
+}
+
+func TestAPI_CreateUpdate(t *testing.T) {
+
+	server, things, _, writer := testAPI(t)
+
+	status, body := apiRequest(t, server, `POST`, `/api/v1/things`, writer, `{"Name":"pebble","Tags":["small"]}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, `{"ThingId":2,"Name":"pebble","Tags":["small"]}`, body)
+
+	status, _ = apiRequest(t, server, `POST`, `/api/v1/things`, writer, `{"Tags":["small"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = apiRequest(t, server, `POST`, `/api/v1/things`, writer, `{"Name":"pebble","Colour":"red"}`)
+	assert.Equal(t, http.StatusBadRequest, status, `unknown fields are rejected`)
+
+	// Only the fields sent are changed, and they are replaced whole
+	status, body = apiRequest(t, server, `PUT`, `/api/v1/things/1`, writer, `{"Tags":["grey"]}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"ThingId":1,"Name":"rock","Tags":["grey"]}`, body)
+
+	// A failed update leaves the existing value alone
+	status, _ = apiRequest(t, server, `PUT`, `/api/v1/things/1`, writer, `{"Name":"","Tags":[]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []string{`grey`}, things[1].Tags)
+
+	status, _ = apiRequest(t, server, `PUT`, `/api/v1/things/9`, writer, `{"Name":"ghost"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = apiRequest(t, server, `GET`, `/api/v1/things/abc`, writer, ``)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// No Delete func, so no DELETE route
+	status, _ = apiRequest(t, server, `DELETE`, `/api/v1/things/1`, writer, ``)
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestAPI_Patch(t *testing.T) {
+
+	server, things, _, writer := testAPI(t)
+
+	status, body := apiRequest(t, server, `PATCH`, `/api/v1/things/1`, writer, `{"Name":"boulder"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"ThingId":1,"Name":"boulder","Tags":["heavy","grey"]}`, body)
+
+	// null removes a field, which leaves it empty
+	status, body = apiRequest(t, server, `PATCH`, `/api/v1/things/1`, writer, `{"Tags":null}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"ThingId":1,"Name":"boulder","Tags":null}`, body)
+
+	status, _ = apiRequest(t, server, `PATCH`, `/api/v1/things/1`, writer, `{"Name":null}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, `boulder`, things[1].Name)
+
+	status, _ = apiRequest(t, server, `PATCH`, `/api/v1/things/1`, writer, `{"Colour":"red"}`)
+	assert.Equal(t, http.StatusBadRequest, status, `unknown fields are rejected`)
+
+	status, _ = apiRequest(t, server, `PATCH`, `/api/v1/things/9`, writer, `{"Name":"ghost"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestCheckSameSiteWrite(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		contentType string
+		allowed     bool
+	}{
+		{"Read", `GET`, `https://evil.example`, ``, true},
+		{"Same site", `POST`, `https://mud.example`, `application/json`, true},
+		{"Same site with charset", `PUT`, `https://mud.example`, `application/json; charset=utf-8`, true},
+		{"Merge patch", `PATCH`, `https://mud.example`, `application/merge-patch+json`, true},
+		{"No origin", `POST`, ``, `application/json`, true},
+		{"Delete", `DELETE`, `https://mud.example`, ``, true},
+		{"Other site", `POST`, `https://evil.example`, `application/json`, false},
+		{"Other port", `POST`, `https://mud.example:8080`, `application/json`, false},
+		{"Sandboxed page", `POST`, `null`, `application/json`, false},
+		{"Other site delete", `DELETE`, `https://evil.example`, ``, false},
+		{"Html form", `POST`, `https://mud.example`, `application/x-www-form-urlencoded`, false},
+		{"Plain text", `POST`, ``, `text/plain`, false},
+		{"No content type", `PATCH`, ``, ``, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, `http://mud.example/admin/api/rooms/1`, strings.NewReader(`{}`))
+			if tt.origin != `` {
+				r.Header.Set(`Origin`, tt.origin)
+			}
+			if tt.contentType != `` {
+				r.Header.Set(`Content-Type`, tt.contentType)
+			}
+
+			err := checkSameSiteWrite(r)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+
+	target := map[string]any{
+		`Title`: `A room`,
+		`Exits`: map[string]any{
+			`north`: map[string]any{`RoomId`: 2, `Secret`: true},
+			`south`: map[string]any{`RoomId`: 3},
+		},
+		`Nouns`: []any{`tree`},
+	}
+
+	mergePatch(target, map[string]any{
+		`Exits`: map[string]any{
+			`north`: map[string]any{`Secret`: false},
+			`south`: nil,
+			`up`:    map[string]any{`RoomId`: 4},
+		},
+		`Nouns`: []any{`rock`, `bush`},
+		`Gold`:  5,
+	})
+
+	assert.Equal(t, map[string]any{
+		`Title`: `A room`,
+		`Exits`: map[string]any{
+			`north`: map[string]any{`RoomId`: 2, `Secret`: false},
+			`up`:    map[string]any{`RoomId`: 4},
+		},
+		`Nouns`: []any{`rock`, `bush`},
+		`Gold`:  5,
+	}, target)
+}
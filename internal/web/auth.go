@@ -1,9 +1,14 @@
 package web
 
 import (
+	"errors"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/users"
 )
@@ -76,3 +81,109 @@ func doBasicAuth(next http.HandlerFunc) http.HandlerFunc {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 	})
 }
+
+// doTokenAuth requires an api token ("Authorization: Bearer <token>") belonging to a user whose role has permissionId.
+// Unlike basic auth nothing is cached, so a revoked token or changed role takes effect on the next request.
+func doTokenAuth(permissionId string, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		tokenStr, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			writeAPIError(w, http.StatusUnauthorized, errors.New("api token required"))
+			return
+		}
+
+		uRecord, err := users.AuthenticateAPIToken(tokenStr)
+		if err != nil {
+			mudlog.Error("API LOGIN", "success", false, "error", err)
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			writeAPIError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if !uRecord.HasRolePermission(permissionId) {
+			mudlog.Error("API LOGIN", "username", uRecord.Username, "success", false, "error", `Role=`+uRecord.Role, "permission", permissionId)
+
+			writeAPIError(w, http.StatusForbidden, errors.New("missing role permission: "+permissionId))
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			mudlog.Info("API", "username", uRecord.Username, "method", r.Method, "path", r.URL.Path)
+
+			changelog.Begin(uRecord.UserId, uRecord.Username, r.Method+` `+r.URL.Path)
+			defer changelog.End()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// doAdminAuth lets the admin pages call the api.
+// The caller logs in the same way as any other admin page, and their role must have the permission.
+func doAdminAuth(permissionId string, next http.HandlerFunc) http.HandlerFunc {
+	return doBasicAuth(func(w http.ResponseWriter, r *http.Request) {
+
+		if err := checkSameSiteWrite(r); err != nil {
+			mudlog.Error("ADMIN API", "method", r.Method, "path", r.URL.Path, "origin", r.Header.Get("Origin"), "error", err)
+
+			writeAPIError(w, http.StatusForbidden, err)
+			return
+		}
+
+		username, _, _ := r.BasicAuth()
+
+		uRecord, err := users.LoadUser(username, true)
+		if err != nil {
+			writeAPIError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if !uRecord.HasRolePermission(permissionId) {
+			mudlog.Error("ADMIN API", "username", uRecord.Username, "success", false, "error", `Role=`+uRecord.Role, "permission", permissionId)
+
+			writeAPIError(w, http.StatusForbidden, errors.New("missing role permission: "+permissionId))
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			mudlog.Info("ADMIN API", "username", uRecord.Username, "method", r.Method, "path", r.URL.Path)
+
+			changelog.Begin(uRecord.UserId, uRecord.Username, r.Method+` `+r.URL.Path)
+			defer changelog.End()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkSameSiteWrite stops other sites using an admin's login to change things.
+// A browser sends basic auth with every request to this server, even ones made by a page somewhere else,
+// so a write has to come from a page on this server, and send json, which a plain html form can't.
+func checkSameSiteWrite(r *http.Request) error {
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+
+	// Browsers always send an Origin with these. Tools such as curl don't, and aren't at risk.
+	if origin := r.Header.Get("Origin"); origin != "" {
+		originUrl, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(originUrl.Host, r.Host) {
+			return errors.New("request from another site refused")
+		}
+	}
+
+	if r.Method == http.MethodDelete {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "application/json" && mediaType != "application/merge-patch+json") {
+		return errors.New("Content-Type must be application/json")
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+)
+
+// serveMetrics writes every metric in the Prometheus text format, for scraping.
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := metrics.WriteText(w); err != nil {
+		mudlog.Error("Metrics", "error", err)
+	}
+}
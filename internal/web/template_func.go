@@ -102,5 +102,13 @@ var (
 		"getconfig": func() configs.Config {
 			return configs.GetConfig()
 		},
+		// Bundles key/value pairs into a map, for passing more than one thing to a template
+		"dict": func(pairs ...any) map[string]any {
+			result := map[string]any{}
+			for i := 0; i+1 < len(pairs); i += 2 {
+				result[fmt.Sprint(pairs[i])] = pairs[i+1]
+			}
+			return result
+		},
 	}
 )
@@ -270,6 +270,9 @@ func Listen(wg *sync.WaitGroup, webSocketHandler func(*websocket.Conn)) {
 	http.HandleFunc("GET /admin/", RunWithMUDLocked(
 		doBasicAuth(adminIndex),
 	))
+	http.HandleFunc("GET /admin/dashboarddata/", RunWithMUDLocked(
+		doBasicAuth(dashboardData),
+	))
 
 	// Item Admin
 	http.HandleFunc("GET /admin/items/", RunWithMUDLocked(
@@ -303,6 +306,22 @@ func Listen(wg *sync.WaitGroup, webSocketHandler func(*websocket.Conn)) {
 		doBasicAuth(mutatorData),
 	))
 
+	// Quest Admin
+	http.HandleFunc("GET /admin/quests/", RunWithMUDLocked(
+		doBasicAuth(questsIndex),
+	))
+	http.HandleFunc("GET /admin/quests/questdata/", RunWithMUDLocked(
+		doBasicAuth(questData),
+	))
+
+	// Buff Admin
+	http.HandleFunc("GET /admin/buffs/", RunWithMUDLocked(
+		doBasicAuth(buffsIndex),
+	))
+	http.HandleFunc("GET /admin/buffs/buffdata/", RunWithMUDLocked(
+		doBasicAuth(buffData),
+	))
+
 	// Room Admin
 	http.HandleFunc("GET /admin/rooms/", RunWithMUDLocked(
 		doBasicAuth(roomsIndex),
@@ -311,6 +330,31 @@ func Listen(wg *sync.WaitGroup, webSocketHandler func(*websocket.Conn)) {
 		doBasicAuth(roomData),
 	))
 
+	// Zone map editor
+	http.HandleFunc("GET /admin/zones/", RunWithMUDLocked(
+		doBasicAuth(zonesIndex),
+	))
+	http.HandleFunc("GET /admin/zones/graphdata/", RunWithMUDLocked(
+		doBasicAuth(zoneGraphData),
+	))
+	http.HandleFunc("POST /admin/zones/graph/move", RunWithMUDLocked(
+		doAdminAuth(`api.rooms.write`, zoneGraphMove),
+	))
+	http.HandleFunc("POST /admin/zones/graph/exit", RunWithMUDLocked(
+		doAdminAuth(`api.rooms.write`, zoneGraphConnect),
+	))
+
+	// Session Recordings
+	registerRecordingRoutes(http.DefaultServeMux)
+
+	// JSON api, and the admin pages' editors
+	registerAPIRoutes(http.DefaultServeMux)
+
+	// Prometheus metrics
+	http.HandleFunc("GET /metrics", RunWithMUDLocked(
+		doTokenAuth(`api.metrics.read`, serveMetrics),
+	))
+
 	//
 	// Https server start up
 	//
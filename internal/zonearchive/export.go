@@ -0,0 +1,203 @@
+package zonearchive
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/items"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+// Export packs the rooms of a zone into a new archive, along with the mobs of its folder,
+// the keys to its locks, and everything those use in turn.
+// It also returns anything referred to that couldn't be found, such as a spawn of a mob that doesn't exist.
+func Export(zoneName string) (ArchiveInfo, Manifest, []string, error) {
+
+	m, files, missing, err := export(dirSource(configs.GetFilePathsConfig().DataFiles.String()), zoneName, util.Now())
+	if err != nil {
+		return ArchiveInfo{}, m, missing, err
+	}
+
+	m.ExportedFrom = configs.GetServerConfig().MudName.String()
+
+	a, err := writeArchive(m, files)
+
+	return a, m, missing, err
+}
+
+func export(src source, zoneName string, now time.Time) (Manifest, memSource, []string, error) {
+
+	m := Manifest{FormatVersion: FormatVersion, Exported: now}
+	files := memSource{}
+	missing := []string{}
+
+	idx, err := buildIndex(src)
+	if err != nil {
+		return m, files, missing, err
+	}
+
+	zoneFolder := rooms.ZoneNameSanitize(zoneName)
+
+	included := map[string]map[string]any{} // kind => id => parsed spec
+	queue := [][2]string{}
+
+	include := func(kind string, id string) {
+		if _, ok := included[kind][id]; ok {
+			return
+		}
+		if included[kind] == nil {
+			included[kind] = map[string]any{}
+		}
+		included[kind][id] = nil
+		queue = append(queue, [2]string{kind, id})
+	}
+
+	for id, relPath := range idx.specs[kindRoom] {
+		if zoneFolderOf(relPath) == zoneFolder {
+			include(kindRoom, id)
+		}
+	}
+
+	if len(included[kindRoom]) == 0 {
+		return m, files, missing, fmt.Errorf(`%w: %s`, ErrZoneNotFound, zoneName)
+	}
+
+	// Mobs of the zone may only be spawned by scripts
+	for id, relPath := range idx.specs[kindMob] {
+		if zoneFolderOf(relPath) == zoneFolder {
+			include(kindMob, id)
+		}
+	}
+
+	// Keys are found by the lock they open, rather than by anything referring to them
+	for id, relPath := range idx.specs[kindItem] {
+		data, err := src.Read(relPath)
+		if err != nil {
+			return m, files, missing, err
+		}
+		obj, err := parseSpec(kindItem, relPath, data)
+		if err != nil {
+			continue
+		}
+		if lockId := obj.(*items.ItemSpec).KeyLockId; lockId != `` {
+			roomId, _, _ := strings.Cut(lockId, `-`)
+			if _, ok := included[kindRoom][roomId]; ok {
+				include(kindItem, id)
+			}
+		}
+	}
+
+	// Follow references until nothing new turns up
+	for len(queue) > 0 {
+
+		kind, id := queue[0][0], queue[0][1]
+		queue = queue[1:]
+
+		relPath := idx.specs[kind][id]
+		data, err := src.Read(relPath)
+		if err != nil {
+			return m, files, missing, err
+		}
+
+		obj, err := parseSpec(kind, relPath, data)
+		if err != nil {
+			return m, files, missing, err
+		}
+		included[kind][id] = obj
+		files[relPath] = data
+
+		refs := []map[string]map[string]bool{refsOf(kind, id, obj)}
+
+		for _, extraPath := range idx.extras[kind][id] {
+
+			// Mobs only use the conversations of the zone they're in
+			if strings.HasPrefix(extraPath, `conversations/`) && zoneFolderOf(extraPath) != zoneFolder {
+				continue
+			}
+
+			extraData, err := src.Read(extraPath)
+			if err != nil {
+				return m, files, missing, err
+			}
+			files[extraPath] = extraData
+
+			if strings.HasPrefix(extraPath, `conversations/`) {
+				conv, err := parseSpec(kind, extraPath, extraData)
+				if err != nil {
+					return m, files, missing, err
+				}
+				refs = append(refs, refsOf(kind, id, conv))
+			}
+		}
+
+		for _, found := range refs {
+			for _, refKind := range slices.Sorted(maps.Keys(found)) {
+
+				// Rooms outside of the zone are where its exits lead, not part of it
+				if refKind == kindRoom {
+					continue
+				}
+
+				for _, refId := range slices.Sorted(maps.Keys(found[refKind])) {
+					if !idx.has(refKind, refId) {
+						missing = append(missing, fmt.Sprintf(`%s %s uses %s %s, which doesn't exist`, kind, id, refKind, refId))
+						continue
+					}
+					include(refKind, refId)
+				}
+			}
+		}
+	}
+
+	for kind, specs := range included {
+		for id, obj := range specs {
+
+			if kind == kindMutator {
+				m.MutatorIds = append(m.MutatorIds, id)
+				continue
+			}
+
+			intId, _ := strconv.Atoi(id)
+
+			switch kind {
+			case kindRoom:
+				m.RoomIds = append(m.RoomIds, intId)
+				if r := obj.(*rooms.Room); r.ZoneConfig.RoomId == r.RoomId {
+					m.RootRoomId = r.RoomId
+					m.Zone = r.Zone
+				}
+			case kindMob:
+				m.MobIds = append(m.MobIds, intId)
+			case kindItem:
+				m.ItemIds = append(m.ItemIds, intId)
+			case kindBuff:
+				m.BuffIds = append(m.BuffIds, intId)
+			case kindQuest:
+				m.QuestIds = append(m.QuestIds, intId)
+			}
+		}
+	}
+
+	for _, ids := range [][]int{m.RoomIds, m.MobIds, m.ItemIds, m.BuffIds, m.QuestIds} {
+		slices.Sort(ids)
+	}
+	slices.Sort(m.MutatorIds)
+	slices.Sort(missing)
+
+	if m.Zone == `` {
+		m.Zone = included[kindRoom][strconv.Itoa(m.RoomIds[0])].(*rooms.Room).Zone
+	}
+
+	m.Files = map[string]string{}
+	for relPath, data := range files {
+		m.Files[relPath] = checksum(data)
+	}
+
+	return m, files, missing, nil
+}
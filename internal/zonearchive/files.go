@@ -0,0 +1,213 @@
+package zonearchive
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/mutators"
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of world file that have ids
+const (
+	kindRoom    = `room`
+	kindMob     = `mob`
+	kindItem    = `item`
+	kindBuff    = `buff`
+	kindQuest   = `quest`
+	kindMutator = `mutator`
+)
+
+var (
+	// The top folders of DataFiles that zones are made of
+	worldFolders = []string{`rooms`, `mobs`, `items`, `buffs`, `quests`, `mutators`, `conversations`}
+
+	folderKinds = map[string]string{
+		`rooms`:         kindRoom,
+		`mobs`:          kindMob,
+		`items`:         kindItem,
+		`buffs`:         kindBuff,
+		`quests`:        kindQuest,
+		`mutators`:      kindMutator,
+		`conversations`: kindMob, // Named after the mob that uses it
+	}
+)
+
+// source is a set of world files. Paths are relative to DataFiles, with forward slashes.
+type source interface {
+	Paths() ([]string, error)
+	Read(relPath string) ([]byte, error)
+}
+
+// dirSource is a DataFiles folder
+type dirSource string
+
+func (d dirSource) Paths() ([]string, error) {
+
+	paths := []string{}
+
+	for _, folder := range worldFolders {
+
+		err := filepath.WalkDir(filepath.Join(string(d), folder), func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			relPath, err := filepath.Rel(string(d), p)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, filepath.ToSlash(relPath))
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+func (d dirSource) Read(relPath string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(string(d), filepath.FromSlash(relPath)))
+}
+
+// memSource is a set of files already in memory, such as the contents of an archive
+type memSource map[string][]byte
+
+func (m memSource) Paths() ([]string, error) {
+	return slices.Sorted(maps.Keys(m)), nil
+}
+
+func (m memSource) Read(relPath string) ([]byte, error) {
+	if data, ok := m[relPath]; ok {
+		return data, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+// leadingId is the number a file name starts with, such as 12 for "12-guard.yaml"
+func leadingId(fileName string) (int, bool) {
+	end := 0
+	for end < len(fileName) && fileName[end] >= '0' && fileName[end] <= '9' {
+		end++
+	}
+	id, err := strconv.Atoi(fileName[:end])
+	return id, err == nil
+}
+
+// zoneFolderOf is the zone folder a room, mob or conversation file is in
+func zoneFolderOf(relPath string) string {
+	parts := strings.Split(relPath, `/`)
+	if len(parts) < 3 {
+		return ``
+	}
+	switch parts[0] {
+	case `rooms`, `mobs`, `conversations`:
+		return parts[1]
+	}
+	return ``
+}
+
+// index finds the files of every spec in a source.
+// The spec file is the .yaml that defines it. Extras are its scripts, and conversations for mobs.
+type index struct {
+	src    source
+	specs  map[string]map[string]string   // kind => id => path
+	extras map[string]map[string][]string // kind => id => paths
+}
+
+func buildIndex(src source) (*index, error) {
+
+	idx := &index{
+		src:    src,
+		specs:  map[string]map[string]string{},
+		extras: map[string]map[string][]string{},
+	}
+	for _, kind := range folderKinds {
+		idx.specs[kind] = map[string]string{}
+		idx.extras[kind] = map[string][]string{}
+	}
+
+	paths, err := src.Paths()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, relPath := range paths {
+
+		top, _, _ := strings.Cut(relPath, `/`)
+		kind, ok := folderKinds[top]
+		if !ok {
+			continue
+		}
+
+		fileName := path.Base(relPath)
+		ext := path.Ext(fileName)
+		if ext != `.yaml` && ext != `.js` {
+			continue // .bak files, readme etc.
+		}
+
+		if kind == kindMutator {
+			if ext != `.yaml` {
+				continue
+			}
+			data, err := src.Read(relPath)
+			if err != nil {
+				return nil, err
+			}
+			spec := mutators.MutatorSpec{}
+			if err := yaml.Unmarshal(data, &spec); err != nil || spec.MutatorId == `` {
+				continue
+			}
+			idx.specs[kind][spec.MutatorId] = relPath
+			continue
+		}
+
+		id, ok := leadingId(fileName)
+		if !ok {
+			continue
+		}
+		idStr := strconv.Itoa(id)
+
+		isSpec := ext == `.yaml` && top != `conversations`
+		if top == `mobs` && strings.Contains(relPath, `/scripts/`) {
+			isSpec = false
+		}
+
+		if isSpec {
+			idx.specs[kind][idStr] = relPath
+		} else {
+			idx.extras[kind][idStr] = append(idx.extras[kind][idStr], relPath)
+		}
+	}
+
+	return idx, nil
+}
+
+// has is true if the spec exists
+func (idx *index) has(kind string, id string) bool {
+	_, ok := idx.specs[kind][id]
+	return ok
+}
+
+// intIds returns the ids of a kind as numbers, for finding unused ones
+func (idx *index) intIds(kind string) map[int]bool {
+	ids := map[int]bool{}
+	for idStr := range idx.specs[kind] {
+		if id, err := strconv.Atoi(idStr); err == nil {
+			ids[id] = true
+		}
+	}
+	return ids
+}
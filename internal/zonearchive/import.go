@@ -0,0 +1,605 @@
+package zonearchive
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/changelog"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mutators"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// The order specs are worked through, so reports come out the same every time
+	kindOrder = []string{kindRoom, kindMob, kindItem, kindBuff, kindQuest, kindMutator}
+)
+
+// Options for Import()
+type Options struct {
+	Zone       string // Import the zone under this name, instead of the one it was exported with
+	NextRoomId int    // Rooms that need a new id get the lowest free one from here up
+	Write      bool   // Otherwise nothing is written, and the Report says what would be
+}
+
+// Remap is a spec given a new id, because its id is already used for something else here
+type Remap struct {
+	Kind  string
+	Name  string
+	OldId string
+	NewId string
+}
+
+// Report is what an import did, or would do
+type Report struct {
+	Archive  string
+	Zone     string // The name the zone is imported as
+	Remapped []Remap
+	Reused   []string // Specs already here exactly as they are in the archive, which aren't written again
+	Files    []string // Paths relative to DataFiles
+	Warnings []string
+	Errors   []string // Nothing is written if there are any
+	Written  bool
+}
+
+func (r *Report) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) fail(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// Import works out how an archive fits into this server and reports it.
+// If opts.Write is set and nothing is wrong, it also writes the files and loads them into the game.
+func Import(name string, opts Options) (Report, error) {
+
+	a, err := Find(name)
+	if err != nil {
+		return Report{}, err
+	}
+
+	m, files, err := readArchive(a)
+	if err != nil {
+		return Report{Archive: a.Name}, err
+	}
+
+	dataFiles := configs.GetFilePathsConfig().DataFiles.String()
+
+	report, toWrite, err := plan(m, files, dirSource(dataFiles), opts)
+	report.Archive = a.Name
+	if err != nil || !opts.Write || len(report.Errors) > 0 {
+		return report, err
+	}
+
+	if err := installFiles(dataFiles, toWrite); err != nil {
+		return report, err
+	}
+
+	written := map[string]bool{}
+	for relPath := range toWrite {
+		written[relPath] = true
+	}
+
+	report.Written = true
+
+	return report, changelog.Reload(written)
+}
+
+// installFiles puts files into DataFiles, all of them or none. Everything is written to a folder
+// alongside first, so a full disk is found before anything is moved into place, and anything
+// already moved is removed again if one can't be.
+func installFiles(dataFiles string, files memSource) (err error) {
+
+	staging, err := os.MkdirTemp(dataFiles, `.zoneimport-`)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	relPaths := slices.Sorted(maps.Keys(files))
+
+	for _, relPath := range relPaths {
+		stagedPath := filepath.Join(staging, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(stagedPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(stagedPath, files[relPath], 0644); err != nil {
+			return err
+		}
+	}
+
+	created := []string{} // Files and folders, in the order they were made
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(created) - 1; i >= 0; i-- {
+			os.Remove(created[i])
+		}
+	}()
+
+	for _, relPath := range relPaths {
+
+		absPath := filepath.Join(dataFiles, filepath.FromSlash(relPath))
+
+		// plan() checked nothing is written over, but something could have been saved since
+		if _, statErr := os.Lstat(absPath); statErr == nil {
+			return fmt.Errorf(`%s already exists here`, relPath)
+		}
+
+		folders, err := makeFolders(filepath.Dir(absPath))
+		created = append(created, folders...)
+		if err != nil {
+			return err
+		}
+
+		changelog.Touch(absPath)
+
+		if err := os.Rename(filepath.Join(staging, filepath.FromSlash(relPath)), absPath); err != nil {
+			return err
+		}
+		created = append(created, absPath)
+	}
+
+	return nil
+}
+
+// makeFolders is os.MkdirAll(), but returns the folders it made, outermost first
+func makeFolders(folder string) ([]string, error) {
+
+	missing := []string{}
+	for f := folder; ; f = filepath.Dir(f) {
+		if _, err := os.Stat(f); err == nil || filepath.Dir(f) == f {
+			break
+		}
+		missing = append(missing, f)
+	}
+
+	made := []string{}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0755); err != nil {
+			return made, err
+		}
+		made = append(made, missing[i])
+	}
+
+	return made, nil
+}
+
+// plan decides the id of everything in the archive, and returns the files to write with every reference updated
+func plan(m Manifest, archive memSource, target source, opts Options) (Report, memSource, error) {
+
+	report := Report{Zone: m.Zone}
+	if opts.Zone != `` {
+		report.Zone = opts.Zone
+	}
+
+	toWrite := memSource{}
+
+	have, err := buildIndex(archive)
+	if err != nil {
+		return report, toWrite, err
+	}
+
+	here, err := buildIndex(target)
+	if err != nil {
+		return report, toWrite, err
+	}
+
+	oldZoneFolder := rooms.ZoneNameSanitize(m.Zone)
+	newZoneFolder := rooms.ZoneNameSanitize(report.Zone)
+
+	if err := rooms.ValidateZoneName(report.Zone); err != nil || report.Zone == `` {
+		report.fail(`%q is not a valid zone name`, report.Zone)
+	}
+
+	for _, relPath := range here.specs[kindRoom] {
+		if zoneFolderOf(relPath) == newZoneFolder {
+			report.fail(`zone %s already exists here`, report.Zone)
+			break
+		}
+	}
+
+	//
+	// Read everything in
+	//
+	specs := map[string]map[string]any{}
+	for _, kind := range kindOrder {
+		specs[kind] = map[string]any{}
+		for _, id := range sortedIds(have.specs[kind]) {
+			relPath := have.specs[kind][id]
+			obj, err := parseSpec(kind, relPath, archive[relPath])
+			if err != nil {
+				report.fail(`%s`, err.Error())
+				continue
+			}
+			specs[kind][id] = obj
+		}
+	}
+
+	//
+	// Decide the ids. Specs that are here already, exactly the same, are used as they are.
+	//
+	newIds := map[string]map[string]string{}
+	reused := map[string]map[string]bool{}
+	used := map[string]map[string]bool{}
+
+	for _, kind := range kindOrder {
+
+		newIds[kind] = map[string]string{}
+		reused[kind] = map[string]bool{}
+		used[kind] = map[string]bool{}
+
+		for id := range here.specs[kind] {
+			used[kind][id] = true
+		}
+
+		for _, id := range sortedIds(specs[kind]) {
+			used[kind][id] = true
+			if kind != kindRoom && here.has(kind, id) && sameAsHere(have, here, kind, id, specs[kind][id]) {
+				reused[kind][id] = true
+			}
+		}
+	}
+
+	giveNewId := func(kind string, id string) {
+		newId := unusedId(kind, id, used[kind], opts.NextRoomId)
+		if newId == `` {
+			report.fail(`there is no unused %s id to give %s %s`, kind, kind, id)
+			newId = id
+		}
+		used[kind][newId] = true
+		newIds[kind][id] = newId
+	}
+
+	for _, kind := range kindOrder {
+		for _, id := range sortedIds(specs[kind]) {
+			switch {
+			case reused[kind][id], !here.has(kind, id):
+				newIds[kind][id] = id
+			default:
+				giveNewId(kind, id)
+			}
+		}
+	}
+
+	// A spec that's the same as the one here, but uses something that has a new id, isn't the same after all
+	for changed := true; changed; {
+		changed = false
+
+		for _, kind := range kindOrder {
+			for _, id := range sortedIds(reused[kind]) {
+				if usesNewId(refsOf(kind, id, specs[kind][id]), newIds) {
+					delete(reused[kind], id)
+					giveNewId(kind, id)
+					changed = true
+				}
+			}
+		}
+	}
+
+	mapper := idMapper(func(kind string, id string) string {
+		if newId, ok := newIds[kind][id]; ok {
+			return newId
+		}
+		return id
+	})
+
+	//
+	// Check what the archive uses that it doesn't have
+	//
+	for _, kind := range kindOrder {
+		for _, id := range sortedIds(specs[kind]) {
+
+			found := refsOf(kind, id, specs[kind][id])
+
+			// Exits are checked as rooms are rewritten
+			if kind == kindRoom {
+				delete(found, kindRoom)
+			}
+
+			for _, refKind := range kindOrder {
+				for _, refId := range sortedIds(found[refKind]) {
+					if _, ok := specs[refKind][refId]; !ok && !here.has(refKind, refId) {
+						report.warn(`%s %s uses %s %s, which isn't in the archive or on this server`, kind, id, refKind, refId)
+					}
+				}
+			}
+		}
+	}
+
+	//
+	// Rewrite and move everything not being reused
+	//
+	for _, kind := range kindOrder {
+		for _, id := range sortedIds(specs[kind]) {
+
+			obj := specs[kind][id]
+			newId := newIds[kind][id]
+			relPath := have.specs[kind][id]
+
+			if reused[kind][id] {
+				report.Reused = append(report.Reused, fmt.Sprintf(`%s %s (%s)`, kind, id, specName(obj)))
+			} else {
+
+				if newId != id {
+					report.Remapped = append(report.Remapped, Remap{Kind: kind, Name: specName(obj), OldId: id, NewId: newId})
+				}
+
+				before, err := yaml.Marshal(obj)
+				if err != nil {
+					return report, toWrite, err
+				}
+
+				if r, ok := obj.(*rooms.Room); ok {
+					checkExits(&report, r, specs[kindRoom], here)
+				}
+
+				mapper.spec(obj)
+
+				switch s := obj.(type) {
+				case *rooms.Room:
+					s.Zone = report.Zone
+				case *mobs.Mob:
+					if rooms.ZoneNameSanitize(s.Zone) == oldZoneFolder {
+						s.Zone = report.Zone
+					}
+				}
+
+				after, err := yaml.Marshal(obj)
+				if err != nil {
+					return report, toWrite, err
+				}
+
+				// Left exactly as it was, unless something in it changed
+				data := archive[relPath]
+				if !bytes.Equal(before, after) {
+					data = after
+				}
+				toWrite[movePath(relPath, id, newId, oldZoneFolder, newZoneFolder)] = data
+
+				if v, ok := obj.(interface{ Validate() error }); ok {
+					if err := v.Validate(); err != nil {
+						report.fail(`%s %s: %s`, kind, id, err.Error())
+					}
+				}
+			}
+
+			for _, extraPath := range have.extras[kind][id] {
+
+				isConversation := strings.HasPrefix(extraPath, `conversations/`)
+
+				// Scripts of specs being reused are already here
+				if reused[kind][id] && !isConversation {
+					continue
+				}
+
+				data := archive[extraPath]
+
+				if isConversation {
+					conv, err := parseSpec(kind, extraPath, data)
+					if err != nil {
+						report.fail(`%s`, err.Error())
+						continue
+					}
+					before, _ := yaml.Marshal(conv)
+					mapper.spec(conv)
+					if after, _ := yaml.Marshal(conv); !bytes.Equal(before, after) {
+						data = after
+					}
+				}
+
+				toWrite[movePath(extraPath, id, newId, oldZoneFolder, newZoneFolder)] = data
+			}
+		}
+	}
+
+	//
+	// Never write over anything
+	//
+	scripts := []string{}
+
+	for _, relPath := range slices.Sorted(maps.Keys(toWrite)) {
+
+		if existing, err := target.Read(relPath); err == nil {
+			if bytes.Equal(existing, toWrite[relPath]) {
+				delete(toWrite, relPath)
+				continue
+			}
+			report.fail(`%s already exists here`, relPath)
+		}
+
+		report.Files = append(report.Files, relPath)
+
+		if strings.HasSuffix(relPath, `.js`) {
+			scripts = append(scripts, relPath)
+		}
+	}
+
+	if len(report.Remapped) > 0 && len(scripts) > 0 {
+		report.warn(`Scripts are copied as they are. Check them for ids that changed: %s`, strings.Join(scripts, `, `))
+	}
+
+	return report, toWrite, nil
+}
+
+// sameAsHere compares a spec (and its scripts) from the archive with the one here that has the same id
+func sameAsHere(have *index, here *index, kind string, id string, obj any) bool {
+
+	herePath := here.specs[kind][id]
+
+	data, err := here.src.Read(herePath)
+	if err != nil {
+		return false
+	}
+
+	hereObj, err := parseSpec(kind, herePath, data)
+	if err != nil {
+		return false
+	}
+
+	a, errA := yaml.Marshal(obj)
+	b, errB := yaml.Marshal(hereObj)
+	if errA != nil || errB != nil || !bytes.Equal(a, b) {
+		return false
+	}
+
+	hereScripts := map[string]bool{}
+	for _, extraPath := range here.extras[kind][id] {
+		if !strings.HasPrefix(extraPath, `conversations/`) {
+			hereScripts[extraPath] = true
+		}
+	}
+
+	for _, extraPath := range have.extras[kind][id] {
+
+		if strings.HasPrefix(extraPath, `conversations/`) {
+			continue
+		}
+
+		archiveData, _ := have.src.Read(extraPath)
+		hereData, err := here.src.Read(extraPath)
+		if err != nil || !bytes.Equal(archiveData, hereData) {
+			return false
+		}
+		delete(hereScripts, extraPath)
+	}
+
+	return len(hereScripts) == 0
+}
+
+// usesNewId is true if any of the references found is to a spec getting a new id
+func usesNewId(found map[string]map[string]bool, newIds map[string]map[string]string) bool {
+	for refKind, refIds := range found {
+		for refId := range refIds {
+			if newId, ok := newIds[refKind][refId]; ok && newId != refId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkExits reports exits that lead out of the zone, and removes any that lead to rooms that don't exist here
+func checkExits(report *Report, r *rooms.Room, zoneRooms map[string]any, here *index) {
+
+	for _, exitName := range slices.Sorted(maps.Keys(r.Exits)) {
+
+		exitRoomId := strconv.Itoa(r.Exits[exitName].RoomId)
+
+		if _, ok := zoneRooms[exitRoomId]; ok {
+			continue
+		}
+
+		if !here.has(kindRoom, exitRoomId) {
+			report.warn(`room %d: exit %s leads to room %s, which doesn't exist here. It will be removed.`, r.RoomId, exitName, exitRoomId)
+			delete(r.Exits, exitName)
+			continue
+		}
+
+		title := ``
+		if data, err := here.src.Read(here.specs[kindRoom][exitRoomId]); err == nil {
+			if obj, err := parseSpec(kindRoom, here.specs[kindRoom][exitRoomId], data); err == nil {
+				title = obj.(*rooms.Room).Title
+			}
+		}
+
+		report.warn(`room %d: exit %s leads out of the zone, to room %s (%s) here`, r.RoomId, exitName, exitRoomId, title)
+	}
+}
+
+// unusedId picks a new id for a spec, that isn't used here or in the archive
+func unusedId(kind string, id string, used map[string]bool, nextRoomId int) string {
+
+	if kind == kindMutator {
+		for i := 2; ; i++ {
+			if newId := fmt.Sprintf(`%s_%d`, id, i); !used[newId] {
+				return newId
+			}
+		}
+	}
+
+	oldId, _ := strconv.Atoi(id)
+
+	// Rooms fill in from the next room id. Everything else goes after the highest id.
+	if kind == kindRoom {
+		for newId := max(nextRoomId, 1); ; newId++ {
+			if !used[strconv.Itoa(newId)] {
+				return strconv.Itoa(newId)
+			}
+		}
+	}
+
+	// Items stay in the range of ids they were in, which decides their folder
+	rangeMin, rangeMax := 1, 0
+	if kind == kindItem {
+		rangeMin = oldId / 10000 * 10000
+		rangeMax = rangeMin + 9999
+	}
+
+	highest := rangeMin - 1
+	for usedId := range used {
+		n, err := strconv.Atoi(usedId)
+		if err != nil || n < rangeMin || (rangeMax > 0 && n > rangeMax) {
+			continue
+		}
+		highest = max(highest, n)
+	}
+
+	if rangeMax > 0 && highest+1 > rangeMax {
+		return ``
+	}
+
+	return strconv.Itoa(max(highest+1, 1))
+}
+
+// movePath is where a file goes once its spec has a new id, and its zone possibly a new name
+func movePath(relPath string, oldId string, newId string, oldZoneFolder string, newZoneFolder string) string {
+
+	parts := strings.Split(relPath, `/`)
+
+	if zoneFolderOf(relPath) == oldZoneFolder {
+		parts[1] = newZoneFolder
+	}
+
+	fileName := parts[len(parts)-1]
+
+	if newId == oldId {
+		// Keeps its name
+	} else if parts[0] == `mutators` {
+		fileName = (&mutators.MutatorSpec{MutatorId: newId}).Filename()
+	} else {
+		digits := 0
+		for digits < len(fileName) && fileName[digits] >= '0' && fileName[digits] <= '9' {
+			digits++
+		}
+		fileName = newId + fileName[digits:]
+	}
+
+	parts[len(parts)-1] = fileName
+
+	return strings.Join(parts, `/`)
+}
+
+// sortedIds returns the keys of a map of ids, numbers in numeric order
+func sortedIds[V any](m map[string]V) []string {
+	return slices.SortedFunc(maps.Keys(m), func(a, b string) int {
+		aInt, aErr := strconv.Atoi(a)
+		bInt, bErr := strconv.Atoi(b)
+		if aErr == nil && bErr == nil {
+			return cmp.Compare(aInt, bInt)
+		}
+		return strings.Compare(a, b)
+	})
+}
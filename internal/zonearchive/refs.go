@@ -0,0 +1,312 @@
+package zonearchive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/conversations"
+	"github.com/GoMudEngine/GoMud/internal/items"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mutators"
+	"github.com/GoMudEngine/GoMud/internal/quests"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"gopkg.in/yaml.v2"
+)
+
+// idMapper is called with every id a world file refers to (including its own), and returns what to replace it with.
+// Finding what a file uses and rewriting it for a new server both walk it the same way.
+type idMapper func(kind string, id string) string
+
+// intId maps a numeric id. Zero means none, so it's left alone.
+func (m idMapper) intId(kind string, id *int) {
+	if *id == 0 {
+		return
+	}
+	if newId, err := strconv.Atoi(m(kind, strconv.Itoa(*id))); err == nil {
+		*id = newId
+	}
+}
+
+func (m idMapper) intIds(kind string, ids []int) {
+	for i := range ids {
+		m.intId(kind, &ids[i])
+	}
+}
+
+// questToken maps the quest id of a token such as "4-start"
+func (m idMapper) questToken(token *string) {
+	idStr, step, hasStep := strings.Cut(*token, quests.QuestTokenSeparator)
+	questId, err := strconv.Atoi(idStr)
+	if err != nil || questId == 0 {
+		return
+	}
+	m.intId(kindQuest, &questId)
+	*token = strconv.Itoa(questId)
+	if hasStep {
+		*token += quests.QuestTokenSeparator + step
+	}
+}
+
+// lockId maps the room id of a lock id such as "778-north"
+func (m idMapper) lockId(lockId *string) {
+	idStr, name, ok := strings.Cut(*lockId, `-`)
+	roomId, err := strconv.Atoi(idStr)
+	if !ok || err != nil {
+		return
+	}
+	m.intId(kindRoom, &roomId)
+	*lockId = fmt.Sprintf(`%d-%s`, roomId, name)
+}
+
+func (m idMapper) mutatorList(list mutators.MutatorList) {
+	for i := range list {
+		list[i].MutatorId = m(kindMutator, list[i].MutatorId)
+	}
+}
+
+func (m idMapper) itemInstances(list []items.Item) {
+	for i := range list {
+		m.itemInstance(&list[i])
+	}
+}
+
+func (m idMapper) itemInstance(itm *items.Item) {
+	m.intId(kindItem, &itm.ItemId)
+	if itm.Spec != nil {
+		m.intIds(kindBuff, itm.Spec.BuffIds)
+		m.intIds(kindBuff, itm.Spec.WornBuffIds)
+		m.intIds(kindBuff, itm.Spec.Damage.CritBuffIds)
+	}
+}
+
+func (m idMapper) room(r *rooms.Room) {
+
+	m.intId(kindRoom, &r.RoomId)
+	m.intId(kindRoom, &r.ZoneConfig.RoomId)
+	m.mutatorList(r.ZoneConfig.Mutators)
+	m.mutatorList(r.Mutators)
+
+	for name, exitInfo := range r.Exits {
+		m.intId(kindRoom, &exitInfo.RoomId)
+		m.intIds(kindBuff, exitInfo.Lock.TrapBuffIds)
+		r.Exits[name] = exitInfo
+	}
+
+	for name, container := range r.Containers {
+		m.intIds(kindBuff, container.Lock.TrapBuffIds)
+		m.itemInstances(container.Items)
+		if len(container.Recipes) > 0 {
+			recipes := map[int][]int{}
+			for itemId, ingredients := range container.Recipes {
+				m.intId(kindItem, &itemId)
+				m.intIds(kindItem, ingredients)
+				recipes[itemId] = ingredients
+			}
+			container.Recipes = recipes
+		}
+		r.Containers[name] = container
+	}
+
+	m.itemInstances(r.Items)
+	m.itemInstances(r.Stash)
+
+	for i := range r.SpawnInfo {
+		spawn := &r.SpawnInfo[i]
+		m.intId(kindMob, &spawn.MobId)
+		m.intId(kindItem, &spawn.ItemId)
+		m.intIds(kindBuff, spawn.BuffIds)
+		for j := range spawn.QuestFlags {
+			m.questToken(&spawn.QuestFlags[j])
+		}
+	}
+}
+
+func (m idMapper) mob(mob *mobs.Mob) {
+
+	mobId := int(mob.MobId)
+	m.intId(kindMob, &mobId)
+	mob.MobId = mobs.MobId(mobId)
+
+	m.intIds(kindBuff, mob.BuffIds)
+	for i := range mob.QuestFlags {
+		m.questToken(&mob.QuestFlags[i])
+	}
+
+	char := &mob.Character
+
+	m.itemInstances(char.Items)
+	for _, worn := range []*items.Item{
+		&char.Equipment.Weapon, &char.Equipment.Offhand, &char.Equipment.Head, &char.Equipment.Neck, &char.Equipment.Body,
+		&char.Equipment.Belt, &char.Equipment.Gloves, &char.Equipment.Ring, &char.Equipment.Legs, &char.Equipment.Feet,
+	} {
+		m.itemInstance(worn)
+	}
+
+	for _, b := range char.Buffs.List {
+		if b != nil {
+			m.intId(kindBuff, &b.BuffId)
+		}
+	}
+
+	for i := range char.Shop {
+		stock := &char.Shop[i]
+		m.intId(kindMob, &stock.MobId)
+		m.intId(kindItem, &stock.ItemId)
+		m.intId(kindBuff, &stock.BuffId)
+		m.intId(kindItem, &stock.TradeItemId)
+	}
+
+	m.intIds(kindBuff, char.Pet.BuffIds)
+	m.itemInstances(char.Pet.Items)
+}
+
+func (m idMapper) itemSpec(spec *items.ItemSpec) {
+	m.intId(kindItem, &spec.ItemId)
+	m.intIds(kindBuff, spec.BuffIds)
+	m.intIds(kindBuff, spec.WornBuffIds)
+	m.intIds(kindBuff, spec.Damage.CritBuffIds)
+	if spec.QuestToken != `` {
+		m.questToken(&spec.QuestToken)
+	}
+	if spec.KeyLockId != `` {
+		m.lockId(&spec.KeyLockId)
+	}
+}
+
+func (m idMapper) buffSpec(spec *buffs.BuffSpec) {
+	m.intId(kindBuff, &spec.BuffId)
+}
+
+func (m idMapper) quest(q *quests.Quest) {
+	m.intId(kindQuest, &q.QuestId)
+	if q.Rewards.QuestId != `` {
+		m.questToken(&q.Rewards.QuestId)
+	}
+	m.intId(kindItem, &q.Rewards.ItemId)
+	m.intId(kindBuff, &q.Rewards.BuffId)
+	m.intId(kindRoom, &q.Rewards.RoomId)
+}
+
+func (m idMapper) mutatorSpec(spec *mutators.MutatorSpec) {
+
+	spec.MutatorId = m(kindMutator, spec.MutatorId)
+	if spec.DecayIntoId != `` {
+		spec.DecayIntoId = m(kindMutator, spec.DecayIntoId)
+	}
+
+	m.intIds(kindBuff, spec.PlayerBuffIds)
+	m.intIds(kindBuff, spec.MobBuffIds)
+	m.intIds(kindBuff, spec.NativeBuffIds)
+
+	for name, exitInfo := range spec.Exits {
+		m.intId(kindRoom, &exitInfo.RoomId)
+		m.intIds(kindBuff, exitInfo.Lock.TrapBuffIds)
+		spec.Exits[name] = exitInfo
+	}
+}
+
+func (m idMapper) conversation(c *conversations.ConversationData) {
+
+	if c.LLMConfig == nil {
+		return
+	}
+
+	for i := range c.LLMConfig.Tools {
+		tool := &c.LLMConfig.Tools[i]
+		for j := range tool.Quests {
+			m.questToken(&tool.Quests[j])
+		}
+		m.intIds(kindItem, tool.ItemIds)
+		m.intIds(kindRoom, tool.RoomIds)
+	}
+}
+
+// spec calls the walk for whichever kind of spec obj is
+func (m idMapper) spec(obj any) {
+	switch s := obj.(type) {
+	case *rooms.Room:
+		m.room(s)
+	case *mobs.Mob:
+		m.mob(s)
+	case *items.ItemSpec:
+		m.itemSpec(s)
+	case *buffs.BuffSpec:
+		m.buffSpec(s)
+	case *quests.Quest:
+		m.quest(s)
+	case *mutators.MutatorSpec:
+		m.mutatorSpec(s)
+	case *conversations.ConversationData:
+		m.conversation(s)
+	}
+}
+
+// parseSpec reads the .yaml of a spec (or a conversation, for the conversations folder)
+func parseSpec(kind string, relPath string, data []byte) (any, error) {
+
+	var obj any
+
+	switch {
+	case strings.HasPrefix(relPath, `conversations/`):
+		obj = &conversations.ConversationData{}
+	case kind == kindRoom:
+		obj = &rooms.Room{}
+	case kind == kindMob:
+		obj = &mobs.Mob{}
+	case kind == kindItem:
+		obj = &items.ItemSpec{}
+	case kind == kindBuff:
+		obj = &buffs.BuffSpec{}
+	case kind == kindQuest:
+		obj = &quests.Quest{}
+	case kind == kindMutator:
+		obj = &mutators.MutatorSpec{}
+	default:
+		return nil, fmt.Errorf(`%s: unknown kind %s`, relPath, kind)
+	}
+
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return nil, fmt.Errorf(`%s: %w`, relPath, err)
+	}
+
+	return obj, nil
+}
+
+// specName is what the spec is called, for reports
+func specName(obj any) string {
+	switch s := obj.(type) {
+	case *rooms.Room:
+		return s.Title
+	case *mobs.Mob:
+		return s.Character.Name
+	case *items.ItemSpec:
+		return s.Name
+	case *buffs.BuffSpec:
+		return s.Name
+	case *quests.Quest:
+		return s.Name
+	case *mutators.MutatorSpec:
+		return s.MutatorId
+	}
+	return ``
+}
+
+// refsOf lists what a spec refers to, other than itself
+func refsOf(kind string, id string, obj any) map[string]map[string]bool {
+
+	found := map[string]map[string]bool{}
+
+	idMapper(func(refKind string, refId string) string {
+		if refKind != kind || refId != id {
+			if found[refKind] == nil {
+				found[refKind] = map[string]bool{}
+			}
+			found[refKind][refId] = true
+		}
+		return refId
+	}).spec(obj)
+
+	return found
+}
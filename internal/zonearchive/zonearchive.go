@@ -0,0 +1,320 @@
+package zonearchive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/util"
+	"gopkg.in/yaml.v2"
+)
+
+//
+// A zone packed into a single archive, so it can be moved from one server to another (staging to production etc.)
+//
+// An archive is a .tar.gz in the "archives" folder of DataFiles. It holds a manifest, the rooms of the zone,
+// and the mobs, items, buffs, quests and mutators they use (with their scripts and conversations),
+// each at the path it had under DataFiles.
+//
+// Importing an archive gives anything that collides with what's already on the server a new id, and
+// rewrites every reference to it (exits, spawns, quest rewards, key lock ids etc.)
+// It always works out a Report first, and only writes the files if asked to and nothing is wrong.
+//
+
+const (
+	FormatVersion  = 1 // Archives newer than this can't be imported
+	folderName     = `archives`
+	fileExt        = `.tar.gz`
+	manifestEntry  = `manifest.yaml`
+	nameTimeFormat = `20060102-150405`
+)
+
+var (
+	ErrZoneNotFound    = errors.New(`zone not found`)
+	ErrArchiveNotFound = errors.New(`archive not found`)
+)
+
+// Manifest describes what's in an archive
+type Manifest struct {
+	FormatVersion int
+	Zone          string
+	RootRoomId    int
+	Exported      time.Time
+	ExportedFrom  string            // Name of the MUD it came from
+	RoomIds       []int             `yaml:",flow"`
+	MobIds        []int             `yaml:",flow"`
+	ItemIds       []int             `yaml:",flow"`
+	BuffIds       []int             `yaml:",flow"`
+	QuestIds      []int             `yaml:",flow"`
+	MutatorIds    []string          `yaml:",flow"`
+	Files         map[string]string // Path relative to DataFiles => sha256 of the contents
+}
+
+// ArchiveInfo is an archive file in the archives folder
+type ArchiveInfo struct {
+	Name     string // Such as frostfang_slums-20261017-150405
+	Modified time.Time
+	Size     int64
+}
+
+func (a ArchiveInfo) path() string {
+	return filepath.Join(folderPath(), a.Name+fileExt)
+}
+
+func folderPath() string {
+	return util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, folderName)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// List returns the archives in the archives folder, newest first
+func List() ([]ArchiveInfo, error) {
+
+	entries, err := os.ReadDir(folderPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return []ArchiveInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	archives := []ArchiveInfo{}
+
+	for _, entry := range entries {
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+
+		fInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		archives = append(archives, ArchiveInfo{
+			Name:     strings.TrimSuffix(entry.Name(), fileExt),
+			Modified: fInfo.ModTime(),
+			Size:     fInfo.Size(),
+		})
+	}
+
+	slices.SortFunc(archives, func(a, b ArchiveInfo) int {
+		if c := b.Modified.Compare(a.Modified); c != 0 {
+			return c
+		}
+		return strings.Compare(b.Name, a.Name)
+	})
+
+	return archives, nil
+}
+
+// Find looks up an archive by name (with or without the extension)
+func Find(name string) (ArchiveInfo, error) {
+
+	archives, err := List()
+	if err != nil {
+		return ArchiveInfo{}, err
+	}
+
+	name = strings.TrimSuffix(filepath.Base(name), fileExt)
+
+	for _, a := range archives {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+
+	return ArchiveInfo{}, fmt.Errorf(`%w: %s`, ErrArchiveNotFound, name)
+}
+
+// writeArchive saves the manifest and files as a new archive named after the zone
+func writeArchive(m Manifest, files memSource) (ArchiveInfo, error) {
+
+	if err := os.MkdirAll(folderPath(), 0755); err != nil {
+		return ArchiveInfo{}, err
+	}
+
+	baseName := strings.ToLower(strings.ReplaceAll(m.Zone, ` `, `_`)) + `-` + m.Exported.Format(nameTimeFormat)
+
+	a := ArchiveInfo{Name: baseName, Modified: m.Exported}
+
+	// More than one a second
+	for i := 2; ; i++ {
+		if _, err := os.Stat(a.path()); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		a.Name = baseName + `-` + strconv.Itoa(i)
+	}
+
+	manifestData, err := yaml.Marshal(m)
+	if err != nil {
+		return a, err
+	}
+
+	// Written to a temporary file so a partial archive is never listed
+	tmpPath := a.path() + `.new`
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return a, err
+	}
+
+	err = func() error {
+
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+
+		addEntry := func(name string, data []byte) error {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: m.Exported}); err != nil {
+				return err
+			}
+			_, err := tw.Write(data)
+			return err
+		}
+
+		// The manifest goes first, so it can be read without unpacking the rest
+		if err := addEntry(manifestEntry, manifestData); err != nil {
+			return err
+		}
+
+		for _, relPath := range slices.Sorted(maps.Keys(files)) {
+			if err := addEntry(relPath, files[relPath]); err != nil {
+				return err
+			}
+		}
+
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		return f.Sync()
+	}()
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return a, err
+	}
+
+	if err := os.Rename(tmpPath, a.path()); err != nil {
+		os.Remove(tmpPath)
+		return a, err
+	}
+
+	if fInfo, err := os.Stat(a.path()); err == nil {
+		a.Size = fInfo.Size()
+	}
+
+	return a, nil
+}
+
+// readArchive unpacks an archive, checking every file listed in the manifest is there and unchanged
+func readArchive(a ArchiveInfo) (Manifest, memSource, error) {
+
+	m := Manifest{}
+	files := memSource{}
+
+	f, err := os.Open(a.path())
+	if err != nil {
+		return m, files, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return m, files, err
+	}
+	defer gz.Close()
+
+	foundManifest := false
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return m, files, err
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return m, files, err
+		}
+
+		if hdr.Name == manifestEntry {
+			if err := yaml.Unmarshal(data, &m); err != nil {
+				return m, files, fmt.Errorf(`%s: %w`, manifestEntry, err)
+			}
+			foundManifest = true
+			continue
+		}
+
+		files[hdr.Name] = data
+	}
+
+	if !foundManifest {
+		return m, files, fmt.Errorf(`%s has no %s`, a.Name, manifestEntry)
+	}
+
+	if m.FormatVersion > FormatVersion {
+		return m, files, fmt.Errorf(`%s is format version %d, but only up to %d can be imported`, a.Name, m.FormatVersion, FormatVersion)
+	}
+
+	for relPath, sum := range m.Files {
+
+		if !safePath(relPath) {
+			return m, files, fmt.Errorf(`%s is not a world file path`, relPath)
+		}
+
+		data, ok := files[relPath]
+		if !ok {
+			return m, files, fmt.Errorf(`%s is listed in the manifest but missing`, relPath)
+		}
+		if checksum(data) != sum {
+			return m, files, fmt.Errorf(`%s does not match its checksum`, relPath)
+		}
+	}
+
+	// Anything not in the manifest is left out
+	for relPath := range files {
+		if _, ok := m.Files[relPath]; !ok {
+			delete(files, relPath)
+		}
+	}
+
+	return m, files, nil
+}
+
+// safePath is true for a relative path inside one of the world file folders
+func safePath(relPath string) bool {
+
+	if relPath != path.Clean(relPath) || path.IsAbs(relPath) || strings.HasPrefix(relPath, `..`) || strings.Contains(relPath, `\`) {
+		return false
+	}
+
+	top, _, _ := strings.Cut(relPath, `/`)
+	return slices.Contains(worldFolders, top)
+}
@@ -0,0 +1,297 @@
+package zonearchive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	testsupport.Main(m)
+}
+
+// useDataFiles points DataFiles at a folder until the test finishes
+func useDataFiles(t *testing.T, dataFiles string) {
+	t.Helper()
+	testsupport.UseConfig(t, map[string]any{
+		"FilePaths.DataFiles": dataFiles,
+	})
+}
+
+// writeFiles creates a DataFiles folder with the files given
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dataFiles := t.TempDir()
+	for relPath, content := range files {
+		path := filepath.Join(dataFiles, filepath.FromSlash(relPath))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dataFiles
+}
+
+func readFile(t *testing.T, dataFiles string, relPath string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dataFiles, filepath.FromSlash(relPath)))
+	if os.IsNotExist(err) {
+		return `(none)`
+	}
+	require.NoError(t, err)
+	return string(data)
+}
+
+var (
+	townSquare = "roomid: 1\nzone: Town\nzoneconfig:\n  roomid: 1\ntitle: Town Square\ndescription: The middle of town.\nexits:\n  north:\n    roomid: 10\n"
+
+	staging = map[string]string{
+		`rooms/town/1.yaml`: townSquare,
+		`rooms/dark_woods/10.yaml`: "roomid: 10\nzone: Dark Woods\nzoneconfig:\n  roomid: 10\ntitle: Edge of the Woods\ndescription: Trees.\n" +
+			"exits:\n  south:\n    roomid: 1\n  east:\n    roomid: 11\n  north:\n    roomid: 12\n    lock:\n      difficulty: 5\n",
+		`rooms/dark_woods/10.js`: "// Edge of the woods\n",
+		`rooms/dark_woods/11.yaml`: "roomid: 11\nzone: Dark Woods\ntitle: Wolf Den\ndescription: Bones everywhere.\nexits:\n  west:\n    roomid: 10\n" +
+			"spawninfo:\n- mobid: 5\n- itemid: 20\n  container: pile\ncontainers:\n  pile:\n    items:\n    - itemid: 21\n",
+		`rooms/dark_woods/12.yaml`:          "roomid: 12\nzone: Dark Woods\ntitle: Clearing\ndescription: Fog.\nexits:\n  south:\n    roomid: 10\n  down:\n    roomid: 99\nmutators:\n- mutatorid: fog\n",
+		`mobs/dark_woods/5-wolf.yaml`:       "mobid: 5\nzone: Dark Woods\nquestflags: [3-start]\nbuffids: [7]\ncharacter:\n  name: wolf\n  items:\n  - itemid: 22\n",
+		`mobs/dark_woods/scripts/5-wolf.js`: "// Howls\n",
+		`conversations/dark_woods/5.yaml`:   "supported:\n  wolf: [\"*\"]\nllmconfig:\n  enabled: true\n  tools:\n  - name: give\n    itemids: [22]\n",
+		`items/other-0/20-stick.yaml`:       "itemid: 20\nname: stick\ntype: junk\n",
+		`items/other-0/21-rock.yaml`:        "itemid: 21\nname: rock\ntype: junk\n",
+		`items/other-0/22-fang.yaml`:        "itemid: 22\nname: fang\ntype: junk\n",
+		`items/other-0/23-key.yaml`:         "itemid: 23\nname: woods key\ntype: key\nkeylockid: 10-north\n",
+		`items/other-0/24-spoon.yaml`:       "itemid: 24\nname: spoon\ntype: junk\n", // Not used by the zone
+		`buffs/7-howling.yaml`:              "buffid: 7\nname: howling\ntriggerrate: 1 round\ntriggercount: 1\n",
+		`quests/3-hunt.yaml`:                "questid: 3\nname: Hunt\nsteps:\n- id: start\nrewards:\n  itemid: 22\n  roomid: 10\n",
+		`mutators/fog.yaml`:                 "mutatorid: fog\ndecayrate: 1 day\n",
+	}
+)
+
+func exportStaging(t *testing.T) (string, ArchiveInfo) {
+	t.Helper()
+
+	dataFiles := writeFiles(t, staging)
+	useDataFiles(t, dataFiles)
+
+	a, m, missing, err := Export(`Dark Woods`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `Dark Woods`, m.Zone)
+	assert.Equal(t, 10, m.RootRoomId)
+	assert.Equal(t, []int{10, 11, 12}, m.RoomIds)
+	assert.Equal(t, []int{5}, m.MobIds)
+	assert.Equal(t, []int{20, 21, 22, 23}, m.ItemIds, `the key is found by its lock, the spoon is left out`)
+	assert.Equal(t, []int{7}, m.BuffIds)
+	assert.Equal(t, []int{3}, m.QuestIds)
+	assert.Equal(t, []string{`fog`}, m.MutatorIds)
+	assert.Contains(t, m.Files, `mobs/dark_woods/scripts/5-wolf.js`)
+	assert.Contains(t, m.Files, `conversations/dark_woods/5.yaml`)
+	assert.NotContains(t, m.Files, `rooms/town/1.yaml`)
+	assert.Empty(t, missing)
+
+	return dataFiles, a
+}
+
+// copyArchive puts an archive into the archives folder of another DataFiles folder
+func copyArchive(t *testing.T, from string, a ArchiveInfo, to string) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(from, folderName, a.Name+fileExt))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(to, folderName), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(to, folderName, a.Name+fileExt), data, 0644))
+}
+
+func TestExport_ZoneNotFound(t *testing.T) {
+
+	useDataFiles(t, writeFiles(t, staging))
+
+	_, _, _, err := Export(`Nowhere`)
+	assert.ErrorIs(t, err, ErrZoneNotFound)
+}
+
+func TestImport(t *testing.T) {
+
+	stagingFiles, a := exportStaging(t)
+
+	production := writeFiles(t, map[string]string{
+		`rooms/town/1.yaml`:           townSquare,
+		`rooms/city/10.yaml`:          "roomid: 10\nzone: City\nzoneconfig:\n  roomid: 10\ntitle: City Gate\ndescription: A gate.\n",
+		`mobs/city/5-guard.yaml`:      "mobid: 5\nzone: City\ncharacter:\n  name: guard\n",
+		`items/other-0/20-stick.yaml`: "itemid: 20\nname: stick\ntype: junk\n", // Same
+		`items/other-0/22-tooth.yaml`: "itemid: 22\nname: tooth\ntype: junk\n", // Different
+		`quests/3-delivery.yaml`:      "questid: 3\nname: Delivery\n",
+		`mutators/fog.yaml`:           "mutatorid: fog\ndecayrate: 1 week\n",
+	})
+	copyArchive(t, stagingFiles, a, production)
+	useDataFiles(t, production)
+
+	// A dry run writes nothing
+	report, err := Import(a.Name, Options{NextRoomId: 100})
+	require.NoError(t, err)
+	require.Empty(t, report.Errors)
+
+	assert.False(t, report.Written)
+	assert.Equal(t, `(none)`, readFile(t, production, `rooms/dark_woods/11.yaml`))
+
+	assert.Equal(t, `Dark Woods`, report.Zone)
+	assert.Equal(t, []Remap{
+		{Kind: kindRoom, Name: `Edge of the Woods`, OldId: `10`, NewId: `100`},
+		{Kind: kindMob, Name: `wolf`, OldId: `5`, NewId: `6`},
+		{Kind: kindItem, Name: `fang`, OldId: `22`, NewId: `24`},
+		{Kind: kindQuest, Name: `Hunt`, OldId: `3`, NewId: `4`},
+		{Kind: kindMutator, Name: `fog`, OldId: `fog`, NewId: `fog_2`},
+	}, report.Remapped)
+	assert.Equal(t, []string{`item 20 (stick)`}, report.Reused)
+	assert.Contains(t, report.Warnings, `room 10: exit south leads out of the zone, to room 1 (Town Square) here`)
+	assert.Contains(t, report.Warnings, `room 12: exit down leads to room 99, which doesn't exist here. It will be removed.`)
+	assert.NotContains(t, report.Files, `items/other-0/20-stick.yaml`)
+
+	report, err = Import(a.Name, Options{NextRoomId: 100, Write: true})
+	require.NoError(t, err)
+	require.Empty(t, report.Errors)
+	assert.True(t, report.Written)
+
+	// Every reference follows the new ids
+	assert.Contains(t, readFile(t, production, `rooms/dark_woods/100.yaml`), "roomid: 100\nzone: Dark Woods\nzoneconfig:\n  roomid: 100\n")
+	assert.Equal(t, "// Edge of the woods\n", readFile(t, production, `rooms/dark_woods/100.js`))
+	assert.Contains(t, readFile(t, production, `rooms/dark_woods/11.yaml`), "- mobid: 6\n")
+	assert.Contains(t, readFile(t, production, `rooms/dark_woods/11.yaml`), "west:\n    roomid: 100\n")
+	assert.Contains(t, readFile(t, production, `rooms/dark_woods/12.yaml`), "- mutatorid: fog_2\n")
+	assert.NotContains(t, readFile(t, production, `rooms/dark_woods/12.yaml`), "roomid: 99")
+	assert.Contains(t, readFile(t, production, `mobs/dark_woods/6-wolf.yaml`), "questflags: [4-start]")
+	assert.Contains(t, readFile(t, production, `mobs/dark_woods/6-wolf.yaml`), "- itemid: 24\n")
+	assert.Equal(t, "// Howls\n", readFile(t, production, `mobs/dark_woods/scripts/6-wolf.js`))
+	assert.Contains(t, readFile(t, production, `conversations/dark_woods/6.yaml`), "itemids: [24]")
+	assert.Contains(t, readFile(t, production, `items/other-0/24-fang.yaml`), "itemid: 24\n")
+	assert.Contains(t, readFile(t, production, `items/other-0/23-key.yaml`), "keylockid: 100-north\n")
+	assert.Contains(t, readFile(t, production, `quests/4-hunt.yaml`), "itemid: 24\n")
+	assert.Contains(t, readFile(t, production, `quests/4-hunt.yaml`), "roomid: 100\n")
+	assert.Contains(t, readFile(t, production, `mutators/fog_2.yaml`), "mutatorid: fog_2\n")
+
+	// Anything with nothing to change is copied exactly
+	assert.Equal(t, staging[`buffs/7-howling.yaml`], readFile(t, production, `buffs/7-howling.yaml`))
+	assert.Equal(t, staging[`items/other-0/21-rock.yaml`], readFile(t, production, `items/other-0/21-rock.yaml`))
+
+	// Nothing here was written over
+	assert.Equal(t, "itemid: 22\nname: tooth\ntype: junk\n", readFile(t, production, `items/other-0/22-tooth.yaml`))
+	assert.Equal(t, "mutatorid: fog\ndecayrate: 1 week\n", readFile(t, production, `mutators/fog.yaml`))
+
+	// The zone is now here, so it can't be imported again under the same name
+	report, err = Import(a.Name, Options{NextRoomId: 100, Write: true})
+	require.NoError(t, err)
+	assert.Contains(t, report.Errors, `zone Dark Woods already exists here`)
+	assert.False(t, report.Written)
+}
+
+func TestImport_NewZoneName(t *testing.T) {
+
+	stagingFiles, a := exportStaging(t)
+
+	// Everything is the same, so only the zone moves
+	production := writeFiles(t, map[string]string{})
+	for relPath, content := range staging {
+		if zoneFolderOf(relPath) != `dark_woods` {
+			path := filepath.Join(production, filepath.FromSlash(relPath))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+	}
+	copyArchive(t, stagingFiles, a, production)
+	useDataFiles(t, production)
+
+	report, err := Import(a.Name, Options{Zone: `Deep Woods`, Write: true})
+	require.NoError(t, err)
+	require.Empty(t, report.Errors)
+
+	assert.Empty(t, report.Remapped)
+	assert.Len(t, report.Reused, 7)
+
+	assert.Contains(t, readFile(t, production, `rooms/deep_woods/10.yaml`), "zone: Deep Woods\n")
+	assert.Contains(t, readFile(t, production, `rooms/deep_woods/11.yaml`), "- mobid: 5\n")
+	assert.Contains(t, readFile(t, production, `mobs/deep_woods/5-wolf.yaml`), "zone: Deep Woods\n")
+	assert.Equal(t, staging[`conversations/dark_woods/5.yaml`], readFile(t, production, `conversations/deep_woods/5.yaml`))
+}
+
+func TestInstallFiles_AllOrNothing(t *testing.T) {
+
+	// rooms/b is a file, so nothing can be put in it
+	dataFiles := writeFiles(t, map[string]string{
+		`rooms/b`: "not a folder\n",
+	})
+
+	err := installFiles(dataFiles, memSource{
+		`mobs/a/1-rat.yaml`: []byte("mobid: 1\n"),
+		`rooms/a/1.yaml`:    []byte("roomid: 1\n"),
+		`rooms/b/2.yaml`:    []byte("roomid: 2\n"),
+	})
+	assert.Error(t, err)
+
+	// Whatever was put in place before it failed was taken out again, along with the folders made for it
+	assert.Equal(t, `(none)`, readFile(t, dataFiles, `mobs/a/1-rat.yaml`))
+	assert.Equal(t, `(none)`, readFile(t, dataFiles, `rooms/a/1.yaml`))
+	assert.Equal(t, "not a folder\n", readFile(t, dataFiles, `rooms/b`))
+
+	entries, err := os.ReadDir(dataFiles)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, `rooms`, entries[0].Name())
+
+	entries, err = os.ReadDir(filepath.Join(dataFiles, `rooms`))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, `b`, entries[0].Name())
+
+	// Once nothing is in the way, everything goes in
+	require.NoError(t, os.Remove(filepath.Join(dataFiles, `rooms/b`)))
+	require.NoError(t, installFiles(dataFiles, memSource{
+		`mobs/a/1-rat.yaml`: []byte("mobid: 1\n"),
+		`rooms/b/2.yaml`:    []byte("roomid: 2\n"),
+	}))
+	assert.Equal(t, "mobid: 1\n", readFile(t, dataFiles, `mobs/a/1-rat.yaml`))
+	assert.Equal(t, "roomid: 2\n", readFile(t, dataFiles, `rooms/b/2.yaml`))
+
+	entries, err = os.ReadDir(dataFiles)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, `the staging folder is gone`)
+}
+
+func TestImport_BadArchive(t *testing.T) {
+
+	dataFiles := writeFiles(t, map[string]string{})
+	useDataFiles(t, dataFiles)
+
+	_, err := Import(`nothing`, Options{})
+	assert.ErrorIs(t, err, ErrArchiveNotFound)
+
+	a, err := writeArchive(Manifest{Zone: `Bad`, FormatVersion: FormatVersion, Files: map[string]string{`rooms/bad/1.yaml`: `abc`}},
+		memSource{`rooms/bad/1.yaml`: []byte("roomid: 1\n")})
+	require.NoError(t, err)
+
+	_, err = Import(a.Name, Options{})
+	assert.ErrorContains(t, err, `rooms/bad/1.yaml does not match its checksum`)
+}
+
+func TestMovePath(t *testing.T) {
+
+	tests := []struct {
+		path  string
+		oldId string
+		newId string
+		want  string
+	}{
+		{`rooms/dark_woods/10.yaml`, `10`, `100`, `rooms/deep_woods/100.yaml`},
+		{`mobs/dark_woods/scripts/5-wolf-angry.js`, `5`, `6`, `mobs/deep_woods/scripts/6-wolf-angry.js`},
+		{`mobs/town/5-wolf.yaml`, `5`, `5`, `mobs/town/5-wolf.yaml`},
+		{`items/armor-20000/head/20001-cap.yaml`, `20001`, `20002`, `items/armor-20000/head/20002-cap.yaml`},
+		{`mutators/fog.yaml`, `fog`, `fog_2`, `mutators/fog_2.yaml`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, movePath(tt.path, tt.oldId, tt.newId, `dark_woods`, `deep_woods`), tt.path)
+	}
+}
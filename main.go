@@ -2,6 +2,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
@@ -18,19 +19,21 @@ import (
 	"time"
 
 	"github.com/GoMudEngine/GoMud/internal/audio"
+	"github.com/GoMudEngine/GoMud/internal/bans"
 	"github.com/GoMudEngine/GoMud/internal/buffs"
+	"github.com/GoMudEngine/GoMud/internal/changelog"
 	"github.com/GoMudEngine/GoMud/internal/characters"
 	"github.com/GoMudEngine/GoMud/internal/colorpatterns"
 	"github.com/GoMudEngine/GoMud/internal/configs"
 	"github.com/GoMudEngine/GoMud/internal/connections"
 	"github.com/GoMudEngine/GoMud/internal/conversations"
+	"github.com/GoMudEngine/GoMud/internal/copyover"
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/flags"
 	"github.com/GoMudEngine/GoMud/internal/gametime"
 	"github.com/GoMudEngine/GoMud/internal/hooks"
 	"github.com/GoMudEngine/GoMud/internal/inputhandlers"
 	"github.com/GoMudEngine/GoMud/internal/integrations/discord"
-	intllm "github.com/GoMudEngine/GoMud/internal/integrations/llm"
 	"github.com/GoMudEngine/GoMud/internal/items"
 	"github.com/GoMudEngine/GoMud/internal/keywords"
 	"github.com/GoMudEngine/GoMud/internal/language"
@@ -40,6 +43,7 @@ import (
 
 	"github.com/GoMudEngine/GoMud/internal/mapper"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mssp"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/mutators"
 	"github.com/GoMudEngine/GoMud/internal/pets"
@@ -49,6 +53,7 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/rooms"
 	"github.com/GoMudEngine/GoMud/internal/scripting"
 	"github.com/GoMudEngine/GoMud/internal/spells"
+	"github.com/GoMudEngine/GoMud/internal/storage"
 	"github.com/GoMudEngine/GoMud/internal/suggestions"
 	"github.com/GoMudEngine/GoMud/internal/templates"
 	"github.com/GoMudEngine/GoMud/internal/term"
@@ -104,14 +109,6 @@ func main() {
 	configs.ReloadConfig()
 	c := configs.GetConfig()
 
-	// Default i18n localize folders
-	if len(c.Translation.LanguagePaths) == 0 {
-		c.Translation.LanguagePaths = []string{
-			path.Join("_datafiles", "localize"),
-			path.Join(c.FilePaths.DataFiles.String(), "localize"),
-		}
-	}
-
 	mudlog.Info(`========================`)
 	//
 	mudlog.Info(`  ___  ____   _______   `)
@@ -123,6 +120,8 @@ func main() {
 	//
 	mudlog.Info(`========================`)
 	//
+	mssp.SetServer(`GoMud `+Version, serverStartTime)
+
 	cfgData := c.AllConfigData()
 	cfgKeys := make([]string, 0, len(cfgData))
 	for k := range cfgData {
@@ -137,16 +136,6 @@ func main() {
 	//
 	mudlog.Info(`========================`)
 
-	// Older versions of GoMud may not have this folder present.
-	// Also deleting the folder is a quick way to reset instance state, so this corrects that if it happens.
-	os.Mkdir(util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, `rooms.instances`), os.ModeDir|0755)
-
-	// Register the plugin filesystem with the template system
-	templates.RegisterFS(plugins.GetPluginRegistry())
-	usercommands.AddFunctionExporter(plugins.GetPluginRegistry())
-
-	inputhandlers.AddIACHandler(plugins.GetPluginRegistry())
-
 	//
 	// System Configurations
 	runtime.GOMAXPROCS(int(c.Server.MaxCPUCores))
@@ -157,13 +146,36 @@ func main() {
 		os.Exit(1)
 	}
 
-	language.InitTranslation(language.BundleCfg{
-		DefaultLanguage: textLang.Make(c.Translation.DefaultLanguage.String()),
-		Language:        textLang.Make(c.Translation.Language.String()),
-		LanguagePaths:   c.Translation.LanguagePaths,
-	})
+	// Copy everything saved into another storage backend, then exit so the config can be switched over
+	if target := flags.MigrateStorage(); target != `` {
+		count, err := storage.Migrate(c.FilePaths.Storage.String(), target, c.FilePaths.DataFiles.String())
+		if err != nil {
+			mudlog.Error("Migrate Storage", "error", err)
+			os.Exit(1)
+		}
+		mudlog.Info("Migrate Storage", "from", c.FilePaths.Storage.String(), "to", target, "records", count, "next", "Set FilePaths.Storage to "+target+" in your config")
+		os.Exit(0)
+	}
 
-	hooks.RegisterListeners()
+	// Put back a backup snapshot, then exit
+	if snapshot, only, name := flags.Restore(); snapshot != `` {
+		if err := runRestore(snapshot, only, name); err != nil {
+			mudlog.Error("Restore", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	registerSystems(c)
+
+	// Run the world offline and report on it, rather than starting the server
+	if rounds := flags.SimulateRounds(); rounds > 0 {
+		if err := runSimulation(rounds, os.Stdout); err != nil {
+			mudlog.Error("Simulate", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	// Discord integration
 	if webhookUrl := string(c.Integrations.Discord.WebhookUrl); webhookUrl != "" {
@@ -175,9 +187,7 @@ func main() {
 
 	// LLM integration
 	if bool(c.Integrations.LLM.Enabled) {
-		intllm.Init()
-		// Initialize the unified LLM service
-		llm.SetupLLMSystem()
+		llm.Init()
 		mudlog.Info("LLM", "info", "integration is enabled")
 	} else {
 		mudlog.Warn("LLM", "info", "integration is disabled")
@@ -194,49 +204,14 @@ func main() {
 
 	mudlog.Info(`========================`)
 
-	// Load all the data files up front.
-	loadAllDataFiles(false)
-
-	mudlog.Info(`========================`)
-
-	mudlog.Info("Mapper", "status", "precaching")
-	timeStart := time.Now()
-	mapper.PreCacheMaps()
-	mudlog.Info("Mapper", "status", "done", "time taken", time.Since(timeStart))
-
-	mudlog.Info(`========================`)
-
-	// Create the user index
-	idx := users.NewUserIndex()
-	if !idx.Exists() {
-		// Since it doesn't exist yet, that's a good indication we should do a quick format migration check
-		users.DoUserMigrations()
-	}
-	idx.Create()
-	idx.Rebuild()
-	mudlog.Info("UserIndex", "info", "User index recreated.")
-
-	// Load the round count from the file
-	if util.LoadRoundCount(c.FilePaths.DataFiles.String()+`/`+util.RoundCountFilename) == util.RoundCountMinimum {
-		gametime.SetToDay(-3)
-	}
-
-	gametime.GetZodiac(1) // The first time this is called it randomizes all zodiacs
-
-	scripting.Setup(int(c.Scripting.LoadTimeoutMs), int(c.Scripting.RoomTimeoutMs))
-
-	mudlog.Info(`========================`)
-
-	// Trigger the load plugins event
-	plugins.Load(
-		configs.GetFilePathsConfig().DataFiles.String(),
-	)
+	loadWorld(c)
 
 	web.SetWebPlugin(plugins.GetPluginRegistry())
 
 	//
 	// Capture OS signals to gracefully shutdown the server
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	copyover.Notify(sigChan)
 
 	// for testing purposes, enable event debugging
 	//events.SetDebug(true)
@@ -248,29 +223,54 @@ func main() {
 	// Set the server to be alive
 	serverAlive.Store(true)
 
+	// Players carried over from the previous process go first, so that nobody new is given their connection ids
+	if copyoverState, ok := copyover.Resume(); ok {
+		resumeCopyover(copyoverState, &wg)
+	}
+
 	mudlog.Info(`========================`)
 	web.Listen(&wg, HandleWebSocketConnection)
 
 	allServerListeners := make([]net.Listener, 0, len(c.Network.TelnetPort))
 	for _, port := range c.Network.TelnetPort {
 		if p, err := strconv.Atoi(port); err == nil {
-			if s := TelnetListenOnPort(``, p, &wg, int(c.Network.MaxTelnetConnections)); s != nil {
+			if s := TelnetListenOnPort(``, p, &wg, int(c.Network.MaxTelnetConnections), nil); s != nil {
 				allServerListeners = append(allServerListeners, s)
 			}
 		}
 	}
 
+	if len(c.Network.TelnetTLSPort) > 0 {
+		if tlsConfig := telnetTLSConfig(c.FilePaths); tlsConfig != nil {
+			for _, port := range c.Network.TelnetTLSPort {
+				if p, err := strconv.Atoi(port); err == nil {
+					if s := TelnetListenOnPort(``, p, &wg, int(c.Network.MaxTelnetConnections), tlsConfig); s != nil {
+						mudlog.Info("Telnet TLS", "port", p)
+						allServerListeners = append(allServerListeners, s)
+					}
+				}
+			}
+		}
+	}
+
 	if c.Network.LocalPort > 0 {
-		TelnetListenOnPort(`127.0.0.1`, int(c.Network.LocalPort), &wg, 0)
+		TelnetListenOnPort(`127.0.0.1`, int(c.Network.LocalPort), &wg, 0, nil)
 	}
 
+	copyover.CloseUnclaimed()
+
 	go worldManager.InputWorker(workerShutdownChan, &wg)
 	go worldManager.MainWorker(workerShutdownChan, &wg)
 
 	mudlog.Info("Server Ready", "Time Taken", time.Since(serverStartTime))
 
-	// block until a signal comes in
-	<-sigChan
+	// block until a signal comes in.
+	// A copyover only comes back if it failed, and the server carries on as it was.
+	for sig := <-sigChan; sig == copyover.Signal; sig = <-sigChan {
+		if err := copyoverServer(); err != nil {
+			mudlog.Error("Copyover", "error", err)
+		}
+	}
 
 	tplTxt, err := templates.Process("goodbye", nil)
 	if err != nil {
@@ -278,7 +278,7 @@ func main() {
 	}
 
 	events.AddToQueue(events.Broadcast{
-		Text: templates.AnsiParse(tplTxt),
+		Text: tplTxt,
 	})
 
 	serverAlive.Store(false) // immediately stop processing incoming connections
@@ -301,6 +301,12 @@ func main() {
 	conversations.Shutdown()
 	mudlog.Info("Conversations", "info", "package shutdown")
 
+	storage.Close()
+	mudlog.Info("Storage", "info", "closed")
+
+	// Abandon any pending LLM requests
+	llm.Shutdown()
+
 	for _, s := range allServerListeners {
 		s.Close()
 	}
@@ -344,7 +350,11 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 		wg.Done()
 	}()
 
-	mudlog.Info("New Connection", "connectionID", connDetails.ConnectionId(), "remoteAddr", connDetails.RemoteAddr().String())
+	mudlog.Info("New Connection", "connectionID", connDetails.ConnectionId(), "remoteAddr", connDetails.RemoteAddr().String(), "secure", connDetails.IsSecure())
+
+	if refuseBannedConnection(connDetails) {
+		return
+	}
 
 	// Setup shared state map for this connection's handlers
 	// Needs to be created BEFORE the first handler call
@@ -358,6 +368,8 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 	// Consider a macro handler at this point?
 	// Text Processing
 	connDetails.AddInputHandler("CleanserInputHandler", inputhandlers.CleanserInputHandler)
+	// Crawlers may ask for MSSP as plain text instead of logging in
+	connDetails.AddInputHandler("MSSPRequestHandler", inputhandlers.MSSPRequestHandler)
 
 	loginHandler := inputhandlers.GetLoginPromptHandler()           // Get the configured handler func
 	connDetails.AddInputHandler("LoginPromptHandler", loginHandler) // Add it with a unique name
@@ -386,6 +398,12 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 		connDetails.ConnectionId(),
 	)
 
+	// Ask what the client is and what it supports (TTYPE/MTTS), which decides how many colors it gets
+	connections.SendTo(
+		term.TtypeRequest.BytesWithPayload(nil),
+		connDetails.ConnectionId(),
+	)
+
 	// Send request to change charset
 	connections.SendTo(
 		term.TelnetRequestChangeCharset.BytesWithPayload(nil),
@@ -403,6 +421,29 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 		connDetails.ConnectionId(),
 	)
 
+	// Offer compression
+	netConfig := configs.GetNetworkConfig()
+	if netConfig.MCCP2 {
+		connections.SendTo(
+			term.Mccp2Enable.BytesWithPayload(nil),
+			connDetails.ConnectionId(),
+		)
+	}
+	if netConfig.MCCP3 {
+		connections.SendTo(
+			term.Mccp3Enable.BytesWithPayload(nil),
+			connDetails.ConnectionId(),
+		)
+	}
+
+	// Offer server status to MUD listing crawlers
+	if netConfig.MSSP {
+		connections.SendTo(
+			term.MsspEnable.BytesWithPayload(nil),
+			connDetails.ConnectionId(),
+		)
+	}
+
 	clientSetupCommands := "" + //term.AnsiAltModeStart.String() + // alternative mode (No scrollback)
 		//term.AnsiCursorHide.String() + // Hide Cursor (Because we will manually echo back)
 		//term.AnsiCharSetUTF8.String() + // UTF8 mode
@@ -417,19 +458,6 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 
 	plugins.OnNetConnect(connDetails)
 
-	// an input buffer for reading data sent over the network
-	inputBuffer := make([]byte, connections.ReadBufferSize)
-
-	// Describes whatever the client sent us
-	clientInput := &connections.ClientInput{
-		ConnectionId: connDetails.ConnectionId(),
-		DataIn:       []byte{},
-		Buffer:       make([]byte, 0, connections.ReadBufferSize), // DataIn is appended to this buffer after processing
-		EnterPressed: false,
-		Clipboard:    []byte{},
-		History:      connections.InputHistory{},
-	}
-
 	if audioConfig := audio.GetFile(`intro`); audioConfig.FilePath != `` {
 		v := 100
 		if audioConfig.Volume > 0 && audioConfig.Volume <= 100 {
@@ -437,14 +465,14 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 		}
 		connections.SendTo(
 			term.MspCommand.BytesWithPayload([]byte("!!MUSIC("+audioConfig.FilePath+" V="+strconv.Itoa(v)+" L=-1 C=1)")),
-			clientInput.ConnectionId,
+			connDetails.ConnectionId(),
 		)
 	}
 
 	// --- Send Initial Welcome/Splash ---
 	// (This part was mostly correct before)
 	splashTxt, _ := templates.Process("login/connect-splash", nil)
-	connections.SendTo([]byte(templates.AnsiParse(splashTxt)), connDetails.ConnectionId())
+	connections.SendTo([]byte(templates.AnsiParse(splashTxt, connDetails.ConnectionId())), connDetails.ConnectionId())
 
 	// --- Trigger the Prompt Handler to initialize state and send the FIRST prompt ---
 	// Create a dummy input that signifies "start the process" but has no actual user data/control codes.
@@ -459,7 +487,26 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 	// 3. Returns false (which we ignore here, as we aren't in the main loop yet).
 	loginHandler(initialTriggerInput, sharedState)
 
-	var userObject *users.UserRecord
+	telnetInputLoop(connDetails, sharedState, nil)
+}
+
+// telnetInputLoop handles everything the client sends until it disconnects.
+// userObject is nil until they have logged in.
+func telnetInputLoop(connDetails *connections.ConnectionDetails, sharedState map[string]any, userObject *users.UserRecord) {
+
+	// an input buffer for reading data sent over the network
+	inputBuffer := make([]byte, connections.ReadBufferSize)
+
+	// Describes whatever the client sent us
+	clientInput := &connections.ClientInput{
+		ConnectionId: connDetails.ConnectionId(),
+		DataIn:       []byte{},
+		Buffer:       make([]byte, 0, connections.ReadBufferSize), // DataIn is appended to this buffer after processing
+		EnterPressed: false,
+		Clipboard:    []byte{},
+		History:      connections.InputHistory{},
+	}
+
 	var sug suggestions.Suggestions
 	lastInput := time.Now()
 	c := configs.GetConfig()
@@ -576,7 +623,7 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 				if connections.IsWebsocket(clientInput.ConnectionId) {
 					connections.SendTo([]byte(pTxt), clientInput.ConnectionId)
 				} else {
-					connections.SendTo([]byte(templates.AnsiParse(pTxt)), clientInput.ConnectionId)
+					connections.SendTo([]byte(templates.AnsiParse(pTxt, clientInput.ConnectionId)), clientInput.ConnectionId)
 				}
 			}
 
@@ -608,22 +655,7 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 				break                                        // Exit the read loop for this connection
 			}
 
-			// Remove the prompt handler (it signaled completion by returning true)
-			connDetails.RemoveInputHandler("LoginPromptHandler")
-			// Replace it with a regular echo handler.
-			connDetails.AddInputHandler("EchoInputHandler", inputhandlers.EchoInputHandler)
-			// Add admin command handler
-			connDetails.AddInputHandler("HistoryInputHandler", inputhandlers.HistoryInputHandler) // Put history tracking after login handling, since login handling aborts input until complete
-
-			if userObject.Role == users.RoleAdmin {
-				connDetails.AddInputHandler("SystemCommandInputHandler", inputhandlers.SystemCommandInputHandler)
-			}
-
-			// Add a signal handler (shortcut ctrl combos) after the AnsiHandler
-			// This captures signals and replaces user input so should happen after AnsiHandler to ensure it happens before other processes.
-			connDetails.AddInputHandler("SignalHandler", inputhandlers.SignalHandler, "AnsiHandler")
-
-			connDetails.SetState(connections.LoggedIn)
+			addGameInputHandlers(connDetails, userObject)
 
 			worldManager.SendEnterWorld(userObject.UserId, userObject.Character.RoomId)
 
@@ -679,7 +711,7 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 					if connections.IsWebsocket(clientInput.ConnectionId) {
 						connections.SendTo([]byte(userObject.GetCommandPrompt()), clientInput.ConnectionId)
 					} else {
-						connections.SendTo([]byte(templates.AnsiParse(userObject.GetCommandPrompt())), clientInput.ConnectionId)
+						connections.SendTo([]byte(templates.AnsiParse(userObject.GetCommandPrompt(), clientInput.ConnectionId)), clientInput.ConnectionId)
 					}
 
 				}
@@ -709,11 +741,37 @@ func handleTelnetConnection(connDetails *connections.ConnectionDetails, wg *sync
 
 }
 
+// addGameInputHandlers swaps the login prompt for the handlers used once a player is in the game
+func addGameInputHandlers(connDetails *connections.ConnectionDetails, userObject *users.UserRecord) {
+
+	// Remove the prompt handler (it signaled completion by returning true)
+	connDetails.RemoveInputHandler("LoginPromptHandler")
+	connDetails.RemoveInputHandler("MSSPRequestHandler")
+	// Replace it with a regular echo handler.
+	connDetails.AddInputHandler("EchoInputHandler", inputhandlers.EchoInputHandler)
+	// Add admin command handler
+	connDetails.AddInputHandler("HistoryInputHandler", inputhandlers.HistoryInputHandler) // Put history tracking after login handling, since login handling aborts input until complete
+
+	if userObject.Role == users.RoleAdmin {
+		connDetails.AddInputHandler("SystemCommandInputHandler", inputhandlers.SystemCommandInputHandler)
+	}
+
+	// Add a signal handler (shortcut ctrl combos) after the AnsiHandler
+	// This captures signals and replaces user input so should happen after AnsiHandler to ensure it happens before other processes.
+	connDetails.AddInputHandler("SignalHandler", inputhandlers.SignalHandler, "AnsiHandler")
+
+	connDetails.SetState(connections.LoggedIn)
+}
+
 func HandleWebSocketConnection(conn *websocket.Conn) {
 
 	var userObject *users.UserRecord
 	connDetails := connections.Add(nil, conn)
 
+	if refuseBannedConnection(connDetails) {
+		return
+	}
+
 	// Setup shared state map for this connection's handlers
 	// Needs to be created BEFORE the first handler call
 	var sharedState map[string]any = make(map[string]any)
@@ -752,7 +810,7 @@ func HandleWebSocketConnection(conn *websocket.Conn) {
 	// --- Send Initial Welcome/Splash ---
 	// (This part was mostly correct before)
 	splashTxt, _ := templates.Process("login/connect-splash", nil)
-	connections.SendTo([]byte(templates.AnsiParse(splashTxt)), connDetails.ConnectionId())
+	connections.SendTo([]byte(templates.AnsiParse(splashTxt, connDetails.ConnectionId())), connDetails.ConnectionId())
 
 	// --- Trigger the Prompt Handler to initialize state and send the FIRST prompt ---
 	// Create a dummy input that signifies "start the process" but has no actual user data/control codes.
@@ -887,14 +945,40 @@ func HandleWebSocketConnection(conn *websocket.Conn) {
 	}
 }
 
-func TelnetListenOnPort(hostname string, portNum int, wg *sync.WaitGroup, maxConnections int) net.Listener {
+// refuseBannedConnection disconnects a new connection whose address is banned, or locked out after too many failed logins
+func refuseBannedConnection(connDetails *connections.ConnectionDetails) bool {
+
+	ip := bans.AddressIP(connDetails.RemoteAddr())
+
+	err := bans.CheckAddress(ip)
+	if err == nil {
+		err = bans.CheckLogin(``, ip)
+	}
+
+	if err == nil {
+		return false
+	}
+
+	mudlog.Warn("Connection refused", "connectionID", connDetails.ConnectionId(), "remoteAddr", ip, "error", err)
+
+	connections.SendTo([]byte(fmt.Sprintf("\n\n\n!!! %s !!!\n\n\n", err)), connDetails.ConnectionId())
+	connections.Remove(connDetails.ConnectionId())
 
-	server, err := net.Listen("tcp", fmt.Sprintf("%s:%d", hostname, portNum))
+	return true
+}
+
+func TelnetListenOnPort(hostname string, portNum int, wg *sync.WaitGroup, maxConnections int, tlsConfig *tls.Config) net.Listener {
+
+	server, err := copyover.Listen(fmt.Sprintf("%s:%d", hostname, portNum))
 	if err != nil {
 		mudlog.Error("Error creating server", "error", err)
 		return nil
 	}
 
+	if tlsConfig != nil {
+		server = tls.NewListener(server, tlsConfig)
+	}
+
 	// Start a goroutine to accept incoming connections, so that we can use a signal to stop the server
 	go func() {
 
@@ -912,20 +996,38 @@ func TelnetListenOnPort(hostname string, portNum int, wg *sync.WaitGroup, maxCon
 				continue
 			}
 
-			if maxConnections > 0 {
-				if connections.ActiveConnectionCount() >= maxConnections {
-					conn.Write([]byte(fmt.Sprintf("\n\n\n!!! Server is full (%d connections). Try again later. !!!\n\n\n", connections.ActiveConnectionCount())))
-					conn.Close()
-					continue
-				}
-			}
-
 			wg.Add(1)
 			// hand off the connection to a handler goroutine so that we can continue handling new connections
-			go handleTelnetConnection(
-				connections.Add(conn, nil),
-				wg,
-			)
+			go func() {
+
+				// Finish the TLS handshake before anything is written, so a slow client can't hold anything up
+				if tlsConn, ok := conn.(*tls.Conn); ok {
+					tlsConn.SetDeadline(time.Now().Add(10 * time.Second))
+					err := tlsConn.Handshake()
+					tlsConn.SetDeadline(time.Time{})
+
+					if err != nil {
+						mudlog.Warn("TLS handshake", "remoteAddr", conn.RemoteAddr().String(), "error", err)
+						conn.Close()
+						wg.Done()
+						return
+					}
+				}
+
+				if maxConnections > 0 {
+					if connections.ActiveConnectionCount() >= maxConnections {
+						conn.Write([]byte(fmt.Sprintf("\n\n\n!!! Server is full (%d connections). Try again later. !!!\n\n\n", connections.ActiveConnectionCount())))
+						conn.Close()
+						wg.Done()
+						return
+					}
+				}
+
+				handleTelnetConnection(
+					connections.Add(conn, nil),
+					wg,
+				)
+			}()
 
 		}
 	}()
@@ -933,6 +1035,111 @@ func TelnetListenOnPort(hostname string, portNum int, wg *sync.WaitGroup, maxCon
 	return server
 }
 
+// telnetTLSConfig loads the https certificate for the TLS telnet ports, returning nil if it can't be used
+func telnetTLSConfig(filePaths configs.FilePaths) *tls.Config {
+
+	if filePaths.HttpsCertFile == `` || filePaths.HttpsKeyFile == `` {
+		mudlog.Error("Telnet TLS", "error", "TelnetTLSPort is set, but HttpsCertFile/HttpsKeyFile are not")
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(string(filePaths.HttpsCertFile), string(filePaths.HttpsKeyFile))
+	if err != nil {
+		mudlog.Error("Telnet TLS", "error", fmt.Errorf("Error loading certificate and key: %w", err))
+		return nil
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// registerSystems connects the packages that are looked up through each other, and must only happen once
+func registerSystems(c configs.Config) {
+
+	// Default i18n localize folders
+	if len(c.Translation.LanguagePaths) == 0 {
+		c.Translation.LanguagePaths = []string{
+			path.Join("_datafiles", "localize"),
+			path.Join(c.FilePaths.DataFiles.String(), "localize"),
+		}
+	}
+
+	// Register the plugin filesystem with the template system
+	templates.RegisterFS(plugins.GetPluginRegistry())
+	usercommands.AddFunctionExporter(plugins.GetPluginRegistry())
+
+	inputhandlers.AddIACHandler(plugins.GetPluginRegistry())
+
+	language.InitTranslation(language.BundleCfg{
+		DefaultLanguage: textLang.Make(c.Translation.DefaultLanguage.String()),
+		Language:        textLang.Make(c.Translation.Language.String()),
+		LanguagePaths:   c.Translation.LanguagePaths,
+	})
+
+	hooks.RegisterListeners()
+}
+
+// loadWorld loads everything the game needs to run, but doesn't start it or listen for connections
+func loadWorld(c configs.Config) {
+
+	// Everything saved about players and the world goes through the store
+	if err := storage.Open(c.FilePaths.Storage.String(), c.FilePaths.DataFiles.String()); err != nil {
+		mudlog.Error("Storage", "backend", c.FilePaths.Storage.String(), "error", err)
+		os.Exit(1)
+	}
+	mudlog.Info("Storage", "backend", c.FilePaths.Storage.String())
+
+	// Older versions of GoMud may not have this folder present.
+	// Also deleting the folder is a quick way to reset instance state, so this corrects that if it happens.
+	os.Mkdir(util.FilePath(configs.GetFilePathsConfig().DataFiles.String(), `/`, `rooms.instances`), os.ModeDir|0755)
+
+	// Load all the data files up front.
+	loadAllDataFiles(false)
+
+	// Builder changes that are undone or redone are put back on disk, then loaded again
+	changelog.RegisterReloader(`rooms`, changelog.Reloader{
+		Check:  rooms.CheckChangedFiles,
+		Reload: rooms.ReloadChangedFiles,
+	})
+	changelog.SetDefaultReloader(changelog.Reloader{
+		Reload: func(map[string]bool) error {
+			loadAllDataFiles(true)
+			return nil
+		},
+	})
+
+	mudlog.Info(`========================`)
+
+	mudlog.Info("Mapper", "status", "precaching")
+	timeStart := time.Now()
+	mapper.PreCacheMaps()
+	mudlog.Info("Mapper", "status", "done", "time taken", time.Since(timeStart))
+
+	mudlog.Info(`========================`)
+
+	// Create the user index
+	users.RebuildIndex()
+	mudlog.Info("UserIndex", "info", "User index recreated.")
+
+	// Load the round count from the file
+	if util.LoadRoundCount(c.FilePaths.DataFiles.String()+`/`+util.RoundCountFilename) == util.RoundCountMinimum {
+		gametime.SetToDay(-3)
+	}
+
+	gametime.GetZodiac(1) // The first time this is called it randomizes all zodiacs
+
+	scripting.Setup(int(c.Scripting.LoadTimeoutMs), int(c.Scripting.RoomTimeoutMs))
+
+	mudlog.Info(`========================`)
+
+	// Trigger the load plugins event
+	plugins.Load(
+		configs.GetFilePathsConfig().DataFiles.String(),
+	)
+}
+
 func loadAllDataFiles(isReload bool) {
 
 	if isReload {
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/metrics"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+var (
+	turnDriftMetric = metrics.NewHistogram(
+		`gomud_turn_drift_seconds`,
+		`How much later than Timing.TurnMs each turn started.`,
+		metrics.DurationBuckets,
+	)
+	roundDriftMetric = metrics.NewHistogram(
+		`gomud_round_drift_seconds`,
+		`How much later than TurnMs * TurnsPerRound each round started.`,
+		metrics.DurationBuckets,
+	)
+)
+
+func init() {
+	metrics.NewGaugeFunc(`gomud_turn_count`, `Turns since the world began.`, func() float64 {
+		return float64(util.GetTurnCount())
+	})
+	metrics.NewGaugeFunc(`gomud_round_count`, `Rounds since the world began.`, func() float64 {
+		return float64(util.GetRoundCount())
+	})
+}
+
+// turnDrift tracks when the last turn and round started, to measure how far behind schedule the turn timer runs.
+type turnDrift struct {
+	lastTurn  time.Time
+	lastRound time.Time
+}
+
+// record is called just after a turn is started by the turn timer.
+func (d *turnDrift) record(now time.Time) {
+
+	timing := configs.GetTimingConfig()
+	turnLength := time.Duration(timing.TurnMs) * time.Millisecond
+
+	if !d.lastTurn.IsZero() {
+		turnDriftMetric.Observe((now.Sub(d.lastTurn) - turnLength).Seconds())
+	}
+	d.lastTurn = now
+
+	if util.GetTurnCount()%uint64(timing.TurnsPerRound()) != 0 {
+		return
+	}
+
+	if !d.lastRound.IsZero() {
+		roundDriftMetric.Observe((now.Sub(d.lastRound) - turnLength*time.Duration(timing.TurnsPerRound())).Seconds())
+	}
+	d.lastRound = now
+}
@@ -10,5 +10,6 @@ import (
 	_ "github.com/GoMudEngine/GoMud/modules/follow"
 	_ "github.com/GoMudEngine/GoMud/modules/gmcp"
 	_ "github.com/GoMudEngine/GoMud/modules/leaderboards"
+	_ "github.com/GoMudEngine/GoMud/modules/msdp"
 	_ "github.com/GoMudEngine/GoMud/modules/time"
 )
@@ -10,6 +10,7 @@ import (
 
 	"github.com/GoMudEngine/GoMud/internal/events"
 	"github.com/GoMudEngine/GoMud/internal/items"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/plugins"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
 	"github.com/GoMudEngine/GoMud/internal/templates"
@@ -361,6 +362,8 @@ func (mod *AuctionsModule) newRoundHandler(e events.Event) events.ListenerReturn
 		// Give the item to the winner and let them know
 		if auctionNow.HighestBidUserId > 0 {
 
+			offlineSaves := []users.UserRecord{}
+
 			if user := users.GetByUserId(auctionNow.HighestBidUserId); user != nil {
 				if user.Character.StoreItem(auctionNow.ItemData) {
 
@@ -393,7 +396,7 @@ func (mod *AuctionsModule) newRoundHandler(e events.Event) events.ListenerReturn
 							Item:     &auctionNow.ItemData,
 						},
 					)
-					users.SaveUser(*user)
+					offlineSaves = append(offlineSaves, *user)
 				}
 
 			}
@@ -432,12 +435,19 @@ func (mod *AuctionsModule) newRoundHandler(e events.Event) events.ListenerReturn
 								Item:     &auctionNow.ItemData,
 							},
 						)
-						users.SaveUser(*sellerUser)
+						offlineSaves = append(offlineSaves, *sellerUser)
 					}
 
 				}
 			}
 
+			// Offline buyer and seller are saved together, so neither can end up with the other's half of the trade missing
+			if len(offlineSaves) > 0 {
+				if err := users.SaveUsers(offlineSaves...); err != nil {
+					mudlog.Error("Auction", "error", err)
+				}
+			}
+
 		} else if auctionNow.SellerUserId > 0 {
 			if user := users.GetByUserId(auctionNow.SellerUserId); user != nil {
 				if user.Character.StoreItem(auctionNow.ItemData) {
@@ -0,0 +1,226 @@
+package gmcp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/audio"
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/plugins"
+	"github.com/GoMudEngine/GoMud/internal/users"
+)
+
+// ////////////////////////////////////////////////////////////////////
+// Client.Media (https://wiki.mudlet.org/w/Manual:Supported_Protocols#Client.Media)
+// Sound and music for clients that advertise Client.Media in Core.Supports.Set.
+// It can do what MSP can't: fades, priorities, looping and stopping by tag.
+// Anyone else falls through to the MSP handler.
+// ////////////////////////////////////////////////////////////////////
+func init() {
+
+	g := GMCPClientMediaModule{
+		plug: plugins.New(`gmcp.Client.Media`, `1.0`),
+	}
+
+	// First, so that MSP is skipped when Client.Media handles it
+	events.RegisterListener(events.MSP{}, g.playMedia, events.First)
+	events.RegisterListener(events.PlayerSpawn{}, g.preloadMedia)
+}
+
+type GMCPClientMediaModule struct {
+	// Keep a reference to the plugin when we create it so that we can call ReadBytes() and WriteBytes() on it.
+	plug *plugins.Plugin
+}
+
+type GMCPClientMedia_Default struct {
+	Url string `json:"url"`
+}
+
+type GMCPClientMedia_Load struct {
+	Name string `json:"name"`
+	Url  string `json:"url,omitempty"`
+}
+
+type GMCPClientMedia_Play struct {
+	Name     string `json:"name"`
+	Url      string `json:"url,omitempty"`
+	Type     string `json:"type,omitempty"` // sound or music
+	Tag      string `json:"tag,omitempty"`
+	Volume   int    `json:"volume,omitempty"`
+	FadeIn   int    `json:"fadein,omitempty"`
+	FadeOut  int    `json:"fadeout,omitempty"`
+	Loops    int    `json:"loops,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	Key      string `json:"key,omitempty"`
+}
+
+type GMCPClientMedia_Stop struct {
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Key      string `json:"key,omitempty"`
+	FadeAway bool   `json:"fadeaway,omitempty"`
+	FadeOut  int    `json:"fadeout,omitempty"`
+}
+
+func supportsClientMedia(connectionId uint64) bool {
+
+	gmcpData, ok := gmcpModule.cache.Get(connectionId)
+	if !ok || !gmcpData.GMCPAccepted {
+		return false
+	}
+
+	_, ok = gmcpData.EnabledModules[`Client.Media`]
+	return ok
+}
+
+// Where media files are downloaded from, with a trailing slash.
+// Empty if no CDN is configured, in which case the client has to already have them.
+func mediaUrl() string {
+	cdn := strings.TrimSpace(configs.GetFilePathsConfig().WebCDNLocation.String())
+	if cdn == `` {
+		return ``
+	}
+	return strings.TrimSuffix(cdn, `/`) + `/`
+}
+
+func (g *GMCPClientMediaModule) sendMedia(userId int, module string, payload any) {
+	events.AddToQueue(GMCPOut{
+		UserId:  userId,
+		Module:  module,
+		Payload: payload,
+	})
+}
+
+// Tells the client where to find media, and has it download anything marked preload in audio.yaml
+func (g *GMCPClientMediaModule) preloadMedia(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.PlayerSpawn)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "PlayerSpawn", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	if !supportsClientMedia(evt.ConnectionId) {
+		return events.Continue
+	}
+
+	url := mediaUrl()
+	if url != `` {
+		g.sendMedia(evt.UserId, `Client.Media.Default`, GMCPClientMedia_Default{Url: url})
+	}
+
+	preloads := audio.GetPreloads()
+
+	identifiers := make([]string, 0, len(preloads))
+	for identifier := range preloads {
+		identifiers = append(identifiers, identifier)
+	}
+	sort.Strings(identifiers)
+
+	for _, identifier := range identifiers {
+		g.sendMedia(evt.UserId, `Client.Media.Load`, GMCPClientMedia_Load{
+			Name: preloads[identifier].FilePath,
+			Url:  url,
+		})
+	}
+
+	return events.Continue
+}
+
+func (g *GMCPClientMediaModule) playMedia(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.MSP)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "MSP", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	if evt.UserId < 1 {
+		return events.Continue
+	}
+
+	user := users.GetByUserId(evt.UserId)
+	if user == nil {
+		return events.Continue
+	}
+
+	if !supportsClientMedia(user.ConnectionId()) {
+		return events.Continue
+	}
+
+	// Handled here, MSP is skipped
+	if evt.Stop {
+
+		if evt.Tag == `` || evt.Tag == audio.MusicTag {
+			user.LastMusic = ``
+		}
+
+		g.sendMedia(evt.UserId, `Client.Media.Stop`, GMCPClientMedia_Stop{
+			Tag:      evt.Tag,
+			FadeAway: evt.FadeMs > 0,
+			FadeOut:  evt.FadeMs,
+		})
+
+		return events.Cancel
+	}
+
+	if evt.SoundFile == `` {
+		return events.Cancel
+	}
+
+	if evt.SoundType == `MUSIC` {
+
+		// Already playing, leave it be rather than restarting it
+		if user.LastMusic == evt.SoundFile {
+			return events.Cancel
+		}
+
+		// Fade out whatever music is playing. New music gets no key, because a matching key
+		// would cut the old music off instead of letting it fade.
+		if user.LastMusic != `` {
+			g.sendMedia(evt.UserId, `Client.Media.Stop`, GMCPClientMedia_Stop{
+				Type:     `music`,
+				Tag:      audio.MusicTag,
+				FadeAway: evt.FadeMs > 0,
+				FadeOut:  evt.FadeMs,
+			})
+		}
+
+		user.LastMusic = evt.SoundFile
+
+		if strings.EqualFold(evt.SoundFile, `Off`) {
+			return events.Cancel
+		}
+
+		g.sendMedia(evt.UserId, `Client.Media.Play`, GMCPClientMedia_Play{
+			Name:     evt.SoundFile,
+			Url:      mediaUrl(),
+			Type:     `music`,
+			Tag:      audio.MusicTag,
+			Volume:   evt.Volume,
+			FadeIn:   evt.FadeMs,
+			FadeOut:  evt.FadeMs,
+			Loops:    evt.Loops,
+			Priority: evt.Priority,
+		})
+
+		return events.Cancel
+	}
+
+	g.sendMedia(evt.UserId, `Client.Media.Play`, GMCPClientMedia_Play{
+		Name:     evt.SoundFile,
+		Url:      mediaUrl(),
+		Type:     `sound`,
+		Tag:      evt.Tag,
+		Volume:   evt.Volume,
+		FadeIn:   evt.FadeMs,
+		FadeOut:  evt.FadeMs,
+		Loops:    evt.Loops,
+		Priority: evt.Priority,
+	})
+
+	return events.Cancel
+}
@@ -0,0 +1,129 @@
+package gmcp
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/hooks"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+	"github.com/GoMudEngine/GoMud/internal/testsupport"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// The MSP fallback normally comes from the hooks registered by main
+	events.RegisterListener(events.MSP{}, hooks.PlaySound)
+	testsupport.Main(m)
+}
+
+// mediaPlayer logs in a player on a virtual connection, whose client either
+// advertised Client.Media or didn't. Returns the player and their connection.
+func mediaPlayer(t *testing.T, userId int, clientMedia bool) (*users.UserRecord, *connections.VirtualConn) {
+	t.Helper()
+
+	testsupport.UseDataFiles(t, map[string]any{
+		"FilePaths.WebCDNLocation": "https://cdn.example.com/media",
+	})
+	t.Cleanup(func() { storage.Close() })
+
+	conn := &connections.VirtualConn{}
+	connDetails := connections.Add(conn, nil)
+
+	user := users.NewUserRecord(userId, connDetails.ConnectionId())
+	user.Username = `mediatester` + strconv.Itoa(userId)
+	user.Character.Name = user.Username
+
+	user, _, err := users.LoginUser(user, connDetails.ConnectionId())
+	require.NoError(t, err)
+
+	settings := GMCPSettings{GMCPAccepted: true, EnabledModules: map[string]int{`Char`: 1}}
+	if clientMedia {
+		settings.EnabledModules[`Client.Media`] = 1
+	}
+	gmcpModule.cache.Add(connDetails.ConnectionId(), settings)
+
+	t.Cleanup(func() {
+		users.LogOutUserByConnectionId(connDetails.ConnectionId())
+		connections.Remove(connDetails.ConnectionId())
+		gmcpModule.cache.Remove(connDetails.ConnectionId())
+	})
+
+	// Nothing sent while logging in matters
+	events.ProcessEvents()
+	conn.Reset()
+
+	return user, conn
+}
+
+func TestClientMedia_Play(t *testing.T) {
+
+	user, conn := mediaPlayer(t, 9001, true)
+
+	events.AddToQueue(events.MSP{UserId: user.UserId, SoundType: `SOUND`, SoundFile: `sounds/hit.wav`, Volume: 50, Category: `combat`, Tag: `combat`, Loops: 2, Priority: 60, FadeMs: 250})
+	events.ProcessEvents()
+
+	out := string(conn.Written())
+	assert.Contains(t, out, `Client.Media.Play {"name":"sounds/hit.wav","url":"https://cdn.example.com/media/","type":"sound","tag":"combat","volume":50,"fadein":250,"fadeout":250,"loops":2,"priority":60}`)
+	assert.NotContains(t, out, `!!SOUND`)
+
+	conn.Reset()
+
+	// Music loops forever and is tagged so it can be faded out when it changes
+	events.AddToQueue(events.MSP{UserId: user.UserId, SoundType: `MUSIC`, SoundFile: `music/town.mp3`, Volume: 40, Loops: -1})
+	events.ProcessEvents()
+
+	out = string(conn.Written())
+	assert.Contains(t, out, `Client.Media.Play {"name":"music/town.mp3","url":"https://cdn.example.com/media/","type":"music","tag":"music","volume":40,"loops":-1}`)
+	assert.NotContains(t, out, `!!MUSIC`)
+	assert.Equal(t, `music/town.mp3`, user.LastMusic)
+
+	conn.Reset()
+
+	// The same music again is left playing
+	events.AddToQueue(events.MSP{UserId: user.UserId, SoundType: `MUSIC`, SoundFile: `music/town.mp3`, Volume: 40, Loops: -1})
+	events.ProcessEvents()
+	assert.Empty(t, conn.Written())
+
+	events.AddToQueue(events.MSP{UserId: user.UserId, Stop: true, Tag: `music`, FadeMs: 1000})
+	events.ProcessEvents()
+
+	out = string(conn.Written())
+	assert.Contains(t, out, `Client.Media.Stop {"tag":"music","fadeaway":true,"fadeout":1000}`)
+	assert.Empty(t, user.LastMusic)
+}
+
+func TestClientMedia_MSPFallback(t *testing.T) {
+
+	user, conn := mediaPlayer(t, 9002, false)
+
+	events.AddToQueue(events.MSP{UserId: user.UserId, SoundType: `SOUND`, SoundFile: `sounds/hit.wav`, Volume: 50, Category: `combat`, Tag: `combat`, Loops: 2})
+	events.ProcessEvents()
+
+	out := string(conn.Written())
+	assert.Contains(t, out, `!!SOUND(sounds/hit.wav T=combat V=50)`)
+	assert.NotContains(t, out, `Client.Media`)
+
+	conn.Reset()
+
+	events.AddToQueue(events.MSP{UserId: user.UserId, SoundType: `MUSIC`, SoundFile: `music/town.mp3`, Volume: 40})
+	events.ProcessEvents()
+
+	out = string(conn.Written())
+	assert.Contains(t, out, `!!MUSIC(Off)`)
+	assert.Contains(t, out, `!!MUSIC(music/town.mp3 V=40 L=-1 C=1)`)
+	assert.NotContains(t, out, `Client.Media`)
+
+	conn.Reset()
+
+	events.AddToQueue(events.MSP{UserId: user.UserId, Stop: true})
+	events.ProcessEvents()
+
+	out = string(conn.Written())
+	assert.Contains(t, out, `!!SOUND(Off)`)
+	assert.Contains(t, out, `!!MUSIC(Off)`)
+	assert.Empty(t, user.LastMusic)
+}
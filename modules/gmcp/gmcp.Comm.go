@@ -49,6 +49,8 @@ func (g *GMCPCommModule) onComm(e events.Event) events.ListenerReturn {
 		Channel: evt.CommType,
 		Sender:  evt.Name,
 		Text:    ansitags.Parse(evt.Message, ansitags.StripTags),
+		// Lets clients show a "speaking..." indicator until the rest arrives
+		Speaking: evt.Speaking,
 	}
 
 	if evt.SourceUserId > 0 {
@@ -123,8 +125,9 @@ func (g *GMCPCommModule) onComm(e events.Event) events.ListenerReturn {
 }
 
 type GMCPCommModule_Payload struct {
-	Channel string `json:"channel"`
-	Sender  string `json:"sender"`
-	Source  string `json:"source"`
-	Text    string `json:"text"`
+	Channel  string `json:"channel"`
+	Sender   string `json:"sender"`
+	Source   string `json:"source"`
+	Text     string `json:"text"`
+	Speaking bool   `json:"speaking,omitempty"`
 }
@@ -61,6 +61,8 @@ func init() {
 
 	gmcpModule.plug.Callbacks.SetIACHandler(gmcpModule.HandleIAC)
 	gmcpModule.plug.Callbacks.SetOnNetConnect(gmcpModule.onNetConnect)
+	gmcpModule.plug.Callbacks.SetOnCopyoverSave(gmcpModule.onCopyoverSave)
+	gmcpModule.plug.Callbacks.SetOnCopyoverRestore(gmcpModule.onCopyoverRestore)
 
 	events.RegisterListener(GMCPOut{}, gmcpModule.dispatchGMCP)
 	events.RegisterListener(events.PlayerSpawn{}, gmcpModule.handlePlayerJoin)
@@ -153,6 +155,34 @@ func (g *GMCPModule) onNetConnect(n plugins.NetConnection) {
 	g.sendGMCPEnableRequest(n.ConnectionId())
 }
 
+// The client already agreed to GMCP and said what it supports, and won't say it again after a copyover
+func (g *GMCPModule) onCopyoverSave(connectionId uint64) []byte {
+
+	setting, ok := g.cache.Get(connectionId)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(setting)
+	if err != nil {
+		mudlog.Error("gmcp", "error", fmt.Errorf("copyover save: %w", err))
+		return nil
+	}
+
+	return data
+}
+
+func (g *GMCPModule) onCopyoverRestore(connectionId uint64, data []byte) {
+
+	setting := GMCPSettings{}
+	if err := json.Unmarshal(data, &setting); err != nil {
+		mudlog.Error("gmcp", "error", fmt.Errorf("copyover restore: %w", err))
+		return
+	}
+
+	g.cache.Add(connectionId, setting)
+}
+
 func (g *GMCPModule) isGMCPCommand(b []byte) bool {
 	return len(b) > 2 && b[0] == term.TELNET_IAC && b[2] == TELNET_GMCP
 }
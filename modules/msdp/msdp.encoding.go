@@ -0,0 +1,139 @@
+package msdp
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/GoMudEngine/GoMud/internal/term"
+)
+
+const (
+	MSDP_VAR         term.IACByte = 1
+	MSDP_VAL         term.IACByte = 2
+	MSDP_TABLE_OPEN  term.IACByte = 3
+	MSDP_TABLE_CLOSE term.IACByte = 4
+	MSDP_ARRAY_OPEN  term.IACByte = 5
+	MSDP_ARRAY_CLOSE term.IACByte = 6
+)
+
+// An MSDP value is a string, an int, an msdpArray or an msdpTable
+type msdpArray []any
+
+type msdpTable map[string]any
+
+// A variable sent by the client, with every value it was given.
+// Arrays are flattened, so REPORT "HEALTH" "MANA" and REPORT ["HEALTH", "MANA"] look the same.
+type msdpCommand struct {
+	Name   string
+	Values []string
+}
+
+func isMSDPControl(b byte) bool {
+	return b == 0 || (b >= MSDP_VAR && b <= MSDP_ARRAY_CLOSE) || b == term.TELNET_IAC
+}
+
+// Drops any bytes that would be mistaken for MSDP or telnet control bytes
+func cleanMSDPString(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, b := range []byte(s) {
+		if isMSDPControl(b) {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// encodeVariable encodes MSDP_VAR name MSDP_VAL value
+func encodeVariable(name string, value any) []byte {
+	out := []byte{MSDP_VAR}
+	out = append(out, cleanMSDPString(name)...)
+	out = append(out, MSDP_VAL)
+	return appendValue(out, value)
+}
+
+func appendValue(out []byte, value any) []byte {
+
+	switch v := value.(type) {
+
+	case msdpTable:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		out = append(out, MSDP_TABLE_OPEN)
+		for _, k := range keys {
+			out = append(out, MSDP_VAR)
+			out = append(out, cleanMSDPString(k)...)
+			out = append(out, MSDP_VAL)
+			out = appendValue(out, v[k])
+		}
+		out = append(out, MSDP_TABLE_CLOSE)
+
+	case msdpArray:
+		out = append(out, MSDP_ARRAY_OPEN)
+		for _, item := range v {
+			out = append(out, MSDP_VAL)
+			out = appendValue(out, item)
+		}
+		out = append(out, MSDP_ARRAY_CLOSE)
+
+	case []string:
+		arr := make(msdpArray, len(v))
+		for i, s := range v {
+			arr[i] = s
+		}
+		out = appendValue(out, arr)
+
+	case int:
+		out = append(out, strconv.Itoa(v)...)
+
+	case string:
+		out = append(out, cleanMSDPString(v)...)
+
+	}
+
+	return out
+}
+
+// parseCommands reads the body of an IAC SB MSDP ... IAC SE sent by the client.
+// Clients only send arrays of strings, so a table is not expected here.
+func parseCommands(b []byte) []msdpCommand {
+
+	commands := []msdpCommand{}
+
+	current := -1
+	for i := 0; i < len(b); {
+
+		control := b[i]
+		i++
+
+		if control != MSDP_VAR && control != MSDP_VAL {
+			continue
+		}
+
+		end := i
+		for end < len(b) && !isMSDPControl(b[end]) {
+			end++
+		}
+		text := string(b[i:end])
+		i = end
+
+		if control == MSDP_VAR {
+			commands = append(commands, msdpCommand{Name: text, Values: []string{}})
+			current = len(commands) - 1
+			continue
+		}
+
+		// Empty values show up in front of an array, and aren't useful anyway
+		if current == -1 || text == `` {
+			continue
+		}
+
+		commands[current].Values = append(commands[current].Values, text)
+	}
+
+	return commands
+}
@@ -0,0 +1,400 @@
+package msdp
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/plugins"
+	"github.com/GoMudEngine/GoMud/internal/term"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const (
+	TELNET_MSDP term.IACByte = 69 // https://tintin.mudhalla.net/protocols/msdp/
+)
+
+/*
+Handshake
+The server sends IAC WILL MSDP.
+The client responds with IAC DO MSDP or IAC DONT MSDP.
+The client then sends commands such as:
+  IAC SB MSDP MSDP_VAR "LIST" MSDP_VAL "REPORTABLE_VARIABLES" IAC SE
+  IAC SB MSDP MSDP_VAR "REPORT" MSDP_VAL "HEALTH" MSDP_VAL "HEALTH_MAX" IAC SE
+Reported variables are sent once when asked for, then again each round they change.
+*/
+
+var (
+	///////////////////////////
+	// MSDP COMMANDS
+	///////////////////////////
+	MsdpEnable = term.TerminalCommand{Chars: []byte{term.TELNET_IAC, term.TELNET_WILL, TELNET_MSDP}, EndChars: []byte{}} // Indicates the server wants to enable MSDP.
+	MsdpAccept = term.TerminalCommand{Chars: []byte{term.TELNET_IAC, term.TELNET_DO, TELNET_MSDP}, EndChars: []byte{}}   // Indicates the client accepts MSDP sub-negotiations.
+	MsdpRefuse = term.TerminalCommand{Chars: []byte{term.TELNET_IAC, term.TELNET_DONT, TELNET_MSDP}, EndChars: []byte{}} // Indicates the client refuses MSDP sub-negotiations.
+
+	MsdpPayload = term.TerminalCommand{Chars: []byte{term.TELNET_IAC, term.TELNET_SB, TELNET_MSDP}, EndChars: []byte{term.TELNET_IAC, term.TELNET_SE}} // Wrapper for sending MSDP payloads
+
+	msdpCommands = []string{`LIST`, `REPORT`, `RESET`, `SEND`, `UNREPORT`}
+	msdpLists    = []string{`COMMANDS`, `LISTS`, `REPORTABLE_VARIABLES`, `REPORTED_VARIABLES`, `SENDABLE_VARIABLES`}
+
+	msdpModule MSDPModule = MSDPModule{}
+)
+
+func init() {
+
+	msdpModule = MSDPModule{
+		plug: plugins.New(`msdp`, `1.0`),
+	}
+
+	msdpModule.cache, _ = lru.New[uint64, *MSDPClient](128)
+
+	msdpModule.plug.Callbacks.SetIACHandler(msdpModule.HandleIAC)
+	msdpModule.plug.Callbacks.SetOnNetConnect(msdpModule.onNetConnect)
+	msdpModule.plug.Callbacks.SetOnCopyoverSave(msdpModule.onCopyoverSave)
+	msdpModule.plug.Callbacks.SetOnCopyoverRestore(msdpModule.onCopyoverRestore)
+
+	events.RegisterListener(MSDPSend{}, msdpModule.dispatchMSDP)
+	events.RegisterListener(events.NewRound{}, msdpModule.reportChanges)
+}
+
+// ///////////////////
+// EVENTS
+// ///////////////////
+
+// Sends the current value of variables to a connection.
+// Values are built from the game loop, since they read from the world.
+type MSDPSend struct {
+	ConnectionId uint64
+	Variables    []string
+}
+
+func (m MSDPSend) Type() string { return `MSDPSend` }
+
+// ///////////////////
+// END EVENTS
+// ///////////////////
+
+type MSDPModule struct {
+	plug  *plugins.Plugin
+	cache *lru.Cache[uint64, *MSDPClient]
+}
+
+// Per connection MSDP state
+type MSDPClient struct {
+	lock     sync.Mutex
+	accepted bool              // Did the client respond with IAC DO MSDP?
+	reported []string          // Variables the client wants updates of
+	sent     map[string]string // The last encoded value sent of each reported variable
+}
+
+func (c *MSDPClient) isAccepted() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.accepted
+}
+
+func (c *MSDPClient) report(names ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, name := range names {
+		if !slices.Contains(c.reported, name) {
+			c.reported = append(c.reported, name)
+		}
+	}
+}
+
+func (c *MSDPClient) unreport(names ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, name := range names {
+		c.reported = slices.DeleteFunc(c.reported, func(n string) bool { return n == name })
+		delete(c.sent, name)
+	}
+}
+
+func (c *MSDPClient) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.reported = []string{}
+	c.sent = map[string]string{}
+}
+
+func (c *MSDPClient) reportedVariables() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return slices.Clone(c.reported)
+}
+
+// changed returns true if value differs from what was last sent, and remembers it if it is reported
+func (c *MSDPClient) changed(name string, encoded []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !slices.Contains(c.reported, name) {
+		return true
+	}
+
+	if c.sent[name] == string(encoded) {
+		return false
+	}
+
+	c.sent[name] = string(encoded)
+	return true
+}
+
+func newMSDPClient() *MSDPClient {
+	return &MSDPClient{
+		reported: []string{},
+		sent:     map[string]string{},
+	}
+}
+
+func (m *MSDPModule) onNetConnect(n plugins.NetConnection) {
+
+	// The web client uses GMCP
+	if n.IsWebSocket() {
+		return
+	}
+
+	m.cache.Add(n.ConnectionId(), newMSDPClient())
+
+	connections.SendTo(
+		MsdpEnable.BytesWithPayload(nil),
+		n.ConnectionId(),
+	)
+}
+
+// What is kept of a client across a copyover
+type msdpCopyover struct {
+	Accepted bool
+	Reported []string
+}
+
+func (m *MSDPModule) onCopyoverSave(connectionId uint64) []byte {
+
+	client, ok := m.cache.Get(connectionId)
+	if !ok {
+		return nil
+	}
+
+	client.lock.Lock()
+	saved := msdpCopyover{Accepted: client.accepted, Reported: slices.Clone(client.reported)}
+	client.lock.Unlock()
+
+	data, _ := json.Marshal(saved)
+	return data
+}
+
+// Nothing has been sent by this process yet, so every reported variable goes out on the next round
+func (m *MSDPModule) onCopyoverRestore(connectionId uint64, data []byte) {
+
+	saved := msdpCopyover{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		mudlog.Error("msdp", "error", "copyover restore: "+err.Error())
+		return
+	}
+
+	client := newMSDPClient()
+	client.accepted = saved.Accepted
+	client.report(saved.Reported...)
+
+	m.cache.Add(connectionId, client)
+}
+
+func (m *MSDPModule) isMSDPCommand(b []byte) bool {
+	return len(b) > 2 && b[0] == term.TELNET_IAC && b[2] == TELNET_MSDP
+}
+
+func (m *MSDPModule) HandleIAC(connectionId uint64, iacCmd []byte) bool {
+
+	if !m.isMSDPCommand(iacCmd) {
+		return false
+	}
+
+	client, ok := m.cache.Get(connectionId)
+	if !ok {
+		client = newMSDPClient()
+		m.cache.Add(connectionId, client)
+	}
+
+	if ok, payload := term.Matches(iacCmd, MsdpAccept); ok {
+
+		client.lock.Lock()
+		client.accepted = true
+		client.lock.Unlock()
+
+		mudlog.Debug("Received", "type", "IAC (Client-MSDP Accept)", "data", term.BytesString(payload))
+		return true
+	}
+
+	if ok, payload := term.Matches(iacCmd, MsdpRefuse); ok {
+
+		client.lock.Lock()
+		client.accepted = false
+		client.lock.Unlock()
+
+		mudlog.Debug("Received", "type", "IAC (Client-MSDP Refuse)", "data", term.BytesString(payload))
+		return true
+	}
+
+	ok, payload := term.Matches(iacCmd, MsdpPayload)
+	if !ok {
+		mudlog.Debug("Received", "type", "IAC (MSDP Unhandled)", "data", term.BytesString(iacCmd))
+		return true
+	}
+
+	if !client.isAccepted() {
+		return true
+	}
+
+	for _, cmd := range parseCommands(payload) {
+
+		mudlog.Debug("Received", "type", "MSDP (Handling)", "command", cmd.Name, "values", strings.Join(cmd.Values, `, `))
+
+		switch strings.ToUpper(cmd.Name) {
+
+		case `LIST`:
+			for _, listName := range cmd.Values {
+				m.sendList(connectionId, client, strings.ToUpper(listName))
+			}
+
+		case `REPORT`:
+			names := knownVariables(cmd.Values)
+			client.report(names...)
+			// Reported variables are sent right away, then only when they change
+			events.AddToQueue(MSDPSend{ConnectionId: connectionId, Variables: names})
+
+		case `UNREPORT`:
+			client.unreport(knownVariables(cmd.Values)...)
+
+		case `RESET`:
+			for _, listName := range cmd.Values {
+				switch strings.ToUpper(listName) {
+				case `REPORTABLE_VARIABLES`, `REPORTED_VARIABLES`:
+					client.reset()
+				}
+			}
+
+		case `SEND`:
+			events.AddToQueue(MSDPSend{ConnectionId: connectionId, Variables: knownVariables(cmd.Values)})
+
+		}
+	}
+
+	return true
+}
+
+// Returns the names that are MSDP variables, in their proper case
+func knownVariables(names []string) []string {
+	known := []string{}
+	for _, name := range names {
+		if v, ok := getVariable(name); ok {
+			known = append(known, v.Name)
+		}
+	}
+	return known
+}
+
+func (m *MSDPModule) sendList(connectionId uint64, client *MSDPClient, listName string) {
+
+	var list []string
+
+	switch listName {
+	case `COMMANDS`:
+		list = msdpCommands
+	case `LISTS`:
+		list = msdpLists
+	case `REPORTABLE_VARIABLES`, `SENDABLE_VARIABLES`:
+		list = variableNames()
+	case `REPORTED_VARIABLES`:
+		list = client.reportedVariables()
+	default:
+		return
+	}
+
+	connections.SendTo(
+		MsdpPayload.BytesWithPayload(encodeVariable(listName, list)),
+		connectionId,
+	)
+}
+
+// Sends requested variables, whether or not they changed
+func (m *MSDPModule) dispatchMSDP(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(MSDPSend)
+	if !typeOk {
+		mudlog.Error("Event", "Expected Type", "MSDPSend", "Actual Type", e.Type())
+		return events.Cancel
+	}
+
+	client, ok := m.cache.Get(evt.ConnectionId)
+	if !ok || !client.isAccepted() {
+		return events.Continue
+	}
+
+	user := users.GetByConnectionId(evt.ConnectionId)
+	values := buildValues(user, evt.Variables)
+
+	payload := []byte{}
+	for _, name := range evt.Variables {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		encoded := encodeVariable(name, value)
+		client.changed(name, encoded)
+		payload = append(payload, encoded...)
+	}
+
+	if len(payload) > 0 {
+		connections.SendTo(MsdpPayload.BytesWithPayload(payload), evt.ConnectionId)
+	}
+
+	return events.Continue
+}
+
+// Each round, sends every reported variable that has changed since it was last sent
+func (m *MSDPModule) reportChanges(e events.Event) events.ListenerReturn {
+
+	for _, user := range users.GetAllActiveUsers() {
+
+		connectionId := user.ConnectionId()
+
+		client, ok := m.cache.Get(connectionId)
+		if !ok || !client.isAccepted() {
+			continue
+		}
+
+		reported := client.reportedVariables()
+		if len(reported) == 0 {
+			continue
+		}
+
+		values := buildValues(user, reported)
+
+		payload := []byte{}
+		for _, name := range reported {
+			value, ok := values[name]
+			if !ok {
+				continue
+			}
+			if encoded := encodeVariable(name, value); client.changed(name, encoded) {
+				payload = append(payload, encoded...)
+			}
+		}
+
+		if len(payload) > 0 {
+			connections.SendTo(MsdpPayload.BytesWithPayload(payload), connectionId)
+		}
+	}
+
+	return events.Continue
+}
@@ -0,0 +1,234 @@
+package msdp
+
+import (
+	"strings"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/modules/gmcp"
+)
+
+//
+// MSDP variables are built from the same data the GMCP module sends,
+// so both protocols always agree on what a player sees.
+// Each variable names the GMCP node it comes from, so that only the
+// nodes actually asked for are built.
+//
+
+type msdpVariable struct {
+	Name string
+	Node string
+}
+
+// In the order they are listed to clients
+var msdpVariables = []msdpVariable{
+	{`SERVER_ID`, `Server`},
+
+	{`ACCOUNT_NAME`, `Char.Info`},
+	{`CHARACTER_NAME`, `Char.Info`},
+	{`RACE`, `Char.Info`},
+	{`CLASS`, `Char.Info`},
+	{`ALIGNMENT`, `Char.Info`},
+	{`LEVEL`, `Char.Info`},
+
+	{`HEALTH`, `Char.Vitals`},
+	{`HEALTH_MAX`, `Char.Vitals`},
+	{`MANA`, `Char.Vitals`},
+	{`MANA_MAX`, `Char.Vitals`},
+
+	{`EXPERIENCE`, `Char.Worth`},
+	{`EXPERIENCE_TNL`, `Char.Worth`},
+	{`GOLD`, `Char.Worth`},
+	{`BANK`, `Char.Worth`},
+	{`TRAINING_POINTS`, `Char.Worth`},
+	{`SKILL_POINTS`, `Char.Worth`},
+
+	{`STRENGTH`, `Char.Stats`},
+	{`SPEED`, `Char.Stats`},
+	{`SMARTS`, `Char.Stats`},
+	{`VITALITY`, `Char.Stats`},
+	{`MYSTICISM`, `Char.Stats`},
+	{`PERCEPTION`, `Char.Stats`},
+
+	{`AFFECTS`, `Char.Affects`},
+
+	{`OPPONENT_NAME`, `Char.Enemies`},
+	{`OPPONENT_LEVEL`, `Char.Enemies`},
+	{`OPPONENT_HEALTH`, `Char.Enemies`},
+	{`OPPONENT_HEALTH_MAX`, `Char.Enemies`},
+
+	{`ROOM`, `Room.Info`},
+	{`ROOM_VNUM`, `Room.Info`},
+	{`ROOM_NAME`, `Room.Info`},
+	{`ROOM_AREA`, `Room.Info`},
+	{`ROOM_TERRAIN`, `Room.Info`},
+	{`ROOM_COORDS`, `Room.Info`},
+	{`ROOM_EXITS`, `Room.Info`},
+}
+
+func variableNames() []string {
+	names := make([]string, len(msdpVariables))
+	for i, v := range msdpVariables {
+		names[i] = v.Name
+	}
+	return names
+}
+
+func getVariable(name string) (msdpVariable, bool) {
+	name = strings.ToUpper(name)
+	for _, v := range msdpVariables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return msdpVariable{}, false
+}
+
+// buildValues returns the current value of each variable named.
+// Unknown names are left out, as is everything but SERVER_ID when there is no user yet.
+func buildValues(user *users.UserRecord, names []string) map[string]any {
+
+	nodes := map[string]map[string]any{}
+	values := map[string]any{}
+
+	for _, name := range names {
+
+		v, ok := getVariable(name)
+		if !ok {
+			continue
+		}
+
+		if _, ok := nodes[v.Node]; !ok {
+			nodes[v.Node] = buildNode(user, v.Node)
+		}
+
+		if value, ok := nodes[v.Node][v.Name]; ok {
+			values[v.Name] = value
+		}
+	}
+
+	return values
+}
+
+// buildNode turns a GMCP node into the MSDP variables that come from it
+func buildNode(user *users.UserRecord, node string) map[string]any {
+
+	if node == `Server` {
+		return map[string]any{
+			`SERVER_ID`: string(configs.GetServerConfig().MudName),
+		}
+	}
+
+	if user == nil || user.Character == nil {
+		return map[string]any{}
+	}
+
+	if strings.HasPrefix(node, `Room.`) {
+		return buildRoomNode(user)
+	}
+
+	data, _ := (&gmcp.GMCPCharModule{}).GetCharNode(user, node)
+
+	switch payload := data.(type) {
+
+	case *gmcp.GMCPCharModule_Payload_Info:
+		return map[string]any{
+			`ACCOUNT_NAME`:   payload.Account,
+			`CHARACTER_NAME`: payload.Name,
+			`RACE`:           payload.Race,
+			`CLASS`:          payload.Class,
+			`ALIGNMENT`:      payload.Alignment,
+			`LEVEL`:          payload.Level,
+		}
+
+	case *gmcp.GMCPCharModule_Payload_Vitals:
+		return map[string]any{
+			`HEALTH`:     payload.Hp,
+			`HEALTH_MAX`: payload.HpMax,
+			`MANA`:       payload.Sp,
+			`MANA_MAX`:   payload.SpMax,
+		}
+
+	case *gmcp.GMCPCharModule_Payload_Worth:
+		return map[string]any{
+			`EXPERIENCE`:      payload.XP,
+			`EXPERIENCE_TNL`:  payload.TNL,
+			`GOLD`:            payload.Gold,
+			`BANK`:            payload.Bank,
+			`TRAINING_POINTS`: payload.TrainingPoints,
+			`SKILL_POINTS`:    payload.SkillPoints,
+		}
+
+	case *gmcp.GMCPCharModule_Payload_Stats:
+		return map[string]any{
+			`STRENGTH`:   payload.Strength,
+			`SPEED`:      payload.Speed,
+			`SMARTS`:     payload.Smarts,
+			`VITALITY`:   payload.Vitality,
+			`MYSTICISM`:  payload.Mysticism,
+			`PERCEPTION`: payload.Perception,
+		}
+
+	case map[string]gmcp.GMCPCharModule_Payload_Affect:
+		// Affect name to seconds left, -1 for permanent
+		affects := msdpTable{}
+		for name, aff := range payload {
+			affects[name] = aff.DurationLeft
+		}
+		return map[string]any{
+			`AFFECTS`: affects,
+		}
+
+	case []gmcp.GMCPCharModule_Enemy:
+		// The one being fought, otherwise whoever is first
+		opponent := gmcp.GMCPCharModule_Enemy{}
+		for i, e := range payload {
+			if i == 0 || e.Engaged {
+				opponent = e
+			}
+			if e.Engaged {
+				break
+			}
+		}
+		return map[string]any{
+			`OPPONENT_NAME`:       opponent.Name,
+			`OPPONENT_LEVEL`:      opponent.Level,
+			`OPPONENT_HEALTH`:     opponent.Hp,
+			`OPPONENT_HEALTH_MAX`: opponent.MaxHp,
+		}
+	}
+
+	return map[string]any{}
+}
+
+func buildRoomNode(user *users.UserRecord) map[string]any {
+
+	data, _ := (&gmcp.GMCPRoomModule{}).GetRoomNode(user, `Room.Info`)
+
+	payload, ok := data.(gmcp.GMCPRoomModule_Payload)
+	if !ok {
+		return map[string]any{}
+	}
+
+	exits := msdpTable{}
+	for dir, roomId := range payload.Exits {
+		exits[dir] = roomId
+	}
+
+	return map[string]any{
+		`ROOM`: msdpTable{
+			`VNUM`:    payload.Id,
+			`NAME`:    payload.Name,
+			`AREA`:    payload.Area,
+			`TERRAIN`: payload.Environment,
+			`COORDS`:  payload.Coordinates,
+			`EXITS`:   exits,
+		},
+		`ROOM_VNUM`:    payload.Id,
+		`ROOM_NAME`:    payload.Name,
+		`ROOM_AREA`:    payload.Area,
+		`ROOM_TERRAIN`: payload.Environment,
+		`ROOM_COORDS`:  payload.Coordinates,
+		`ROOM_EXITS`:   exits,
+	}
+}
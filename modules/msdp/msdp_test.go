@@ -0,0 +1,120 @@
+package msdp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeVariable(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		varName  string
+		value    any
+		expected []byte
+	}{
+		{
+			name:     "String",
+			varName:  "ROOM_NAME",
+			value:    "Town Square",
+			expected: []byte("\x01ROOM_NAME\x02Town Square"),
+		},
+		{
+			name:     "Int",
+			varName:  "HEALTH",
+			value:    42,
+			expected: []byte("\x01HEALTH\x0242"),
+		},
+		{
+			name:     "Array",
+			varName:  "COMMANDS",
+			value:    []string{"LIST", "SEND"},
+			expected: []byte("\x01COMMANDS\x02\x05\x02LIST\x02SEND\x06"),
+		},
+		{
+			name:     "Table sorted by key",
+			varName:  "ROOM_EXITS",
+			value:    msdpTable{"west": 2, "east": 3},
+			expected: []byte("\x01ROOM_EXITS\x02\x03\x01east\x023\x01west\x022\x04"),
+		},
+		{
+			name:     "Control bytes removed",
+			varName:  "CHARACTER_NAME",
+			value:    "Bad\x01\xffName",
+			expected: []byte("\x01CHARACTER_NAME\x02BadName"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, encodeVariable(tt.varName, tt.value))
+		})
+	}
+}
+
+func TestParseCommands(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected []msdpCommand
+	}{
+		{
+			name:     "Single value",
+			input:    []byte("\x01LIST\x02COMMANDS"),
+			expected: []msdpCommand{{Name: "LIST", Values: []string{"COMMANDS"}}},
+		},
+		{
+			name:     "Several values",
+			input:    []byte("\x01REPORT\x02HEALTH\x02MANA"),
+			expected: []msdpCommand{{Name: "REPORT", Values: []string{"HEALTH", "MANA"}}},
+		},
+		{
+			name:     "Array",
+			input:    []byte("\x01SEND\x02\x05\x02HEALTH\x02ROOM\x06"),
+			expected: []msdpCommand{{Name: "SEND", Values: []string{"HEALTH", "ROOM"}}},
+		},
+		{
+			name:  "Several commands",
+			input: []byte("\x01REPORT\x02HEALTH\x01UNREPORT\x02MANA"),
+			expected: []msdpCommand{
+				{Name: "REPORT", Values: []string{"HEALTH"}},
+				{Name: "UNREPORT", Values: []string{"MANA"}},
+			},
+		},
+		{
+			name:     "Value without a variable",
+			input:    []byte("\x02HEALTH"),
+			expected: []msdpCommand{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseCommands(tt.input))
+		})
+	}
+}
+
+func TestMSDPClientChanged(t *testing.T) {
+
+	c := newMSDPClient()
+
+	// Variables that aren't reported are always sent
+	assert.True(t, c.changed("HEALTH", []byte("10")))
+	assert.True(t, c.changed("HEALTH", []byte("10")))
+
+	c.report("HEALTH")
+	assert.True(t, c.changed("HEALTH", []byte("10")))
+	assert.False(t, c.changed("HEALTH", []byte("10")))
+	assert.True(t, c.changed("HEALTH", []byte("9")))
+
+	// Reporting again after unreporting sends the value again
+	c.unreport("HEALTH")
+	c.report("HEALTH")
+	assert.True(t, c.changed("HEALTH", []byte("9")))
+
+	c.reset()
+	assert.Empty(t, c.reportedVariables())
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/GoMudEngine/GoMud/internal/backups"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/storage"
+)
+
+// runRestore puts back everything in a snapshot, or only one user or zone, while the server isn't running
+func runRestore(snapshot string, only string, name string) error {
+
+	defer storage.Close()
+
+	if only != `` && name == `` {
+		return fmt.Errorf(`-restore-only needs a name, such as %s:example`, only)
+	}
+
+	switch only {
+
+	case ``:
+		snap, restoredCt, err := backups.RestoreAll(snapshot)
+		if err != nil {
+			return err
+		}
+		mudlog.Info("Restore", "snapshot", snap.Name, "records", restoredCt)
+
+	case `user`:
+		snap, userId, err := backups.RestoreUser(snapshot, name)
+		if err != nil {
+			return err
+		}
+		mudlog.Info("Restore", "snapshot", snap.Name, "user", name, "userId", userId)
+
+	case `zone`:
+		snap, restoredCt, err := backups.RestoreZone(snapshot, rooms.ZoneToFolder(name))
+		if err != nil {
+			return err
+		}
+		mudlog.Info("Restore", "snapshot", snap.Name, "zone", name, "rooms", restoredCt)
+
+	default:
+		return fmt.Errorf(`-restore-only must be user:[name] or zone:[zone], not %s`, only)
+	}
+
+	return nil
+}
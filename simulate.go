@@ -0,0 +1,384 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GoMudEngine/GoMud/internal/configs"
+	"github.com/GoMudEngine/GoMud/internal/connections"
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mudlog"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/GoMudEngine/GoMud/internal/users"
+	"github.com/GoMudEngine/GoMud/internal/util"
+)
+
+//
+// -simulate runs the world for a number of rounds as fast as it can, with no listeners.
+// Each zone gets an adventurer at its root room who wanders the zone fighting whatever they
+// find and picking up gold. The clock and randomness come from the config seed, so the
+// same world and seed always play out the same way, and designers can compare changes to a zone.
+//
+// The world is copied somewhere temporary first, so nothing the simulation does is saved.
+//
+
+var (
+	// Where the simulated clock starts, so that timestamps are the same every run
+	simulationStart = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type simulation struct {
+	clock       *util.SimClock
+	adventurers map[int]*simAdventurer // by UserId
+	zones       map[string]*zoneSummary
+}
+
+type simAdventurer struct {
+	zone       string
+	homeRoomId int
+	gold       int // Gold held at the end of the last round
+}
+
+type zoneSummary struct {
+	Level   int // Level of the zone's adventurer
+	Kills   int // Mobs that died
+	Deaths  int // Times the adventurer died
+	XP      int
+	GoldIn  int
+	GoldOut int
+}
+
+// runSimulation loads a temporary copy of the world and runs it for some rounds, then writes a summary
+func runSimulation(rounds int, out io.Writer) error {
+
+	tmpDir, err := os.MkdirTemp(``, `gomud-simulate-`)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dataFiles := filepath.Join(tmpDir, `world`)
+	if err := os.CopyFS(dataFiles, os.DirFS(configs.GetFilePathsConfig().DataFiles.String())); err != nil {
+		return err
+	}
+
+	// No backup snapshots of a world that is thrown away
+	if err := configs.AddOverlayOverrides(map[string]any{`FilePaths.DataFiles`: dataFiles, `Backups.Period`: ``}); err != nil {
+		return err
+	}
+
+	c := configs.GetConfig()
+
+	util.SeedRand(c.SeedInt())
+
+	s := &simulation{
+		clock:       util.NewSimClock(simulationStart),
+		adventurers: map[int]*simAdventurer{},
+		zones:       map[string]*zoneSummary{},
+	}
+
+	util.SetClock(s.clock.Now)
+	defer util.SetClock(nil)
+
+	loadWorld(c)
+
+	util.LockMud()
+
+	events.RegisterListener(events.MobDeath{}, s.onMobDeath)
+	events.RegisterListener(events.PlayerDeath{}, s.onPlayerDeath)
+	events.RegisterListener(events.GainExperience{}, s.onGainExperience)
+
+	err = s.addAdventurers()
+
+	util.UnlockMud()
+
+	if err != nil {
+		return err
+	}
+
+	mudlog.Info("Simulate", "rounds", rounds, "zones", len(s.zones), "seed", c.SeedInt())
+
+	simStart := time.Now()
+	turnLength := time.Duration(c.Timing.TurnMs) * time.Millisecond
+	lastMaintenance := s.clock.Now()
+
+	startRound := util.GetRoundCount()
+	endRound := startRound + uint64(rounds)
+
+	for util.GetRoundCount() < endRound {
+
+		roundBefore := util.GetRoundCount()
+
+		util.LockMud()
+
+		now := s.clock.Advance(turnLength)
+
+		worldManager.advanceTurn()
+		worldManager.EventLoop()
+
+		if util.GetRoundCount() != roundBefore {
+			s.adventurersAct()
+		}
+
+		if now.Sub(lastMaintenance) >= roomMaintenancePeriod {
+			worldManager.roomMaintenance()
+			lastMaintenance = now
+		}
+
+		util.UnlockMud()
+	}
+
+	s.writeSummary(out, rounds, time.Since(simStart))
+
+	return nil
+}
+
+// addAdventurers puts an adventurer at the root room of every zone a player could be in.
+// Expects the mud lock to already be held.
+func (s *simulation) addAdventurers() error {
+
+	specialRooms := configs.GetSpecialRoomsConfig()
+
+	skipZones := map[string]struct{}{}
+	skipRoomIds := []int{int(specialRooms.DeathRecoveryRoom)}
+	for _, roomIdStr := range specialRooms.TutorialRooms {
+		if roomId, err := strconv.Atoi(roomIdStr); err == nil {
+			skipRoomIds = append(skipRoomIds, roomId)
+		}
+	}
+	for _, roomId := range skipRoomIds {
+		if room := rooms.LoadRoom(roomId); room != nil {
+			skipZones[room.Zone] = struct{}{}
+		}
+	}
+
+	zoneNames := rooms.GetAllZoneNames()
+	slices.Sort(zoneNames)
+
+	for _, zone := range zoneNames {
+
+		if _, ok := skipZones[zone]; ok {
+			continue
+		}
+
+		rootRoomId, err := rooms.GetZoneRoot(zone)
+		if err != nil || rooms.LoadRoom(rootRoomId) == nil {
+			continue
+		}
+
+		level := 1
+		if zoneConfig := rooms.GetZoneConfig(zone); zoneConfig != nil && zoneConfig.MobAutoScale.Minimum > 0 {
+			level = zoneConfig.MobAutoScale.Minimum
+		}
+
+		user, err := s.newAdventurer(fmt.Sprintf(`simadventurer%d`, len(s.adventurers)+1), rootRoomId, level)
+		if err != nil {
+			return fmt.Errorf("adventurer for %s: %w", zone, err)
+		}
+
+		s.adventurers[user.UserId] = &simAdventurer{
+			zone:       zone,
+			homeRoomId: rootRoomId,
+			gold:       user.Character.Gold,
+		}
+		s.zones[zone] = &zoneSummary{Level: level}
+	}
+
+	if len(s.adventurers) == 0 {
+		return errors.New("no zones to simulate")
+	}
+
+	return nil
+}
+
+// newAdventurer creates a character on a connection that goes nowhere, and brings them into the world
+func (s *simulation) newAdventurer(name string, roomId int, level int) (*users.UserRecord, error) {
+
+	connDetails := connections.Add(&connections.VirtualConn{Discard: true}, nil)
+	connDetails.SetState(connections.LoggedIn)
+
+	user := users.NewUserRecord(0, connDetails.ConnectionId())
+	if err := user.SetUsername(name); err != nil {
+		connections.Remove(connDetails.ConnectionId())
+		return nil, err
+	}
+	if err := user.SetPassword(name); err != nil {
+		connections.Remove(connDetails.ConnectionId())
+		return nil, err
+	}
+
+	user.Character.Name = name
+	user.Character.RaceId = 1
+	user.Character.RoomId = roomId
+	user.Character.Level = level
+	user.Character.Validate()
+	user.Character.Health = user.Character.HealthMax.Value
+	user.Character.Mana = user.Character.ManaMax.Value
+
+	if err := users.CreateUser(user); err != nil {
+		connections.Remove(connDetails.ConnectionId())
+		return nil, err
+	}
+
+	worldManager.enterWorld(user.UserId, roomId)
+
+	return user, nil
+}
+
+// adventurersAct decides what every adventurer does with the new round.
+// Expects the mud lock to already be held.
+func (s *simulation) adventurersAct() {
+
+	deathRoomId := int(configs.GetSpecialRoomsConfig().DeathRecoveryRoom)
+	roundNow := util.GetRoundCount()
+
+	for _, userId := range slices.Sorted(maps.Keys(s.adventurers)) {
+
+		a := s.adventurers[userId]
+
+		user := users.GetByUserId(userId)
+		if user == nil {
+			continue
+		}
+
+		if goldDelta := user.Character.Gold - a.gold; goldDelta > 0 {
+			s.zones[a.zone].GoldIn += goldDelta
+		} else if goldDelta < 0 {
+			s.zones[a.zone].GoldOut -= goldDelta
+		}
+		a.gold = user.Character.Gold
+
+		// Nobody is at the keyboard, but they're never idle
+		user.SetLastInputRound(roundNow)
+
+		if user.Character.Aggro != nil || user.Character.Health <= 0 {
+			continue
+		}
+
+		room := rooms.LoadRoom(user.Character.RoomId)
+
+		// After dying, or wandering off, go straight back to where they started
+		if room == nil || room.RoomId == deathRoomId || room.Zone != a.zone {
+			rooms.MoveToRoom(userId, a.homeRoomId)
+			user.Character.Health = user.Character.HealthMax.Value
+			user.Character.Mana = user.Character.ManaMax.Value
+			continue
+		}
+
+		// Wait to heal up
+		if user.Character.Health < user.Character.HealthMax.Value/3 {
+			continue
+		}
+
+		if room.Gold > 0 {
+			user.Command(`get gold`)
+			continue
+		}
+
+		targets := []int{}
+		for _, mobInstanceId := range room.GetMobs() {
+			if mob := mobs.GetInstance(mobInstanceId); mob != nil && !mob.Character.IsCharmed() {
+				targets = append(targets, mobInstanceId)
+			}
+		}
+
+		if len(targets) > 0 {
+			user.Command(fmt.Sprintf(`attack #%d`, targets[util.Rand(len(targets))]))
+			continue
+		}
+
+		exitNames := []string{}
+		for exitName, exitInfo := range room.Exits {
+			if exitInfo.Secret || exitInfo.HasLock() {
+				continue
+			}
+			if toRoom := rooms.LoadRoom(exitInfo.RoomId); toRoom != nil && toRoom.Zone == a.zone {
+				exitNames = append(exitNames, exitName)
+			}
+		}
+
+		if len(exitNames) > 0 {
+			slices.Sort(exitNames)
+			user.Command(exitNames[util.Rand(len(exitNames))])
+		}
+	}
+}
+
+func (s *simulation) onMobDeath(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.MobDeath)
+	if !typeOk {
+		return events.Continue
+	}
+
+	if room := rooms.LoadRoom(evt.RoomId); room != nil {
+		if zs, ok := s.zones[room.Zone]; ok {
+			zs.Kills++
+		}
+	}
+
+	return events.Continue
+}
+
+func (s *simulation) onPlayerDeath(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.PlayerDeath)
+	if !typeOk {
+		return events.Continue
+	}
+
+	if a, ok := s.adventurers[evt.UserId]; ok {
+		s.zones[a.zone].Deaths++
+	}
+
+	return events.Continue
+}
+
+func (s *simulation) onGainExperience(e events.Event) events.ListenerReturn {
+
+	evt, typeOk := e.(events.GainExperience)
+	if !typeOk {
+		return events.Continue
+	}
+
+	if a, ok := s.adventurers[evt.UserId]; ok {
+		s.zones[a.zone].XP += evt.Experience
+	}
+
+	return events.Continue
+}
+
+func (s *simulation) writeSummary(out io.Writer, rounds int, took time.Duration) {
+
+	fmt.Fprintf(out, "Simulated %d rounds (%s of game time) in %s\n\n", rounds, s.clock.Now().Sub(simulationStart), took.Round(time.Millisecond))
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Zone\tLevel\tKills\tDeaths\tXP\tGold In\tGold Out\t")
+
+	total := zoneSummary{}
+
+	zoneNames := slices.Sorted(maps.Keys(s.zones))
+	for _, zone := range zoneNames {
+		zs := s.zones[zone]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t\n", zone, zs.Level, zs.Kills, zs.Deaths, zs.XP, zs.GoldIn, zs.GoldOut)
+
+		total.Kills += zs.Kills
+		total.Deaths += zs.Deaths
+		total.XP += zs.XP
+		total.GoldIn += zs.GoldIn
+		total.GoldOut += zs.GoldOut
+	}
+
+	fmt.Fprintf(tw, "Total\t\t%d\t%d\t%d\t%d\t%d\t\n", total.Kills, total.Deaths, total.XP, total.GoldIn, total.GoldOut)
+
+	tw.Flush()
+}
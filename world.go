@@ -17,6 +17,7 @@ import (
 	"github.com/GoMudEngine/GoMud/internal/keywords"
 	"github.com/GoMudEngine/GoMud/internal/mobcommands"
 	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/mssp"
 	"github.com/GoMudEngine/GoMud/internal/mudlog"
 	"github.com/GoMudEngine/GoMud/internal/prompt"
 	"github.com/GoMudEngine/GoMud/internal/rooms"
@@ -726,6 +727,7 @@ func (w *World) MainWorker(shutdown chan bool, wg *sync.WaitGroup) {
 	eventLoopTimer := time.NewTimer(time.Millisecond)
 	turnTimer := time.NewTimer(time.Duration(c.Timing.TurnMs) * time.Millisecond)
 	statsTimer := time.NewTimer(time.Duration(10) * time.Second)
+	drift := turnDrift{}
 
 loop:
 	for {
@@ -766,8 +768,7 @@ loop:
 
 			// TODO: Move this to events
 			util.LockMud()
-			scripting.PruneRoomVMs(rooms.RoomMaintenance()...)
-			scripting.PruneRoomVMs(rooms.EphemeralRoomMaintenance()...)
+			w.roomMaintenance()
 			util.UnlockMud()
 
 			roomUpdateTimer.Reset(roomMaintenancePeriod)
@@ -794,17 +795,8 @@ loop:
 			util.LockMud()
 			turnTimer.Reset(time.Duration(c.Timing.TurnMs) * time.Millisecond)
 
-			turnCt := util.IncrementTurnCount()
-
-			events.AddToQueue(events.NewTurn{TurnNumber: turnCt, TimeNow: time.Now()})
-
-			// After a full round of turns, we can do a round tick.
-			if turnCt%uint64(c.Timing.TurnsPerRound()) == 0 {
-
-				roundNumber := util.IncrementRoundCount()
-
-				events.AddToQueue(events.NewRound{RoundNumber: roundNumber, TimeNow: time.Now()})
-			}
+			w.advanceTurn()
+			drift.record(time.Now())
 
 			util.UnlockMud()
 
@@ -848,6 +840,30 @@ loop:
 
 }
 
+// roomMaintenance unloads rooms nobody is using, and the scripts that went with them.
+// The mud lock must already be held.
+func (w *World) roomMaintenance() {
+	scripting.PruneRoomVMs(rooms.RoomMaintenance()...)
+	scripting.PruneRoomVMs(rooms.EphemeralRoomMaintenance()...)
+}
+
+// advanceTurn queues the next turn, and the next round after a full round of turns.
+// The mud lock must already be held.
+func (w *World) advanceTurn() {
+
+	turnCt := util.IncrementTurnCount()
+
+	events.AddToQueue(events.NewTurn{TurnNumber: turnCt, TimeNow: util.Now()})
+
+	// After a full round of turns, we can do a round tick.
+	if turnCt%uint64(configs.GetTimingConfig().TurnsPerRound()) == 0 {
+
+		roundNumber := util.IncrementRoundCount()
+
+		events.AddToQueue(events.NewRound{RoundNumber: roundNumber, TimeNow: util.Now()})
+	}
+}
+
 // Should be goroutine/threadsafe
 // Only reads from world channel
 func (w *World) InputWorker(shutdown chan bool, wg *sync.WaitGroup) {
@@ -963,7 +979,7 @@ func (w *World) processInput(userId int, inputText string, flags events.EventFla
 
 	} else {
 		connId := user.ConnectionId()
-		connections.SendTo([]byte(templates.AnsiParse(user.GetCommandPrompt())), connId)
+		connections.SendTo([]byte(templates.AnsiParse(user.GetCommandPrompt(), connId)), connId)
 	}
 
 	if !handled {
@@ -979,7 +995,18 @@ func (w *World) processInput(userId int, inputText string, flags events.EventFla
 	// If they had an input prompt, but now they don't, lets make sure to resend a status prompt
 	if hadPrompt || (!hadPrompt && user.GetPrompt() != nil) {
 		connId := user.ConnectionId()
-		connections.SendTo([]byte(templates.AnsiParse(user.GetCommandPrompt())), connId)
+		connections.SendTo([]byte(templates.AnsiParse(user.GetCommandPrompt(), connId)), connId)
+	}
+
+	// Keep the reply to a password prompt out of session recordings
+	if cd := connections.Get(user.ConnectionId()); cd != nil {
+		masked := false
+		if cmdPrompt := user.GetPrompt(); cmdPrompt != nil {
+			if q := cmdPrompt.GetNextQuestion(); q != nil {
+				masked = q.IsMasked()
+			}
+		}
+		cd.SetInputMasked(masked)
 	}
 	// Removing this as possibly redundant.
 	// Leaving in case I need to remember that I did it...
@@ -1060,6 +1087,8 @@ func (w *World) UpdateStats() {
 	s.WebSocketPort = int(c.HttpPort)
 
 	web.UpdateStats(s)
+
+	mssp.Update()
 }
 
 // Force disconnect a user (Makes them a zombie)
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/GoMudEngine/GoMud/internal/events"
+	"github.com/GoMudEngine/GoMud/internal/mobs"
+	"github.com/GoMudEngine/GoMud/internal/rooms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorld_PlayersSeeEachOther(t *testing.T) {
+	h := NewHarness(t, harnessWorld)
+
+	alice := h.NewPlayer(`Alice`, 1)
+	bob := h.NewPlayer(`Bob`, 1)
+
+	require.Len(t, EventsOf[events.PlayerSpawn](h), 2)
+	assert.Contains(t, alice.Output(), `Bob`, "Alice sees Bob arrive")
+
+	alice.ClearOutput()
+	bob.ClearOutput()
+
+	bob.Do(`say hello there`)
+
+	assert.Contains(t, bob.Output(), `hello there`)
+	assert.Contains(t, alice.Output(), `Bob says, "hello there"`)
+
+	// Moving takes action points, which build up over turns
+	h.Rounds(1)
+	bob.Do(`north`)
+
+	assert.Equal(t, 2, bob.Character.RoomId)
+	assert.Contains(t, bob.Output(), `End of the Line`)
+	assert.Contains(t, alice.Output(), `Bob leaves towards the north exit`)
+}
+
+func TestWorld_KillRat(t *testing.T) {
+	h := NewHarness(t, harnessWorld)
+
+	p := h.NewPlayer(`Ratcatcher`, 2)
+	p.Character.Level = 10
+	p.Character.Validate()
+	p.Character.Health = p.Character.HealthMax.Value
+
+	room := rooms.LoadRoom(2)
+	h.RoundsUntil(10, func() bool {
+		return len(room.GetMobs()) > 0
+	})
+
+	rat := mobs.GetInstance(room.GetMobs()[0])
+	require.NotNil(t, rat)
+
+	p.Do(`attack rat`)
+
+	h.RoundsUntil(50, func() bool {
+		return len(EventsOf[events.MobDeath](h)) > 0
+	})
+
+	deaths := EventsOf[events.MobDeath](h)
+	assert.Equal(t, rat.InstanceId, deaths[0].InstanceId)
+	assert.NotEmpty(t, EventsOf[events.GainExperience](h))
+	assert.Contains(t, p.Output(), `rat`)
+}